@@ -0,0 +1,298 @@
+package einvoice
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// VATScenario is the VAT treatment of invoice lines and document level
+// allowances and charges: the VAT category code (BT-151, BT-95, BT-102), the
+// rate and, for categories without VAT, the exemption reason (BT-120, BT-121)
+// of the VAT breakdown.
+type VATScenario struct {
+	CategoryCode        string
+	Percent             decimal.Decimal
+	ExemptionReason     string
+	ExemptionReasonCode string
+}
+
+// Common VAT scenarios with their VATEX exemption reason codes.
+var (
+	// VATReverseCharge is the reverse charge (AE): the buyer accounts for the
+	// VAT. Seller and buyer need a VAT identifier (BR-AE-02).
+	VATReverseCharge = VATScenario{CategoryCode: "AE", ExemptionReason: "Reverse charge", ExemptionReasonCode: "VATEX-EU-AE"}
+	// VATIntraCommunitySupply is the VAT exempt intra-community supply of
+	// goods (K). Seller and buyer need a VAT identifier (BR-IC-02), the
+	// invoice a delivery date or invoicing period (BR-IC-11) and the
+	// deliver to country (BR-IC-12).
+	VATIntraCommunitySupply = VATScenario{CategoryCode: "K", ExemptionReason: "Intra-community supply", ExemptionReasonCode: "VATEX-EU-IC"}
+	// VATExport is the export outside the EU (G). The seller needs a VAT
+	// identifier (BR-G-02).
+	VATExport = VATScenario{CategoryCode: "G", ExemptionReason: "Export outside the EU", ExemptionReasonCode: "VATEX-EU-G"}
+	// VATNotSubject is used for supplies not subject to VAT (O).
+	VATNotSubject = VATScenario{CategoryCode: "O", ExemptionReason: "Not subject to VAT", ExemptionReasonCode: "VATEX-EU-O"}
+)
+
+// VATStandard returns the standard rated scenario (S) with the given rate.
+func VATStandard(percent decimal.Decimal) VATScenario {
+	return VATScenario{CategoryCode: "S", Percent: percent}
+}
+
+// VATExempt returns the scenario exempt from VAT (E) with the exemption reason
+// text and code, such as VATEX-EU-132-1I.
+func VATExempt(reason, code string) VATScenario {
+	return VATScenario{CategoryCode: "E", ExemptionReason: reason, ExemptionReasonCode: code}
+}
+
+// InvoiceBuilder creates an invoice step by step, see NewInvoice. The methods
+// return the builder so that calls can be chained.
+type InvoiceBuilder struct {
+	inv         *Invoice
+	vat         *VATScenario
+	scenarios   map[string]VATScenario // exemption reasons per VAT category code
+	opts        *CalculationOptions
+	prepayments []*Invoice
+}
+
+// NewInvoice returns a builder for a commercial invoice (type code 380) with
+// the specification identifier profile (BT-24) such as SpecEN16931 or
+// SpecXRechnung30. For SpecPEPPOLBilling30 the business process (BT-23) is
+// set to BPPEPPOLBilling01 and the syntax to UBL.
+//
+//	inv, err := einvoice.NewInvoice(einvoice.SpecEN16931).
+//		Number("RE-2025-001").
+//		Date(time.Now()).
+//		Currency("EUR").
+//		Seller(seller).
+//		Buyer(buyer).
+//		VAT(einvoice.VATStandard(decimal.NewFromInt(19))).
+//		AddLine(einvoice.InvoiceLine{ItemName: "Consulting", BilledQuantity: decimal.NewFromInt(8), NetPrice: decimal.NewFromInt(120)}).
+//		Build()
+func NewInvoice(profile string) *InvoiceBuilder {
+	b := &InvoiceBuilder{
+		inv: &Invoice{
+			GuidelineSpecifiedDocumentContextParameter: profile,
+			InvoiceTypeCode: 380,
+		},
+		scenarios: map[string]VATScenario{},
+	}
+	for _, s := range []VATScenario{VATReverseCharge, VATIntraCommunitySupply, VATExport, VATNotSubject} {
+		b.scenarios[s.CategoryCode] = s
+	}
+	if profile == SpecPEPPOLBilling30 {
+		b.inv.BPSpecifiedDocumentContextParameter = BPPEPPOLBilling01
+		b.inv.SchemaType = UBL
+	}
+	return b
+}
+
+// Number sets the invoice number (BT-1).
+func (b *InvoiceBuilder) Number(number string) *InvoiceBuilder {
+	b.inv.InvoiceNumber = number
+	return b
+}
+
+// Date sets the invoice issue date (BT-2).
+func (b *InvoiceBuilder) Date(date time.Time) *InvoiceBuilder {
+	b.inv.InvoiceDate = date
+	return b
+}
+
+// TypeCode sets the invoice type code (BT-3), 380 if not set.
+func (b *InvoiceBuilder) TypeCode(code CodeDocument) *InvoiceBuilder {
+	b.inv.InvoiceTypeCode = code
+	return b
+}
+
+// Currency sets the invoice currency code (BT-5).
+func (b *InvoiceBuilder) Currency(code string) *InvoiceBuilder {
+	b.inv.InvoiceCurrencyCode = code
+	return b
+}
+
+// Syntax sets the syntax the invoice is written in (CII or UBL).
+func (b *InvoiceBuilder) Syntax(syntax CodeSchemaType) *InvoiceBuilder {
+	b.inv.SchemaType = syntax
+	return b
+}
+
+// BuyerReference sets the buyer reference (BT-10), the Leitweg-ID for
+// XRechnung.
+func (b *InvoiceBuilder) BuyerReference(ref string) *InvoiceBuilder {
+	b.inv.BuyerReference = ref
+	return b
+}
+
+// Note adds an invoice note (BG-1).
+func (b *InvoiceBuilder) Note(text string) *InvoiceBuilder {
+	b.inv.Notes = append(b.inv.Notes, Note{Text: text})
+	return b
+}
+
+// Seller sets the seller (BG-4).
+func (b *InvoiceBuilder) Seller(seller Party) *InvoiceBuilder {
+	b.inv.Seller = seller
+	return b
+}
+
+// Buyer sets the buyer (BG-7).
+func (b *InvoiceBuilder) Buyer(buyer Party) *InvoiceBuilder {
+	b.inv.Buyer = buyer
+	return b
+}
+
+// ShipTo sets the deliver to party and address (BG-13).
+func (b *InvoiceBuilder) ShipTo(party Party) *InvoiceBuilder {
+	b.inv.ShipTo = &party
+	return b
+}
+
+// DeliveryDate sets the actual delivery date (BT-72).
+func (b *InvoiceBuilder) DeliveryDate(date time.Time) *InvoiceBuilder {
+	b.inv.OccurrenceDateTime = date
+	return b
+}
+
+// BillingPeriod sets the invoicing period (BT-73, BT-74).
+func (b *InvoiceBuilder) BillingPeriod(start, end time.Time) *InvoiceBuilder {
+	b.inv.BillingSpecifiedPeriodStart = start
+	b.inv.BillingSpecifiedPeriodEnd = end
+	return b
+}
+
+// PaymentMeans adds a payment instruction (BG-16).
+func (b *InvoiceBuilder) PaymentMeans(pm PaymentMeans) *InvoiceBuilder {
+	b.inv.PaymentMeans = append(b.inv.PaymentMeans, pm)
+	return b
+}
+
+// PaymentTerms adds payment terms (BT-20) with the due date (BT-9).
+func (b *InvoiceBuilder) PaymentTerms(terms SpecifiedTradePaymentTerms) *InvoiceBuilder {
+	b.inv.SpecifiedTradePaymentTerms = append(b.inv.SpecifiedTradePaymentTerms, terms)
+	return b
+}
+
+// VAT sets the VAT scenario of the following lines, allowances and charges
+// without a VAT category code. Its exemption reason is used for the VAT
+// breakdown of the category.
+func (b *InvoiceBuilder) VAT(s VATScenario) *InvoiceBuilder {
+	b.vat = &s
+	if s.ExemptionReason != "" || s.ExemptionReasonCode != "" {
+		b.scenarios[s.CategoryCode] = s
+	}
+	return b
+}
+
+// ReverseCharge is a shortcut for VAT(VATReverseCharge).
+func (b *InvoiceBuilder) ReverseCharge() *InvoiceBuilder {
+	return b.VAT(VATReverseCharge)
+}
+
+// IntraCommunitySupply is a shortcut for VAT(VATIntraCommunitySupply).
+func (b *InvoiceBuilder) IntraCommunitySupply() *InvoiceBuilder {
+	return b.VAT(VATIntraCommunitySupply)
+}
+
+// Export is a shortcut for VAT(VATExport).
+func (b *InvoiceBuilder) Export() *InvoiceBuilder {
+	return b.VAT(VATExport)
+}
+
+// AddLine adds an invoice line (BG-25). The line ID (BT-126) defaults to the
+// position of the line, the unit (BT-130) to C62 (one) and the VAT category
+// and rate to the current VAT scenario. The line net amount (BT-131) is
+// calculated by Build.
+func (b *InvoiceBuilder) AddLine(line InvoiceLine) *InvoiceBuilder {
+	if line.LineID == "" {
+		line.LineID = fmt.Sprint(len(b.inv.InvoiceLines) + 1)
+	}
+	if line.BilledQuantityUnit == "" {
+		line.BilledQuantityUnit = "C62"
+	}
+	if line.TaxCategoryCode == "" && b.vat != nil {
+		line.TaxCategoryCode = b.vat.CategoryCode
+		line.TaxRateApplicablePercent = b.vat.Percent
+	}
+	b.inv.InvoiceLines = append(b.inv.InvoiceLines, line)
+	return b
+}
+
+// AddAllowance adds a document level allowance (BG-20) with the VAT category
+// of the current VAT scenario, unless set.
+func (b *InvoiceBuilder) AddAllowance(ac AllowanceCharge) *InvoiceBuilder {
+	ac.ChargeIndicator = false
+	return b.addAllowanceCharge(ac)
+}
+
+// AddCharge adds a document level charge (BG-21) with the VAT category of the
+// current VAT scenario, unless set.
+func (b *InvoiceBuilder) AddCharge(ac AllowanceCharge) *InvoiceBuilder {
+	ac.ChargeIndicator = true
+	return b.addAllowanceCharge(ac)
+}
+
+func (b *InvoiceBuilder) addAllowanceCharge(ac AllowanceCharge) *InvoiceBuilder {
+	if ac.CategoryTradeTaxCategoryCode == "" && b.vat != nil {
+		ac.CategoryTradeTaxCategoryCode = b.vat.CategoryCode
+		ac.CategoryTradeTaxRateApplicablePercent = b.vat.Percent
+	}
+	if ac.CategoryTradeTaxType == "" {
+		ac.CategoryTradeTaxType = "VAT"
+	}
+	b.inv.SpecifiedTradeAllowanceCharge = append(b.inv.SpecifiedTradeAllowanceCharge, ac)
+	return b
+}
+
+// DeductPrepayments deducts the prepayment invoices (386) from the amount
+// due, see Invoice.DeductPrepayments. Build checks the final invoice with
+// Invoice.ValidatePrepayments.
+func (b *InvoiceBuilder) DeductPrepayments(prepayments ...*Invoice) *InvoiceBuilder {
+	b.prepayments = append(b.prepayments, prepayments...)
+	return b
+}
+
+// Options sets the calculation options (VAT rounding, cash rounding) used by
+// Build.
+func (b *InvoiceBuilder) Options(opts *CalculationOptions) *InvoiceBuilder {
+	b.opts = opts
+	return b
+}
+
+// Build calculates the line net amounts, the VAT breakdown with the exemption
+// reasons of the VAT scenarios and the document totals, and validates the
+// invoice. Lines with a tax inclusive price are calculated with
+// UpdateTaxInclusive. It returns the invoice or the *ValidationError with all
+// violations, or the error of deducting the prepayments. The totals are only
+// calculated from the BASIC profile on, see UpdateTotals.
+//
+// The builder must not be used after Build.
+func (b *InvoiceBuilder) Build() (*Invoice, error) {
+	inv := b.inv
+	if len(b.prepayments) > 0 {
+		if err := inv.DeductPrepayments(b.prepayments...); err != nil {
+			return nil, err
+		}
+	}
+	inv.calculate(b.opts)
+	for i := range inv.TradeTaxes {
+		tt := &inv.TradeTaxes[i]
+		s, ok := b.scenarios[tt.CategoryCode]
+		if !ok || tt.ExemptionReason != "" || tt.ExemptionReasonCode != "" {
+			continue
+		}
+		tt.ExemptionReason = s.ExemptionReason
+		tt.ExemptionReasonCode = s.ExemptionReasonCode
+	}
+
+	if err := inv.Validate(); err != nil {
+		return nil, err
+	}
+	if len(b.prepayments) > 0 {
+		if err := inv.ValidatePrepayments(b.prepayments...); err != nil {
+			return nil, err
+		}
+	}
+	return inv, nil
+}
@@ -0,0 +1,143 @@
+package einvoice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// builderInvoice returns a builder with the mandatory header data and a
+// seller and buyer in different EU countries.
+func builderInvoice(profile string) *InvoiceBuilder {
+	return NewInvoice(profile).
+		Number("RE-2025-001").
+		Date(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)).
+		Currency("EUR").
+		PaymentTerms(SpecifiedTradePaymentTerms{DueDate: time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC)}).
+		Seller(Party{
+			Name:                      "Seller GmbH",
+			PostalAddress:             &PostalAddress{Line1: "Hauptstraße 1", City: "Berlin", PostcodeCode: "10115", CountryID: "DE"},
+			VATaxRegistration:         "DE123456789",
+			URIUniversalCommunication: "seller@example.com", URIUniversalCommunicationScheme: "EM",
+		}).
+		Buyer(Party{
+			Name:                      "Buyer SARL",
+			PostalAddress:             &PostalAddress{Line1: "1 rue de Paris", City: "Paris", PostcodeCode: "75001", CountryID: "FR"},
+			VATaxRegistration:         "FR12345678901",
+			URIUniversalCommunication: "buyer@example.com", URIUniversalCommunicationScheme: "EM",
+		})
+}
+
+func TestInvoiceBuilder(t *testing.T) {
+	inv, err := builderInvoice(SpecEN16931).
+		VAT(VATStandard(decimal.NewFromInt(19))).
+		AddLine(InvoiceLine{ItemName: "Consulting", BilledQuantity: decimal.NewFromInt(8), NetPrice: decimal.NewFromInt(120)}).
+		AddLine(InvoiceLine{ItemName: "Book", BilledQuantity: decimal.NewFromInt(2), NetPrice: decimal.RequireFromString("24.95"), TaxCategoryCode: "S", TaxRateApplicablePercent: decimal.NewFromInt(7)}).
+		AddAllowance(AllowanceCharge{ActualAmount: decimal.NewFromInt(60), Reason: "Discount"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+
+	if inv.InvoiceTypeCode != 380 || inv.InvoiceLines[1].LineID != "2" || inv.InvoiceLines[1].BilledQuantityUnit != "C62" {
+		t.Errorf("defaults not applied: type %d, line %+v", inv.InvoiceTypeCode, inv.InvoiceLines[1])
+	}
+	// 960.00 + 49.90 - 60.00, VAT 171.00 + 3.49
+	for _, tc := range []struct {
+		name      string
+		got, want decimal.Decimal
+	}{
+		{"LineTotal", inv.LineTotal, decimal.RequireFromString("1009.90")},
+		{"TaxBasisTotal", inv.TaxBasisTotal, decimal.RequireFromString("949.90")},
+		{"TaxTotal", inv.TaxTotal, decimal.RequireFromString("174.49")},
+		{"DuePayableAmount", inv.DuePayableAmount, decimal.RequireFromString("1124.39")},
+	} {
+		if !tc.got.Equal(tc.want) {
+			t.Errorf("%s = %s, want %s", tc.name, tc.got, tc.want)
+		}
+	}
+	if ac := inv.SpecifiedTradeAllowanceCharge[0]; ac.ChargeIndicator || ac.CategoryTradeTaxCategoryCode != "S" || !ac.CategoryTradeTaxRateApplicablePercent.Equal(decimal.NewFromInt(19)) {
+		t.Errorf("allowance = %+v", ac)
+	}
+
+	// The built invoice can be written and read back
+	parsed, err := ParseReader(strings.NewReader(writeWithOptions(t, inv, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Errorf("Validate() after round trip = %v", err)
+	}
+}
+
+func TestInvoiceBuilder_PEPPOLDefaults(t *testing.T) {
+	inv, err := builderInvoice(SpecPEPPOLBilling30).
+		BuyerReference("PO-4711").
+		VAT(VATStandard(decimal.NewFromInt(19))).
+		AddLine(InvoiceLine{ItemName: "Item", BilledQuantity: decimal.NewFromInt(1), NetPrice: decimal.NewFromInt(100)}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	if inv.BPSpecifiedDocumentContextParameter != BPPEPPOLBilling01 || inv.SchemaType != UBL {
+		t.Errorf("BT-23 = %q, syntax %v, want %q and UBL", inv.BPSpecifiedDocumentContextParameter, inv.SchemaType, BPPEPPOLBilling01)
+	}
+}
+
+func TestInvoiceBuilder_VATScenarios(t *testing.T) {
+	delivery := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		scenario func(*InvoiceBuilder) *InvoiceBuilder
+		wantCode string
+	}{
+		{"reverse charge", (*InvoiceBuilder).ReverseCharge, "VATEX-EU-AE"},
+		{"intra-community supply", func(b *InvoiceBuilder) *InvoiceBuilder {
+			return b.IntraCommunitySupply().DeliveryDate(delivery).ShipTo(Party{PostalAddress: &PostalAddress{CountryID: "FR"}})
+		}, "VATEX-EU-IC"},
+		{"export", (*InvoiceBuilder).Export, "VATEX-EU-G"},
+		{"exempt", func(b *InvoiceBuilder) *InvoiceBuilder {
+			return b.VAT(VATExempt("Exempt based on article 132, section 1 (i) of Council Directive 2006/112/EC", "VATEX-EU-132-1I"))
+		}, "VATEX-EU-132-1I"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.scenario(builderInvoice(SpecEN16931))
+			inv, err := b.AddLine(InvoiceLine{ItemName: "Machine", BilledQuantity: decimal.NewFromInt(1), NetPrice: decimal.NewFromInt(5000)}).Build()
+			if err != nil {
+				t.Fatalf("Build() = %v", err)
+			}
+			if len(inv.TradeTaxes) != 1 || inv.TradeTaxes[0].ExemptionReasonCode != tt.wantCode || inv.TradeTaxes[0].ExemptionReason == "" {
+				t.Errorf("TradeTaxes = %+v, want exemption reason code %s", inv.TradeTaxes, tt.wantCode)
+			}
+			if !inv.DuePayableAmount.Equal(decimal.NewFromInt(5000)) {
+				t.Errorf("DuePayableAmount = %s, want 5000", inv.DuePayableAmount)
+			}
+		})
+	}
+}
+
+func TestInvoiceBuilder_ValidationError(t *testing.T) {
+	// Intra-community supply without delivery date and deliver to country
+	inv, err := builderInvoice(SpecEN16931).
+		IntraCommunitySupply().
+		AddLine(InvoiceLine{ItemName: "Machine", BilledQuantity: decimal.NewFromInt(1), NetPrice: decimal.NewFromInt(5000)}).
+		Build()
+	if inv != nil {
+		t.Error("Build() returned an invoice with violations")
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Build() error = %v, want *ValidationError", err)
+	}
+	codes := map[string]bool{}
+	for _, v := range ve.Violations() {
+		codes[v.Rule.Code] = true
+	}
+	if !codes["BR-IC-11"] || !codes["BR-IC-12"] {
+		t.Errorf("violations = %v, want BR-IC-11 and BR-IC-12", ve.Violations())
+	}
+}
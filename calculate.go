@@ -36,91 +36,141 @@ func (line InvoiceLine) isDetailLine() bool {
 	return line.LineStatusReasonCode == "" || line.LineStatusReasonCode == "DETAIL"
 }
 
-// UpdateApplicableTradeTax removes the existing trade tax lines in the invoice
-// and re-creates new ones from the line items and document-level allowances/charges.
-// er is a map that contains exemption reasons for each category code.
-// According to BR-45 and category-specific rules (BR-S-8, BR-AE-8, BR-E-8, etc.),
-// the VAT category taxable amount must include:
-// - Sum of invoice line net amounts for that category
-// - Minus document level allowance amounts for that category
-// - Plus document level charge amounts for that category
-func (inv *Invoice) UpdateApplicableTradeTax(exemptReason map[string]string) {
-	var applicableTradeTaxes = make([]*TradeTax, 0, len(inv.TradeTaxes))
-
-	// Process invoice lines. Sub invoice line aggregation lines (GROUP /
-	// INFORMATION) are skipped so only detail lines contribute to the VAT
-	// breakdown (avoids double counting; EXTENDED).
-	for i := range inv.InvoiceLines {
-		if !inv.InvoiceLines[i].isDetailLine() {
-			continue
-		}
-		tradeTax := TradeTax{
-			CategoryCode: inv.InvoiceLines[i].TaxCategoryCode,
-			Percent:      inv.InvoiceLines[i].TaxRateApplicablePercent,
-			BasisAmount:  inv.InvoiceLines[i].Total,
-			TypeCode:     "VAT",
+// Calculate derives the calculated amounts of the invoice line from its
+// commercial data:
+//   - Item net price (BT-146) = Item gross price (BT-148) - price discounts
+//     (BT-147) + price charges, if the gross price is set. A price discount
+//     with a percentage and without an amount is calculated from its base
+//     amount or the gross price.
+//   - Invoice line allowance (BT-136) and charge (BT-141) amounts with a
+//     percentage (BT-138, BT-143) = base amount (BT-137, BT-142) × percentage
+//     / 100. A missing base amount is set to the line amount before allowances
+//     and charges.
+//   - Invoice line net amount (BT-131) = Invoiced quantity (BT-129) × Item net
+//     price (BT-146) / Item price base quantity (BT-149) - allowances + charges
+//     (PEPPOL-EN16931-R120).
+//
+// If the line has a tax inclusive price, the item net price is the tax
+// inclusive price without VAT (TaxInclusivePrice × 100 / (100 + BT-152)),
+// rounded to four decimals, and the gross price is not used.
+//
+// Amounts are rounded half up to two decimals, unit prices are not rounded.
+func (line *InvoiceLine) Calculate() {
+	if !line.TaxInclusivePrice.IsZero() {
+		line.NetPrice = roundHalfUp(line.TaxInclusivePrice.Mul(decimal100).Div(decimal100.Add(line.TaxRateApplicablePercent)), taxInclusivePricePlaces)
+		line.hasNetPriceInXML = true
+	} else if !line.GrossPrice.IsZero() {
+		line.NetPrice = line.GrossPrice
+		for i := range line.AppliedTradeAllowanceCharge {
+			ac := &line.AppliedTradeAllowanceCharge[i]
+			if ac.ActualAmount.IsZero() && !ac.CalculationPercent.IsZero() {
+				basis := ac.BasisAmount
+				if basis.IsZero() {
+					basis = line.GrossPrice
+				}
+				ac.ActualAmount = basis.Mul(ac.CalculationPercent).Div(decimal100)
+			}
+			if ac.ChargeIndicator {
+				line.NetPrice = line.NetPrice.Add(ac.ActualAmount)
+			} else {
+				line.NetPrice = line.NetPrice.Sub(ac.ActualAmount)
+			}
 		}
-		found := false
+		line.hasNetPriceInXML = true
+	}
 
-		for _, att := range applicableTradeTaxes {
-			if att.CategoryCode == tradeTax.CategoryCode && att.Percent.Equal(tradeTax.Percent) {
-				att.BasisAmount = att.BasisAmount.Add(inv.InvoiceLines[i].Total)
-				found = true
+	amount := roundHalfUp(line.BilledQuantity.Mul(line.NetPrice).Div(line.priceBaseQuantity()), 2)
 
-				break
-			}
-		}
+	total := amount
+	for i := range line.InvoiceLineAllowances {
+		calculateAllowanceCharge(&line.InvoiceLineAllowances[i], amount)
+		total = total.Sub(line.InvoiceLineAllowances[i].ActualAmount)
+	}
+	for i := range line.InvoiceLineCharges {
+		calculateAllowanceCharge(&line.InvoiceLineCharges[i], amount)
+		total = total.Add(line.InvoiceLineCharges[i].ActualAmount)
+	}
+	// BR-DEC-23: Invoice line net amount (BT-131) must have max 2 decimal places
+	line.Total = roundHalfUp(total, 2)
+	line.hasLineTotalInXML = true
+}
 
-		if !found {
-			applicableTradeTaxes = append(applicableTradeTaxes, &tradeTax)
-		}
+// priceBaseQuantity returns the item price base quantity (BT-149), which
+// defaults to 1 when not specified (per EN 16931).
+func (line *InvoiceLine) priceBaseQuantity() decimal.Decimal {
+	if line.BasisQuantity.IsZero() {
+		return decimal.NewFromInt(1)
 	}
+	return line.BasisQuantity
+}
 
-	// Process document-level allowances and charges
-	for i := range inv.SpecifiedTradeAllowanceCharge {
-		found := false
-		for _, att := range applicableTradeTaxes {
-			if att.CategoryCode == inv.SpecifiedTradeAllowanceCharge[i].CategoryTradeTaxCategoryCode && att.Percent.Equal(inv.SpecifiedTradeAllowanceCharge[i].CategoryTradeTaxRateApplicablePercent) {
-				// Charges add to the basis, allowances subtract
-				if inv.SpecifiedTradeAllowanceCharge[i].ChargeIndicator {
-					att.BasisAmount = att.BasisAmount.Add(inv.SpecifiedTradeAllowanceCharge[i].ActualAmount)
-				} else {
-					att.BasisAmount = att.BasisAmount.Sub(inv.SpecifiedTradeAllowanceCharge[i].ActualAmount)
-				}
-				found = true
-				break
-			}
-		}
+// calculateAllowanceCharge sets the amount of an allowance or charge with a
+// percentage from its base amount, which defaults to amount.
+func calculateAllowanceCharge(ac *AllowanceCharge, amount decimal.Decimal) {
+	if ac.CalculationPercent.IsZero() {
+		return
+	}
+	if ac.BasisAmount.IsZero() {
+		ac.BasisAmount = amount
+	}
+	ac.ActualAmount = roundHalfUp(ac.BasisAmount.Mul(ac.CalculationPercent).Div(decimal100), 2)
+}
 
-		// If not found, create a new tax entry for this category
-		if !found {
-			basisAmount := inv.SpecifiedTradeAllowanceCharge[i].ActualAmount
-			if !inv.SpecifiedTradeAllowanceCharge[i].ChargeIndicator {
-				basisAmount = basisAmount.Neg()
-			}
-			tradeTax := &TradeTax{
-				CategoryCode: inv.SpecifiedTradeAllowanceCharge[i].CategoryTradeTaxCategoryCode,
-				Percent:      inv.SpecifiedTradeAllowanceCharge[i].CategoryTradeTaxRateApplicablePercent,
-				BasisAmount:  basisAmount,
-				TypeCode:     "VAT",
-			}
-			applicableTradeTaxes = append(applicableTradeTaxes, tradeTax)
+// UpdateLines calculates the net price and the net amount of every invoice
+// line, see InvoiceLine.Calculate. In an EXTENDED sub invoice line hierarchy
+// the net amount of a GROUP line is set to the sum of its child lines
+// (without INFORMATION lines) instead.
+//
+// Call UpdateLines before UpdateApplicableTradeTax and UpdateTotals.
+func (inv *Invoice) UpdateLines() {
+	for i := range inv.InvoiceLines {
+		if inv.InvoiceLines[i].LineStatusReasonCode != "GROUP" {
+			inv.InvoiceLines[i].Calculate()
 		}
 	}
 
-	inv.TradeTaxes = []TradeTax{}
-	for _, att := range applicableTradeTaxes {
-		// BR-DEC-19: VAT category taxable amount (BT-116) must have max 2 decimal places
-		att.BasisAmount = roundHalfUp(att.BasisAmount, 2)
-		att.CalculatedAmount = roundHalfUp(att.BasisAmount.Mul(att.Percent.Div(decimal100)), 2)
-		if att.Percent.IsZero() {
-			att.ExemptionReason = exemptReason[att.CategoryCode]
+	// Groups may contain groups, so the sums are calculated depth first.
+	children := make(map[string][]int)
+	for i := range inv.InvoiceLines {
+		if p := inv.InvoiceLines[i].ParentLineID; p != "" {
+			children[p] = append(children[p], i)
 		}
-
-		inv.TradeTaxes = append(inv.TradeTaxes, *att)
+	}
+	done := make(map[int]bool)
+	var groupTotal func(i int) decimal.Decimal
+	groupTotal = func(i int) decimal.Decimal {
+		line := &inv.InvoiceLines[i]
+		if line.LineStatusReasonCode != "GROUP" || done[i] {
+			return line.Total
+		}
+		done[i] = true // also guards against cyclic references
+		sum := decimal.Zero
+		for _, c := range children[line.LineID] {
+			if inv.InvoiceLines[c].LineStatusReasonCode != "INFORMATION" {
+				sum = sum.Add(groupTotal(c))
+			}
+		}
+		line.Total = roundHalfUp(sum, 2)
+		line.hasLineTotalInXML = true
+		return line.Total
+	}
+	for i := range inv.InvoiceLines {
+		groupTotal(i)
 	}
 }
 
+// UpdateApplicableTradeTax removes the existing trade tax lines in the invoice
+// and re-creates new ones from the line items and document-level allowances/charges.
+// er is a map that contains exemption reasons for each category code.
+// According to BR-45 and category-specific rules (BR-S-8, BR-AE-8, BR-E-8, etc.),
+// the VAT category taxable amount must include:
+// - Sum of invoice line net amounts for that category
+// - Minus document level allowance amounts for that category
+// - Plus document level charge amounts for that category
+func (inv *Invoice) UpdateApplicableTradeTax(exemptReason map[string]string) {
+	inv.UpdateApplicableTradeTaxWithOptions(&CalculationOptions{ExemptionReasons: exemptReason})
+}
+
 // updateAllowancesAndCharges recalculates the document-level allowance and charge totals
 // according to EN 16931 business rules.
 // This function implements the following business rules:
@@ -153,6 +203,7 @@ func (inv *Invoice) updateAllowancesAndCharges() {
 // - BR-CO-12: ChargeTotal (BT-108) = Sum of all document level charge amounts (BT-99)
 // - BR-CO-13: TaxBasisTotal (BT-109) = LineTotal - AllowanceTotal + ChargeTotal
 // - BR-CO-15: GrandTotal (BT-112) = TaxBasisTotal + TaxTotal (BT-110)
+// - TaxTotalAccounting (BT-111) = VAT total in accounting currency, see TaxAmountsInAccountingCurrency
 // - BR-CO-16: DuePayableAmount (BT-115) = GrandTotal - TotalPrepaid (BT-113) + RoundingAmount (BT-114)
 //
 // Profile handling: Only recalculate for EN 16931 profiles that support line items.
@@ -161,6 +212,16 @@ func (inv *Invoice) updateAllowancesAndCharges() {
 // - Level 2 (BasicWL): Skip - no line items by design, totals provided directly
 // - Level 3+ (Basic, EN16931, Extended, etc.): Calculate - have line items
 func (inv *Invoice) UpdateTotals() {
+	inv.UpdateTotalsWithOptions(nil)
+}
+
+// UpdateTotalsWithOptions works like UpdateTotals. If opts has a cash
+// rounding increment, the rounding amount (BT-114) is set so that the amount
+// due for payment (BT-115) is GrandTotal - TotalPrepaid rounded to the
+// increment, for example 0.05 for CHF with CashRounding005. The rounding
+// amount is only part of EN 16931 and the more comprehensive profiles, so
+// the cash rounding is not applied to BASIC invoices.
+func (inv *Invoice) UpdateTotalsWithOptions(opts *CalculationOptions) {
 	// Only recalculate for profiles that explicitly support line-based calculations
 	// This ensures we don't modify totals for unknown or non-compliant invoices
 	profileLevel := inv.ProfileLevel()
@@ -192,6 +253,9 @@ func (inv *Invoice) UpdateTotals() {
 	// BR-DEC-13: TaxTotal (BT-110) must have max 2 decimal places
 	inv.TaxTotal = roundHalfUp(inv.TaxTotal, 2)
 
+	// BT-111: VAT total in accounting currency
+	inv.updateTaxTotalAccounting()
+
 	// BR-CO-13: TaxBasisTotal = LineTotal - AllowanceTotal + ChargeTotal
 	inv.TaxBasisTotal = inv.LineTotal.Sub(inv.AllowanceTotal).Add(inv.ChargeTotal)
 	// BR-DEC-12: TaxBasisTotal (BT-109) must have max 2 decimal places
@@ -202,6 +266,11 @@ func (inv *Invoice) UpdateTotals() {
 	// BR-DEC-14: GrandTotal (BT-112) must have max 2 decimal places
 	inv.GrandTotal = roundHalfUp(inv.GrandTotal, 2)
 
+	if opts != nil && opts.CashRounding.IsPositive() && is(levelEN16931, inv) {
+		due := inv.GrandTotal.Sub(inv.TotalPrepaid)
+		inv.RoundingAmount = opts.cashRound(due).Sub(due)
+	}
+
 	// BR-CO-16: DuePayableAmount = GrandTotal - TotalPrepaid + RoundingAmount
 	inv.DuePayableAmount = inv.GrandTotal.Sub(inv.TotalPrepaid).Add(inv.RoundingAmount)
 	// BR-DEC-18: DuePayableAmount (BT-115) must have max 2 decimal places
@@ -0,0 +1,55 @@
+package einvoice
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// taxExchangeRate returns the rate that converts amounts in the invoice
+// currency (BT-5) into the VAT accounting currency (BT-6). ok is false if the
+// invoice has no VAT accounting currency other than the invoice currency or
+// no exchange rate (TaxCurrencyExchange). A rate given from the accounting
+// currency to the invoice currency is inverted.
+func (inv *Invoice) taxExchangeRate() (rate decimal.Decimal, ok bool) {
+	ce := inv.TaxCurrencyExchange
+	if inv.TaxCurrencyCode == "" || inv.TaxCurrencyCode == inv.InvoiceCurrencyCode || ce == nil || !ce.ConversionRate.IsPositive() {
+		return decimal.Zero, false
+	}
+	if ce.SourceCurrencyCode == inv.TaxCurrencyCode && ce.TargetCurrencyCode == inv.InvoiceCurrencyCode {
+		return decimal.NewFromInt(1).Div(ce.multiplyRate()), true
+	}
+	return ce.multiplyRate(), true
+}
+
+// TaxAmountsInAccountingCurrency returns the VAT category tax amounts (BT-117)
+// of the VAT breakdown converted into the VAT accounting currency (BT-6) with
+// the exchange rate (TaxCurrencyExchange), each rounded half up to two
+// decimals. The amounts are in the order of TradeTaxes, their sum is the
+// invoice total VAT amount in accounting currency (BT-111) UpdateTotals
+// calculates. ok is false if the invoice has no VAT accounting currency or
+// no exchange rate.
+func (inv *Invoice) TaxAmountsInAccountingCurrency() (amounts []decimal.Decimal, ok bool) {
+	rate, ok := inv.taxExchangeRate()
+	if !ok {
+		return nil, false
+	}
+	amounts = make([]decimal.Decimal, len(inv.TradeTaxes))
+	for i := range inv.TradeTaxes {
+		amounts[i] = roundHalfUp(inv.TradeTaxes[i].CalculatedAmount.Mul(rate), 2)
+	}
+	return amounts, true
+}
+
+// updateTaxTotalAccounting sets the invoice total VAT amount in accounting
+// currency (BT-111) to the sum of the converted VAT category tax amounts.
+// Without an exchange rate BT-111 is left unchanged.
+func (inv *Invoice) updateTaxTotalAccounting() {
+	amounts, ok := inv.TaxAmountsInAccountingCurrency()
+	if !ok {
+		return
+	}
+	inv.TaxTotalAccounting = decimal.Zero
+	for _, amount := range amounts {
+		inv.TaxTotalAccounting = inv.TaxTotalAccounting.Add(amount)
+	}
+	inv.TaxTotalAccountingCurrency = inv.TaxCurrencyCode
+}
@@ -0,0 +1,152 @@
+package einvoice
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// usdInvoice returns a UBL invoice in USD with the VAT accounting currency
+// EUR and the VAT amounts 19.00 (19 %) and 2.33 (7 %).
+func usdInvoice(exchange *CurrencyExchange) *Invoice {
+	inv := createMinimalInvoice()
+	inv.SchemaType = UBL
+	inv.InvoiceCurrencyCode = "USD"
+	inv.TaxCurrencyCode = "EUR"
+	inv.TaxCurrencyExchange = exchange
+	inv.InvoiceLines = append(inv.InvoiceLines, InvoiceLine{
+		LineID:                   "2",
+		ItemName:                 "Reduced",
+		BilledQuantity:           decimal.NewFromInt(1),
+		BilledQuantityUnit:       "C62",
+		NetPrice:                 decimal.RequireFromString("33.33"),
+		TaxCategoryCode:          "S",
+		TaxRateApplicablePercent: decimal.NewFromInt(7),
+	})
+	inv.UpdateLines()
+	inv.UpdateApplicableTradeTax(nil)
+	inv.UpdateTotals()
+	return inv
+}
+
+func TestUpdateTotals_TaxTotalAccounting(t *testing.T) {
+	tests := []struct {
+		name        string
+		exchange    *CurrencyExchange
+		wantAmounts []string
+		wantTotal   string
+	}{
+		{
+			name:        "USD to EUR",
+			exchange:    &CurrencyExchange{SourceCurrencyCode: "USD", TargetCurrencyCode: "EUR", ConversionRate: decimal.RequireFromString("0.92")},
+			wantAmounts: []string{"17.48", "2.14"},
+			wantTotal:   "19.62",
+		},
+		{
+			name:        "EUR to USD",
+			exchange:    &CurrencyExchange{SourceCurrencyCode: "EUR", TargetCurrencyCode: "USD", ConversionRate: decimal.RequireFromString("1.25")},
+			wantAmounts: []string{"15.2", "1.86"},
+			wantTotal:   "17.06",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := usdInvoice(tt.exchange)
+			amounts, ok := inv.TaxAmountsInAccountingCurrency()
+			if !ok || len(amounts) != len(tt.wantAmounts) {
+				t.Fatalf("TaxAmountsInAccountingCurrency() = %v, %v", amounts, ok)
+			}
+			for i, want := range tt.wantAmounts {
+				if !amounts[i].Equal(decimal.RequireFromString(want)) {
+					t.Errorf("amount %d = %s, want %s", i, amounts[i], want)
+				}
+			}
+			if !inv.TaxTotalAccounting.Equal(decimal.RequireFromString(tt.wantTotal)) {
+				t.Errorf("TaxTotalAccounting = %s, want %s", inv.TaxTotalAccounting, tt.wantTotal)
+			}
+			if inv.TaxTotalAccountingCurrency != "EUR" {
+				t.Errorf("TaxTotalAccountingCurrency = %q, want EUR", inv.TaxTotalAccountingCurrency)
+			}
+			if err := inv.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+
+	// Without exchange rate BT-111 is not touched
+	inv := usdInvoice(nil)
+	if _, ok := inv.TaxAmountsInAccountingCurrency(); ok {
+		t.Error("TaxAmountsInAccountingCurrency() ok without exchange rate")
+	}
+	if !inv.TaxTotalAccounting.IsZero() {
+		t.Errorf("TaxTotalAccounting = %s, want 0", inv.TaxTotalAccounting)
+	}
+}
+
+func TestValidate_TaxTotalAccountingExchangeRate(t *testing.T) {
+	inv := usdInvoice(&CurrencyExchange{SourceCurrencyCode: "USD", TargetCurrencyCode: "EUR", ConversionRate: decimal.RequireFromString("0.92")})
+
+	// 21.33 × 0.92 = 19.6236, two VAT breakdowns allow 19.60 to 19.64
+	for _, tc := range []struct {
+		amount string
+		want   bool
+	}{
+		{"19.62", false},
+		{"19.64", false},
+		{"19.60", false},
+		{"19.65", true},
+		{"20.00", true},
+	} {
+		inv.TaxTotalAccounting = decimal.RequireFromString(tc.amount)
+		_ = inv.Validate()
+		if got := hasViolationCode(inv, "BR-USER-07"); got != tc.want {
+			t.Errorf("BT-111 %s: BR-USER-07 = %v, want %v", tc.amount, got, tc.want)
+		}
+	}
+}
+
+func TestTaxExchangeRate_UBLRoundTrip(t *testing.T) {
+	inv := usdInvoice(&CurrencyExchange{
+		SourceCurrencyCode: "USD",
+		TargetCurrencyCode: "EUR",
+		ConversionRate:     decimal.RequireFromString("0.92"),
+		ConversionRateDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	})
+	xml := writeWithOptions(t, inv, nil)
+	if !strings.Contains(xml, "<cac:TaxExchangeRate>") {
+		t.Fatal("cac:TaxExchangeRate not written")
+	}
+
+	parsed, err := ParseReader(strings.NewReader(xml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := inv.TaxCurrencyExchange
+	if ce := parsed.TaxCurrencyExchange; ce == nil || ce.SourceCurrencyCode != want.SourceCurrencyCode || ce.TargetCurrencyCode != want.TargetCurrencyCode ||
+		!ce.ConversionRate.Equal(want.ConversionRate) || !ce.ConversionRateDate.Equal(want.ConversionRateDate) {
+		t.Errorf("TaxCurrencyExchange = %+v, want %+v", ce, want)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Errorf("Validate() after round trip = %v", err)
+	}
+
+	// MathematicOperatorCode Divide: 1 / 1.25 = 0.8
+	xml = strings.Replace(xml, "<cbc:CalculationRate>0.92<", "<cbc:CalculationRate>1.25<", 1)
+	xml = strings.Replace(xml, ">Multiply<", ">Divide<", 1)
+	parsed, err = ParseReader(strings.NewReader(xml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate, _ := parsed.taxExchangeRate(); !rate.Equal(decimal.RequireFromString("0.8")) {
+		t.Errorf("taxExchangeRate() = %s, want 0.8", rate)
+	}
+
+	// The rate and its operator are written back unchanged
+	parsed.TaxCurrencyExchange.ConversionRate = decimal.NewFromInt(3)
+	xml = writeWithOptions(t, parsed, nil)
+	if !strings.Contains(xml, "<cbc:CalculationRate>3</cbc:CalculationRate>") || !strings.Contains(xml, ">Divide<") {
+		t.Errorf("cac:TaxExchangeRate with operator Divide not written back:\n%s", xml)
+	}
+}
@@ -0,0 +1,323 @@
+package einvoice
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice/rules"
+)
+
+// RoundingMode selects how amounts are rounded to two decimals.
+type RoundingMode int
+
+const (
+	// RoundingHalfUp is commercial rounding (0.125 becomes 0.13, -0.125
+	// becomes -0.13) as required by EN 16931.
+	RoundingHalfUp RoundingMode = iota
+	// RoundingHalfEven is banker's rounding (0.125 becomes 0.12).
+	RoundingHalfEven
+	// RoundingDown truncates towards zero (0.129 becomes 0.12).
+	RoundingDown
+)
+
+// String returns the name of the rounding mode.
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundingHalfUp:
+		return "half up"
+	case RoundingHalfEven:
+		return "half even"
+	case RoundingDown:
+		return "down"
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+// TaxRoundingPoint selects where the VAT amounts are rounded.
+type TaxRoundingPoint int
+
+const (
+	// TaxPerCategory calculates the VAT category tax amount (BT-117) from the
+	// rounded VAT category taxable amount (BT-116) of each category and rate
+	// as required by BR-CO-17.
+	TaxPerCategory TaxRoundingPoint = iota
+	// TaxPerLine rounds the VAT of every invoice line and document level
+	// allowance or charge and sums the rounded amounts per category and rate,
+	// as many ERP systems do. The result may differ from BR-CO-17 by a few
+	// cents.
+	TaxPerLine
+)
+
+// String returns the name of the rounding point.
+func (p TaxRoundingPoint) String() string {
+	switch p {
+	case TaxPerCategory:
+		return "per category"
+	case TaxPerLine:
+		return "per line"
+	}
+	return fmt.Sprintf("TaxRoundingPoint(%d)", int(p))
+}
+
+// CalculationOptions control the VAT calculation of
+// UpdateApplicableTradeTaxWithOptions and CompareTaxCalculations. The zero
+// value (and nil) calculates like UpdateApplicableTradeTax.
+type CalculationOptions struct {
+	// Rounding is the rounding mode of the taxable and tax amounts.
+	Rounding RoundingMode
+	// RoundingPoint is where the VAT amounts are rounded.
+	RoundingPoint TaxRoundingPoint
+	// Tolerance is the accepted difference between a VAT category tax amount
+	// and the taxable amount × rate (BR-CO-17) in CompareTaxCalculations.
+	// Zero requires the exact amount.
+	Tolerance decimal.Decimal
+	// ExemptionReasons maps VAT category codes to the exemption reason
+	// (BT-120) of zero rated categories.
+	ExemptionReasons map[string]string
+	// CashRounding is the smallest cash unit the amount due for payment
+	// (BT-115) is rounded to (half up), such as CashRounding005. The
+	// difference is the rounding amount (BT-114). Zero keeps the rounding
+	// amount of the invoice.
+	CashRounding decimal.Decimal
+}
+
+// Cash rounding rules for CalculationOptions.CashRounding.
+var (
+	// CashRounding005 rounds to 0.05 as for CHF in Switzerland
+	// (Rappenrundung) and for cash payments in EUR in the Netherlands, Finland
+	// and Belgium.
+	CashRounding005 = decimal.RequireFromString("0.05")
+	// CashRoundingWholeUnit rounds to whole units as in Sweden and Norway.
+	CashRoundingWholeUnit = decimal.NewFromInt(1)
+)
+
+// String returns a short description of the options such as
+// "per line, half up".
+func (opts *CalculationOptions) String() string {
+	if opts == nil {
+		opts = &CalculationOptions{}
+	}
+	return opts.RoundingPoint.String() + ", " + opts.Rounding.String()
+}
+
+// round rounds d to two decimals with the rounding mode of opts.
+func (opts *CalculationOptions) round(d decimal.Decimal) decimal.Decimal {
+	if opts != nil {
+		switch opts.Rounding {
+		case RoundingHalfEven:
+			return d.RoundBank(2)
+		case RoundingDown:
+			return d.Truncate(2)
+		}
+	}
+	return roundHalfUp(d, 2)
+}
+
+// cashRound rounds amount half up to a multiple of the cash rounding
+// increment of opts.
+func (opts *CalculationOptions) cashRound(amount decimal.Decimal) decimal.Decimal {
+	if opts == nil || !opts.CashRounding.IsPositive() {
+		return amount
+	}
+	return roundHalfUp(amount.Div(opts.CashRounding), 0).Mul(opts.CashRounding)
+}
+
+// UpdateApplicableTradeTaxWithOptions works like UpdateApplicableTradeTax but
+// rounds the amounts as configured in opts. Options other than the defaults
+// can produce VAT breakdowns which violate BR-CO-17 and the category rules
+// such as BR-S-09, use CompareTaxCalculations to see the differences.
+func (inv *Invoice) UpdateApplicableTradeTaxWithOptions(opts *CalculationOptions) {
+	inv.TradeTaxes = inv.calculateTradeTaxes(opts)
+}
+
+// calculateTradeTaxes returns the VAT breakdown of the detail lines and the
+// document level allowances and charges grouped by category code and rate.
+func (inv *Invoice) calculateTradeTaxes(opts *CalculationOptions) []TradeTax {
+	perLine := opts != nil && opts.RoundingPoint == TaxPerLine
+	var applicableTradeTaxes []*TradeTax
+
+	add := func(category string, percent, amount decimal.Decimal) {
+		var att *TradeTax
+		for _, t := range applicableTradeTaxes {
+			if t.CategoryCode == category && t.Percent.Equal(percent) {
+				att = t
+				break
+			}
+		}
+		if att == nil {
+			att = &TradeTax{CategoryCode: category, Percent: percent, TypeCode: "VAT"}
+			applicableTradeTaxes = append(applicableTradeTaxes, att)
+		}
+		att.BasisAmount = att.BasisAmount.Add(amount)
+		if perLine {
+			att.CalculatedAmount = att.CalculatedAmount.Add(opts.round(amount.Mul(percent).Div(decimal100)))
+		}
+	}
+
+	// Sub invoice line aggregation lines (GROUP / INFORMATION) are skipped so
+	// only detail lines contribute to the VAT breakdown (EXTENDED).
+	for i := range inv.InvoiceLines {
+		line := &inv.InvoiceLines[i]
+		if line.isDetailLine() {
+			add(line.TaxCategoryCode, line.TaxRateApplicablePercent, line.Total)
+		}
+	}
+	// Charges add to the basis, allowances subtract
+	for i := range inv.SpecifiedTradeAllowanceCharge {
+		ac := &inv.SpecifiedTradeAllowanceCharge[i]
+		amount := ac.ActualAmount
+		if !ac.ChargeIndicator {
+			amount = amount.Neg()
+		}
+		add(ac.CategoryTradeTaxCategoryCode, ac.CategoryTradeTaxRateApplicablePercent, amount)
+	}
+
+	tradeTaxes := make([]TradeTax, 0, len(applicableTradeTaxes))
+	for _, att := range applicableTradeTaxes {
+		// BR-DEC-19: VAT category taxable amount (BT-116) must have max 2 decimal places
+		att.BasisAmount = opts.round(att.BasisAmount)
+		if !perLine {
+			att.CalculatedAmount = opts.round(att.BasisAmount.Mul(att.Percent.Div(decimal100)))
+		}
+		if att.Percent.IsZero() && opts != nil {
+			att.ExemptionReason = opts.ExemptionReasons[att.CategoryCode]
+		}
+		tradeTaxes = append(tradeTaxes, *att)
+	}
+	return tradeTaxes
+}
+
+// TaxDifference is the difference of a VAT category tax amount (BT-117)
+// between the VAT breakdown of the invoice and a calculated breakdown.
+type TaxDifference struct {
+	CategoryCode string          // VAT category code (BT-118)
+	Percent      decimal.Decimal // VAT category rate (BT-119)
+	Declared     decimal.Decimal // tax amount in the invoice, zero if the category is missing
+	Calculated   decimal.Decimal // calculated tax amount, zero if the category is not calculated
+}
+
+// Difference returns Calculated - Declared.
+func (d TaxDifference) Difference() decimal.Decimal {
+	return d.Calculated.Sub(d.Declared)
+}
+
+// TaxCalculationResult is a VAT breakdown calculated with one set of options,
+// see CompareTaxCalculations.
+type TaxCalculationResult struct {
+	Options    CalculationOptions
+	TradeTaxes []TradeTax      // calculated VAT breakdown (BG-23)
+	TaxTotal   decimal.Decimal // sum of the calculated tax amounts (BT-110)
+	// Differences contains one entry per category and rate of the invoice or
+	// the calculation, in the order of the invoice.
+	Differences []TaxDifference
+	// Violations lists the breakdown entries which do not satisfy BR-CO-17
+	// and the rate rules of their category (BR-S-09, BR-AF-09, BR-AG-09)
+	// within the tolerance of the options.
+	Violations []SemanticError
+}
+
+// MatchesInvoice reports whether the calculated VAT amounts equal the VAT
+// breakdown of the invoice.
+func (r *TaxCalculationResult) MatchesInvoice() bool {
+	for _, d := range r.Differences {
+		if !d.Difference().IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// Valid reports whether the calculated breakdown satisfies BR-CO-17 and the
+// category rules within the tolerance.
+func (r *TaxCalculationResult) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// DefaultCalculationVariants returns the combinations of all rounding points
+// and rounding modes, used by CompareTaxCalculations without options.
+func DefaultCalculationVariants() []CalculationOptions {
+	var variants []CalculationOptions
+	for _, point := range []TaxRoundingPoint{TaxPerCategory, TaxPerLine} {
+		for _, mode := range []RoundingMode{RoundingHalfUp, RoundingHalfEven, RoundingDown} {
+			variants = append(variants, CalculationOptions{RoundingPoint: point, Rounding: mode})
+		}
+	}
+	return variants
+}
+
+// CompareTaxCalculations calculates the VAT breakdown of the invoice with
+// each of the variants (DefaultCalculationVariants if none are given) and
+// reports the per category differences to the VAT breakdown of the invoice
+// and the rules each variant violates. It helps to find the calculation an
+// ERP system used and to see why BR-CO-17 or the rate rules of the
+// categories (BR-S-09, BR-AF-09, BR-AG-09) fail. The declared VAT total
+// (BT-110) is not checked. The invoice is not modified.
+//
+// The line net amounts (BT-131) should be calculated before, see UpdateLines.
+func (inv *Invoice) CompareTaxCalculations(variants ...CalculationOptions) []TaxCalculationResult {
+	if len(variants) == 0 {
+		variants = DefaultCalculationVariants()
+	}
+	results := make([]TaxCalculationResult, 0, len(variants))
+	for _, opts := range variants {
+		r := TaxCalculationResult{Options: opts, TradeTaxes: inv.calculateTradeTaxes(&opts)}
+		for _, tt := range r.TradeTaxes {
+			r.TaxTotal = r.TaxTotal.Add(tt.CalculatedAmount)
+		}
+		r.Differences = taxDifferences(inv.TradeTaxes, r.TradeTaxes)
+		r.Violations = checkTaxAmounts(r.TradeTaxes, opts.Tolerance)
+		results = append(results, r)
+	}
+	return results
+}
+
+// taxDifferences pairs the declared and calculated breakdown entries by
+// category code and rate.
+func taxDifferences(declared, calculated []TradeTax) []TaxDifference {
+	var diffs []TaxDifference
+	find := func(category string, percent decimal.Decimal) int {
+		for i := range diffs {
+			if diffs[i].CategoryCode == category && diffs[i].Percent.Equal(percent) {
+				return i
+			}
+		}
+		diffs = append(diffs, TaxDifference{CategoryCode: category, Percent: percent})
+		return len(diffs) - 1
+	}
+	for _, tt := range declared {
+		i := find(tt.CategoryCode, tt.Percent)
+		diffs[i].Declared = diffs[i].Declared.Add(tt.CalculatedAmount)
+	}
+	for _, tt := range calculated {
+		i := find(tt.CategoryCode, tt.Percent)
+		diffs[i].Calculated = diffs[i].Calculated.Add(tt.CalculatedAmount)
+	}
+	return diffs
+}
+
+// checkTaxAmounts checks BR-CO-17 and the rate rules of the categories with a
+// VAT rate (BR-S-09, BR-AF-09, BR-AG-09) with the given tolerance.
+func checkTaxAmounts(tradeTaxes []TradeTax, tolerance decimal.Decimal) []SemanticError {
+	var violations []SemanticError
+	for _, tt := range tradeTaxes {
+		expected := roundHalfUp(tt.BasisAmount.Mul(tt.Percent).Div(decimal100), 2)
+		if tt.CalculatedAmount.Sub(expected).Abs().LessThanOrEqual(tolerance.Abs()) {
+			continue
+		}
+		text := fmt.Sprintf("VAT category %s (%s%%) tax amount %s does not match %s (basis %s × rate ÷ 100)", tt.CategoryCode, tt.Percent, tt.CalculatedAmount.StringFixed(2), expected.StringFixed(2), tt.BasisAmount.StringFixed(2))
+		violations = append(violations, SemanticError{Rule: rules.BRCO17, Text: text})
+		var categoryRule rules.Rule
+		switch tt.CategoryCode {
+		case "S":
+			categoryRule = rules.BRS9
+		case "L":
+			categoryRule = rules.BRAF9
+		case "M":
+			categoryRule = rules.BRAG9
+		default:
+			continue
+		}
+		violations = append(violations, SemanticError{Rule: categoryRule, Text: text})
+	}
+	return violations
+}
@@ -0,0 +1,202 @@
+package einvoice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice/rules"
+)
+
+// roundingInvoice returns an invoice with three lines of 0.25 at 7 % (VAT
+// per line 0.0175, per category 0.0525) and one line of 0.50 at 25 % (VAT
+// 0.125).
+func roundingInvoice() *Invoice {
+	line := func(total, percent string) InvoiceLine {
+		return InvoiceLine{
+			TaxCategoryCode:          "S",
+			TaxRateApplicablePercent: decimal.RequireFromString(percent),
+			Total:                    decimal.RequireFromString(total),
+		}
+	}
+	return &Invoice{
+		GuidelineSpecifiedDocumentContextParameter: SpecEN16931,
+		InvoiceLines: []InvoiceLine{
+			line("0.25", "7"), line("0.25", "7"), line("0.25", "7"), line("0.50", "25"),
+		},
+	}
+}
+
+func TestUpdateApplicableTradeTaxWithOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *CalculationOptions
+		want [2]string // tax amount at 7 % and 25 %
+	}{
+		{"nil", nil, [2]string{"0.05", "0.13"}},
+		{"per category half up", &CalculationOptions{}, [2]string{"0.05", "0.13"}},
+		{"per category half even", &CalculationOptions{Rounding: RoundingHalfEven}, [2]string{"0.05", "0.12"}},
+		{"per category down", &CalculationOptions{Rounding: RoundingDown}, [2]string{"0.05", "0.12"}},
+		{"per line half up", &CalculationOptions{RoundingPoint: TaxPerLine}, [2]string{"0.06", "0.13"}},
+		{"per line half even", &CalculationOptions{RoundingPoint: TaxPerLine, Rounding: RoundingHalfEven}, [2]string{"0.06", "0.12"}},
+		{"per line down", &CalculationOptions{RoundingPoint: TaxPerLine, Rounding: RoundingDown}, [2]string{"0.03", "0.12"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := roundingInvoice()
+			inv.UpdateApplicableTradeTaxWithOptions(tt.opts)
+			if len(inv.TradeTaxes) != 2 {
+				t.Fatalf("got %d TradeTax entries, want 2", len(inv.TradeTaxes))
+			}
+			for i, want := range tt.want {
+				if got := inv.TradeTaxes[i].CalculatedAmount; !got.Equal(decimal.RequireFromString(want)) {
+					t.Errorf("%s%%: CalculatedAmount = %s, want %s", inv.TradeTaxes[i].Percent, got, want)
+				}
+			}
+			if got := inv.TradeTaxes[0].BasisAmount; !got.Equal(decimal.RequireFromString("0.75")) {
+				t.Errorf("BasisAmount = %s, want 0.75", got)
+			}
+		})
+	}
+}
+
+func TestUpdateApplicableTradeTaxWithOptions_PerLineAllowances(t *testing.T) {
+	inv := roundingInvoice()
+	inv.SpecifiedTradeAllowanceCharge = []AllowanceCharge{
+		{ActualAmount: decimal.RequireFromString("0.05"), CategoryTradeTaxCategoryCode: "S", CategoryTradeTaxRateApplicablePercent: decimal.NewFromInt(7)},
+		{ChargeIndicator: true, ActualAmount: decimal.RequireFromString("0.10"), CategoryTradeTaxCategoryCode: "S", CategoryTradeTaxRateApplicablePercent: decimal.NewFromInt(7)},
+	}
+	inv.UpdateApplicableTradeTaxWithOptions(&CalculationOptions{RoundingPoint: TaxPerLine})
+
+	// 3 × 0.02 - 0.00 (0.0035) + 0.01 (0.007)
+	if got := inv.TradeTaxes[0].CalculatedAmount; !got.Equal(decimal.RequireFromString("0.07")) {
+		t.Errorf("CalculatedAmount = %s, want 0.07", got)
+	}
+	if got := inv.TradeTaxes[0].BasisAmount; !got.Equal(decimal.RequireFromString("0.80")) {
+		t.Errorf("BasisAmount = %s, want 0.80", got)
+	}
+}
+
+func TestCompareTaxCalculations(t *testing.T) {
+	inv := roundingInvoice()
+	// The ERP rounded per line
+	inv.UpdateApplicableTradeTaxWithOptions(&CalculationOptions{RoundingPoint: TaxPerLine})
+	declared := inv.TradeTaxes[0].CalculatedAmount
+
+	results := inv.CompareTaxCalculations()
+	if len(results) != 6 {
+		t.Fatalf("got %d results, want 6", len(results))
+	}
+	if !inv.TradeTaxes[0].CalculatedAmount.Equal(declared) {
+		t.Error("CompareTaxCalculations modified the invoice")
+	}
+
+	var matching []string
+	for _, r := range results {
+		if r.MatchesInvoice() {
+			matching = append(matching, r.Options.String())
+		}
+	}
+	if len(matching) != 1 || matching[0] != "per line, half up" {
+		t.Errorf("matching variants = %v, want [per line, half up]", matching)
+	}
+
+	perCategory, perLine := results[0], results[3]
+	if !perCategory.Valid() {
+		t.Errorf("per category violations = %v, want none", perCategory.Violations)
+	}
+	if d := perCategory.Differences[0].Difference(); !d.Equal(decimal.RequireFromString("-0.01")) {
+		t.Errorf("per category difference = %s, want -0.01", d)
+	}
+	if len(perLine.Violations) != 2 || perLine.Violations[0].Rule.Code != rules.BRCO17.Code || perLine.Violations[1].Rule.Code != rules.BRS9.Code {
+		t.Errorf("per line violations = %v, want BR-CO-17 and BR-S-09", perLine.Violations)
+	}
+	if !perLine.TaxTotal.Equal(decimal.RequireFromString("0.19")) {
+		t.Errorf("per line TaxTotal = %s, want 0.19", perLine.TaxTotal)
+	}
+
+	// A tolerance of one cent accepts the per line rounding
+	tolerant := inv.CompareTaxCalculations(CalculationOptions{RoundingPoint: TaxPerLine, Tolerance: decimal.RequireFromString("0.01")})
+	if len(tolerant) != 1 || !tolerant[0].Valid() || !tolerant[0].MatchesInvoice() {
+		t.Errorf("tolerant result = %+v, want valid and matching", tolerant)
+	}
+}
+
+func TestUpdateTotalsWithOptions_CashRounding(t *testing.T) {
+	tests := []struct {
+		name      string
+		increment decimal.Decimal
+		prepaid   string
+		wantRound string
+		wantDue   string
+	}{
+		{"none", decimal.Zero, "0", "0", "11.92"},
+		{"five cents", CashRounding005, "0", "-0.02", "11.90"},
+		{"whole unit", CashRoundingWholeUnit, "0", "0.08", "12.00"},
+		{"five cents with prepaid amount", CashRounding005, "5.00", "-0.02", "6.90"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := createMinimalInvoice()
+			inv.InvoiceLines[0].NetPrice = decimal.RequireFromString("10.02")
+			inv.TotalPrepaid = decimal.RequireFromString(tt.prepaid)
+			opts := &CalculationOptions{CashRounding: tt.increment}
+			inv.UpdateLines()
+			inv.UpdateApplicableTradeTaxWithOptions(opts)
+			inv.UpdateTotalsWithOptions(opts)
+
+			// 10.02 + 1.90 VAT
+			if !inv.GrandTotal.Equal(decimal.RequireFromString("11.92")) {
+				t.Fatalf("GrandTotal = %s, want 11.92", inv.GrandTotal)
+			}
+			if !inv.RoundingAmount.Equal(decimal.RequireFromString(tt.wantRound)) {
+				t.Errorf("RoundingAmount = %s, want %s", inv.RoundingAmount, tt.wantRound)
+			}
+			if !inv.DuePayableAmount.Equal(decimal.RequireFromString(tt.wantDue)) {
+				t.Errorf("DuePayableAmount = %s, want %s", inv.DuePayableAmount, tt.wantDue)
+			}
+
+			// BR-CO-16 includes the rounding amount, also after a round trip
+			if err := inv.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+			for _, syntax := range []CodeSchemaType{CII, UBL} {
+				inv.SchemaType = syntax
+				parsed, err := ParseReader(strings.NewReader(writeWithOptions(t, inv, nil)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !parsed.RoundingAmount.Equal(inv.RoundingAmount) {
+					t.Errorf("%v: parsed RoundingAmount = %s, want %s", syntax, parsed.RoundingAmount, inv.RoundingAmount)
+				}
+				if err := parsed.Validate(); err != nil {
+					t.Errorf("%v: Validate() after round trip = %v", syntax, err)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateTotalsWithOptions_CashRoundingBasic(t *testing.T) {
+	inv, err := ParseXMLFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.GuidelineSpecifiedDocumentContextParameter = SpecFacturXBasic
+	inv.UpdateTotalsWithOptions(&CalculationOptions{CashRounding: CashRoundingWholeUnit})
+
+	// BASIC has no rounding amount (BT-114) in CII, so no cash rounding
+	if !inv.RoundingAmount.IsZero() {
+		t.Errorf("RoundingAmount = %s, want 0", inv.RoundingAmount)
+	}
+	parsed, err := ParseReader(strings.NewReader(writeWithOptions(t, inv, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.DuePayableAmount.Equal(inv.DuePayableAmount) {
+		t.Errorf("parsed DuePayableAmount = %s, want %s", parsed.DuePayableAmount, inv.DuePayableAmount)
+	}
+	if hasViolationCode(parsed, "BR-CO-16") {
+		t.Errorf("BR-CO-16 violation after round trip: %v", parsed.Validate())
+	}
+}
@@ -0,0 +1,83 @@
+package einvoice
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// taxInclusivePricePlaces is the number of decimals of a net price derived
+// from a tax inclusive price.
+const taxInclusivePricePlaces = 4
+
+// grossAmount returns the amount including VAT at percent, rounded to two
+// decimals.
+func grossAmount(amount, percent decimal.Decimal) decimal.Decimal {
+	return roundHalfUp(amount.Mul(decimal100.Add(percent)).Div(decimal100), 2)
+}
+
+// UpdateTaxInclusive calculates an invoice whose lines have tax inclusive
+// prices (TaxInclusivePrice), as used in retail and other B2C sales, so that
+// the amount due matches the customer's receipt:
+//
+//  1. The net prices and line net amounts (BT-131) are derived from the tax
+//     inclusive prices, see UpdateLines.
+//  2. The VAT breakdown is calculated with opts, see
+//     UpdateApplicableTradeTaxWithOptions.
+//  3. The document totals are calculated, see UpdateTotals.
+//  4. The difference between the receipt total and the invoice total amount
+//     with VAT (BT-112) is recorded as rounding amount (BT-114) and included
+//     in the amount due for payment (BT-115). With a cash rounding increment
+//     in opts the amount due is rounded to the increment as well.
+//
+// The receipt total is the sum of the tax inclusive line amounts (quantity ×
+// tax inclusive price / price base quantity, rounded to two decimals). Lines
+// without a tax inclusive price, line and document level allowances and
+// charges are net amounts and contribute their amount including VAT. The
+// receipt total is returned.
+//
+// The totals are only calculated for the profiles which UpdateTotals
+// supports. The rounding amount is only part of EN 16931 and the more
+// comprehensive profiles. In the Factur-X BASIC profile the amount due is
+// not adjusted and differs from the returned receipt total if the rounding
+// of the VAT breakdown causes a difference.
+func (inv *Invoice) UpdateTaxInclusive(opts *CalculationOptions) decimal.Decimal {
+	inv.UpdateLines()
+	inv.UpdateApplicableTradeTaxWithOptions(opts)
+	inv.RoundingAmount = decimal.Zero
+	inv.UpdateTotals()
+
+	receipt := decimal.Zero
+	for i := range inv.InvoiceLines {
+		line := &inv.InvoiceLines[i]
+		if !line.isDetailLine() {
+			continue
+		}
+		percent := line.TaxRateApplicablePercent
+		if line.TaxInclusivePrice.IsZero() {
+			receipt = receipt.Add(grossAmount(line.Total, percent))
+			continue
+		}
+		receipt = receipt.Add(roundHalfUp(line.BilledQuantity.Mul(line.TaxInclusivePrice).Div(line.priceBaseQuantity()), 2))
+		for _, ac := range line.InvoiceLineAllowances {
+			receipt = receipt.Sub(grossAmount(ac.ActualAmount, percent))
+		}
+		for _, ac := range line.InvoiceLineCharges {
+			receipt = receipt.Add(grossAmount(ac.ActualAmount, percent))
+		}
+	}
+	for _, ac := range inv.SpecifiedTradeAllowanceCharge {
+		amount := grossAmount(ac.ActualAmount, ac.CategoryTradeTaxRateApplicablePercent)
+		if ac.ChargeIndicator {
+			receipt = receipt.Add(amount)
+		} else {
+			receipt = receipt.Sub(amount)
+		}
+	}
+
+	if is(levelEN16931, inv) {
+		// BR-CO-16: DuePayableAmount = GrandTotal - TotalPrepaid + RoundingAmount
+		due := opts.cashRound(receipt.Sub(inv.TotalPrepaid))
+		inv.RoundingAmount = due.Sub(inv.GrandTotal.Sub(inv.TotalPrepaid))
+		inv.DuePayableAmount = roundHalfUp(inv.GrandTotal.Sub(inv.TotalPrepaid).Add(inv.RoundingAmount), 2)
+	}
+	return receipt
+}
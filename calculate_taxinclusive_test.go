@@ -0,0 +1,154 @@
+package einvoice
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestUpdateTaxInclusive(t *testing.T) {
+	line := func(id, qty, price, percent string) InvoiceLine {
+		return InvoiceLine{
+			LineID:                   id,
+			ItemName:                 "Item " + id,
+			BilledQuantity:           decimal.RequireFromString(qty),
+			BilledQuantityUnit:       "C62",
+			TaxInclusivePrice:        decimal.RequireFromString(price),
+			TaxCategoryCode:          "S",
+			TaxRateApplicablePercent: decimal.RequireFromString(percent),
+		}
+	}
+	tests := []struct {
+		name        string
+		lines       []InvoiceLine
+		wantNet     []string // line net amounts (BT-131)
+		wantReceipt string
+		wantGrand   string
+		wantRound   string
+	}{
+		{
+			name:        "no residual",
+			lines:       []InvoiceLine{line("1", "1", "11.90", "19")},
+			wantNet:     []string{"10.00"},
+			wantReceipt: "11.90",
+			wantGrand:   "11.90",
+			wantRound:   "0",
+		},
+		{
+			// 5.03 + 0.96 VAT (19 %), 4.17 + 0.29 VAT (7 %)
+			name: "residual cent",
+			lines: []InvoiceLine{
+				line("1", "2", "2.99", "19"),
+				line("2", "1", "1.49", "7"),
+				line("3", "3", "0.99", "7"),
+			},
+			wantNet:     []string{"5.03", "1.39", "2.78"},
+			wantReceipt: "10.44",
+			wantGrand:   "10.45",
+			wantRound:   "-0.01",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := createMinimalInvoice()
+			inv.InvoiceLines = tt.lines
+			receipt := inv.UpdateTaxInclusive(nil)
+
+			for i, want := range tt.wantNet {
+				if got := inv.InvoiceLines[i].Total; !got.Equal(decimal.RequireFromString(want)) {
+					t.Errorf("line %d: Total = %s, want %s", i+1, got, want)
+				}
+			}
+			if !receipt.Equal(decimal.RequireFromString(tt.wantReceipt)) {
+				t.Errorf("receipt = %s, want %s", receipt, tt.wantReceipt)
+			}
+			if !inv.GrandTotal.Equal(decimal.RequireFromString(tt.wantGrand)) {
+				t.Errorf("GrandTotal = %s, want %s", inv.GrandTotal, tt.wantGrand)
+			}
+			if !inv.RoundingAmount.Equal(decimal.RequireFromString(tt.wantRound)) {
+				t.Errorf("RoundingAmount = %s, want %s", inv.RoundingAmount, tt.wantRound)
+			}
+			if !inv.DuePayableAmount.Equal(receipt) {
+				t.Errorf("DuePayableAmount = %s, want the receipt total %s", inv.DuePayableAmount, receipt)
+			}
+			// BR-CO-10, BR-CO-14, BR-CO-16, BR-CO-17 and PEPPOL-EN16931-R120
+			if err := inv.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateTaxInclusive_Allowances(t *testing.T) {
+	inv := createMinimalInvoice()
+	inv.InvoiceLines = []InvoiceLine{{
+		LineID:                   "1",
+		ItemName:                 "Item",
+		BilledQuantity:           decimal.NewFromInt(1),
+		BilledQuantityUnit:       "C62",
+		TaxInclusivePrice:        decimal.RequireFromString("119.00"),
+		TaxCategoryCode:          "S",
+		TaxRateApplicablePercent: decimal.NewFromInt(19),
+	}}
+	inv.SpecifiedTradeAllowanceCharge = []AllowanceCharge{{
+		ActualAmount:                          decimal.NewFromInt(10),
+		Reason:                                "Discount",
+		CategoryTradeTaxCategoryCode:          "S",
+		CategoryTradeTaxRateApplicablePercent: decimal.NewFromInt(19),
+	}}
+
+	// 119.00 - 11.90 (10.00 net)
+	if receipt := inv.UpdateTaxInclusive(nil); !receipt.Equal(decimal.RequireFromString("107.10")) {
+		t.Errorf("receipt = %s, want 107.10", receipt)
+	}
+	if !inv.RoundingAmount.IsZero() {
+		t.Errorf("RoundingAmount = %s, want 0", inv.RoundingAmount)
+	}
+	if !inv.InvoiceLines[0].NetPrice.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("NetPrice = %s, want 100", inv.InvoiceLines[0].NetPrice)
+	}
+}
+
+func TestUpdateTaxInclusive_CashRounding(t *testing.T) {
+	inv := createMinimalInvoice()
+	inv.InvoiceLines[0].TaxInclusivePrice = decimal.RequireFromString("9.98")
+	receipt := inv.UpdateTaxInclusive(&CalculationOptions{CashRounding: CashRounding005})
+	if !receipt.Equal(decimal.RequireFromString("9.98")) {
+		t.Errorf("receipt = %s, want 9.98", receipt)
+	}
+	if !inv.DuePayableAmount.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("DuePayableAmount = %s, want 10.00", inv.DuePayableAmount)
+	}
+}
+
+func TestUpdateTaxInclusive_Basic(t *testing.T) {
+	inv := createMinimalInvoice()
+	inv.GuidelineSpecifiedDocumentContextParameter = SpecFacturXBasic
+	base := inv.InvoiceLines[0]
+	inv.InvoiceLines = nil
+	for i, price := range []string{"2.99", "1.49", "0.99"} {
+		line := base
+		line.LineID = strconv.Itoa(i + 1)
+		line.TaxInclusivePrice = decimal.RequireFromString(price)
+		inv.InvoiceLines = append(inv.InvoiceLines, line)
+	}
+	receipt := inv.UpdateTaxInclusive(nil)
+
+	// BASIC has no rounding amount (BT-114), the amount due is the invoice total
+	if !inv.RoundingAmount.IsZero() || !inv.DuePayableAmount.Equal(inv.GrandTotal) {
+		t.Errorf("RoundingAmount = %s, DuePayableAmount = %s, want 0 and %s", inv.RoundingAmount, inv.DuePayableAmount, inv.GrandTotal)
+	}
+	if receipt.Equal(inv.GrandTotal) {
+		t.Fatalf("receipt %s equals the invoice total, the test needs a residual", receipt)
+	}
+
+	parsed, err := ParseReader(strings.NewReader(writeWithOptions(t, inv, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Errorf("Validate() after round trip = %v", err)
+	}
+}
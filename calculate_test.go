@@ -3,6 +3,7 @@ package einvoice
 import (
 	"bytes"
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -1212,3 +1213,107 @@ func BenchmarkCalculateBoth(b *testing.B) {
 		inv.UpdateTotals()
 	}
 }
+
+func TestInvoiceLine_Calculate(t *testing.T) {
+	d := decimal.RequireFromString
+	tests := []struct {
+		name      string
+		line      InvoiceLine
+		wantNet   string
+		wantTotal string
+	}{
+		{
+			name:      "quantity times price",
+			line:      InvoiceLine{BilledQuantity: d("3"), NetPrice: d("9.99")},
+			wantNet:   "9.99",
+			wantTotal: "29.97",
+		},
+		{
+			name:      "base quantity",
+			line:      InvoiceLine{BilledQuantity: d("250"), NetPrice: d("12.50"), BasisQuantity: d("100")},
+			wantNet:   "12.5",
+			wantTotal: "31.25",
+		},
+		{
+			name: "gross price with percentage discount",
+			line: InvoiceLine{BilledQuantity: d("2"), GrossPrice: d("40"), AppliedTradeAllowanceCharge: []AllowanceCharge{
+				{CalculationPercent: d("10")},
+			}},
+			wantNet:   "36",
+			wantTotal: "72",
+		},
+		{
+			name: "gross price with discount amount",
+			line: InvoiceLine{BilledQuantity: d("1"), GrossPrice: d("10.00"), AppliedTradeAllowanceCharge: []AllowanceCharge{
+				{ActualAmount: d("0.55")},
+			}},
+			wantNet:   "9.45",
+			wantTotal: "9.45",
+		},
+		{
+			name: "line allowance percentage and charge",
+			line: InvoiceLine{BilledQuantity: d("3"), NetPrice: d("33.333"),
+				InvoiceLineAllowances: []AllowanceCharge{{CalculationPercent: d("5")}},
+				InvoiceLineCharges:    []AllowanceCharge{{ChargeIndicator: true, ActualAmount: d("2.50")}},
+			},
+			wantNet:   "33.333",
+			wantTotal: "97.5", // 100.00 - 5.00 + 2.50
+		},
+		{
+			name:      "rounding half up",
+			line:      InvoiceLine{BilledQuantity: d("1"), NetPrice: d("0.125")},
+			wantNet:   "0.125",
+			wantTotal: "0.13",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := tt.line
+			line.Calculate()
+			if !line.NetPrice.Equal(d(tt.wantNet)) {
+				t.Errorf("NetPrice = %s, want %s", line.NetPrice, tt.wantNet)
+			}
+			if !line.Total.Equal(d(tt.wantTotal)) {
+				t.Errorf("Total = %s, want %s", line.Total, tt.wantTotal)
+			}
+		})
+	}
+
+	line := tests[4].line
+	line.Calculate()
+	if a := line.InvoiceLineAllowances[0]; !a.BasisAmount.Equal(d("100")) || !a.ActualAmount.Equal(d("5")) {
+		t.Errorf("allowance base/amount = %s/%s, want 100/5", a.BasisAmount, a.ActualAmount)
+	}
+}
+
+// TestUpdateLines_Fixtures recalculates the lines of the test fixtures (gross
+// prices, percentages, sub invoice lines), the net prices and net amounts must
+// not change.
+func TestUpdateLines_Fixtures(t *testing.T) {
+	fixtures := []string{
+		"testdata/cii/en16931/zugferd-en16931-rabatte.xml",
+		"testdata/cii/extended/zugferd-extended-fremdwaehrung.xml",
+		"testdata/cii/extended/zf25-subline-nested.xml",
+		"testdata/cii/extended/zf25-subline-information.xml",
+		"testdata/peppol/valid/Allowance-example.xml",
+		"testdata/ubl/invoice/ubl-tc434-example5.xml",
+	}
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			inv, err := ParseXMLFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			orig := slices.Clone(inv.InvoiceLines)
+			for i := range inv.InvoiceLines {
+				inv.InvoiceLines[i].Total = decimal.Zero
+			}
+			inv.UpdateLines()
+			for i, line := range inv.InvoiceLines {
+				if !line.Total.Equal(orig[i].Total) || !line.NetPrice.Equal(orig[i].NetPrice) {
+					t.Errorf("line %s: net price/amount = %s/%s, want %s/%s", line.LineID, line.NetPrice, line.Total, orig[i].NetPrice, orig[i].Total)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice"
+)
+
+// createProfiles maps the --profile names of the create command to the
+// specification identifier (BT-24).
+var createProfiles = map[string]string{
+	"minimum":   einvoice.SpecFacturXMinimum,
+	"basicwl":   einvoice.SpecFacturXBasicWL,
+	"basic":     einvoice.SpecFacturXBasic,
+	"en16931":   einvoice.SpecEN16931,
+	"extended":  einvoice.SpecFacturXExtended,
+	"xrechnung": einvoice.SpecXRechnung30,
+	"peppol":    einvoice.SpecPEPPOLBilling30,
+}
+
+// createOptions are the command line options of the create command.
+type createOptions struct {
+	syntax    string // cii, ubl or empty (schema_type of the JSON input)
+	profile   string // key of createProfiles, a specification identifier or empty
+	calculate bool
+	cashRound string // cash rounding increment of the amount due, such as 0.05
+	force     bool
+	verbose   bool
+	visual    string // visual PDF for a Factur-X output
+	output    string // output file, stdout if empty
+}
+
+func runCreate(args []string) int {
+	// Parse flags for the create subcommand
+	createFlags := flag.NewFlagSet("create", flag.ExitOnError)
+	var opts createOptions
+	createFlags.StringVar(&opts.syntax, "format", "", "XML syntax: cii, ubl (default: schema_type of the input, cii)")
+	createFlags.StringVar(&opts.profile, "profile", "", "Profile: minimum, basicwl, basic, en16931, extended, xrechnung, peppol or a specification identifier URN")
+	createFlags.BoolVar(&opts.calculate, "calculate", false, "Calculate the line amounts, VAT breakdown and document totals")
+	createFlags.StringVar(&opts.cashRound, "cash-rounding", "", "Round the amount due to this increment (e.g. 0.05) with --calculate")
+	createFlags.BoolVar(&opts.force, "force", false, "Write the invoice even if it has validation violations")
+	createFlags.BoolVar(&opts.verbose, "verbose", false, "Show detailed rule descriptions and all fields")
+	createFlags.StringVar(&opts.visual, "pdf", "", "Visual PDF to embed the invoice into (Factur-X output)")
+	createFlags.StringVar(&opts.output, "o", "", "Output file (default: standard output)")
+	createFlags.Usage = createUsage
+
+	// Allow flags after the file name (einvoice create input.json -o invoice.xml).
+	files := parseInterspersed(createFlags, args)
+
+	// Require exactly one file argument
+	if len(files) != 1 {
+		createUsage()
+		return exitError
+	}
+
+	inv, err := createInvoice(files[0], opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	exitCode := exitOK
+	if err := inv.Validate(); err != nil {
+		var ve *einvoice.ValidationError
+		if !errors.As(err, &ve) {
+			fmt.Fprintf(os.Stderr, "Error: validation failed: %v\n", err)
+			return exitError
+		}
+		violations := convertViolations(ve.Violations())
+		fmt.Fprintf(os.Stderr, "✗ Invoice %s has %d violation(s):\n", inv.InvoiceNumber, len(violations))
+		outputViolations(os.Stderr, violations, opts.verbose)
+		if !opts.force {
+			return exitViolations
+		}
+		exitCode = exitViolations
+	}
+
+	if err := writeCreatedInvoice(inv, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitCode
+}
+
+// createInvoice reads the JSON invoice from filename ("-" for standard input)
+// and applies the syntax, profile and calculation options.
+func createInvoice(filename string, opts createOptions) (*einvoice.Invoice, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	inv := &einvoice.Invoice{}
+	if err := json.Unmarshal(data, inv); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(opts.syntax) {
+	case "":
+	case "cii":
+		inv.SchemaType = einvoice.CII
+	case "ubl":
+		inv.SchemaType = einvoice.UBL
+	default:
+		return nil, fmt.Errorf("unknown format %q (use 'cii' or 'ubl')", opts.syntax)
+	}
+	if opts.visual != "" && inv.SchemaType == einvoice.UBL {
+		return nil, errors.New("--pdf requires the CII format")
+	}
+
+	if opts.profile != "" {
+		urn, ok := createProfiles[strings.ToLower(opts.profile)]
+		if !ok {
+			if !einvoice.IsProfileURN(opts.profile) {
+				return nil, fmt.Errorf("unknown profile %q", opts.profile)
+			}
+			urn = opts.profile
+		}
+		inv.GuidelineSpecifiedDocumentContextParameter = urn
+		if urn == einvoice.SpecPEPPOLBilling30 && inv.BPSpecifiedDocumentContextParameter == "" {
+			inv.BPSpecifiedDocumentContextParameter = einvoice.BPPEPPOLBilling01
+		}
+	}
+
+	if opts.cashRound != "" && !opts.calculate {
+		return nil, errors.New("--cash-rounding requires --calculate")
+	}
+	if opts.cashRound != "" && !inv.MeetsProfileLevel(4) {
+		return nil, errors.New("--cash-rounding requires the EN 16931 profile or a more comprehensive one")
+	}
+	if opts.calculate {
+		calc := &einvoice.CalculationOptions{}
+		if opts.cashRound != "" {
+			calc.CashRounding, err = decimal.NewFromString(opts.cashRound)
+			if err != nil || !calc.CashRounding.IsPositive() {
+				return nil, fmt.Errorf("invalid cash rounding increment %q", opts.cashRound)
+			}
+		}
+		if slices.ContainsFunc(inv.InvoiceLines, func(line einvoice.InvoiceLine) bool { return !line.TaxInclusivePrice.IsZero() }) {
+			inv.UpdateTaxInclusive(calc)
+		} else {
+			inv.UpdateLines()
+			inv.UpdateApplicableTradeTaxWithOptions(calc)
+			inv.UpdateTotalsWithOptions(calc)
+		}
+	}
+	return inv, nil
+}
+
+// writeCreatedInvoice writes the invoice as XML or, with a visual PDF, as
+// Factur-X PDF to the output file or standard output.
+func writeCreatedInvoice(inv *einvoice.Invoice, opts createOptions) error {
+	var buf bytes.Buffer
+	if opts.visual != "" {
+		f, err := os.Open(opts.visual)
+		if err != nil {
+			return fmt.Errorf("failed to open PDF: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		if err := inv.WritePDF(&buf, f); err != nil {
+			return err
+		}
+	} else if err := inv.Write(&buf); err != nil {
+		return err
+	}
+
+	if opts.output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(opts.output, buf.Bytes(), 0o644)
+}
+
+func createUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: einvoice create [options] <file.json>
+
+Creates an invoice XML (or a ZUGFeRD/Factur-X PDF) from a JSON invoice.
+
+The JSON input uses the mapping of the einvoice library (keys annotated with
+the business terms, e.g. "bt1_invoice_number"), see schema/invoice.schema.json.
+Use "-" to read from standard input.
+
+The invoice is validated before it is written. Validation violations are
+printed to standard error and nothing is written unless --force is given.
+
+Options:
+  --format string    XML syntax: cii, ubl (default: schema_type of the input, cii)
+  --profile string   Profile: minimum, basicwl, basic, en16931, extended,
+                     xrechnung, peppol or a specification identifier URN (BT-24)
+  --calculate        Calculate the line net amounts (BT-131), the VAT breakdown
+                     (BG-23) and the document totals. With tax inclusive line
+                     prices (tax_inclusive_price) the rounding amount (BT-114)
+                     makes the amount due match the receipt total
+  --cash-rounding d  With --calculate: round the amount due (BT-115) to the
+                     increment d, e.g. 0.05 for CHF, and set the rounding
+                     amount (BT-114). Requires EN 16931 or a more
+                     comprehensive profile
+  --pdf file         Embed the CII invoice into this visual PDF (Factur-X output)
+  -o file            Output file (default: standard output)
+  --force            Write the invoice even if it has validation violations
+  --verbose          Show detailed rule descriptions and all fields
+  --help             Show this help message
+
+Exit codes:
+  0  Invoice written
+  1  Error occurred (file not found, invalid JSON, etc.)
+  2  Invoice has validation violations
+
+Examples:
+  einvoice create --profile xrechnung --calculate invoice.json -o invoice.xml
+  einvoice create --format ubl --profile peppol invoice.json > invoice.xml
+  einvoice create --profile en16931 --pdf visual.pdf invoice.json -o invoice.pdf
+`)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/speedata/einvoice"
+)
+
+// writeInvoiceJSON converts an XML fixture to the JSON input of the create
+// command and returns the path of the JSON file.
+func writeInvoiceJSON(t *testing.T, fixture string) string {
+	t.Helper()
+	inv, err := einvoice.ParseXMLFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "invoice.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// minimalPDF returns a PDF with an empty page tree.
+func minimalPDF() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	off1 := buf.Len()
+	buf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	off2 := buf.Len()
+	buf.WriteString("2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n")
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 3\n0000000000 65535 f \n%010d 00000 n \n%010d 00000 n \n", off1, off2)
+	fmt.Fprintf(&buf, "trailer\n<< /Size 3 /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", xref)
+	return buf.Bytes()
+}
+
+func TestRunCreate(t *testing.T) {
+	input := writeInvoiceJSON(t, "../../testdata/cii/en16931/CII_example1.xml")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantSchema einvoice.CodeSchemaType
+	}{
+		{"cii", nil, einvoice.CII},
+		{"ubl", []string{"--format", "ubl"}, einvoice.UBL},
+		{"xrechnung", []string{"--profile", "xrechnung", "--force"}, einvoice.CII},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "invoice.xml")
+			args := append(append([]string{}, tt.args...), input, "-o", out)
+			if code := runCreate(args); code != exitOK && code != exitViolations {
+				t.Fatalf("runCreate() = %d", code)
+			}
+			inv, err := einvoice.ParseXMLFile(out)
+			if err != nil {
+				t.Fatalf("written XML does not parse: %v", err)
+			}
+			if inv.SchemaType != tt.wantSchema {
+				t.Errorf("SchemaType = %v, want %v", inv.SchemaType, tt.wantSchema)
+			}
+			if inv.InvoiceNumber != "12115118" {
+				t.Errorf("InvoiceNumber = %q", inv.InvoiceNumber)
+			}
+		})
+	}
+}
+
+func TestRunCreate_Profile(t *testing.T) {
+	input := writeInvoiceJSON(t, "../../testdata/cii/en16931/CII_example1.xml")
+	inv, err := createInvoice(input, createOptions{profile: "peppol", calculate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.GuidelineSpecifiedDocumentContextParameter != einvoice.SpecPEPPOLBilling30 {
+		t.Errorf("BT-24 = %q", inv.GuidelineSpecifiedDocumentContextParameter)
+	}
+	if inv.BPSpecifiedDocumentContextParameter != einvoice.BPPEPPOLBilling01 {
+		t.Errorf("BT-23 = %q", inv.BPSpecifiedDocumentContextParameter)
+	}
+
+	if _, err := createInvoice(input, createOptions{profile: "unknown"}); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+	if _, err := createInvoice(input, createOptions{syntax: "ubl", visual: "visual.pdf"}); err == nil {
+		t.Error("expected error for UBL with --pdf")
+	}
+}
+
+func TestRunCreate_Violations(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "invoice.json")
+	if err := os.WriteFile(input, []byte(`{"version":1,"bt1_invoice_number":"1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "invoice.xml")
+
+	if code := runCreate([]string{input, "-o", out}); code != exitViolations {
+		t.Errorf("runCreate() = %d, want %d", code, exitViolations)
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Error("invoice written despite violations")
+	}
+
+	if code := runCreate([]string{"--force", input, "-o", out}); code != exitViolations {
+		t.Errorf("runCreate(--force) = %d, want %d", code, exitViolations)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("invoice not written with --force: %v", err)
+	}
+}
+
+func TestRunCreate_Errors(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "invoice.json")
+	if err := os.WriteFile(input, []byte(`{"unknown_key":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{input},
+		{"non_existent.json"},
+		{},
+	} {
+		if code := runCreate(args); code != exitError {
+			t.Errorf("runCreate(%v) = %d, want %d", args, code, exitError)
+		}
+	}
+}
+
+func TestRunCreate_PDF(t *testing.T) {
+	input := writeInvoiceJSON(t, "../../testdata/cii/en16931/CII_example1.xml")
+	dir := t.TempDir()
+	visual := filepath.Join(dir, "visual.pdf")
+	if err := os.WriteFile(visual, minimalPDF(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "invoice.pdf")
+	if code := runCreate([]string{"--pdf", visual, input, "-o", out}); code != exitOK {
+		t.Fatalf("runCreate() = %d", code)
+	}
+	inv, info, err := einvoice.ParseFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.InvoiceFilename != "factur-x.xml" || inv.InvoiceNumber != "12115118" {
+		t.Errorf("got %s with invoice %s", info.InvoiceFilename, inv.InvoiceNumber)
+	}
+}
+
+func TestRunCreate_CashRounding(t *testing.T) {
+	input := writeInvoiceJSON(t, "../../testdata/cii/en16931/CII_example1.xml")
+	inv, err := createInvoice(input, createOptions{profile: "en16931", calculate: true, cashRound: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inv.DuePayableAmount.Equal(inv.DuePayableAmount.Truncate(0)) {
+		t.Errorf("DuePayableAmount = %s, want whole units", inv.DuePayableAmount)
+	}
+	if want := inv.GrandTotal.Sub(inv.TotalPrepaid).Add(inv.RoundingAmount); !inv.DuePayableAmount.Equal(want) {
+		t.Errorf("DuePayableAmount = %s, want %s", inv.DuePayableAmount, want)
+	}
+
+	for _, opts := range []createOptions{
+		{cashRound: "0.05"},
+		{profile: "basic", calculate: true, cashRound: "1"},
+		{calculate: true, cashRound: "abc"},
+		{calculate: true, cashRound: "-1"},
+	} {
+		if _, err := createInvoice(input, opts); err == nil {
+			t.Errorf("createInvoice(%+v): expected error", opts)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice"
+)
+
+// ExplainInfo is the breakdown of the document totals for display
+type ExplainInfo struct {
+	File         string          `json:"file"`
+	Currency     string          `json:"currency,omitempty"`
+	Valid        bool            `json:"valid"`
+	Totals       []ExplainedSum  `json:"totals,omitempty"`
+	VATBreakdown []ExplainedSum  `json:"vat_breakdown,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	ParseError   *ParseErrorInfo `json:"parse_error,omitempty"`
+}
+
+// ExplainedSum is one total (BT-106 to BT-116) with its components
+type ExplainedSum struct {
+	Term         string             `json:"term"`
+	Name         string             `json:"name"`
+	Rule         string             `json:"rule,omitempty"`
+	CategoryCode string             `json:"category_code,omitempty"`
+	Percent      string             `json:"percent,omitempty"`
+	Components   []ExplainComponent `json:"components"`
+	Sum          string             `json:"sum"`
+	Rounding     string             `json:"rounding,omitempty"`
+	Calculated   string             `json:"calculated"`
+	Declared     string             `json:"declared"`
+	Tolerance    string             `json:"tolerance,omitempty"`
+	Difference   string             `json:"difference,omitempty"`
+	Matches      bool               `json:"matches"`
+}
+
+// ExplainComponent is an amount entering a total
+type ExplainComponent struct {
+	Term   string `json:"term"`
+	Label  string `json:"label"`
+	Amount string `json:"amount"`
+}
+
+func runExplain(args []string) int {
+	// Parse flags for the explain subcommand
+	explainFlags := flag.NewFlagSet("explain", flag.ExitOnError)
+	var format string
+	explainFlags.StringVar(&format, "format", "text", "Output format: text, json")
+	explainFlags.Usage = explainUsage
+	_ = explainFlags.Parse(args)
+
+	// Require exactly one file argument
+	if explainFlags.NArg() != 1 {
+		explainUsage()
+		return exitError
+	}
+
+	info := explainInvoice(explainFlags.Arg(0))
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		}
+	case "text":
+		if info.Error != "" {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", info.Error)
+		} else {
+			outputExplainText(os.Stdout, info)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (use 'text' or 'json')\n", format)
+		return exitError
+	}
+
+	if info.Error != "" {
+		return exitError
+	}
+	if !info.Valid {
+		return exitViolations
+	}
+	return exitOK
+}
+
+// explainInvoice parses the file and returns the breakdown of its totals.
+func explainInvoice(filename string) ExplainInfo {
+	info := ExplainInfo{File: filename}
+	inv, err := parseInvoiceFile(filename)
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to parse invoice: %v", err)
+		info.ParseError = newParseErrorInfo(err)
+		return info
+	}
+
+	te := inv.ExplainTotals()
+	info.Currency = te.Currency
+	info.Valid = te.Valid()
+	for _, e := range te.Totals {
+		info.Totals = append(info.Totals, newExplainedSum(e))
+	}
+	for _, e := range te.VATBreakdown {
+		info.VATBreakdown = append(info.VATBreakdown, newExplainedSum(e))
+	}
+	return info
+}
+
+func newExplainedSum(e einvoice.TotalExplanation) ExplainedSum {
+	es := ExplainedSum{
+		Term:         e.Term,
+		Name:         e.Name,
+		Rule:         e.Rule.Code,
+		CategoryCode: e.CategoryCode,
+		Sum:          formatExplainAmount(e.Sum),
+		Calculated:   formatExplainAmount(e.Calculated),
+		Declared:     formatExplainAmount(e.Declared),
+		Matches:      e.Matches(),
+		Components:   []ExplainComponent{},
+	}
+	if e.Term == "BT-116" {
+		es.Percent = e.Percent.String()
+	}
+	if !e.Rounding().IsZero() {
+		es.Rounding = formatExplainAmount(e.Rounding())
+	}
+	if !e.Tolerance.IsZero() {
+		es.Tolerance = e.Tolerance.String()
+	}
+	if !e.Difference().IsZero() {
+		es.Difference = formatExplainAmount(e.Difference())
+	}
+	for _, c := range e.Components {
+		es.Components = append(es.Components, ExplainComponent{Term: c.Term, Label: c.Label, Amount: formatExplainAmount(c.Amount)})
+	}
+	return es
+}
+
+// formatExplainAmount formats an amount with at least two decimals, so that
+// amounts with more decimals (not rounded yet) stay visible.
+func formatExplainAmount(d decimal.Decimal) string {
+	if d.Exponent() < -2 {
+		return d.String()
+	}
+	return d.StringFixed(2)
+}
+
+func outputExplainText(w io.Writer, info ExplainInfo) {
+	fmt.Fprintf(w, "Totals of %s (%s)\n\n", info.File, info.Currency)
+	// Amounts are right aligned within their column
+	width := len("Amount")
+	for _, es := range append(slices.Clone(info.Totals), info.VATBreakdown...) {
+		for _, amount := range []string{es.Sum, es.Rounding, es.Calculated, es.Declared} {
+			width = max(width, len(amount))
+		}
+		for _, c := range es.Components {
+			width = max(width, len(c.Amount))
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Term\tComponent\t%*s\tCheck\n", width, "Amount")
+	for _, es := range info.Totals {
+		writeExplainedSum(tw, es, es.Name, width)
+	}
+	for _, es := range info.VATBreakdown {
+		writeExplainedSum(tw, es, fmt.Sprintf("%s, category %s %s%%", es.Name, es.CategoryCode, es.Percent), width)
+	}
+	_ = tw.Flush()
+
+	if info.Valid {
+		fmt.Fprintln(w, "\n✓ All totals match")
+	} else {
+		fmt.Fprintln(w, "\n✗ Declared totals differ from the calculation")
+	}
+}
+
+// writeExplainedSum writes the rows of one total: a heading, one row per
+// component, the rounding step and the comparison of the declared amount.
+func writeExplainedSum(w io.Writer, es ExplainedSum, title string, width int) {
+	if es.Rule != "" {
+		title += " (" + es.Rule + ")"
+	}
+	fmt.Fprintf(w, "%s\t%s\t\t\n", es.Term, title)
+	for _, c := range es.Components {
+		fmt.Fprintf(w, "\t  %s %s\t%*s\t\n", c.Term, c.Label, width, c.Amount)
+	}
+	if es.Rounding != "" {
+		fmt.Fprintf(w, "\tsum\t%*s\t\n", width, es.Sum)
+		fmt.Fprintf(w, "\trounding\t%*s\t\n", width, es.Rounding)
+	}
+	fmt.Fprintf(w, "\tcalculated\t%*s\t\n", width, es.Calculated)
+	check := "✓"
+	if !es.Matches {
+		check = "✗ difference " + es.Difference
+	}
+	if es.Tolerance != "" {
+		check += " (tolerance " + es.Tolerance + ")"
+	}
+	fmt.Fprintf(w, "\tdeclared\t%*s\t%s\n", width, es.Declared, check)
+	fmt.Fprintln(w, "\t\t\t")
+}
+
+func explainUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: einvoice explain [options] <file>
+
+Explains how the document totals (BT-106 to BT-115) and the VAT category
+taxable amounts (BT-116) of an electronic invoice are derived. Every invoice
+line and document level allowance or charge entering a sum is listed with the
+rounding applied, the tolerance of the checking business rule and the
+declared amount.
+
+Use it to trace violations of BR-CO-10 to BR-CO-16 or BR-S-08 and the like
+reported by "einvoice validate" back to the amounts causing them.
+
+Supports both XML and ZUGFeRD/Factur-X PDF formats.
+
+Options:
+  --format string   Output format: text, json (default "text")
+  --help            Show this help message
+
+Exit codes:
+  0  All declared totals match the calculation
+  1  Error occurred (file not found, parse error, etc.)
+  2  At least one declared total differs from the calculation
+
+Examples:
+  einvoice explain invoice.xml
+  einvoice explain --format json invoice.pdf
+`)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExplainInvoice(t *testing.T) {
+	info := explainInvoice("../../testdata/cii/en16931/CII_example1.xml")
+	if info.Error != "" {
+		t.Fatal(info.Error)
+	}
+	if !info.Valid || info.Currency != "EUR" {
+		t.Errorf("Valid = %v, Currency = %q, want true and EUR", info.Valid, info.Currency)
+	}
+	if len(info.Totals) == 0 || info.Totals[0].Term != "BT-106" || len(info.Totals[0].Components) != 20 {
+		t.Fatalf("BT-106 = %+v, want 20 line components", info.Totals)
+	}
+	if c := info.Totals[0].Components[0]; c.Label != "line 1" || c.Amount != "19.90" {
+		t.Errorf("first component = %+v", c)
+	}
+	if len(info.VATBreakdown) != 2 || info.VATBreakdown[1].Percent != "21" || info.VATBreakdown[1].Declared != "46.37" {
+		t.Errorf("VAT breakdown = %+v", info.VATBreakdown)
+	}
+
+	var buf bytes.Buffer
+	outputExplainText(&buf, info)
+	out := buf.String()
+	for _, want := range []string{
+		"Sum of Invoice line net amount (BR-CO-10)",
+		"BT-131 line 20",
+		"-109.98",
+		"VAT category taxable amount, category S 21% (BR-S-08)",
+		"✓ All totals match",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunExplain_Mismatch(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte("<ram:TaxBasisTotalAmount>229.6<"), []byte("<ram:TaxBasisTotalAmount>229.7<"), 1)
+	file := filepath.Join(t.TempDir(), "invoice.xml")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	info := explainInvoice(file)
+	var bt109 ExplainedSum
+	for _, es := range info.Totals {
+		if es.Term == "BT-109" {
+			bt109 = es
+		}
+	}
+	if info.Valid || bt109.Matches || bt109.Difference != "0.10" {
+		t.Errorf("BT-109 = %+v, want difference 0.10", bt109)
+	}
+
+	oldStdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stdout = oldStdout }()
+	if code := runExplain([]string{file}); code != exitViolations {
+		t.Errorf("runExplain() = %d, want %d", code, exitViolations)
+	}
+	if code := runExplain([]string{"--format", "json", "../../testdata/cii/en16931/CII_example1.xml"}); code != exitOK {
+		t.Errorf("runExplain() = %d, want %d", code, exitOK)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/speedata/einvoice"
+)
+
+// manifestFilename is the name of the manifest written to the output directory.
+const manifestFilename = "manifest.json"
+
+// ExtractManifest describes the files written by the extract command.
+type ExtractManifest struct {
+	File        string          `json:"file"`
+	Directory   string          `json:"directory"`
+	Invoice     *ExtractedFile  `json:"invoice,omitempty"`
+	Attachments []ExtractedFile `json:"attachments"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// ExtractedFile is a single extracted file. Path is relative to the output
+// directory.
+type ExtractedFile struct {
+	Path         string `json:"path"`
+	OriginalName string `json:"original_name,omitempty"`
+	ID           string `json:"id,omitempty"`          // BT-122
+	Description  string `json:"description,omitempty"` // BT-123
+	MimeType     string `json:"mime_type,omitempty"`   // BT-125
+	Size         int    `json:"size"`
+}
+
+// mimeExtensions maps the MIME codes allowed for BT-125 (and the XML types)
+// to file extensions.
+var mimeExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"text/csv":        ".csv",
+	"text/xml":        ".xml",
+	"application/xml": ".xml",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": ".xlsx",
+	"application/vnd.oasis.opendocument.spreadsheet":                    ".ods",
+}
+
+func runExtract(args []string) int {
+	// Parse flags for the extract subcommand
+	extractFlags := flag.NewFlagSet("extract", flag.ExitOnError)
+	var format string
+	var outDir string
+	extractFlags.StringVar(&format, "format", "text", "Output format: text, json")
+	extractFlags.StringVar(&outDir, "o", ".", "Output directory")
+	extractFlags.Usage = extractUsage
+
+	// Allow flags after the file name (einvoice extract invoice.pdf -o dir).
+	files := parseInterspersed(extractFlags, args)
+
+	// Require exactly one file argument
+	if len(files) != 1 {
+		extractUsage()
+		return exitError
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (use 'text' or 'json')\n", format)
+		return exitError
+	}
+
+	manifest := extractFiles(files[0], outDir)
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(manifest); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		}
+	case "text":
+		outputExtractText(manifest)
+	}
+
+	if manifest.Error != "" {
+		return exitError
+	}
+	return exitOK
+}
+
+// extractFiles writes the embedded invoice XML (PDF input only) and all
+// BT-125 attachments of the invoice to outDir, followed by the manifest.
+func extractFiles(filename, outDir string) ExtractManifest {
+	manifest := ExtractManifest{
+		File:        filename,
+		Directory:   outDir,
+		Attachments: []ExtractedFile{},
+	}
+
+	inv, pdfInfo, err := einvoice.ParseFile(filename)
+	if err != nil {
+		manifest.Error = fmt.Sprintf("Failed to parse invoice: %v", err)
+		return manifest
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		manifest.Error = fmt.Sprintf("Failed to create output directory: %v", err)
+		return manifest
+	}
+
+	used := map[string]bool{manifestFilename: true}
+	write := func(name string, data []byte) (string, error) {
+		name = uniqueFilename(name, used)
+		if err := os.WriteFile(filepath.Join(outDir, name), data, 0o644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return name, nil
+	}
+
+	if pdfInfo != nil {
+		if att := pdfInfo.Attachment(pdfInfo.InvoiceFilename); att != nil {
+			name := sanitizeFilename(att.Name)
+			if name == "" {
+				name = "invoice.xml"
+			}
+			p, err := write(name, att.Data)
+			if err != nil {
+				manifest.Error = err.Error()
+				return manifest
+			}
+			manifest.Invoice = &ExtractedFile{
+				Path:         p,
+				OriginalName: att.Name,
+				MimeType:     att.MimeType,
+				Size:         len(att.Data),
+			}
+		}
+	}
+
+	for i, doc := range inv.AdditionalReferencedDocument {
+		if len(doc.AttachmentBinaryObject) == 0 {
+			continue
+		}
+		p, err := write(attachmentFilename(doc, i+1), doc.AttachmentBinaryObject)
+		if err != nil {
+			manifest.Error = err.Error()
+			return manifest
+		}
+		manifest.Attachments = append(manifest.Attachments, ExtractedFile{
+			Path:         p,
+			OriginalName: doc.AttachmentFilename,
+			ID:           doc.IssuerAssignedID,
+			Description:  doc.Name,
+			MimeType:     doc.AttachmentMimeCode,
+			Size:         len(doc.AttachmentBinaryObject),
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(outDir, manifestFilename), append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		manifest.Error = fmt.Sprintf("Failed to write manifest: %v", err)
+	}
+	return manifest
+}
+
+// attachmentFilename returns the file name for a BT-125 attachment: the
+// attachment file name, or the document ID (BT-122) with an extension derived
+// from the MIME code. n is used when neither yields a usable name.
+func attachmentFilename(doc einvoice.Document, n int) string {
+	if name := sanitizeFilename(doc.AttachmentFilename); name != "" {
+		return name
+	}
+	ext, ok := mimeExtensions[strings.ToLower(doc.AttachmentMimeCode)]
+	if !ok {
+		ext = ".bin"
+	}
+	if name := sanitizeFilename(doc.IssuerAssignedID); name != "" {
+		return name + ext
+	}
+	return fmt.Sprintf("attachment-%d%s", n, ext)
+}
+
+// sanitizeFilename reduces name to a plain file name that cannot escape the
+// output directory: directory components are removed, control and reserved
+// characters are replaced and leading dots are stripped. It returns an empty
+// string if nothing usable remains.
+func sanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(strings.TrimLeft(name, ". "))
+	if name == "/" {
+		return ""
+	}
+	return name
+}
+
+// uniqueFilename returns name, or name with a numeric suffix if it was used
+// before, and records the result in used. The comparison is case-insensitive
+// for case-insensitive file systems.
+func uniqueFilename(name string, used map[string]bool) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func outputExtractText(manifest ExtractManifest) {
+	if manifest.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", manifest.Error)
+		return
+	}
+
+	count := len(manifest.Attachments)
+	if manifest.Invoice != nil {
+		count++
+	}
+	fmt.Printf("Extracted %d file(s) to %s:\n", count, manifest.Directory)
+	if manifest.Invoice != nil {
+		fmt.Printf("  %s (invoice, %d bytes)\n", manifest.Invoice.Path, manifest.Invoice.Size)
+	}
+	for _, att := range manifest.Attachments {
+		fmt.Printf("  %s (%s, %d bytes)\n", att.Path, att.MimeType, att.Size)
+	}
+	fmt.Printf("Manifest: %s\n", filepath.Join(manifest.Directory, manifestFilename))
+}
+
+func extractUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: einvoice extract [options] <file>
+
+Extracts the embedded invoice XML from a ZUGFeRD/Factur-X PDF and all
+attachments (BT-125) contained in the invoice into a directory. A JSON
+manifest (%s) describing the extracted files is written as well.
+
+Attachments are named after their file name (BT-125 filename), falling back
+to the document identifier (BT-122) with an extension derived from the MIME
+code. File names are sanitized so that no file is written outside of the
+output directory.
+
+Options:
+  -o string         Output directory (default ".")
+  --format string   Output format: text, json (default "text")
+  --help            Show this help message
+
+Exit codes:
+  0  Files extracted
+  1  Error occurred (file not found, parse error, write error, etc.)
+
+Examples:
+  einvoice extract invoice.pdf -o attachments
+  einvoice extract --format json invoice.xml -o out
+`, manifestFilename)
+}
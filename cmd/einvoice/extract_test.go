@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/speedata/einvoice"
+)
+
+func TestExtractFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+
+	manifest := extractFiles("../../testdata/ubl/invoice/ubl-tc434-example2.xml", dir)
+	if manifest.Error != "" {
+		t.Fatalf("extractFiles() error = %s", manifest.Error)
+	}
+	if manifest.Invoice != nil {
+		t.Errorf("Invoice = %+v, want nil for XML input", manifest.Invoice)
+	}
+	if len(manifest.Attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(manifest.Attachments))
+	}
+	att := manifest.Attachments[0]
+	if att.Path != "test.pdf" || att.MimeType != "application/pdf" {
+		t.Errorf("attachment = %+v", att)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "test.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Testing Base64 encoding" {
+		t.Errorf("test.pdf content = %q", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, manifestFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written ExtractManifest
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if len(written.Attachments) != 1 || written.Attachments[0].Size != len("Testing Base64 encoding") {
+		t.Errorf("manifest attachments = %+v", written.Attachments)
+	}
+}
+
+func TestExtractFiles_NonExistent(t *testing.T) {
+	manifest := extractFiles("non_existent_file.xml", t.TempDir())
+	if manifest.Error == "" {
+		t.Error("extractFiles() expected error, got none")
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"invoice.pdf", "invoice.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{`..\..\windows\win.ini`, "win.ini"},
+		{"/absolute/path.csv", "path.csv"},
+		{"..", ""},
+		{".hidden", "hidden"},
+		{"", ""},
+		{"/", ""},
+		{"a:b*c?.png", "a_b_c_.png"},
+		{"line\nbreak.txt", "line_break.txt"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeFilename(tt.in); got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAttachmentFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  einvoice.Document
+		want string
+	}{
+		{"filename", einvoice.Document{AttachmentFilename: "sheet.xlsx", IssuerAssignedID: "A1"}, "sheet.xlsx"},
+		{"ID and MIME code", einvoice.Document{IssuerAssignedID: "A1", AttachmentMimeCode: "image/png"}, "A1.png"},
+		{"unknown MIME code", einvoice.Document{IssuerAssignedID: "A1", AttachmentMimeCode: "foo/bar"}, "A1.bin"},
+		{"ID with path", einvoice.Document{IssuerAssignedID: "../A1", AttachmentMimeCode: "application/pdf"}, "A1.pdf"},
+		{"nothing", einvoice.Document{AttachmentMimeCode: "text/csv"}, "attachment-3.csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attachmentFilename(tt.doc, 3); got != tt.want {
+				t.Errorf("attachmentFilename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueFilename(t *testing.T) {
+	used := map[string]bool{manifestFilename: true}
+	for _, want := range []string{"a.pdf", "a-2.pdf", "a-3.pdf"} {
+		if got := uniqueFilename("a.pdf", used); got != want {
+			t.Errorf("uniqueFilename() = %q, want %q", got, want)
+		}
+	}
+	if got := uniqueFilename("Manifest.json", used); got != "Manifest-2.json" {
+		t.Errorf("uniqueFilename() = %q, want Manifest-2.json", got)
+	}
+}
@@ -20,9 +20,10 @@ var embeddedTemplates embed.FS
 
 // InvoiceInfo represents the complete invoice information for display
 type InvoiceInfo struct {
-	File    string          `json:"file"`
-	Invoice *InvoiceDetails `json:"invoice,omitempty"`
-	Error   string          `json:"error,omitempty"`
+	File       string          `json:"file"`
+	Invoice    *InvoiceDetails `json:"invoice,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	ParseError *ParseErrorInfo `json:"parse_error,omitempty"`
 }
 
 // NoteInfo holds an invoice note and its subject qualifier (e.g., UNCL 4451
@@ -409,6 +410,7 @@ func getInvoiceInfo(filename string, showCodes bool, verbose bool) InvoiceInfo {
 	invoice, err := parseInvoiceFile(filename)
 	if err != nil {
 		info.Error = fmt.Sprintf("Failed to parse invoice: %v", err)
+		info.ParseError = newParseErrorInfo(err)
 		return info
 	}
 
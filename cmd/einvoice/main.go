@@ -2,6 +2,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 )
@@ -9,7 +10,7 @@ import (
 const (
 	exitOK         = 0 // Success
 	exitError      = 1 // Error occurred (file not found, parse error, etc.)
-	exitViolations = 2 // Invoice has validation violations (validate command only)
+	exitViolations = 2 // Invoice has validation violations (validate, pdfcheck and explain commands)
 )
 
 func main() {
@@ -29,8 +30,18 @@ func run() int {
 	switch subcommand {
 	case "validate":
 		return runValidate(os.Args[2:])
+	case "create":
+		return runCreate(os.Args[2:])
+	case "explain":
+		return runExplain(os.Args[2:])
+	case "extract":
+		return runExtract(os.Args[2:])
 	case "info":
 		return runInfo(os.Args[2:])
+	case "pdfcheck":
+		return runPDFCheck(os.Args[2:])
+	case "render":
+		return runRender(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n", subcommand)
 		usage()
@@ -42,9 +53,32 @@ func usage() {
 	fmt.Fprintf(os.Stderr, `Usage: einvoice <command> [options]
 
 Commands:
+  create      Create an invoice XML or Factur-X PDF from JSON
+  explain     Explain how the totals of an electronic invoice are derived
+  extract     Extract the embedded invoice XML and attachments (BT-125)
   info        Display detailed information about an electronic invoice
+  pdfcheck    Check a ZUGFeRD/Factur-X PDF for PDF/A-3 and Factur-X conformance
+  render      Render an electronic invoice as HTML or PDF
   validate    Validate an electronic invoice against EN 16931 business rules
 
 Use "einvoice <command> --help" for more information about a command.
 `)
 }
+
+// parseInterspersed parses the flags in args like fs.Parse, but also accepts
+// flags after the positional arguments, which it returns. All arguments after
+// the terminator "--" are positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		if parsed := len(args) - fs.NArg(); parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, fs.Args()...)
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
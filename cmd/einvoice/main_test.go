@@ -1,8 +1,10 @@
 package main
 
 import (
+	"flag"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -140,3 +142,27 @@ func TestSubcommandDispatch(t *testing.T) {
 		})
 	}
 }
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	out := fs.String("o", "", "")
+	verbose := fs.Bool("verbose", false, "")
+
+	files := parseInterspersed(fs, []string{"--verbose", "a.xml", "-o", "dir", "b.xml"})
+	if !slices.Equal(files, []string{"a.xml", "b.xml"}) {
+		t.Errorf("parseInterspersed() = %v, want [a.xml b.xml]", files)
+	}
+	if *out != "dir" || !*verbose {
+		t.Errorf("flags -o = %q, --verbose = %v", *out, *verbose)
+	}
+
+	// Arguments after the terminator are positional, even if they look like flags
+	*out = ""
+	files = parseInterspersed(fs, []string{"c.xml", "--", "a.pdf", "-o", "x"})
+	if !slices.Equal(files, []string{"c.xml", "a.pdf", "-o", "x"}) {
+		t.Errorf("parseInterspersed() = %v, want [c.xml a.pdf -o x]", files)
+	}
+	if *out != "" {
+		t.Errorf("flag -o = %q after terminator", *out)
+	}
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -10,25 +10,59 @@ import (
 )
 
 // parseInvoiceFile parses an invoice from an XML file or ZUGFeRD/Factur-X PDF.
-// It detects the file type based on the file extension and routes to the
-// appropriate parser.
+// It accepts files with a .xml or .pdf extension; the format itself is
+// detected from the file content by einvoice.ParseFile.
 //
 // Supported formats:
 //   - Plain XML invoice files
 //   - ZUGFeRD/Factur-X PDF files with embedded XML
 func parseInvoiceFile(filename string) (*einvoice.Invoice, error) {
 	switch strings.ToLower(filepath.Ext(filename)) {
-	case ".pdf":
-		// Extract embedded XML from PDF
-		xmlBytes, err := extractXMLFromPDF(filename)
-		if err != nil {
-			return nil, err
-		}
-		// Parse the extracted XML
-		return einvoice.ParseReader(bytes.NewReader(xmlBytes))
-	case ".xml":
-		return einvoice.ParseXMLFile(filename)
+	case ".pdf", ".xml":
+		inv, _, err := einvoice.ParseFile(filename)
+		return inv, err
 	default:
 		return nil, fmt.Errorf("unsupported file format (expected XML or PDF)")
 	}
 }
+
+// ParseErrorInfo is the machine-readable form of a parse failure for JSON
+// output.
+type ParseErrorInfo struct {
+	Kind    string `json:"kind"` // malformed_xml, empty_namespace, unknown_namespace or invalid_value
+	Field   string `json:"field,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// newParseErrorInfo returns the details of a parse failure, or nil if err is
+// not caused by the invoice content (for example file not found).
+func newParseErrorInfo(err error) *ParseErrorInfo {
+	info := &ParseErrorInfo{Message: err.Error()}
+	switch {
+	case errors.Is(err, einvoice.ErrMalformedXML):
+		info.Kind = "malformed_xml"
+	case errors.Is(err, einvoice.ErrEmptyNamespace):
+		info.Kind = "empty_namespace"
+	case errors.Is(err, einvoice.ErrUnknownNamespace):
+		info.Kind = "unknown_namespace"
+	}
+	var pe *einvoice.ParseError
+	if errors.As(err, &pe) {
+		if info.Kind == "" {
+			info.Kind = "invalid_value"
+		}
+		info.Field = pe.Field
+		info.Path = pe.Path
+		info.Value = pe.Value
+		info.Line = pe.Line
+		info.Column = pe.Column
+	}
+	if info.Kind == "" {
+		return nil
+	}
+	return info
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -183,3 +184,45 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestNewParseErrorInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := []struct {
+		name     string
+		content  string
+		wantKind string
+	}{
+		{"malformed", "<Invoice>\n<ID></Invoice>", "malformed_xml"},
+		{"empty namespace", "<Invoice/>", "empty_namespace"},
+		{"unknown namespace", `<Invoice xmlns="urn:example"/>`, "unknown_namespace"},
+		{"invalid value", `<Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+    xmlns:cbc="urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2">
+  <cbc:IssueDate>01.01.2024</cbc:IssueDate>
+</Invoice>`, "invalid_value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(tmpDir, strings.ReplaceAll(tt.name, " ", "_")+".xml")
+			if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			result := validateInvoice(filename)
+			if result.ParseError == nil {
+				t.Fatalf("ParseError = nil, error = %s", result.Error)
+			}
+			if result.ParseError.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", result.ParseError.Kind, tt.wantKind)
+			}
+			if tt.wantKind == "invalid_value" {
+				pe := result.ParseError
+				if pe.Field != "BT-2" || pe.Value != "01.01.2024" || pe.Line != 3 {
+					t.Errorf("ParseError = %+v", pe)
+				}
+			}
+		})
+	}
+
+	if info := newParseErrorInfo(os.ErrNotExist); info != nil {
+		t.Errorf("newParseErrorInfo(ErrNotExist) = %+v, want nil", info)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/speedata/einvoice"
+)
+
+func runPDFCheck(args []string) int {
+	// Parse flags for the pdfcheck subcommand
+	pdfcheckFlags := flag.NewFlagSet("pdfcheck", flag.ExitOnError)
+	var format string
+	var verbose bool
+	pdfcheckFlags.StringVar(&format, "format", "text", "Output format: text, json")
+	pdfcheckFlags.BoolVar(&verbose, "verbose", false, "Show detailed rule descriptions")
+	pdfcheckFlags.Usage = pdfcheckUsage
+	_ = pdfcheckFlags.Parse(args)
+
+	// Require exactly one file argument
+	if pdfcheckFlags.NArg() != 1 {
+		pdfcheckUsage()
+		return exitError
+	}
+
+	result := checkPDF(pdfcheckFlags.Arg(0))
+
+	switch format {
+	case "json":
+		outputJSON(result)
+	case "text":
+		outputPDFCheckText(result, verbose)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (use 'text' or 'json')\n", format)
+		return exitError
+	}
+
+	if result.Error != "" {
+		return exitError
+	}
+	if !result.Valid {
+		return exitViolations
+	}
+	return exitOK
+}
+
+// checkPDF runs the Factur-X PDF/A-3 conformance checks on the given file.
+func checkPDF(filename string) Result {
+	result := Result{
+		File: filename,
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to open file: %v", err)
+		return result
+	}
+	defer func() { _ = f.Close() }()
+
+	_, err = einvoice.CheckPDF(f)
+	if err == nil {
+		result.Valid = true
+		return result
+	}
+
+	var ve *einvoice.ValidationError
+	if !errors.As(err, &ve) {
+		result.Error = fmt.Sprintf("Failed to check PDF: %v", err)
+		return result
+	}
+	result.Violations = convertViolations(ve.Violations())
+	return result
+}
+
+func outputPDFCheckText(result Result, verbose bool) {
+	if result.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", result.Error)
+		return
+	}
+
+	if result.Valid {
+		fmt.Printf("✓ %s is a conforming Factur-X PDF/A-3 file\n", result.File)
+		return
+	}
+
+	fmt.Printf("✗ %s has %d violation(s):\n", result.File, len(result.Violations))
+	outputViolations(os.Stdout, result.Violations, verbose)
+}
+
+func pdfcheckUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: einvoice pdfcheck [options] <file.pdf>
+
+Checks a ZUGFeRD/Factur-X PDF for conformance with the PDF/A-3 and Factur-X
+requirements on the PDF container:
+
+  - PDF/A-3 identification in the XMP metadata
+  - Factur-X PDF/A extension schema and its values
+  - /AF array and /AFRelationship (Data, Alternative or Source)
+  - MIME subtype text/xml of the embedded invoice
+  - File name of the embedded invoice matching the profile
+  - PDF/A output intent
+
+The embedded invoice itself is not validated; use "einvoice validate" for that.
+This is not a full PDF/A validation (fonts, color spaces, ...).
+
+Options:
+  --format string   Output format: text, json (default "text")
+  --verbose         Show detailed rule descriptions
+  --help            Show this help message
+
+Exit codes:
+  0  PDF passes all checks
+  1  Error occurred (file not found, not a PDF, etc.)
+  2  PDF has violations
+
+Examples:
+  einvoice pdfcheck invoice.pdf
+  einvoice pdfcheck --format json invoice.pdf
+`)
+}
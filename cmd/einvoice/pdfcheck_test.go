@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckPDF(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+	}{
+		{"non-existent file", "non_existent_file.pdf"},
+		{"XML file", "../../testdata/cii/en16931/CII_example1.xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checkPDF(tt.filename)
+			if result.Error == "" {
+				t.Errorf("checkPDF() expected error, got none")
+			}
+			if result.Valid {
+				t.Errorf("checkPDF() Valid = true, want false")
+			}
+		})
+	}
+}
+
+func TestOutputPDFCheckText(t *testing.T) {
+	result := Result{
+		File:       "invoice.pdf",
+		Violations: []Violation{{Rule: "FX-PDF-10", Text: "embedded file 'factur-x.xml' has MIME subtype 'application/xml', expected 'text/xml'"}},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputPDFCheckText(result, false)
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	var buf strings.Builder
+	_, _ = io.Copy(&buf, r)
+	output := buf.String()
+
+	for _, want := range []string{"invoice.pdf has 1 violation(s)", "FX-PDF-10", "text/xml"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("outputPDFCheckText() output missing %q, got: %v", want, output)
+		}
+	}
+}
+
+func TestRunPDFCheck_NoFile(t *testing.T) {
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	exitCode := runPDFCheck(nil)
+
+	_ = w.Close()
+	os.Stderr = oldStderr
+
+	var buf strings.Builder
+	_, _ = io.Copy(&buf, r)
+
+	if exitCode != exitError {
+		t.Errorf("runPDFCheck() = %d, want %d", exitCode, exitError)
+	}
+	if !strings.Contains(buf.String(), "Usage: einvoice pdfcheck") {
+		t.Errorf("runPDFCheck() stderr = %q, want usage", buf.String())
+	}
+}
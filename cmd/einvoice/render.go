@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/speedata/einvoice/pkg/render"
+)
+
+// renderOptions are the command line options of the render command.
+type renderOptions struct {
+	html         bool
+	pdf          bool
+	paymentQR    bool
+	templatePath string // user template for the HTML output
+	noValidate   bool
+	output       string // output file, stdout if empty
+}
+
+func runRender(args []string) int {
+	// Parse flags for the render subcommand
+	renderFlags := flag.NewFlagSet("render", flag.ExitOnError)
+	var opts renderOptions
+	renderFlags.BoolVar(&opts.html, "html", true, "Render the invoice as HTML")
+	renderFlags.BoolVar(&opts.pdf, "pdf", false, "Render the invoice as PDF")
+	renderFlags.BoolVar(&opts.paymentQR, "qr", false, "Add an EPC payment QR code to the PDF")
+	renderFlags.StringVar(&opts.templatePath, "template", "", "Path to a custom Go HTML template file")
+	renderFlags.BoolVar(&opts.noValidate, "novalidate", false, "Do not show the validation results")
+	renderFlags.StringVar(&opts.output, "o", "", "Output file (default: standard output)")
+	renderFlags.Usage = renderUsage
+
+	// Allow flags after the file name (einvoice render invoice.xml -o invoice.html).
+	files := parseInterspersed(renderFlags, args)
+
+	// Require exactly one file argument
+	if len(files) != 1 {
+		renderUsage()
+		return exitError
+	}
+	htmlSet := false
+	renderFlags.Visit(func(f *flag.Flag) { htmlSet = htmlSet || f.Name == "html" })
+	switch {
+	case opts.pdf && htmlSet && opts.html:
+		fmt.Fprintln(os.Stderr, "Error: --html and --pdf cannot be combined")
+		return exitError
+	case opts.pdf && opts.templatePath != "":
+		fmt.Fprintln(os.Stderr, "Error: --template is only supported for HTML output")
+		return exitError
+	case !opts.pdf && !opts.html:
+		fmt.Fprintln(os.Stderr, "Error: no output format selected (use --html or --pdf)")
+		return exitError
+	}
+
+	inv, err := parseInvoiceFile(files[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	var buf bytes.Buffer
+	if opts.pdf {
+		err = render.PDF(&buf, inv, &render.PDFOptions{PaymentQR: opts.paymentQR})
+	} else {
+		htmlOpts := &render.HTMLOptions{SkipValidation: opts.noValidate}
+		if opts.templatePath != "" {
+			tpl, err := os.ReadFile(opts.templatePath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: cannot load template: %v\n", err)
+				return exitError
+			}
+			htmlOpts.Template = string(tpl)
+		}
+		err = render.HTML(&buf, inv, htmlOpts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	if opts.output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = os.WriteFile(opts.output, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func renderUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: einvoice render [options] <file>
+
+Renders a human readable view of an electronic invoice (XML or ZUGFeRD/Factur-X
+PDF). The HTML output shows all business terms with their BT/BG identifiers,
+the invoice lines with the sub line hierarchy, the VAT breakdown, the payment
+instructions, the additional documents as download links and the validation
+results.
+
+The PDF output is an A4 invoice document with the parties, the invoice lines,
+the VAT breakdown, the totals, the payment instructions and the notes. It uses
+non-embedded standard fonts, so it is not PDF/A and not suitable as visual PDF
+of a ZUGFeRD/Factur-X invoice ("einvoice create --pdf").
+
+Options:
+  --html             Render the invoice as HTML (default)
+  --pdf              Render the invoice as PDF
+  --qr               Add an EPC payment QR code (GiroCode) to the PDF if the
+                     invoice is payable by SEPA credit transfer in euro
+  --template string  Path to a custom Go HTML template file. The template can
+                     define "invoice" to replace the document or override the
+                     blocks "style", "header", "parties", "lines", "vat",
+                     "totals", "payment", "attachments" and "validation"
+  --novalidate       Do not show the validation results
+  -o file            Output file (default: standard output)
+  --help             Show this help message
+
+Exit codes:
+  0  Invoice rendered
+  1  Error occurred (file not found, parse error, template error, etc.)
+
+Examples:
+  einvoice render invoice.xml -o invoice.html
+  einvoice render --template custom.gohtml invoice.pdf > invoice.html
+  einvoice render --pdf --qr xrechnung.xml -o invoice.pdf
+`)
+}
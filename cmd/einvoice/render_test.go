@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunRender(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "invoice.html")
+	if code := runRender([]string{"../../testdata/cii/en16931/CII_example1.xml", "-o", out}); code != exitOK {
+		t.Fatalf("runRender() = %d", code)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "12115118") || !strings.Contains(string(data), "Validation:") {
+		t.Error("HTML output does not contain the invoice number and validation results")
+	}
+
+	tpl := filepath.Join(dir, "custom.gohtml")
+	if err := os.WriteFile(tpl, []byte(`{{ define "invoice" }}{{ .Invoice.InvoiceNumber }}{{ end }}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code := runRender([]string{"--template", tpl, "--novalidate", "../../testdata/cii/en16931/CII_example1.xml", "-o", out}); code != exitOK {
+		t.Fatalf("runRender(--template) = %d", code)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "12115118" {
+		t.Errorf("custom template output = %q", data)
+	}
+}
+
+func TestRunRender_PDF(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "invoice.pdf")
+	if code := runRender([]string{"--pdf", "--qr", "../../testdata/cii/en16931/CII_example1.xml", "-o", out}); code != exitOK {
+		t.Fatalf("runRender(--pdf) = %d", code)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "%PDF-") {
+		t.Error("output is not a PDF")
+	}
+}
+
+func TestRunRender_Errors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"non_existent.xml"},
+		{"--template", "non_existent.gohtml", "../../testdata/cii/en16931/CII_example1.xml"},
+		{"--html=false", "../../testdata/cii/en16931/CII_example1.xml"},
+		{"--html", "--pdf", "../../testdata/cii/en16931/CII_example1.xml"},
+		{"--pdf", "--template", "custom.gohtml", "../../testdata/cii/en16931/CII_example1.xml"},
+	} {
+		if code := runRender(args); code != exitError {
+			t.Errorf("runRender(%v) = %d, want %d", args, code, exitError)
+		}
+	}
+}
@@ -4,19 +4,22 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/speedata/einvoice"
+	"github.com/speedata/einvoice/pkg/semantic"
 )
 
 // Result represents the validation result for JSON output
 type Result struct {
-	Invoice    *InvoiceRef `json:"invoice,omitempty"`
-	File       string      `json:"file"`
-	Error      string      `json:"error,omitempty"`
-	Violations []Violation `json:"violations,omitempty"`
-	Valid      bool        `json:"valid"`
+	Invoice    *InvoiceRef     `json:"invoice,omitempty"`
+	File       string          `json:"file"`
+	Error      string          `json:"error,omitempty"`
+	ParseError *ParseErrorInfo `json:"parse_error,omitempty"`
+	Violations []Violation     `json:"violations,omitempty"`
+	Valid      bool            `json:"valid"`
 }
 
 // Violation represents a business rule violation
@@ -85,6 +88,7 @@ func validateInvoice(filename string) Result {
 	invoice, err := parseInvoiceFile(filename)
 	if err != nil {
 		result.Error = fmt.Sprintf("Failed to parse invoice: %v", err)
+		result.ParseError = newParseErrorInfo(err)
 		return result
 	}
 
@@ -109,16 +113,7 @@ func validateInvoice(filename string) Result {
 	// Extract violations
 	if ve, ok := validationErr.(*einvoice.ValidationError); ok {
 		result.Valid = false
-		semanticErrors := ve.Violations()
-		result.Violations = make([]Violation, len(semanticErrors))
-		for i, se := range semanticErrors {
-			result.Violations[i] = Violation{
-				Rule:        se.Rule.Code,
-				Fields:      se.Rule.Fields,
-				Description: se.Rule.Description,
-				Text:        se.Text,
-			}
-		}
+		result.Violations = convertViolations(ve.Violations())
 	} else {
 		result.Error = fmt.Sprintf("Validation failed: %v", validationErr)
 	}
@@ -138,15 +133,34 @@ func outputText(result Result, verbose bool) {
 	}
 
 	fmt.Printf("✗ Invoice %s has %d violation(s):\n", result.Invoice.Number, len(result.Violations))
-	for _, violation := range result.Violations {
+	outputViolations(os.Stdout, result.Violations, verbose)
+}
+
+// convertViolations converts the library's semantic errors for output.
+func convertViolations(semanticErrors []einvoice.SemanticError) []Violation {
+	violations := make([]Violation, len(semanticErrors))
+	for i, se := range semanticErrors {
+		violations[i] = Violation{
+			Rule:        se.Rule.Code,
+			Fields:      se.Rule.Fields,
+			Description: se.Rule.Description,
+			Text:        se.Text,
+		}
+	}
+	return violations
+}
+
+// outputViolations prints one line per violation (more in verbose mode) to w.
+func outputViolations(w io.Writer, violations []Violation, verbose bool) {
+	for _, violation := range violations {
 		if verbose {
 			// Verbose mode: show full details
-			fmt.Printf("  - %s: %s\n", violation.Rule, violation.Text)
+			fmt.Fprintf(w, "  - %s: %s\n", violation.Rule, violation.Text)
 			if violation.Description != "" {
-				fmt.Printf("    Specification: %s\n", violation.Description)
+				fmt.Fprintf(w, "    Specification: %s\n", violation.Description)
 			}
 			if len(violation.Fields) > 0 {
-				fmt.Printf("    Fields: %s\n", formatFields(violation.Fields))
+				fmt.Fprintf(w, "    Fields: %s\n", formatFields(violation.Fields))
 			}
 		} else {
 			// Normal mode: show primary field inline
@@ -154,14 +168,22 @@ func outputText(result Result, verbose bool) {
 			if len(violation.Fields) > 0 {
 				primaryField = fmt.Sprintf(" (%s)", violation.Fields[0])
 			}
-			fmt.Printf("  - %s%s: %s\n", violation.Rule, primaryField, violation.Text)
+			fmt.Fprintf(w, "  - %s%s: %s\n", violation.Rule, primaryField, violation.Text)
 		}
 	}
 }
 
-// formatFields joins field identifiers with commas
+// formatFields joins field identifiers with commas and adds the name of the
+// business term, for example "BT-27 (Seller name)".
 func formatFields(fields []string) string {
-	return strings.Join(fields, ", ")
+	named := make([]string, len(fields))
+	for i, field := range fields {
+		named[i] = field
+		if term, ok := semantic.Lookup(field); ok {
+			named[i] = fmt.Sprintf("%s (%s)", field, term.Name)
+		}
+	}
+	return strings.Join(named, ", ")
 }
 
 func outputJSON(result Result) {
@@ -208,6 +208,14 @@ func TestOutputText(t *testing.T) {
 	}
 }
 
+func TestFormatFields(t *testing.T) {
+	got := formatFields([]string{"BT-27", "BG-5", "XY-1"})
+	want := "BT-27 (Seller name), BG-5 (SELLER POSTAL ADDRESS), XY-1"
+	if got != want {
+		t.Errorf("formatFields() = %q, want %q", got, want)
+	}
+}
+
 func TestRunValidate(t *testing.T) {
 	tests := []struct {
 		name     string
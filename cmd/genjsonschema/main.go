@@ -0,0 +1,28 @@
+// genjsonschema writes the JSON Schema of the einvoice JSON mapping.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/speedata/einvoice"
+)
+
+func main() {
+	output := flag.String("output", "", "output file path")
+	flag.Parse()
+
+	if *output == "" {
+		log.Fatal("--output flag is required")
+	}
+
+	schema, err := einvoice.JSONSchema()
+	if err != nil {
+		log.Fatalf("Failed to generate JSON schema: %v", err)
+	}
+	if err := os.WriteFile(*output, append(schema, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *output, err)
+	}
+	log.Printf("Wrote %s", *output)
+}
@@ -0,0 +1,263 @@
+package einvoice
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreditLine selects an invoice line to credit, see CreditNoteOptions.
+type CreditLine struct {
+	LineID   string          // BT-126 of the invoice line
+	Quantity decimal.Decimal // quantity to credit, the whole line if zero
+}
+
+// CreditNoteOptions are the data of a credit note created with
+// Invoice.CreditNote.
+type CreditNoteOptions struct {
+	Number string    // BT-1 of the credit note
+	Date   time.Time // BT-2 of the credit note
+	Reason string    // added as invoice note (BT-22) if not empty
+	// Lines selects the lines and quantities of a partial credit. If empty,
+	// the whole invoice including the document level allowances and charges
+	// is credited.
+	Lines []CreditLine
+}
+
+// CorrectiveOptions are the data of a corrective invoice created with
+// Invoice.Corrective.
+type CorrectiveOptions struct {
+	Number string    // BT-1 of the corrective invoice
+	Date   time.Time // BT-2 of the corrective invoice
+	Reason string    // added as invoice note (BT-22) if not empty
+	// Change is called with the copy of the invoice before it is calculated
+	// to apply the corrections, for example to change quantities or prices.
+	Change func(*Invoice)
+}
+
+// CreditNote returns a credit note (381) for the invoice. It copies the
+// parties, references and payment instructions of the invoice, references the
+// invoice as preceding invoice (BT-25, BT-26) and calculates the lines, the
+// VAT breakdown and the totals. The exemption reasons of the VAT breakdown are
+// taken from the invoice.
+//
+// The credit note has the positive amounts of the credited invoice, as
+// required for UBL, where type 381 is written as CreditNote document, and
+// usual in CII. Crediting a credit note returns a commercial invoice (380).
+//
+// For a partial credit (opts.Lines) only the selected detail lines are
+// credited, with line allowances and charges scaled to the credited quantity;
+// document level allowances and charges are not taken over. Below the BASIC
+// profile, which has no invoice lines, only a full credit is possible and the
+// totals are taken from the invoice.
+//
+// The credit note is not validated.
+func (inv *Invoice) CreditNote(opts CreditNoteOptions) (*Invoice, error) {
+	cn := inv.derive(opts.Number, opts.Date, opts.Reason)
+	cn.InvoiceTypeCode = 381
+	if inv.InvoiceTypeCode == 381 {
+		cn.InvoiceTypeCode = 380
+	}
+	// The credited amount is not due by the buyer
+	cn.SpecifiedTradePaymentTerms = nil
+
+	if len(opts.Lines) > 0 {
+		if inv.ProfileLevel() > 0 && inv.ProfileLevel() < levelBasic {
+			return nil, fmt.Errorf("partial credit requires invoice lines, not available in %s", GetProfileName(inv.GuidelineSpecifiedDocumentContextParameter))
+		}
+		lines := make([]InvoiceLine, 0, len(opts.Lines))
+		for _, cl := range opts.Lines {
+			i := slices.IndexFunc(cn.InvoiceLines, func(line InvoiceLine) bool { return line.LineID == cl.LineID })
+			if i < 0 {
+				return nil, fmt.Errorf("invoice line %q not found", cl.LineID)
+			}
+			line := cn.InvoiceLines[i]
+			if !line.isDetailLine() {
+				return nil, fmt.Errorf("invoice line %q is not a detail line", cl.LineID)
+			}
+			if !cl.Quantity.IsZero() {
+				if cl.Quantity.Sign() != line.BilledQuantity.Sign() || cl.Quantity.Abs().GreaterThan(line.BilledQuantity.Abs()) {
+					return nil, fmt.Errorf("invoice line %q: cannot credit quantity %s of invoiced quantity %s", cl.LineID, cl.Quantity, line.BilledQuantity)
+				}
+				line.scaleQuantity(cl.Quantity)
+			}
+			line.ParentLineID = ""
+			line.LineStatusReasonCode = ""
+			lines = append(lines, line)
+		}
+		cn.InvoiceLines = lines
+		cn.SpecifiedTradeAllowanceCharge = nil
+	}
+
+	cn.recalculate(inv)
+	return cn, nil
+}
+
+// Corrective returns a corrective invoice (384) that replaces the invoice. It
+// copies the invoice, references it as preceding invoice (BT-25, BT-26),
+// applies opts.Change and calculates the lines, the VAT breakdown and the
+// totals like CreditNote. In UBL type 384 is written as Invoice document.
+//
+// The corrective invoice is not validated.
+func (inv *Invoice) Corrective(opts CorrectiveOptions) *Invoice {
+	ci := inv.derive(opts.Number, opts.Date, opts.Reason)
+	ci.InvoiceTypeCode = 384
+	if opts.Change != nil {
+		opts.Change(ci)
+	}
+	ci.recalculate(inv)
+	return ci
+}
+
+// derive returns a copy of the invoice with a new number, date and note that
+// references the invoice (BG-3). Prepaid and rounding amounts and the parse
+// state are reset.
+func (inv *Invoice) derive(number string, date time.Time, reason string) *Invoice {
+	d := inv.clone()
+	d.isParsed = false
+	d.hasLineTotalInXML, d.hasTaxBasisTotalInXML, d.hasGrandTotalInXML, d.hasDuePayableAmountInXML = false, false, false, false
+	d.hasBillingPeriodInXML = false
+	d.unexpectedTaxCurrencies = nil
+	d.sbdh = nil
+	d.pdfConformanceLevel = ""
+	d.violations, d.warnings = nil, nil
+	d.InvoiceNumber = number
+	d.InvoiceDate = date
+	d.InvoiceReferencedDocument = []ReferencedDocument{{ID: inv.InvoiceNumber, Date: inv.InvoiceDate}}
+	d.Notes = nil
+	if reason != "" {
+		d.Notes = []Note{{Text: reason}}
+	}
+	d.TotalPrepaid = decimal.Zero
+	d.AdvancePayments = nil
+	d.deductedPrepayments = nil
+	d.RoundingAmount = decimal.Zero
+	return d
+}
+
+// recalculate calculates the lines, the VAT breakdown and the totals of a
+// document derived from orig and takes over the exemption reasons and tax
+// point of orig's VAT breakdown. Below the BASIC profile the totals of orig
+// are kept and only the amount due is set.
+func (inv *Invoice) recalculate(orig *Invoice) {
+	if inv.ProfileLevel() > 0 && inv.ProfileLevel() < levelBasic {
+		inv.DuePayableAmount = inv.GrandTotal
+		return
+	}
+	inv.calculate(nil)
+	for i := range inv.TradeTaxes {
+		tt := &inv.TradeTaxes[i]
+		j := slices.IndexFunc(orig.TradeTaxes, tt.sameCategory)
+		if j < 0 {
+			continue
+		}
+		o := &orig.TradeTaxes[j]
+		tt.ExemptionReason, tt.ExemptionReasonCode = o.ExemptionReason, o.ExemptionReasonCode
+		tt.TaxPointDate, tt.DueDateTypeCode = o.TaxPointDate, o.DueDateTypeCode
+	}
+}
+
+// calculate derives the line net amounts, the VAT breakdown and the totals,
+// with UpdateTaxInclusive if a line has a tax inclusive price.
+func (inv *Invoice) calculate(opts *CalculationOptions) {
+	if slices.ContainsFunc(inv.InvoiceLines, func(line InvoiceLine) bool { return !line.TaxInclusivePrice.IsZero() }) {
+		inv.UpdateTaxInclusive(opts)
+		return
+	}
+	inv.UpdateLines()
+	inv.UpdateApplicableTradeTaxWithOptions(opts)
+	inv.UpdateTotalsWithOptions(opts)
+}
+
+// scaleQuantity sets the invoiced quantity (BT-129) to quantity and scales the
+// line allowances and charges accordingly.
+func (line *InvoiceLine) scaleQuantity(quantity decimal.Decimal) {
+	ratio := quantity.Div(line.BilledQuantity)
+	for _, acs := range [][]AllowanceCharge{line.InvoiceLineAllowances, line.InvoiceLineCharges} {
+		for i := range acs {
+			acs[i].BasisAmount = roundHalfUp(acs[i].BasisAmount.Mul(ratio), 2)
+			acs[i].ActualAmount = roundHalfUp(acs[i].ActualAmount.Mul(ratio), 2)
+		}
+	}
+	line.BilledQuantity = quantity
+}
+
+// clone returns a deep copy of the invoice.
+func (inv *Invoice) clone() *Invoice {
+	c := *inv
+	c.unexpectedTaxCurrencies = slices.Clone(inv.unexpectedTaxCurrencies)
+	c.violations = slices.Clone(inv.violations)
+	c.warnings = slices.Clone(inv.warnings)
+	c.deductedPrepayments = slices.Clone(inv.deductedPrepayments)
+	if inv.sbdh != nil {
+		h := *inv.sbdh
+		h.Scopes = slices.Clone(h.Scopes)
+		c.sbdh = &h
+	}
+
+	c.Seller = cloneParty(inv.Seller)
+	c.Buyer = cloneParty(inv.Buyer)
+	for _, p := range []**Party{&c.PayeeTradeParty, &c.SellerTaxRepresentativeTradeParty, &c.ShipTo, &c.UltimateShipTo, &c.ShipFrom, &c.InvoicerTradeParty, &c.InvoiceeTradeParty} {
+		if *p != nil {
+			cp := cloneParty(**p)
+			*p = &cp
+		}
+	}
+	c.DeliveryNoteReferencedDocument = clonePtr(inv.DeliveryNoteReferencedDocument)
+	c.TaxCurrencyExchange = clonePtr(inv.TaxCurrencyExchange)
+
+	c.PaymentMeans = slices.Clone(inv.PaymentMeans)
+	c.Notes = slices.Clone(inv.Notes)
+	c.TradeTaxes = slices.Clone(inv.TradeTaxes)
+	c.SpecifiedTradeAllowanceCharge = slices.Clone(inv.SpecifiedTradeAllowanceCharge)
+	c.AdditionalReferencedDocument = slices.Clone(inv.AdditionalReferencedDocument)
+	c.SpecifiedTradePaymentTerms = slices.Clone(inv.SpecifiedTradePaymentTerms)
+	for i := range c.SpecifiedTradePaymentTerms {
+		pt := &c.SpecifiedTradePaymentTerms[i]
+		pt.PenaltyTerms = clonePtr(pt.PenaltyTerms)
+		pt.DiscountTerms = clonePtr(pt.DiscountTerms)
+	}
+	c.InvoiceReferencedDocument = slices.Clone(inv.InvoiceReferencedDocument)
+	c.AdvancePayments = slices.Clone(inv.AdvancePayments)
+	for i := range c.AdvancePayments {
+		c.AdvancePayments[i].TradeTaxes = slices.Clone(c.AdvancePayments[i].TradeTaxes)
+	}
+	c.InvoiceLines = slices.Clone(inv.InvoiceLines)
+	for i := range c.InvoiceLines {
+		line := &c.InvoiceLines[i]
+		line.Characteristics = slices.Clone(line.Characteristics)
+		line.ProductClassification = slices.Clone(line.ProductClassification)
+		line.InvoiceLineAllowances = slices.Clone(line.InvoiceLineAllowances)
+		line.InvoiceLineCharges = slices.Clone(line.InvoiceLineCharges)
+		line.AppliedTradeAllowanceCharge = slices.Clone(line.AppliedTradeAllowanceCharge)
+		line.DespatchAdviceReferencedDocument = clonePtr(line.DespatchAdviceReferencedDocument)
+		line.DeliveryNoteReferencedDocument = clonePtr(line.DeliveryNoteReferencedDocument)
+	}
+	return &c
+}
+
+// clonePtr returns a pointer to a copy of *p or nil if p is nil.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+func cloneParty(p Party) Party {
+	p.ID = slices.Clone(p.ID)
+	p.GlobalID = slices.Clone(p.GlobalID)
+	p.DefinedTradeContact = slices.Clone(p.DefinedTradeContact)
+	if p.PostalAddress != nil {
+		a := *p.PostalAddress
+		p.PostalAddress = &a
+	}
+	if p.SpecifiedLegalOrganization != nil {
+		o := *p.SpecifiedLegalOrganization
+		p.SpecifiedLegalOrganization = &o
+	}
+	return p
+}
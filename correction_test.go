@@ -0,0 +1,229 @@
+package einvoice
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// correctionInvoice returns a validated invoice with two standard rated lines
+// (8 × 120.00 with a line allowance of 40.00 and 2 × 24.95 at 7 %) and a
+// document level allowance of 60.00.
+func correctionInvoice(t *testing.T, build func(*InvoiceBuilder) *InvoiceBuilder) *Invoice {
+	t.Helper()
+	b := builderInvoice(SpecEN16931)
+	if build != nil {
+		b = build(b)
+	} else {
+		b = b.VAT(VATStandard(decimal.NewFromInt(19))).
+			AddLine(InvoiceLine{
+				ItemName: "Consulting", BilledQuantity: decimal.NewFromInt(8), NetPrice: decimal.NewFromInt(120),
+				InvoiceLineAllowances: []AllowanceCharge{{ActualAmount: decimal.NewFromInt(40), Reason: "Discount"}},
+			}).
+			AddLine(InvoiceLine{ItemName: "Book", BilledQuantity: decimal.NewFromInt(2), NetPrice: decimal.RequireFromString("24.95"), TaxCategoryCode: "S", TaxRateApplicablePercent: decimal.NewFromInt(7)}).
+			AddAllowance(AllowanceCharge{ActualAmount: decimal.NewFromInt(60), Reason: "Discount"})
+	}
+	inv, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	return inv
+}
+
+func TestCreditNote(t *testing.T) {
+	inv := correctionInvoice(t, nil)
+	date := time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)
+	cn, err := inv.CreditNote(CreditNoteOptions{Number: "GS-2025-001", Date: date, Reason: "Cancellation"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cn.InvoiceTypeCode != 381 || cn.InvoiceNumber != "GS-2025-001" || !cn.InvoiceDate.Equal(date) {
+		t.Errorf("header = %d %s %s", cn.InvoiceTypeCode, cn.InvoiceNumber, cn.InvoiceDate)
+	}
+	if len(cn.InvoiceReferencedDocument) != 1 || cn.InvoiceReferencedDocument[0].ID != inv.InvoiceNumber || !cn.InvoiceReferencedDocument[0].Date.Equal(inv.InvoiceDate) {
+		t.Errorf("InvoiceReferencedDocument = %+v", cn.InvoiceReferencedDocument)
+	}
+	if len(cn.Notes) != 1 || cn.Notes[0].Text != "Cancellation" {
+		t.Errorf("Notes = %+v", cn.Notes)
+	}
+	if !cn.DuePayableAmount.Equal(inv.DuePayableAmount) || !cn.TaxTotal.Equal(inv.TaxTotal) {
+		t.Errorf("credit note due %s VAT %s, want %s %s", cn.DuePayableAmount, cn.TaxTotal, inv.DuePayableAmount, inv.TaxTotal)
+	}
+	if err := cn.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	// The invoice is not modified
+	cn.Seller.PostalAddress.City = "Hamburg"
+	cn.InvoiceLines[0].InvoiceLineAllowances[0].Reason = "changed"
+	if inv.Seller.PostalAddress.City != "Berlin" || inv.InvoiceLines[0].InvoiceLineAllowances[0].Reason != "Discount" || inv.InvoiceTypeCode != 380 {
+		t.Error("CreditNote modified the invoice")
+	}
+
+	// UBL writes a CreditNote document, CII type code 381, both with positive amounts
+	for _, syntax := range []CodeSchemaType{CII, UBL} {
+		cn.SchemaType = syntax
+		xml := writeWithOptions(t, cn, nil)
+		if syntax == UBL && !strings.Contains(xml, "<CreditNote ") {
+			t.Error("UBL credit note not written as CreditNote document")
+		}
+		parsed, err := ParseReader(strings.NewReader(xml))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.InvoiceTypeCode != 381 || !parsed.DuePayableAmount.IsPositive() {
+			t.Errorf("%v: parsed type %d, due %s", syntax, parsed.InvoiceTypeCode, parsed.DuePayableAmount)
+		}
+		if err := parsed.Validate(); err != nil {
+			t.Errorf("%v: Validate() after round trip = %v", syntax, err)
+		}
+	}
+
+	// Crediting the credit note invoices the amount again
+	again, err := cn.CreditNote(CreditNoteOptions{Number: "RE-2025-002", Date: date})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.InvoiceTypeCode != 380 {
+		t.Errorf("InvoiceTypeCode = %d, want 380", again.InvoiceTypeCode)
+	}
+}
+
+func TestCreditNote_Partial(t *testing.T) {
+	inv := correctionInvoice(t, nil)
+	cn, err := inv.CreditNote(CreditNoteOptions{
+		Number: "GS-2025-002",
+		Date:   time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		Lines:  []CreditLine{{LineID: "1", Quantity: decimal.NewFromInt(2)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cn.InvoiceLines) != 1 || len(cn.SpecifiedTradeAllowanceCharge) != 0 {
+		t.Fatalf("lines %d, allowances %d, want 1 and 0", len(cn.InvoiceLines), len(cn.SpecifiedTradeAllowanceCharge))
+	}
+	// 2 × 120.00 - 10.00 (2/8 of the line allowance), VAT 43.70
+	line := cn.InvoiceLines[0]
+	if !line.InvoiceLineAllowances[0].ActualAmount.Equal(decimal.NewFromInt(10)) || !line.Total.Equal(decimal.NewFromInt(230)) {
+		t.Errorf("line allowance %s, total %s, want 10 and 230", line.InvoiceLineAllowances[0].ActualAmount, line.Total)
+	}
+	if !cn.DuePayableAmount.Equal(decimal.RequireFromString("273.70")) {
+		t.Errorf("DuePayableAmount = %s, want 273.70", cn.DuePayableAmount)
+	}
+	if err := cn.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if !inv.InvoiceLines[0].BilledQuantity.Equal(decimal.NewFromInt(8)) {
+		t.Error("CreditNote modified the invoice lines")
+	}
+
+	for _, lines := range [][]CreditLine{
+		{{LineID: "3"}},
+		{{LineID: "1", Quantity: decimal.NewFromInt(9)}},
+		{{LineID: "1", Quantity: decimal.NewFromInt(-1)}},
+	} {
+		if _, err := inv.CreditNote(CreditNoteOptions{Lines: lines}); err == nil {
+			t.Errorf("CreditNote(%+v) succeeded, want error", lines)
+		}
+	}
+}
+
+func TestCreditNote_ExemptionReason(t *testing.T) {
+	inv := correctionInvoice(t, func(b *InvoiceBuilder) *InvoiceBuilder {
+		return b.ReverseCharge().AddLine(InvoiceLine{ItemName: "Installation", BilledQuantity: decimal.NewFromInt(8), NetPrice: decimal.NewFromInt(95)})
+	})
+	cn, err := inv.CreditNote(CreditNoteOptions{Number: "GS-2025-003", Date: inv.InvoiceDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cn.TradeTaxes[0].ExemptionReasonCode != "VATEX-EU-AE" {
+		t.Errorf("ExemptionReasonCode = %q, want VATEX-EU-AE", cn.TradeTaxes[0].ExemptionReasonCode)
+	}
+	if err := cn.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestCorrective(t *testing.T) {
+	inv := correctionInvoice(t, nil)
+	ci := inv.Corrective(CorrectiveOptions{
+		Number: "RE-2025-001-K",
+		Date:   time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		Reason: "Wrong price",
+		Change: func(c *Invoice) {
+			c.InvoiceLines[0].NetPrice = decimal.NewFromInt(110)
+		},
+	})
+
+	if ci.InvoiceTypeCode != 384 || len(ci.InvoiceReferencedDocument) != 1 || ci.InvoiceReferencedDocument[0].ID != "RE-2025-001" {
+		t.Errorf("type %d, references %+v", ci.InvoiceTypeCode, ci.InvoiceReferencedDocument)
+	}
+	// 880.00 - 40.00 + 49.90 - 60.00
+	if !ci.TaxBasisTotal.Equal(decimal.RequireFromString("829.90")) {
+		t.Errorf("TaxBasisTotal = %s, want 829.90", ci.TaxBasisTotal)
+	}
+	if len(ci.SpecifiedTradePaymentTerms) != 1 {
+		t.Error("payment terms not copied")
+	}
+	if err := ci.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if !inv.InvoiceLines[0].NetPrice.Equal(decimal.NewFromInt(120)) {
+		t.Error("Corrective modified the invoice")
+	}
+
+	ci.SchemaType = UBL
+	if xml := writeWithOptions(t, ci, nil); !strings.Contains(xml, "<Invoice ") || !strings.Contains(xml, ">384<") {
+		t.Error("UBL corrective invoice not written as Invoice document with type 384")
+	}
+}
+
+func TestCorrective_Copy(t *testing.T) {
+	inv := correctionInvoice(t, nil)
+	inv.InvoiceLines[0].DespatchAdviceReferencedDocument = &LineReference{ID: "DA-1", LineID: "1"}
+	inv.InvoiceLines[0].DeliveryNoteReferencedDocument = &LineReference{ID: "DN-1", LineID: "1"}
+	inv.SpecifiedTradePaymentTerms[0].DiscountTerms = &PaymentAdjustmentTerms{CalculationPercent: decimal.NewFromInt(2)}
+	inv.SpecifiedTradePaymentTerms[0].PenaltyTerms = &PaymentAdjustmentTerms{CalculationPercent: decimal.NewFromInt(5)}
+
+	inv.Corrective(CorrectiveOptions{
+		Number: "RE-2025-001-K",
+		Date:   time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		Change: func(c *Invoice) {
+			c.InvoiceLines[0].DespatchAdviceReferencedDocument.ID = "DA-2"
+			c.InvoiceLines[0].DeliveryNoteReferencedDocument.ID = "DN-2"
+			c.SpecifiedTradePaymentTerms[0].DiscountTerms.CalculationPercent = decimal.NewFromInt(3)
+			c.SpecifiedTradePaymentTerms[0].PenaltyTerms.CalculationPercent = decimal.NewFromInt(6)
+		},
+	})
+
+	line := inv.InvoiceLines[0]
+	if line.DespatchAdviceReferencedDocument.ID != "DA-1" || line.DeliveryNoteReferencedDocument.ID != "DN-1" {
+		t.Errorf("line references changed: %+v, %+v", line.DespatchAdviceReferencedDocument, line.DeliveryNoteReferencedDocument)
+	}
+	terms := inv.SpecifiedTradePaymentTerms[0]
+	if !terms.DiscountTerms.CalculationPercent.Equal(decimal.NewFromInt(2)) || !terms.PenaltyTerms.CalculationPercent.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("payment terms changed: %+v, %+v", terms.DiscountTerms, terms.PenaltyTerms)
+	}
+}
+
+func TestCreditNote_BasicWL(t *testing.T) {
+	inv := createMinimalInvoice()
+	inv.GuidelineSpecifiedDocumentContextParameter = SpecFacturXBasicWL
+	inv.InvoiceLines = nil
+	inv.TotalPrepaid = decimal.NewFromInt(19)
+	inv.DuePayableAmount = decimal.NewFromInt(100)
+
+	cn, err := inv.CreditNote(CreditNoteOptions{Number: "GS-1", Date: inv.InvoiceDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cn.GrandTotal.Equal(inv.GrandTotal) || !cn.DuePayableAmount.Equal(inv.GrandTotal) || !cn.TotalPrepaid.IsZero() {
+		t.Errorf("grand %s, due %s, prepaid %s", cn.GrandTotal, cn.DuePayableAmount, cn.TotalPrepaid)
+	}
+	if _, err := inv.CreditNote(CreditNoteOptions{Lines: []CreditLine{{LineID: "1"}}}); err == nil {
+		t.Error("partial credit in Basic WL succeeded, want error")
+	}
+}
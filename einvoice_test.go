@@ -665,7 +665,8 @@ func FuzzRoundTrip(f *testing.F) {
 
 // assertInvoiceEqual compares critical fields between two invoices after round-trip using go-cmp.
 // This ensures no data is lost during Parse → Write → Parse cycle.
-func assertInvoiceEqual(t *testing.T, original, roundtrip *Invoice) {
+// Additional options are appended to the default options.
+func assertInvoiceEqual(t *testing.T, original, roundtrip *Invoice, extra ...cmp.Option) {
 	t.Helper()
 
 	opts := []cmp.Option{
@@ -687,6 +688,7 @@ func assertInvoiceEqual(t *testing.T, original, roundtrip *Invoice) {
 			Note{}, Document{}, ReferencedDocument{}, GlobalID{}, SpecifiedLegalOrganization{},
 			Characteristic{}, Classification{}),
 	}
+	opts = append(opts, extra...)
 
 	if diff := cmp.Diff(original, roundtrip, opts...); diff != "" {
 		t.Errorf("Invoice round-trip mismatch (-original +roundtrip):\n%s", diff)
@@ -0,0 +1,274 @@
+package einvoice
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice/rules"
+)
+
+// TotalComponent is one amount that enters a document total, for example an
+// invoice line net amount (BT-131) in the sum of invoice line net amounts
+// (BT-106).
+type TotalComponent struct {
+	Term   string          // business term of the amount, e.g. "BT-131"
+	Label  string          // human readable source, e.g. "line 1" or "allowance 1 (Discount)"
+	Amount decimal.Decimal // signed contribution to the total (allowances are negative)
+}
+
+// TotalExplanation describes how a single document total (BT-106 to BT-115)
+// or a VAT category taxable amount (BT-116) is derived and how it is checked.
+//
+// Sum is the plain sum of the components, Calculated the value the declared
+// amount is compared against after the rounding the business rule applies.
+// Both are equal when the rule does not round. Tolerance is the deviation
+// accepted by the rule (EXTENDED profile), zero means exact match.
+type TotalExplanation struct {
+	Term         string // "BT-106" ... "BT-116"
+	Name         string
+	Rule         rules.Rule // business rule comparing Declared and Calculated, empty if none
+	CategoryCode string     // BT-118, only for BT-116
+	Percent      decimal.Decimal
+	Components   []TotalComponent
+	Sum          decimal.Decimal
+	Calculated   decimal.Decimal
+	Declared     decimal.Decimal
+	Tolerance    decimal.Decimal
+}
+
+// Rounding returns the rounding step from Sum to Calculated.
+func (e TotalExplanation) Rounding() decimal.Decimal {
+	return e.Calculated.Sub(e.Sum)
+}
+
+// Difference returns the declared minus the calculated amount.
+func (e TotalExplanation) Difference() decimal.Decimal {
+	return e.Declared.Sub(e.Calculated)
+}
+
+// Matches reports whether the declared amount equals the calculated amount
+// within the tolerance.
+func (e TotalExplanation) Matches() bool {
+	return e.Difference().Abs().LessThanOrEqual(e.Tolerance)
+}
+
+// TotalsExplanation is the breakdown returned by ExplainTotals.
+type TotalsExplanation struct {
+	Currency     string             // BT-5
+	Totals       []TotalExplanation // BT-106 to BT-115 in calculation order
+	VATBreakdown []TotalExplanation // BT-116 for each entry of TradeTaxes and rule checking it
+}
+
+// Valid reports whether every declared amount matches its calculation.
+func (te *TotalsExplanation) Valid() bool {
+	for _, e := range te.Totals {
+		if !e.Matches() {
+			return false
+		}
+	}
+	for _, e := range te.VATBreakdown {
+		if !e.Matches() {
+			return false
+		}
+	}
+	return true
+}
+
+// vatBasisCheck is a rule checking the VAT category taxable amount (BT-116),
+// its EXTENDED replacement and whether the basis is summed per VAT rate.
+type vatBasisCheck struct {
+	strict, fxext rules.Rule
+	perRate       bool
+}
+
+// vatBasisRules lists, per VAT category code, the checks Validate applies to
+// the taxable amount (BT-116). Intra-community supply, IGIC and IPSI check the
+// amount both for the whole category and per VAT rate.
+var vatBasisRules = map[string][]vatBasisCheck{
+	"S":  {{rules.BRS8, rules.BRFXEXTS08, true}},
+	"Z":  {{rules.BRZ8, rules.BRFXEXTZ08, false}},
+	"E":  {{rules.BRE8, rules.BRFXEXTE08, false}},
+	"AE": {{rules.BRAE8, rules.BRFXEXTAE08, false}},
+	"K":  {{rules.BRIC6, rules.Rule{}, false}, {rules.BRIC8, rules.Rule{}, true}},
+	"G":  {{rules.BRG8, rules.Rule{}, false}},
+	"L":  {{rules.BRAF5, rules.Rule{}, false}, {rules.BRAF7, rules.Rule{}, true}},
+	"M":  {{rules.BRAG5, rules.Rule{}, false}, {rules.BRAG7, rules.Rule{}, true}},
+	"O":  {{rules.BRO8, rules.Rule{}, false}},
+}
+
+// ExplainTotals returns how the document totals (BT-106 to BT-115) and the
+// VAT category taxable amounts (BT-116) of the invoice are derived: every
+// invoice line and document level allowance or charge entering a sum, the
+// rounding applied and the tolerance of the checking rule. The calculation
+// mirrors Validate, so a failing BR-CO-10 to BR-CO-16 or BR-*-08 check can be
+// traced to the amounts that caused it. The invoice is not modified.
+//
+// Amounts Validate does not check are left out: BT-106 and BT-109 without
+// invoice lines, BT-110 without VAT breakdown, BT-111 without exchange rate to
+// the VAT accounting currency and BT-116 below the BASIC profile. Sub invoice
+// line aggregation lines (GROUP / INFORMATION) are not listed as they do not
+// contribute to any total.
+func (inv *Invoice) ExplainTotals() *TotalsExplanation {
+	te := &TotalsExplanation{Currency: inv.InvoiceCurrencyCode}
+	cent := decimal.New(1, -2)
+
+	// BR-CO-10
+	if len(inv.InvoiceLines) > 0 {
+		lineTotal := TotalExplanation{Term: "BT-106", Name: "Sum of Invoice line net amount", Rule: rules.BRCO10, Declared: inv.LineTotal}
+		for i := range inv.InvoiceLines {
+			line := &inv.InvoiceLines[i]
+			if !line.isDetailLine() {
+				continue
+			}
+			lineTotal.Components = append(lineTotal.Components, TotalComponent{Term: "BT-131", Label: "line " + line.LineID, Amount: line.Total})
+		}
+		if inv.IsExtended() {
+			lineTotal.Rule = rules.BRFXEXTCO10
+			lineTotal.Tolerance = cent.Mul(decimal.NewFromInt(int64(len(lineTotal.Components))))
+		}
+		te.add(lineTotal, false)
+	}
+
+	// BR-CO-11 and BR-CO-12
+	allowanceTotal := TotalExplanation{Term: "BT-107", Name: "Sum of allowances on document level", Rule: rules.BRCO11, Declared: inv.AllowanceTotal}
+	chargeTotal := TotalExplanation{Term: "BT-108", Name: "Sum of charges on document level", Rule: rules.BRCO12, Declared: inv.ChargeTotal}
+	for i := range inv.SpecifiedTradeAllowanceCharge {
+		ac := &inv.SpecifiedTradeAllowanceCharge[i]
+		if ac.ChargeIndicator {
+			chargeTotal.Components = append(chargeTotal.Components, TotalComponent{Term: "BT-99", Label: allowanceChargeLabel(ac, i), Amount: ac.ActualAmount})
+		} else {
+			allowanceTotal.Components = append(allowanceTotal.Components, TotalComponent{Term: "BT-92", Label: allowanceChargeLabel(ac, i), Amount: ac.ActualAmount})
+		}
+	}
+	te.add(allowanceTotal, false)
+	te.add(chargeTotal, false)
+
+	// BR-CO-13
+	if len(inv.InvoiceLines) > 0 || !inv.LineTotal.IsZero() {
+		te.add(TotalExplanation{
+			Term: "BT-109", Name: "Invoice total amount without VAT", Rule: rules.BRCO13, Declared: inv.TaxBasisTotal,
+			Components: []TotalComponent{
+				{Term: "BT-106", Label: "line total", Amount: inv.LineTotal},
+				{Term: "BT-107", Label: "allowance total", Amount: inv.AllowanceTotal.Neg()},
+				{Term: "BT-108", Label: "charge total", Amount: inv.ChargeTotal},
+			},
+		}, false)
+	}
+
+	// BR-CO-14
+	if len(inv.TradeTaxes) > 0 {
+		taxTotal := TotalExplanation{Term: "BT-110", Name: "Invoice total VAT amount", Rule: rules.BRCO14, Declared: inv.TaxTotal}
+		for i := range inv.TradeTaxes {
+			tt := &inv.TradeTaxes[i]
+			taxTotal.Components = append(taxTotal.Components, TotalComponent{Term: "BT-117", Label: vatLabel(tt.CategoryCode, tt.Percent), Amount: tt.CalculatedAmount})
+		}
+		te.add(taxTotal, false)
+	}
+
+	// BR-USER-07, converted as a whole with a tolerance of 0.01 per VAT
+	// breakdown for amounts converted one by one
+	if rate, ok := inv.taxExchangeRate(); ok && !inv.TaxTotalAccounting.IsZero() {
+		te.add(TotalExplanation{
+			Term: "BT-111", Name: "Invoice total VAT amount in accounting currency", Rule: rules.BRUSER07, Declared: inv.TaxTotalAccounting,
+			Components: []TotalComponent{
+				{Term: "BT-110", Label: fmt.Sprintf("VAT total × %s (%s)", rate, inv.TaxCurrencyCode), Amount: inv.TaxTotal.Mul(rate)},
+			},
+			Tolerance: cent.Mul(decimal.NewFromInt(int64(max(1, len(inv.TradeTaxes))))),
+		}, true)
+	}
+
+	// BR-CO-15
+	te.add(TotalExplanation{
+		Term: "BT-112", Name: "Invoice total amount with VAT", Rule: rules.BRCO15, Declared: inv.GrandTotal,
+		Components: []TotalComponent{
+			{Term: "BT-109", Label: "total without VAT", Amount: inv.TaxBasisTotal},
+			{Term: "BT-110", Label: "VAT total", Amount: inv.TaxTotal},
+		},
+	}, true)
+
+	// BR-CO-16, the rounding amount is added after rounding the difference
+	dueRounded := roundHalfUp(inv.GrandTotal.Sub(inv.TotalPrepaid), 2)
+	due := TotalExplanation{
+		Term: "BT-115", Name: "Amount due for payment", Rule: rules.BRCO16, Declared: inv.DuePayableAmount,
+		Components: []TotalComponent{
+			{Term: "BT-112", Label: "total with VAT", Amount: inv.GrandTotal},
+			{Term: "BT-113", Label: "paid amount", Amount: inv.TotalPrepaid.Neg()},
+			{Term: "BT-114", Label: "rounding amount", Amount: inv.RoundingAmount},
+		},
+	}
+	due.Sum = inv.GrandTotal.Sub(inv.TotalPrepaid).Add(inv.RoundingAmount)
+	due.Calculated = dueRounded.Add(inv.RoundingAmount)
+	te.Totals = append(te.Totals, due)
+
+	if inv.ProfileLevel() >= levelBasic || (inv.ProfileLevel() == 0 && len(inv.InvoiceLines) > 0) {
+		for i := range inv.TradeTaxes {
+			tt := &inv.TradeTaxes[i]
+			checks, ok := vatBasisRules[tt.CategoryCode]
+			if !ok {
+				checks = []vatBasisCheck{{}}
+			}
+			for _, check := range checks {
+				te.VATBreakdown = append(te.VATBreakdown, inv.explainVATBasis(tt, check))
+			}
+		}
+	}
+	return te
+}
+
+// add sums the components of e, rounds the sum to two decimals if round is
+// set and appends e to the totals.
+func (te *TotalsExplanation) add(e TotalExplanation, round bool) {
+	e.Sum = sumComponents(e.Components)
+	e.Calculated = e.Sum
+	if round {
+		e.Calculated = roundHalfUp(e.Sum, 2)
+	}
+	te.Totals = append(te.Totals, e)
+}
+
+// explainVATBasis explains the VAT category taxable amount (BT-116) of tt
+// as checked by check.
+func (inv *Invoice) explainVATBasis(tt *TradeTax, check vatBasisCheck) TotalExplanation {
+	e := TotalExplanation{
+		Term:         "BT-116",
+		Name:         "VAT category taxable amount",
+		Rule:         check.strict,
+		CategoryCode: tt.CategoryCode,
+		Percent:      tt.Percent,
+		Components:   inv.detailLineBasis(tt.CategoryCode, tt.Percent, check.perRate),
+		Declared:     tt.BasisAmount,
+	}
+	e.Sum = sumComponents(e.Components)
+	e.Calculated = roundHalfUp(e.Sum, 2)
+	if inv.IsExtended() && check.fxext.Code != "" {
+		e.Rule = check.fxext
+		e.Tolerance = decimal.New(1, -2).Mul(decimal.NewFromInt(int64(len(e.Components))))
+	}
+	return e
+}
+
+func sumComponents(components []TotalComponent) decimal.Decimal {
+	sum := decimal.Zero
+	for _, c := range components {
+		sum = sum.Add(c.Amount)
+	}
+	return sum
+}
+
+// allowanceChargeLabel returns "allowance n" or "charge n" (1-based position
+// among the document level allowances and charges) followed by the reason.
+func allowanceChargeLabel(ac *AllowanceCharge, i int) string {
+	kind := "allowance"
+	if ac.ChargeIndicator {
+		kind = "charge"
+	}
+	label := fmt.Sprintf("%s %d", kind, i+1)
+	if ac.Reason != "" {
+		label += " (" + ac.Reason + ")"
+	}
+	return label
+}
+
+func vatLabel(category string, percent decimal.Decimal) string {
+	return fmt.Sprintf("VAT %s %s%%", category, percent.String())
+}
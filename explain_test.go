@@ -0,0 +1,135 @@
+package einvoice
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice/rules"
+)
+
+func TestExplainTotals(t *testing.T) {
+	inv := createMinimalInvoice()
+	inv.InvoiceLines = append(inv.InvoiceLines, InvoiceLine{
+		LineID:                   "2",
+		ItemName:                 "Reduced",
+		BilledQuantity:           decimal.NewFromInt(3),
+		BilledQuantityUnit:       "C62",
+		NetPrice:                 decimal.RequireFromString("3.333"),
+		TaxCategoryCode:          "S",
+		TaxRateApplicablePercent: decimal.NewFromInt(7),
+	})
+	inv.SpecifiedTradeAllowanceCharge = []AllowanceCharge{{
+		ActualAmount:                          decimal.NewFromInt(10),
+		Reason:                                "Discount",
+		CategoryTradeTaxCategoryCode:          "S",
+		CategoryTradeTaxRateApplicablePercent: decimal.NewFromInt(19),
+	}}
+	inv.UpdateLines()
+	inv.UpdateApplicableTradeTax(nil)
+	inv.UpdateTotals()
+
+	te := inv.ExplainTotals()
+	if !te.Valid() {
+		t.Fatalf("ExplainTotals() not valid: %+v", te)
+	}
+	var terms []string
+	for _, e := range te.Totals {
+		terms = append(terms, e.Term)
+	}
+	if want := []string{"BT-106", "BT-107", "BT-108", "BT-109", "BT-110", "BT-112", "BT-115"}; !slices.Equal(terms, want) {
+		t.Errorf("terms = %v, want %v", terms, want)
+	}
+
+	lineTotal := te.Totals[0]
+	if len(lineTotal.Components) != 2 || lineTotal.Components[1].Label != "line 2" || !lineTotal.Components[1].Amount.Equal(decimal.RequireFromString("10")) {
+		t.Errorf("BT-106 components = %+v", lineTotal.Components)
+	}
+
+	// BT-116 for 19 %: line 1 minus the allowance
+	if len(te.VATBreakdown) != 2 {
+		t.Fatalf("got %d VAT breakdown explanations, want 2", len(te.VATBreakdown))
+	}
+	basis := te.VATBreakdown[0]
+	if basis.Rule.Code != rules.BRS8.Code || len(basis.Components) != 2 {
+		t.Fatalf("BT-116 = %+v", basis)
+	}
+	if c := basis.Components[1]; c.Term != "BT-92" || c.Label != "allowance 1 (Discount)" || !c.Amount.Equal(decimal.NewFromInt(-10)) {
+		t.Errorf("allowance component = %+v", c)
+	}
+	if !basis.Calculated.Equal(decimal.NewFromInt(90)) {
+		t.Errorf("BT-116 calculated = %s, want 90", basis.Calculated)
+	}
+
+	// A wrong taxable amount is reported with its difference
+	inv.TradeTaxes[0].BasisAmount = decimal.NewFromInt(100)
+	te = inv.ExplainTotals()
+	if te.Valid() || te.VATBreakdown[0].Matches() {
+		t.Error("wrong BT-116 not detected")
+	}
+	if d := te.VATBreakdown[0].Difference(); !d.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("Difference() = %s, want 10", d)
+	}
+}
+
+func TestExplainTotals_Rounding(t *testing.T) {
+	inv := createMinimalInvoice()
+	inv.GrandTotal = decimal.RequireFromString("119.00")
+	inv.TotalPrepaid = decimal.RequireFromString("0.004")
+	inv.RoundingAmount = decimal.RequireFromString("0.01")
+	inv.DuePayableAmount = decimal.RequireFromString("119.01")
+
+	te := inv.ExplainTotals()
+	due := te.Totals[len(te.Totals)-1]
+	if due.Term != "BT-115" || !due.Matches() {
+		t.Fatalf("BT-115 = %+v", due)
+	}
+	// 119.00 - 0.004 = 118.996 rounded to 119.00 before adding the rounding amount
+	if !due.Sum.Equal(decimal.RequireFromString("119.006")) || !due.Rounding().Equal(decimal.RequireFromString("0.004")) {
+		t.Errorf("Sum = %s, Rounding() = %s", due.Sum, due.Rounding())
+	}
+}
+
+func TestExplainTotals_ExtendedTolerance(t *testing.T) {
+	inv := createMinimalInvoice()
+	inv.GuidelineSpecifiedDocumentContextParameter = SpecFacturXExtended
+	inv.TradeTaxes[0].BasisAmount = decimal.RequireFromString("100.01")
+
+	te := inv.ExplainTotals()
+	basis := te.VATBreakdown[0]
+	if basis.Rule.Code != rules.BRFXEXTS08.Code || !basis.Tolerance.Equal(decimal.RequireFromString("0.01")) || !basis.Matches() {
+		t.Errorf("BT-116 = %+v, want BR-FXEXT-S-08 within tolerance 0.01", basis)
+	}
+	if lineTotal := te.Totals[0]; lineTotal.Rule.Code != rules.BRFXEXTCO10.Code {
+		t.Errorf("BT-106 rule = %s, want BR-FXEXT-CO-10", lineTotal.Rule.Code)
+	}
+}
+
+func TestExplainTotals_IntraCommunity(t *testing.T) {
+	inv := createMinimalInvoice()
+	inv.InvoiceLines[0].TaxCategoryCode = "K"
+	inv.InvoiceLines[0].TaxRateApplicablePercent = decimal.Zero
+	inv.TradeTaxes[0].CategoryCode = "K"
+	inv.TradeTaxes[0].Percent = decimal.Zero
+	inv.TradeTaxes[0].CalculatedAmount = decimal.Zero
+	inv.TradeTaxes[0].BasisAmount = decimal.NewFromInt(90)
+
+	// Validate checks the whole category (BR-IC-6) and each rate (BR-IC-8)
+	te := inv.ExplainTotals()
+	var codes []string
+	for _, e := range te.VATBreakdown {
+		codes = append(codes, e.Rule.Code)
+		if e.Matches() || len(e.Components) != 1 || !e.Calculated.Equal(decimal.NewFromInt(100)) {
+			t.Errorf("%s = %+v, want mismatch of 100 from line 1", e.Rule.Code, e)
+		}
+	}
+	if want := []string{rules.BRIC6.Code, rules.BRIC8.Code}; !slices.Equal(codes, want) {
+		t.Errorf("rules = %v, want %v", codes, want)
+	}
+	_ = inv.Validate()
+	for _, code := range codes {
+		if !hasViolationCode(inv, code) {
+			t.Errorf("Validate() has no %s violation", code)
+		}
+	}
+}
@@ -8,7 +8,9 @@ require (
 	github.com/beevik/etree v1.6.0
 	github.com/google/go-cmp v0.7.0
 	github.com/shopspring/decimal v1.4.0
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/speedata/cxpath v0.0.9
+	github.com/speedata/goxml v1.0.9
 	github.com/speedata/pdfdisassembler v0.0.7
 	golang.org/x/term v0.44.0
 )
@@ -174,7 +176,6 @@ require (
 	github.com/sivchari/containedctx v1.0.3 // indirect
 	github.com/sonatard/noctx v0.4.0 // indirect
 	github.com/sourcegraph/go-diff v0.7.0 // indirect
-	github.com/speedata/goxml v1.0.9 // indirect
 	github.com/speedata/goxpath v1.0.12 // indirect
 	github.com/spf13/afero v1.14.0 // indirect
 	github.com/spf13/cast v1.5.0 // indirect
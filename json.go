@@ -0,0 +1,961 @@
+package einvoice
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+//go:generate go run ./cmd/genjsonschema --output schema/invoice.schema.json
+
+// JSONVersion is the version of the JSON mapping written by MarshalJSON. It
+// is incremented on incompatible changes of the mapping.
+const JSONVersion = 1
+
+// ErrJSONVersion is returned by UnmarshalJSON for documents with an
+// unsupported version.
+var ErrJSONVersion = errors.New("unsupported JSON version")
+
+// MarshalJSON encodes the invoice in the versioned JSON mapping of the
+// semantic model. Keys carry the business term (BT) or business group (BG)
+// of the value, decimals are encoded as strings and dates as ISO 8601
+// (YYYY-MM-DD). Zero values are omitted. The JSON Schema of the mapping is
+// returned by JSONSchema.
+func (inv Invoice) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONInvoice(&inv))
+}
+
+// UnmarshalJSON decodes an invoice in the JSON mapping written by
+// MarshalJSON. Unknown keys are rejected. A missing version is treated as
+// the current version.
+func (inv *Invoice) UnmarshalJSON(data []byte) error {
+	var j jsonInvoice
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&j); err != nil {
+		return fmt.Errorf("decode invoice JSON: %w", err)
+	}
+	if j.Version > JSONVersion || j.Version < 0 {
+		return fmt.Errorf("%w: %d (supported: %d)", ErrJSONVersion, j.Version, JSONVersion)
+	}
+	*inv = j.invoice()
+	return nil
+}
+
+// jsonDate is a date encoded as YYYY-MM-DD.
+type jsonDate time.Time
+
+func (d jsonDate) IsZero() bool {
+	return time.Time(d).IsZero()
+}
+
+func (d jsonDate) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + time.Time(d).Format("2006-01-02") + `"`), nil
+}
+
+func (d *jsonDate) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = jsonDate{}
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", s)
+	}
+	*d = jsonDate(t)
+	return nil
+}
+
+// The struct tag desc is used for the descriptions in the JSON Schema.
+
+type jsonNote struct {
+	Text        string `json:"bt22_text,omitempty" desc:"BT-22 Invoice note"`
+	SubjectCode string `json:"bt21_subject_code,omitempty" desc:"BT-21 Invoice note subject code"`
+}
+
+type jsonReferencedDocument struct {
+	ID   string   `json:"bt25_id,omitempty" desc:"BT-25 Preceding Invoice reference"`
+	Date jsonDate `json:"bt26_date,omitzero" desc:"BT-26 Preceding Invoice issue date"`
+}
+
+type jsonDeliveryNote struct {
+	ID   string   `json:"id,omitempty" desc:"Delivery note number"`
+	Date jsonDate `json:"date,omitzero" desc:"Delivery note date"`
+}
+
+type jsonLineReference struct {
+	ID     string   `json:"id,omitempty" desc:"Document number"`
+	LineID string   `json:"line_id,omitempty" desc:"Line number in the referenced document"`
+	Date   jsonDate `json:"date,omitzero" desc:"Issue date of the referenced document"`
+}
+
+type jsonCurrencyExchange struct {
+	SourceCurrencyCode string          `json:"source_currency,omitempty" desc:"Source currency code (invoice currency)"`
+	TargetCurrencyCode string          `json:"target_currency,omitempty" desc:"Target currency code (VAT accounting currency)"`
+	ConversionRate     decimal.Decimal `json:"rate,omitzero" desc:"Conversion rate"`
+	Operator           string          `json:"operator,omitempty" desc:"Mathematic operator of the conversion rate (UBL): Multiply or Divide"`
+	ConversionRateDate jsonDate        `json:"date,omitzero" desc:"Conversion rate date"`
+}
+
+type jsonPaymentAdjustmentTerms struct {
+	BasisDate          jsonDate        `json:"basis_date,omitzero" desc:"Start of the period"`
+	BasisPeriodMeasure decimal.Decimal `json:"period,omitzero" desc:"Length of the period"`
+	BasisPeriodUnit    string          `json:"period_unit,omitempty" desc:"Unit of the period such as DAY"`
+	BasisAmount        decimal.Decimal `json:"base_amount,omitzero" desc:"Base amount"`
+	CalculationPercent decimal.Decimal `json:"percentage,omitzero" desc:"Percentage"`
+	ActualAmount       decimal.Decimal `json:"amount,omitzero" desc:"Discount or penalty amount"`
+}
+
+type jsonGlobalID struct {
+	ID     string `json:"id,omitempty" desc:"Identifier (BT-29, BT-46, BT-60, BT-71, BT-157)"`
+	Scheme string `json:"scheme,omitempty" desc:"Identification scheme identifier (ISO/IEC 6523)"`
+}
+
+type jsonPostalAddress struct {
+	Line1                  string `json:"line1,omitempty" desc:"Address line 1 (BT-35, BT-50, BT-64, BT-75)"`
+	Line2                  string `json:"line2,omitempty" desc:"Address line 2 (BT-36, BT-51, BT-65, BT-76)"`
+	Line3                  string `json:"line3,omitempty" desc:"Address line 3 (BT-162, BT-163, BT-164, BT-165)"`
+	City                   string `json:"city,omitempty" desc:"City (BT-37, BT-52, BT-66, BT-77)"`
+	PostcodeCode           string `json:"postcode,omitempty" desc:"Post code (BT-38, BT-53, BT-67, BT-78)"`
+	CountrySubDivisionName string `json:"country_subdivision,omitempty" desc:"Country subdivision (BT-39, BT-54, BT-68, BT-79)"`
+	CountryID              string `json:"country,omitempty" desc:"Country code, ISO 3166-1 alpha-2 (BT-40, BT-55, BT-69, BT-80)"`
+}
+
+type jsonLegalOrganization struct {
+	ID                  string `json:"id,omitempty" desc:"Legal registration identifier (BT-30, BT-47, BT-61)"`
+	Scheme              string `json:"scheme,omitempty" desc:"Legal registration identifier scheme (BT-30, BT-47, BT-61)"`
+	TradingBusinessName string `json:"trading_name,omitempty" desc:"Trading name (BT-28, BT-45)"`
+}
+
+type jsonContact struct {
+	PersonName     string `json:"person_name,omitempty" desc:"Contact point (BT-41, BT-56)"`
+	DepartmentName string `json:"department_name,omitempty" desc:"Contact department (BT-41, BT-56)"`
+	EMail          string `json:"email,omitempty" desc:"Contact email address (BT-43, BT-58)"`
+	PhoneNumber    string `json:"phone,omitempty" desc:"Contact telephone number (BT-42, BT-57)"`
+}
+
+type jsonParty struct {
+	Name                    string                 `json:"name,omitempty" desc:"Name (BT-27, BT-44, BT-59, BT-62, BT-70)"`
+	ID                      []string               `json:"ids,omitempty" desc:"Identifiers (BT-29, BT-46, BT-60, BT-71)"`
+	GlobalID                []jsonGlobalID         `json:"global_ids,omitempty" desc:"Identifiers with scheme (BT-29, BT-46, BT-60, BT-71)"`
+	Description             string                 `json:"description,omitempty" desc:"Additional legal information (BT-33)"`
+	ElectronicAddress       string                 `json:"electronic_address,omitempty" desc:"Electronic address (BT-34, BT-49)"`
+	ElectronicAddressScheme string                 `json:"electronic_address_scheme,omitempty" desc:"Electronic address scheme (BT-34, BT-49)"`
+	PostalAddress           *jsonPostalAddress     `json:"postal_address,omitempty" desc:"Postal address (BG-5, BG-8, BG-12, BG-15)"`
+	LegalOrganization       *jsonLegalOrganization `json:"legal_organization,omitempty" desc:"Legal registration (BT-30, BT-47, BT-61)"`
+	Contacts                []jsonContact          `json:"contacts,omitempty" desc:"Contacts (BG-6, BG-9)"`
+	VATID                   string                 `json:"vat_id,omitempty" desc:"VAT identifier (BT-31, BT-48, BT-63)"`
+	TaxRegistration         string                 `json:"tax_registration,omitempty" desc:"Tax registration identifier (BT-32)"`
+}
+
+type jsonAllowanceCharge struct {
+	ChargeIndicator bool            `json:"charge_indicator" desc:"true for a charge, false for an allowance"`
+	ActualAmount    decimal.Decimal `json:"amount,omitzero" desc:"Amount (BT-92, BT-99, BT-136, BT-141, BT-147)"`
+	BasisAmount     decimal.Decimal `json:"base_amount,omitzero" desc:"Base amount (BT-93, BT-100, BT-137, BT-142)"`
+	Percent         decimal.Decimal `json:"percentage,omitzero" desc:"Percentage (BT-94, BT-101, BT-138, BT-143)"`
+	Reason          string          `json:"reason,omitempty" desc:"Reason (BT-97, BT-104, BT-139, BT-144)"`
+	ReasonCode      string          `json:"reason_code,omitempty" desc:"Reason code (BT-98, BT-105, BT-140, BT-145)"`
+	TaxType         string          `json:"vat_type,omitempty" desc:"Tax type, VAT"`
+	TaxCategoryCode string          `json:"vat_category,omitempty" desc:"VAT category code (BT-95, BT-102)"`
+	TaxRate         decimal.Decimal `json:"vat_rate,omitzero" desc:"VAT rate (BT-96, BT-103)"`
+	Logistics       bool            `json:"logistics_service_charge,omitempty" desc:"Logistics service charge (EXTENDED)"`
+}
+
+type jsonTradeTax struct {
+	BasisAmount         decimal.Decimal `json:"bt116_taxable_amount,omitzero" desc:"BT-116 VAT category taxable amount"`
+	CalculatedAmount    decimal.Decimal `json:"bt117_tax_amount,omitzero" desc:"BT-117 VAT category tax amount"`
+	TypeCode            string          `json:"vat_type,omitempty" desc:"Tax type, VAT"`
+	CategoryCode        string          `json:"bt118_category,omitempty" desc:"BT-118 VAT category code"`
+	Percent             decimal.Decimal `json:"bt119_rate,omitzero" desc:"BT-119 VAT category rate"`
+	ExemptionReason     string          `json:"bt120_exemption_reason,omitempty" desc:"BT-120 VAT exemption reason text"`
+	ExemptionReasonCode string          `json:"bt121_exemption_reason_code,omitempty" desc:"BT-121 VAT exemption reason code"`
+	TaxPointDate        jsonDate        `json:"bt7_tax_point_date,omitzero" desc:"BT-7 Value added tax point date"`
+	DueDateTypeCode     string          `json:"bt8_tax_point_date_code,omitempty" desc:"BT-8 Value added tax point date code"`
+}
+
+type jsonAdvancePayment struct {
+	PaidAmount   decimal.Decimal `json:"paid_amount,omitzero" desc:"Amount paid including VAT"`
+	ReceivedDate jsonDate        `json:"received_date,omitzero" desc:"Date the payment was received"`
+	TradeTaxes   []jsonTradeTax  `json:"vat,omitempty" desc:"VAT included in the paid amount per category and rate"`
+	InvoiceID    string          `json:"invoice_id,omitempty" desc:"Number of the prepayment invoice"`
+	InvoiceDate  jsonDate        `json:"invoice_date,omitzero" desc:"Issue date of the prepayment invoice"`
+}
+
+type jsonDocument struct {
+	ID                string `json:"bt122_id,omitempty" desc:"BT-122 Supporting document reference"`
+	TypeCode          string `json:"type_code,omitempty" desc:"Document type code (916 for supporting documents, 50 for BT-17, 130 for BT-18)"`
+	ReferenceTypeCode string `json:"reference_type_code,omitempty" desc:"Invoiced object identifier scheme (BT-18)"`
+	Name              string `json:"bt123_description,omitempty" desc:"BT-123 Supporting document description"`
+	URIID             string `json:"bt124_uri,omitempty" desc:"BT-124 External document location"`
+	MimeCode          string `json:"bt125_mime_code,omitempty" desc:"BT-125 Attached document MIME code"`
+	Filename          string `json:"bt125_filename,omitempty" desc:"BT-125 Attached document filename"`
+	Content           []byte `json:"bt125_content,omitempty" desc:"BT-125 Attached document, base64 encoded"`
+}
+
+type jsonPaymentMeans struct {
+	TypeCode                int    `json:"bt81_type_code,omitempty" desc:"BT-81 Payment means type code (UNTDID 4461)"`
+	Information             string `json:"bt82_text,omitempty" desc:"BT-82 Payment means text"`
+	PayeeIBAN               string `json:"bt84_iban,omitempty" desc:"BT-84 Payment account identifier (IBAN)"`
+	PayeeProprietaryID      string `json:"bt84_proprietary_id,omitempty" desc:"BT-84 Payment account identifier (proprietary)"`
+	PayeeAccountName        string `json:"bt85_account_name,omitempty" desc:"BT-85 Payment account name"`
+	PayeeBIC                string `json:"bt86_bic,omitempty" desc:"BT-86 Payment service provider identifier"`
+	CardID                  string `json:"bt87_card_number,omitempty" desc:"BT-87 Payment card primary account number"`
+	CardholderName          string `json:"bt88_cardholder_name,omitempty" desc:"BT-88 Payment card holder name"`
+	PayerDebitedAccountIBAN string `json:"bt91_debited_account,omitempty" desc:"BT-91 Debited account identifier"`
+}
+
+type jsonPaymentTerms struct {
+	Description          string                      `json:"bt20_description,omitempty" desc:"BT-20 Payment terms"`
+	DueDate              jsonDate                    `json:"bt9_due_date,omitzero" desc:"BT-9 Payment due date"`
+	DirectDebitMandateID string                      `json:"bt89_mandate_id,omitempty" desc:"BT-89 Mandate reference identifier"`
+	PartialPaymentAmount decimal.Decimal             `json:"ext_partial_payment_amount,omitzero" desc:"Amount due with this payment term (EXTENDED)"`
+	PenaltyTerms         *jsonPaymentAdjustmentTerms `json:"ext_penalty_terms,omitempty" desc:"Late payment penalty (EXTENDED)"`
+	DiscountTerms        *jsonPaymentAdjustmentTerms `json:"ext_discount_terms,omitempty" desc:"Early payment discount (EXTENDED)"`
+}
+
+type jsonCharacteristic struct {
+	Description string `json:"bt160_name,omitempty" desc:"BT-160 Item attribute name"`
+	Value       string `json:"bt161_value,omitempty" desc:"BT-161 Item attribute value"`
+}
+
+type jsonClassification struct {
+	ClassCode     string `json:"code,omitempty" desc:"Item classification identifier (BT-158)"`
+	ListID        string `json:"list_id,omitempty" desc:"Scheme identifier (UNTDID 7143)"`
+	ListVersionID string `json:"list_version,omitempty" desc:"Scheme version identifier"`
+}
+
+type jsonInvoiceLine struct {
+	LineID                 string                `json:"bt126_line_id,omitempty" desc:"BT-126 Invoice line identifier"`
+	ParentLineID           string                `json:"btx304_parent_line_id,omitempty" desc:"BT-X-304 Parent line identifier (EXTENDED)"`
+	LineStatusCode         string                `json:"btx7_line_status_code,omitempty" desc:"BT-X-7 Line status code (EXTENDED)"`
+	LineStatusReasonCode   string                `json:"btx8_line_status_reason_code,omitempty" desc:"BT-X-8 Line subtype: DETAIL, GROUP or INFORMATION (EXTENDED)"`
+	Note                   string                `json:"bt127_note,omitempty" desc:"BT-127 Invoice line note"`
+	ObjectID               string                `json:"bt128_object_id,omitempty" desc:"BT-128 Invoice line object identifier"`
+	ObjectTypeCode         string                `json:"bt128_type_code,omitempty" desc:"BT-128 Document type code of the object identifier"`
+	ObjectScheme           string                `json:"bt128_scheme,omitempty" desc:"BT-128 Invoice line object identifier scheme"`
+	BilledQuantity         decimal.Decimal       `json:"bt129_quantity,omitzero" desc:"BT-129 Invoiced quantity"`
+	BilledQuantityUnit     string                `json:"bt130_unit,omitempty" desc:"BT-130 Invoiced quantity unit of measure code"`
+	Total                  decimal.Decimal       `json:"bt131_net_amount,omitzero" desc:"BT-131 Invoice line net amount"`
+	BuyerOrderReference    string                `json:"bt132_order_line_reference,omitempty" desc:"BT-132 Referenced purchase order line reference"`
+	AccountingAccount      string                `json:"bt133_accounting_reference,omitempty" desc:"BT-133 Invoice line Buyer accounting reference"`
+	PeriodStart            jsonDate              `json:"bt134_period_start,omitzero" desc:"BT-134 Invoice line period start date"`
+	PeriodEnd              jsonDate              `json:"bt135_period_end,omitzero" desc:"BT-135 Invoice line period end date"`
+	Allowances             []jsonAllowanceCharge `json:"bg27_allowances,omitempty" desc:"BG-27 Invoice line allowances"`
+	Charges                []jsonAllowanceCharge `json:"bg28_charges,omitempty" desc:"BG-28 Invoice line charges"`
+	NetPrice               decimal.Decimal       `json:"bt146_net_price,omitzero" desc:"BT-146 Item net price"`
+	TaxInclusivePrice      decimal.Decimal       `json:"tax_inclusive_price,omitzero" desc:"Item price including VAT, the net price is derived from it"`
+	PriceAllowancesCharges []jsonAllowanceCharge `json:"bt147_price_allowances,omitempty" desc:"BT-147 Item price discounts"`
+	GrossPrice             decimal.Decimal       `json:"bt148_gross_price,omitzero" desc:"BT-148 Item gross price"`
+	BasisQuantity          decimal.Decimal       `json:"bt149_base_quantity,omitzero" desc:"BT-149 Item price base quantity"`
+	BasisQuantityUnit      string                `json:"bt150_base_quantity_unit,omitempty" desc:"BT-150 Item price base quantity unit of measure code"`
+	NetBilledQuantity      decimal.Decimal       `json:"net_billed_quantity,omitzero" desc:"Net price base quantity (CII)"`
+	NetBilledQuantityUnit  string                `json:"net_billed_quantity_unit,omitempty" desc:"Net price base quantity unit (CII)"`
+	TaxTypeCode            string                `json:"vat_type,omitempty" desc:"Tax type, VAT"`
+	TaxCategoryCode        string                `json:"bt151_vat_category,omitempty" desc:"BT-151 Invoiced item VAT category code"`
+	TaxRate                decimal.Decimal       `json:"bt152_vat_rate,omitzero" desc:"BT-152 Invoiced item VAT rate"`
+	ItemName               string                `json:"bt153_item_name,omitempty" desc:"BT-153 Item name"`
+	Description            string                `json:"bt154_item_description,omitempty" desc:"BT-154 Item description"`
+	ArticleNumber          string                `json:"bt155_seller_item_id,omitempty" desc:"BT-155 Item Seller's identifier"`
+	ArticleNumberBuyer     string                `json:"bt156_buyer_item_id,omitempty" desc:"BT-156 Item Buyer's identifier"`
+	GlobalID               string                `json:"bt157_standard_item_id,omitempty" desc:"BT-157 Item standard identifier"`
+	GlobalIDType           string                `json:"bt157_scheme,omitempty" desc:"BT-157 Item standard identifier scheme"`
+	ProductClassification  []jsonClassification  `json:"bt158_classifications,omitempty" desc:"BT-158 Item classification identifiers"`
+	OriginTradeCountry     string                `json:"bt159_origin_country,omitempty" desc:"BT-159 Item country of origin"`
+	Characteristics        []jsonCharacteristic  `json:"bg32_attributes,omitempty" desc:"BG-32 Item attributes"`
+	PackageQuantity        decimal.Decimal       `json:"ext_package_quantity,omitzero" desc:"Number of packages (EXTENDED)"`
+	PackageQuantityUnit    string                `json:"ext_package_quantity_unit,omitempty" desc:"Package unit code (EXTENDED)"`
+	DeliveryDate           jsonDate              `json:"ext_delivery_date,omitzero" desc:"Actual delivery date of the line (EXTENDED)"`
+	DespatchAdvice         *jsonLineReference    `json:"ext_despatch_advice,omitempty" desc:"Despatch advice line reference (EXTENDED)"`
+	DeliveryNote           *jsonLineReference    `json:"ext_delivery_note,omitempty" desc:"Delivery note line reference (EXTENDED)"`
+}
+
+type jsonInvoice struct {
+	Version                    int                      `json:"version" desc:"Version of the JSON mapping"`
+	SchemaType                 string                   `json:"schema_type,omitempty" desc:"XML syntax of the invoice: CII or UBL" enum:"CII,UBL"`
+	InvoiceNumber              string                   `json:"bt1_invoice_number,omitempty" desc:"BT-1 Invoice number"`
+	InvoiceDate                jsonDate                 `json:"bt2_invoice_date,omitzero" desc:"BT-2 Invoice issue date"`
+	InvoiceTypeCode            int                      `json:"bt3_type_code,omitempty" desc:"BT-3 Invoice type code (UNTDID 1001)"`
+	InvoiceCurrencyCode        string                   `json:"bt5_currency,omitempty" desc:"BT-5 Invoice currency code"`
+	TaxCurrencyCode            string                   `json:"bt6_tax_currency,omitempty" desc:"BT-6 VAT accounting currency code"`
+	BuyerReference             string                   `json:"bt10_buyer_reference,omitempty" desc:"BT-10 Buyer reference"`
+	ProjectID                  string                   `json:"bt11_project_id,omitempty" desc:"BT-11 Project reference"`
+	ProjectName                string                   `json:"bt11_project_name,omitempty" desc:"BT-11 Project name"`
+	ContractReference          string                   `json:"bt12_contract_reference,omitempty" desc:"BT-12 Contract reference"`
+	BuyerOrderReference        string                   `json:"bt13_purchase_order_reference,omitempty" desc:"BT-13 Purchase order reference"`
+	SellerOrderReference       string                   `json:"bt14_sales_order_reference,omitempty" desc:"BT-14 Sales order reference"`
+	ReceivingAdviceReference   string                   `json:"bt15_receiving_advice_reference,omitempty" desc:"BT-15 Receiving advice reference"`
+	DespatchAdviceReference    string                   `json:"bt16_despatch_advice_reference,omitempty" desc:"BT-16 Despatch advice reference"`
+	AccountingAccount          string                   `json:"bt19_accounting_reference,omitempty" desc:"BT-19 Buyer accounting reference"`
+	BusinessProcess            string                   `json:"bt23_business_process,omitempty" desc:"BT-23 Business process type"`
+	SpecificationIdentifier    string                   `json:"bt24_specification_identifier,omitempty" desc:"BT-24 Specification identifier"`
+	Notes                      []jsonNote               `json:"bg1_notes,omitempty" desc:"BG-1 Invoice notes"`
+	PrecedingInvoices          []jsonReferencedDocument `json:"bg3_preceding_invoices,omitempty" desc:"BG-3 Preceding invoice references"`
+	Seller                     jsonParty                `json:"bg4_seller" desc:"BG-4 Seller"`
+	Buyer                      jsonParty                `json:"bg7_buyer" desc:"BG-7 Buyer"`
+	Payee                      *jsonParty               `json:"bg10_payee,omitempty" desc:"BG-10 Payee"`
+	SellerTaxRepresentative    *jsonParty               `json:"bg11_tax_representative,omitempty" desc:"BG-11 Seller tax representative party"`
+	ShipTo                     *jsonParty               `json:"bg13_ship_to,omitempty" desc:"BG-13 Delivery information"`
+	UltimateShipTo             *jsonParty               `json:"ext_ultimate_ship_to,omitempty" desc:"Final recipient of the goods (EXTENDED)"`
+	ShipFrom                   *jsonParty               `json:"ext_ship_from,omitempty" desc:"Party the goods are shipped from (EXTENDED)"`
+	DeliveryNote               *jsonDeliveryNote        `json:"ext_delivery_note,omitempty" desc:"Delivery note reference (EXTENDED)"`
+	Invoicer                   *jsonParty               `json:"ext_invoicer,omitempty" desc:"Party issuing the invoice on behalf of the seller (EXTENDED)"`
+	Invoicee                   *jsonParty               `json:"ext_invoicee,omitempty" desc:"Party the invoice is addressed to (EXTENDED)"`
+	DeliveryDate               jsonDate                 `json:"bt72_delivery_date,omitzero" desc:"BT-72 Actual delivery date"`
+	PeriodStart                jsonDate                 `json:"bt73_period_start,omitzero" desc:"BT-73 Invoicing period start date"`
+	PeriodEnd                  jsonDate                 `json:"bt74_period_end,omitzero" desc:"BT-74 Invoicing period end date"`
+	PaymentReference           string                   `json:"bt83_payment_reference,omitempty" desc:"BT-83 Remittance information"`
+	CreditorReferenceID        string                   `json:"bt90_creditor_reference,omitempty" desc:"BT-90 Bank assigned creditor identifier"`
+	TaxCurrencyExchange        *jsonCurrencyExchange    `json:"ext_tax_currency_exchange,omitempty" desc:"Exchange rate to the VAT accounting currency (EXTENDED)"`
+	PaymentMeans               []jsonPaymentMeans       `json:"bg16_payment_means,omitempty" desc:"BG-16 Payment instructions"`
+	PaymentTerms               []jsonPaymentTerms       `json:"bt20_payment_terms,omitempty" desc:"BT-20 Payment terms with due date (BT-9) and mandate (BT-89)"`
+	AllowancesCharges          []jsonAllowanceCharge    `json:"bg20_bg21_allowances_charges,omitempty" desc:"BG-20 Document level allowances and BG-21 document level charges"`
+	TradeTaxes                 []jsonTradeTax           `json:"bg23_vat_breakdown,omitempty" desc:"BG-23 VAT breakdown"`
+	AdditionalDocuments        []jsonDocument           `json:"bg24_additional_documents,omitempty" desc:"BG-24 Additional supporting documents"`
+	Lines                      []jsonInvoiceLine        `json:"bg25_lines,omitempty" desc:"BG-25 Invoice lines"`
+	LineTotal                  decimal.Decimal          `json:"bt106_line_total,omitzero" desc:"BT-106 Sum of Invoice line net amount"`
+	AllowanceTotal             decimal.Decimal          `json:"bt107_allowance_total,omitzero" desc:"BT-107 Sum of allowances on document level"`
+	ChargeTotal                decimal.Decimal          `json:"bt108_charge_total,omitzero" desc:"BT-108 Sum of charges on document level"`
+	TaxBasisTotal              decimal.Decimal          `json:"bt109_tax_basis_total,omitzero" desc:"BT-109 Invoice total amount without VAT"`
+	TaxTotal                   decimal.Decimal          `json:"bt110_tax_total,omitzero" desc:"BT-110 Invoice total VAT amount"`
+	TaxTotalCurrency           string                   `json:"bt110_currency,omitempty" desc:"Currency of BT-110"`
+	TaxTotalAccounting         decimal.Decimal          `json:"bt111_tax_total_accounting,omitzero" desc:"BT-111 Invoice total VAT amount in accounting currency"`
+	TaxTotalAccountingCurrency string                   `json:"bt111_currency,omitempty" desc:"Currency of BT-111"`
+	GrandTotal                 decimal.Decimal          `json:"bt112_grand_total,omitzero" desc:"BT-112 Invoice total amount with VAT"`
+	TotalPrepaid               decimal.Decimal          `json:"bt113_prepaid,omitzero" desc:"BT-113 Paid amount"`
+	AdvancePayments            []jsonAdvancePayment     `json:"ext_advance_payments,omitempty" desc:"Prepayments included in the paid amount BT-113 (EXTENDED)"`
+	RoundingAmount             decimal.Decimal          `json:"bt114_rounding,omitzero" desc:"BT-114 Rounding amount"`
+	DuePayableAmount           decimal.Decimal          `json:"bt115_due_payable,omitzero" desc:"BT-115 Amount due for payment"`
+}
+
+// mapSlice applies f to all elements of s. A nil or empty slice yields nil.
+func mapSlice[S, T any](s []S, f func(S) T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	ret := make([]T, len(s))
+	for i, v := range s {
+		ret[i] = f(v)
+	}
+	return ret
+}
+
+func newJSONInvoice(inv *Invoice) *jsonInvoice {
+	j := &jsonInvoice{
+		Version:                    JSONVersion,
+		InvoiceNumber:              inv.InvoiceNumber,
+		InvoiceDate:                jsonDate(inv.InvoiceDate),
+		InvoiceTypeCode:            int(inv.InvoiceTypeCode),
+		InvoiceCurrencyCode:        inv.InvoiceCurrencyCode,
+		TaxCurrencyCode:            inv.TaxCurrencyCode,
+		BuyerReference:             inv.BuyerReference,
+		ProjectID:                  inv.SpecifiedProcuringProjectID,
+		ProjectName:                inv.SpecifiedProcuringProjectName,
+		ContractReference:          inv.ContractReferencedDocument,
+		BuyerOrderReference:        inv.BuyerOrderReferencedDocument,
+		SellerOrderReference:       inv.SellerOrderReferencedDocument,
+		ReceivingAdviceReference:   inv.ReceivingAdviceReferencedDocument,
+		DespatchAdviceReference:    inv.DespatchAdviceReferencedDocument,
+		AccountingAccount:          inv.ReceivableSpecifiedTradeAccountingAccount,
+		BusinessProcess:            inv.BPSpecifiedDocumentContextParameter,
+		SpecificationIdentifier:    inv.GuidelineSpecifiedDocumentContextParameter,
+		Notes:                      mapSlice(inv.Notes, func(n Note) jsonNote { return jsonNote(n) }),
+		PrecedingInvoices:          mapSlice(inv.InvoiceReferencedDocument, newJSONReferencedDocument),
+		Seller:                     newJSONParty(inv.Seller),
+		Buyer:                      newJSONParty(inv.Buyer),
+		Payee:                      newJSONPartyPtr(inv.PayeeTradeParty),
+		SellerTaxRepresentative:    newJSONPartyPtr(inv.SellerTaxRepresentativeTradeParty),
+		ShipTo:                     newJSONPartyPtr(inv.ShipTo),
+		UltimateShipTo:             newJSONPartyPtr(inv.UltimateShipTo),
+		ShipFrom:                   newJSONPartyPtr(inv.ShipFrom),
+		Invoicer:                   newJSONPartyPtr(inv.InvoicerTradeParty),
+		Invoicee:                   newJSONPartyPtr(inv.InvoiceeTradeParty),
+		DeliveryDate:               jsonDate(inv.OccurrenceDateTime),
+		PeriodStart:                jsonDate(inv.BillingSpecifiedPeriodStart),
+		PeriodEnd:                  jsonDate(inv.BillingSpecifiedPeriodEnd),
+		PaymentReference:           inv.PaymentReference,
+		CreditorReferenceID:        inv.CreditorReferenceID,
+		PaymentMeans:               mapSlice(inv.PaymentMeans, newJSONPaymentMeans),
+		PaymentTerms:               mapSlice(inv.SpecifiedTradePaymentTerms, newJSONPaymentTerms),
+		AllowancesCharges:          mapSlice(inv.SpecifiedTradeAllowanceCharge, newJSONAllowanceCharge),
+		TradeTaxes:                 mapSlice(inv.TradeTaxes, newJSONTradeTax),
+		AdditionalDocuments:        mapSlice(inv.AdditionalReferencedDocument, newJSONDocument),
+		Lines:                      mapSlice(inv.InvoiceLines, newJSONInvoiceLine),
+		LineTotal:                  inv.LineTotal,
+		AllowanceTotal:             inv.AllowanceTotal,
+		ChargeTotal:                inv.ChargeTotal,
+		TaxBasisTotal:              inv.TaxBasisTotal,
+		TaxTotal:                   inv.TaxTotal,
+		TaxTotalCurrency:           inv.TaxTotalCurrency,
+		TaxTotalAccounting:         inv.TaxTotalAccounting,
+		TaxTotalAccountingCurrency: inv.TaxTotalAccountingCurrency,
+		GrandTotal:                 inv.GrandTotal,
+		TotalPrepaid:               inv.TotalPrepaid,
+		AdvancePayments:            mapSlice(inv.AdvancePayments, newJSONAdvancePayment),
+		RoundingAmount:             inv.RoundingAmount,
+		DuePayableAmount:           inv.DuePayableAmount,
+	}
+	if dn := inv.DeliveryNoteReferencedDocument; dn != nil {
+		j.DeliveryNote = &jsonDeliveryNote{ID: dn.ID, Date: jsonDate(dn.Date)}
+	}
+	if ce := inv.TaxCurrencyExchange; ce != nil {
+		j.TaxCurrencyExchange = &jsonCurrencyExchange{
+			SourceCurrencyCode: ce.SourceCurrencyCode,
+			TargetCurrencyCode: ce.TargetCurrencyCode,
+			ConversionRate:     ce.ConversionRate,
+			Operator:           ce.MathematicOperatorCode,
+			ConversionRateDate: jsonDate(ce.ConversionRateDate),
+		}
+	}
+	switch inv.SchemaType {
+	case CII:
+		j.SchemaType = "CII"
+	case UBL:
+		j.SchemaType = "UBL"
+	}
+	return j
+}
+
+func (j *jsonInvoice) invoice() Invoice {
+	inv := Invoice{
+		InvoiceNumber:                              j.InvoiceNumber,
+		InvoiceDate:                                time.Time(j.InvoiceDate),
+		InvoiceTypeCode:                            CodeDocument(j.InvoiceTypeCode),
+		InvoiceCurrencyCode:                        j.InvoiceCurrencyCode,
+		TaxCurrencyCode:                            j.TaxCurrencyCode,
+		BuyerReference:                             j.BuyerReference,
+		SpecifiedProcuringProjectID:                j.ProjectID,
+		SpecifiedProcuringProjectName:              j.ProjectName,
+		ContractReferencedDocument:                 j.ContractReference,
+		BuyerOrderReferencedDocument:               j.BuyerOrderReference,
+		SellerOrderReferencedDocument:              j.SellerOrderReference,
+		ReceivingAdviceReferencedDocument:          j.ReceivingAdviceReference,
+		DespatchAdviceReferencedDocument:           j.DespatchAdviceReference,
+		ReceivableSpecifiedTradeAccountingAccount:  j.AccountingAccount,
+		BPSpecifiedDocumentContextParameter:        j.BusinessProcess,
+		GuidelineSpecifiedDocumentContextParameter: j.SpecificationIdentifier,
+		Notes:                             mapSlice(j.Notes, func(n jsonNote) Note { return Note(n) }),
+		InvoiceReferencedDocument:         mapSlice(j.PrecedingInvoices, jsonReferencedDocument.referencedDocument),
+		Seller:                            j.Seller.party(),
+		Buyer:                             j.Buyer.party(),
+		PayeeTradeParty:                   j.Payee.partyPtr(),
+		SellerTaxRepresentativeTradeParty: j.SellerTaxRepresentative.partyPtr(),
+		ShipTo:                            j.ShipTo.partyPtr(),
+		UltimateShipTo:                    j.UltimateShipTo.partyPtr(),
+		ShipFrom:                          j.ShipFrom.partyPtr(),
+		InvoicerTradeParty:                j.Invoicer.partyPtr(),
+		InvoiceeTradeParty:                j.Invoicee.partyPtr(),
+		OccurrenceDateTime:                time.Time(j.DeliveryDate),
+		BillingSpecifiedPeriodStart:       time.Time(j.PeriodStart),
+		BillingSpecifiedPeriodEnd:         time.Time(j.PeriodEnd),
+		PaymentReference:                  j.PaymentReference,
+		CreditorReferenceID:               j.CreditorReferenceID,
+		PaymentMeans:                      mapSlice(j.PaymentMeans, jsonPaymentMeans.paymentMeans),
+		SpecifiedTradePaymentTerms:        mapSlice(j.PaymentTerms, jsonPaymentTerms.paymentTerms),
+		SpecifiedTradeAllowanceCharge:     mapSlice(j.AllowancesCharges, jsonAllowanceCharge.allowanceCharge),
+		TradeTaxes:                        mapSlice(j.TradeTaxes, jsonTradeTax.tradeTax),
+		AdditionalReferencedDocument:      mapSlice(j.AdditionalDocuments, jsonDocument.document),
+		InvoiceLines:                      mapSlice(j.Lines, jsonInvoiceLine.invoiceLine),
+		LineTotal:                         j.LineTotal,
+		AllowanceTotal:                    j.AllowanceTotal,
+		ChargeTotal:                       j.ChargeTotal,
+		TaxBasisTotal:                     j.TaxBasisTotal,
+		TaxTotal:                          j.TaxTotal,
+		TaxTotalCurrency:                  j.TaxTotalCurrency,
+		TaxTotalAccounting:                j.TaxTotalAccounting,
+		TaxTotalAccountingCurrency:        j.TaxTotalAccountingCurrency,
+		GrandTotal:                        j.GrandTotal,
+		TotalPrepaid:                      j.TotalPrepaid,
+		AdvancePayments:                   mapSlice(j.AdvancePayments, jsonAdvancePayment.advancePayment),
+		RoundingAmount:                    j.RoundingAmount,
+		DuePayableAmount:                  j.DuePayableAmount,
+	}
+	if dn := j.DeliveryNote; dn != nil {
+		inv.DeliveryNoteReferencedDocument = &ReferencedDocument{ID: dn.ID, Date: time.Time(dn.Date)}
+	}
+	if ce := j.TaxCurrencyExchange; ce != nil {
+		inv.TaxCurrencyExchange = &CurrencyExchange{
+			SourceCurrencyCode:     ce.SourceCurrencyCode,
+			TargetCurrencyCode:     ce.TargetCurrencyCode,
+			ConversionRate:         ce.ConversionRate,
+			MathematicOperatorCode: ce.Operator,
+			ConversionRateDate:     time.Time(ce.ConversionRateDate),
+		}
+	}
+	switch j.SchemaType {
+	case "CII":
+		inv.SchemaType = CII
+	case "UBL":
+		inv.SchemaType = UBL
+	}
+	return inv
+}
+
+func newJSONReferencedDocument(rd ReferencedDocument) jsonReferencedDocument {
+	return jsonReferencedDocument{ID: rd.ID, Date: jsonDate(rd.Date)}
+}
+
+func (j jsonReferencedDocument) referencedDocument() ReferencedDocument {
+	return ReferencedDocument{ID: j.ID, Date: time.Time(j.Date)}
+}
+
+func newJSONParty(p Party) jsonParty {
+	j := jsonParty{
+		Name:                    p.Name,
+		ID:                      p.ID,
+		GlobalID:                mapSlice(p.GlobalID, func(g GlobalID) jsonGlobalID { return jsonGlobalID(g) }),
+		Description:             p.Description,
+		ElectronicAddress:       p.URIUniversalCommunication,
+		ElectronicAddressScheme: p.URIUniversalCommunicationScheme,
+		Contacts:                mapSlice(p.DefinedTradeContact, func(c DefinedTradeContact) jsonContact { return jsonContact(c) }),
+		VATID:                   p.VATaxRegistration,
+		TaxRegistration:         p.FCTaxRegistration,
+	}
+	if len(j.ID) == 0 {
+		j.ID = nil
+	}
+	if p.PostalAddress != nil {
+		a := p.PostalAddress
+		j.PostalAddress = &jsonPostalAddress{
+			Line1:                  a.Line1,
+			Line2:                  a.Line2,
+			Line3:                  a.Line3,
+			City:                   a.City,
+			PostcodeCode:           a.PostcodeCode,
+			CountrySubDivisionName: a.CountrySubDivisionName,
+			CountryID:              a.CountryID,
+		}
+	}
+	if p.SpecifiedLegalOrganization != nil {
+		lo := jsonLegalOrganization(*p.SpecifiedLegalOrganization)
+		j.LegalOrganization = &lo
+	}
+	return j
+}
+
+func newJSONPartyPtr(p *Party) *jsonParty {
+	if p == nil {
+		return nil
+	}
+	j := newJSONParty(*p)
+	return &j
+}
+
+func (j jsonParty) party() Party {
+	p := Party{
+		Name:                            j.Name,
+		ID:                              j.ID,
+		GlobalID:                        mapSlice(j.GlobalID, func(g jsonGlobalID) GlobalID { return GlobalID(g) }),
+		Description:                     j.Description,
+		URIUniversalCommunication:       j.ElectronicAddress,
+		URIUniversalCommunicationScheme: j.ElectronicAddressScheme,
+		DefinedTradeContact:             mapSlice(j.Contacts, func(c jsonContact) DefinedTradeContact { return DefinedTradeContact(c) }),
+		VATaxRegistration:               j.VATID,
+		FCTaxRegistration:               j.TaxRegistration,
+	}
+	if j.PostalAddress != nil {
+		a := j.PostalAddress
+		p.PostalAddress = &PostalAddress{
+			Line1:                  a.Line1,
+			Line2:                  a.Line2,
+			Line3:                  a.Line3,
+			City:                   a.City,
+			PostcodeCode:           a.PostcodeCode,
+			CountrySubDivisionName: a.CountrySubDivisionName,
+			CountryID:              a.CountryID,
+		}
+	}
+	if j.LegalOrganization != nil {
+		lo := SpecifiedLegalOrganization(*j.LegalOrganization)
+		p.SpecifiedLegalOrganization = &lo
+	}
+	return p
+}
+
+func (j *jsonParty) partyPtr() *Party {
+	if j == nil {
+		return nil
+	}
+	p := j.party()
+	return &p
+}
+
+func newJSONAllowanceCharge(ac AllowanceCharge) jsonAllowanceCharge {
+	return jsonAllowanceCharge{
+		ChargeIndicator: ac.ChargeIndicator,
+		ActualAmount:    ac.ActualAmount,
+		BasisAmount:     ac.BasisAmount,
+		Percent:         ac.CalculationPercent,
+		Reason:          ac.Reason,
+		ReasonCode:      ac.ReasonCode,
+		TaxType:         ac.CategoryTradeTaxType,
+		TaxCategoryCode: ac.CategoryTradeTaxCategoryCode,
+		TaxRate:         ac.CategoryTradeTaxRateApplicablePercent,
+		Logistics:       ac.LogisticsServiceCharge,
+	}
+}
+
+func (j jsonAllowanceCharge) allowanceCharge() AllowanceCharge {
+	return AllowanceCharge{
+		ChargeIndicator:                       j.ChargeIndicator,
+		ActualAmount:                          j.ActualAmount,
+		BasisAmount:                           j.BasisAmount,
+		CalculationPercent:                    j.Percent,
+		Reason:                                j.Reason,
+		ReasonCode:                            j.ReasonCode,
+		CategoryTradeTaxType:                  j.TaxType,
+		CategoryTradeTaxCategoryCode:          j.TaxCategoryCode,
+		CategoryTradeTaxRateApplicablePercent: j.TaxRate,
+		LogisticsServiceCharge:                j.Logistics,
+	}
+}
+
+func newJSONTradeTax(tt TradeTax) jsonTradeTax {
+	return jsonTradeTax{
+		BasisAmount:         tt.BasisAmount,
+		CalculatedAmount:    tt.CalculatedAmount,
+		TypeCode:            tt.TypeCode,
+		CategoryCode:        tt.CategoryCode,
+		Percent:             tt.Percent,
+		ExemptionReason:     tt.ExemptionReason,
+		ExemptionReasonCode: tt.ExemptionReasonCode,
+		TaxPointDate:        jsonDate(tt.TaxPointDate),
+		DueDateTypeCode:     tt.DueDateTypeCode,
+	}
+}
+
+func (j jsonTradeTax) tradeTax() TradeTax {
+	return TradeTax{
+		BasisAmount:         j.BasisAmount,
+		CalculatedAmount:    j.CalculatedAmount,
+		TypeCode:            j.TypeCode,
+		CategoryCode:        j.CategoryCode,
+		Percent:             j.Percent,
+		ExemptionReason:     j.ExemptionReason,
+		ExemptionReasonCode: j.ExemptionReasonCode,
+		TaxPointDate:        time.Time(j.TaxPointDate),
+		DueDateTypeCode:     j.DueDateTypeCode,
+	}
+}
+
+func newJSONAdvancePayment(ap AdvancePayment) jsonAdvancePayment {
+	return jsonAdvancePayment{
+		PaidAmount:   ap.PaidAmount,
+		ReceivedDate: jsonDate(ap.ReceivedDate),
+		TradeTaxes:   mapSlice(ap.TradeTaxes, newJSONTradeTax),
+		InvoiceID:    ap.InvoiceID,
+		InvoiceDate:  jsonDate(ap.InvoiceDate),
+	}
+}
+
+func (j jsonAdvancePayment) advancePayment() AdvancePayment {
+	return AdvancePayment{
+		PaidAmount:   j.PaidAmount,
+		ReceivedDate: time.Time(j.ReceivedDate),
+		TradeTaxes:   mapSlice(j.TradeTaxes, jsonTradeTax.tradeTax),
+		InvoiceID:    j.InvoiceID,
+		InvoiceDate:  time.Time(j.InvoiceDate),
+	}
+}
+
+func newJSONDocument(d Document) jsonDocument {
+	return jsonDocument{
+		ID:                d.IssuerAssignedID,
+		TypeCode:          d.TypeCode,
+		ReferenceTypeCode: d.ReferenceTypeCode,
+		Name:              d.Name,
+		URIID:             d.URIID,
+		MimeCode:          d.AttachmentMimeCode,
+		Filename:          d.AttachmentFilename,
+		Content:           d.AttachmentBinaryObject,
+	}
+}
+
+func (j jsonDocument) document() Document {
+	return Document{
+		IssuerAssignedID:       j.ID,
+		TypeCode:               j.TypeCode,
+		ReferenceTypeCode:      j.ReferenceTypeCode,
+		Name:                   j.Name,
+		URIID:                  j.URIID,
+		AttachmentMimeCode:     j.MimeCode,
+		AttachmentFilename:     j.Filename,
+		AttachmentBinaryObject: j.Content,
+	}
+}
+
+func newJSONPaymentMeans(pm PaymentMeans) jsonPaymentMeans {
+	return jsonPaymentMeans{
+		TypeCode:                pm.TypeCode,
+		Information:             pm.Information,
+		PayeeIBAN:               pm.PayeePartyCreditorFinancialAccountIBAN,
+		PayeeProprietaryID:      pm.PayeePartyCreditorFinancialAccountProprietaryID,
+		PayeeAccountName:        pm.PayeePartyCreditorFinancialAccountName,
+		PayeeBIC:                pm.PayeeSpecifiedCreditorFinancialInstitutionBIC,
+		CardID:                  pm.ApplicableTradeSettlementFinancialCardID,
+		CardholderName:          pm.ApplicableTradeSettlementFinancialCardCardholderName,
+		PayerDebitedAccountIBAN: pm.PayerPartyDebtorFinancialAccountIBAN,
+	}
+}
+
+func (j jsonPaymentMeans) paymentMeans() PaymentMeans {
+	return PaymentMeans{
+		TypeCode:                               j.TypeCode,
+		Information:                            j.Information,
+		PayeePartyCreditorFinancialAccountIBAN: j.PayeeIBAN,
+		PayeePartyCreditorFinancialAccountProprietaryID:      j.PayeeProprietaryID,
+		PayeePartyCreditorFinancialAccountName:               j.PayeeAccountName,
+		PayeeSpecifiedCreditorFinancialInstitutionBIC:        j.PayeeBIC,
+		ApplicableTradeSettlementFinancialCardID:             j.CardID,
+		ApplicableTradeSettlementFinancialCardCardholderName: j.CardholderName,
+		PayerPartyDebtorFinancialAccountIBAN:                 j.PayerDebitedAccountIBAN,
+	}
+}
+
+func newJSONPaymentTerms(pt SpecifiedTradePaymentTerms) jsonPaymentTerms {
+	return jsonPaymentTerms{
+		Description:          pt.Description,
+		DueDate:              jsonDate(pt.DueDate),
+		DirectDebitMandateID: pt.DirectDebitMandateID,
+		PartialPaymentAmount: pt.PartialPaymentAmount,
+		PenaltyTerms:         newJSONPaymentAdjustmentTerms(pt.PenaltyTerms),
+		DiscountTerms:        newJSONPaymentAdjustmentTerms(pt.DiscountTerms),
+	}
+}
+
+func (j jsonPaymentTerms) paymentTerms() SpecifiedTradePaymentTerms {
+	return SpecifiedTradePaymentTerms{
+		Description:          j.Description,
+		DueDate:              time.Time(j.DueDate),
+		DirectDebitMandateID: j.DirectDebitMandateID,
+		PartialPaymentAmount: j.PartialPaymentAmount,
+		PenaltyTerms:         j.PenaltyTerms.paymentAdjustmentTerms(),
+		DiscountTerms:        j.DiscountTerms.paymentAdjustmentTerms(),
+	}
+}
+
+func newJSONPaymentAdjustmentTerms(pat *PaymentAdjustmentTerms) *jsonPaymentAdjustmentTerms {
+	if pat == nil {
+		return nil
+	}
+	return &jsonPaymentAdjustmentTerms{
+		BasisDate:          jsonDate(pat.BasisDate),
+		BasisPeriodMeasure: pat.BasisPeriodMeasure,
+		BasisPeriodUnit:    pat.BasisPeriodUnit,
+		BasisAmount:        pat.BasisAmount,
+		CalculationPercent: pat.CalculationPercent,
+		ActualAmount:       pat.ActualAmount,
+	}
+}
+
+func (j *jsonPaymentAdjustmentTerms) paymentAdjustmentTerms() *PaymentAdjustmentTerms {
+	if j == nil {
+		return nil
+	}
+	return &PaymentAdjustmentTerms{
+		BasisDate:          time.Time(j.BasisDate),
+		BasisPeriodMeasure: j.BasisPeriodMeasure,
+		BasisPeriodUnit:    j.BasisPeriodUnit,
+		BasisAmount:        j.BasisAmount,
+		CalculationPercent: j.CalculationPercent,
+		ActualAmount:       j.ActualAmount,
+	}
+}
+
+func newJSONLineReference(lr *LineReference) *jsonLineReference {
+	if lr == nil {
+		return nil
+	}
+	return &jsonLineReference{ID: lr.ID, LineID: lr.LineID, Date: jsonDate(lr.Date)}
+}
+
+func (j *jsonLineReference) lineReference() *LineReference {
+	if j == nil {
+		return nil
+	}
+	return &LineReference{ID: j.ID, LineID: j.LineID, Date: time.Time(j.Date)}
+}
+
+func newJSONInvoiceLine(l InvoiceLine) jsonInvoiceLine {
+	return jsonInvoiceLine{
+		LineID:                 l.LineID,
+		ParentLineID:           l.ParentLineID,
+		LineStatusCode:         l.LineStatusCode,
+		LineStatusReasonCode:   l.LineStatusReasonCode,
+		Note:                   l.Note,
+		ObjectID:               l.AdditionalReferencedDocumentID,
+		ObjectTypeCode:         l.AdditionalReferencedDocumentTypeCode,
+		ObjectScheme:           l.AdditionalReferencedDocumentRefTypeCode,
+		BilledQuantity:         l.BilledQuantity,
+		BilledQuantityUnit:     l.BilledQuantityUnit,
+		Total:                  l.Total,
+		BuyerOrderReference:    l.BuyerOrderReferencedDocument,
+		AccountingAccount:      l.ReceivableSpecifiedTradeAccountingAccount,
+		PeriodStart:            jsonDate(l.BillingSpecifiedPeriodStart),
+		PeriodEnd:              jsonDate(l.BillingSpecifiedPeriodEnd),
+		Allowances:             mapSlice(l.InvoiceLineAllowances, newJSONAllowanceCharge),
+		Charges:                mapSlice(l.InvoiceLineCharges, newJSONAllowanceCharge),
+		NetPrice:               l.NetPrice,
+		TaxInclusivePrice:      l.TaxInclusivePrice,
+		PriceAllowancesCharges: mapSlice(l.AppliedTradeAllowanceCharge, newJSONAllowanceCharge),
+		GrossPrice:             l.GrossPrice,
+		BasisQuantity:          l.BasisQuantity,
+		BasisQuantityUnit:      l.BasisQuantityUnit,
+		NetBilledQuantity:      l.NetBilledQuantity,
+		NetBilledQuantityUnit:  l.NetBilledQuantityUnit,
+		TaxTypeCode:            l.TaxTypeCode,
+		TaxCategoryCode:        l.TaxCategoryCode,
+		TaxRate:                l.TaxRateApplicablePercent,
+		ItemName:               l.ItemName,
+		Description:            l.Description,
+		ArticleNumber:          l.ArticleNumber,
+		ArticleNumberBuyer:     l.ArticleNumberBuyer,
+		GlobalID:               l.GlobalID,
+		GlobalIDType:           l.GlobalIDType,
+		ProductClassification:  mapSlice(l.ProductClassification, func(c Classification) jsonClassification { return jsonClassification(c) }),
+		OriginTradeCountry:     l.OriginTradeCountry,
+		Characteristics:        mapSlice(l.Characteristics, func(c Characteristic) jsonCharacteristic { return jsonCharacteristic(c) }),
+		PackageQuantity:        l.PackageQuantity,
+		PackageQuantityUnit:    l.PackageQuantityUnit,
+		DeliveryDate:           jsonDate(l.ActualDeliveryDate),
+		DespatchAdvice:         newJSONLineReference(l.DespatchAdviceReferencedDocument),
+		DeliveryNote:           newJSONLineReference(l.DeliveryNoteReferencedDocument),
+	}
+}
+
+func (j jsonInvoiceLine) invoiceLine() InvoiceLine {
+	return InvoiceLine{
+		LineID:                                  j.LineID,
+		ParentLineID:                            j.ParentLineID,
+		LineStatusCode:                          j.LineStatusCode,
+		LineStatusReasonCode:                    j.LineStatusReasonCode,
+		Note:                                    j.Note,
+		AdditionalReferencedDocumentID:          j.ObjectID,
+		AdditionalReferencedDocumentTypeCode:    j.ObjectTypeCode,
+		AdditionalReferencedDocumentRefTypeCode: j.ObjectScheme,
+		BilledQuantity:                          j.BilledQuantity,
+		BilledQuantityUnit:                      j.BilledQuantityUnit,
+		Total:                                   j.Total,
+		BuyerOrderReferencedDocument:            j.BuyerOrderReference,
+		ReceivableSpecifiedTradeAccountingAccount: j.AccountingAccount,
+		BillingSpecifiedPeriodStart:               time.Time(j.PeriodStart),
+		BillingSpecifiedPeriodEnd:                 time.Time(j.PeriodEnd),
+		InvoiceLineAllowances:                     mapSlice(j.Allowances, jsonAllowanceCharge.allowanceCharge),
+		InvoiceLineCharges:                        mapSlice(j.Charges, jsonAllowanceCharge.allowanceCharge),
+		NetPrice:                                  j.NetPrice,
+		TaxInclusivePrice:                         j.TaxInclusivePrice,
+		AppliedTradeAllowanceCharge:               mapSlice(j.PriceAllowancesCharges, jsonAllowanceCharge.allowanceCharge),
+		GrossPrice:                                j.GrossPrice,
+		BasisQuantity:                             j.BasisQuantity,
+		BasisQuantityUnit:                         j.BasisQuantityUnit,
+		NetBilledQuantity:                         j.NetBilledQuantity,
+		NetBilledQuantityUnit:                     j.NetBilledQuantityUnit,
+		TaxTypeCode:                               j.TaxTypeCode,
+		TaxCategoryCode:                           j.TaxCategoryCode,
+		TaxRateApplicablePercent:                  j.TaxRate,
+		ItemName:                                  j.ItemName,
+		Description:                               j.Description,
+		ArticleNumber:                             j.ArticleNumber,
+		ArticleNumberBuyer:                        j.ArticleNumberBuyer,
+		GlobalID:                                  j.GlobalID,
+		GlobalIDType:                              j.GlobalIDType,
+		ProductClassification:                     mapSlice(j.ProductClassification, func(c jsonClassification) Classification { return Classification(c) }),
+		OriginTradeCountry:                        j.OriginTradeCountry,
+		Characteristics:                           mapSlice(j.Characteristics, func(c jsonCharacteristic) Characteristic { return Characteristic(c) }),
+		PackageQuantity:                           j.PackageQuantity,
+		PackageQuantityUnit:                       j.PackageQuantityUnit,
+		ActualDeliveryDate:                        time.Time(j.DeliveryDate),
+		DespatchAdviceReferencedDocument:          j.DespatchAdvice.lineReference(),
+		DeliveryNoteReferencedDocument:            j.DeliveryNote.lineReference(),
+	}
+}
+
+var (
+	jsonDateType    = reflect.TypeFor[jsonDate]()
+	jsonDecimalType = reflect.TypeFor[decimal.Decimal]()
+)
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of the JSON mapping
+// written by MarshalJSON. The schema is also available in the file
+// schema/invoice.schema.json of the repository.
+func JSONSchema() ([]byte, error) {
+	defs := map[string]any{}
+	root := jsonSchemaObject(reflect.TypeFor[jsonInvoice](), defs)
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = "https://github.com/speedata/einvoice/schema/invoice.schema.json"
+	root["title"] = "EN 16931 invoice"
+	root["description"] = fmt.Sprintf("JSON mapping of the EN 16931 semantic model, version %d", JSONVersion)
+	root["$defs"] = defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// jsonSchemaObject returns the schema of the struct type t. Nested structs
+// are added to defs and referenced by name.
+func jsonSchemaObject(t reflect.Type, defs map[string]any) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		prop := jsonSchemaType(f.Type, defs)
+		if desc := f.Tag.Get("desc"); desc != "" {
+			prop["description"] = desc
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			prop["enum"] = strings.Split(enum, ",")
+		}
+		properties[name] = prop
+		if opts == "" && f.Type.Kind() != reflect.Bool {
+			required = append(required, name)
+		}
+	}
+	obj := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	return obj
+}
+
+func jsonSchemaType(t reflect.Type, defs map[string]any) map[string]any {
+	switch t {
+	case jsonDateType:
+		return map[string]any{"type": "string", "format": "date"}
+	case jsonDecimalType:
+		return map[string]any{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?$`}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonSchemaType(t.Elem(), defs)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": jsonSchemaType(t.Elem(), defs)}
+	case reflect.Struct:
+		name := strings.TrimPrefix(t.Name(), "json")
+		if _, ok := defs[name]; !ok {
+			defs[name] = nil // guard against recursion
+			defs[name] = jsonSchemaObject(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
@@ -0,0 +1,179 @@
+package einvoice
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/shopspring/decimal"
+)
+
+// TestJSONRoundTripFixtures parses every valid fixture, encodes it as JSON,
+// decodes the JSON and compares the result with the parsed invoice.
+func TestJSONRoundTripFixtures(t *testing.T) {
+	t.Parallel()
+
+	var fixtures []string
+	for _, pattern := range []string{"testdata/cii/*/*.xml", "testdata/ubl/*/*.xml", "testdata/peppol/valid/*.xml"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixtures = append(fixtures, matches...)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			t.Parallel()
+
+			inv, err := ParseXMLFile(fixture)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			data, err := json.Marshal(inv)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			var decoded Invoice
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			// The parsers create empty slices where the JSON mapping omits the key.
+			assertInvoiceEqual(t, inv, &decoded, cmpopts.EquateEmpty())
+
+			// A second encoding must be byte-identical.
+			data2, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("marshal decoded: %v", err)
+			}
+			if !bytes.Equal(data, data2) {
+				t.Errorf("JSON encoding not stable:\n%s\n%s", data, data2)
+			}
+		})
+	}
+}
+
+func TestJSONEncoding(t *testing.T) {
+	inv := Invoice{
+		SchemaType:          CII,
+		InvoiceNumber:       "RE-1",
+		InvoiceDate:         time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+		InvoiceTypeCode:     380,
+		InvoiceCurrencyCode: "EUR",
+		GrandTotal:          decimal.RequireFromString("119.00"),
+		AdditionalReferencedDocument: []Document{
+			{IssuerAssignedID: "A1", AttachmentBinaryObject: []byte("hello")},
+		},
+	}
+	data, err := json.Marshal(inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		`"version":1`,
+		`"schema_type":"CII"`,
+		`"bt1_invoice_number":"RE-1"`,
+		`"bt2_invoice_date":"2025-03-14"`,
+		`"bt3_type_code":380`,
+		`"bt112_grand_total":"119"`,
+		`"bt125_content":"aGVsbG8="`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("JSON does not contain %s:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"bt115_due_payable", "bt9_due_date", "bg10_payee"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("JSON contains zero value %s:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestJSONUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr error
+		wantMsg string
+	}{
+		{"future version", `{"version":2}`, ErrJSONVersion, ""},
+		{"unknown key", `{"version":1,"invoice_number":"1"}`, nil, "unknown field"},
+		{"invalid date", `{"bt2_invoice_date":"14.03.2025"}`, nil, "invalid date"},
+		{"invalid decimal", `{"bt112_grand_total":"abc"}`, nil, "decimal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inv Invoice
+			err := json.Unmarshal([]byte(tt.json), &inv)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error = %q, want substring %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+// TestJSONWrite checks that a decoded invoice can be written as XML directly.
+func TestJSONWrite(t *testing.T) {
+	for _, fixture := range []string{
+		"testdata/cii/en16931/CII_example1.xml",
+		"testdata/ubl/invoice/UBL-Invoice-2.1-Example.xml",
+	} {
+		t.Run(fixture, func(t *testing.T) {
+			inv, err := ParseXMLFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(inv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded Invoice
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := decoded.Write(&buf); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			reparsed, err := ParseReader(&buf)
+			if err != nil {
+				t.Fatalf("parse written XML: %v", err)
+			}
+			if reparsed.InvoiceNumber != inv.InvoiceNumber || !reparsed.GrandTotal.Equal(inv.GrandTotal) {
+				t.Errorf("got %s/%s, want %s/%s", reparsed.InvoiceNumber, reparsed.GrandTotal, inv.InvoiceNumber, inv.GrandTotal)
+			}
+		})
+	}
+}
+
+// TestJSONSchemaUpToDate ensures the committed schema matches JSONSchema.
+// Run go generate to update the file.
+func TestJSONSchemaUpToDate(t *testing.T) {
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	committed, err := os.ReadFile("schema/invoice.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(append(schema, '\n'), committed) {
+		t.Error("schema/invoice.schema.json is outdated, run go generate")
+	}
+}
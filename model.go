@@ -216,6 +216,7 @@ type InvoiceLine struct {
 	InvoiceLineCharges                        []AllowanceCharge // BG-28
 	AppliedTradeAllowanceCharge               []AllowanceCharge // BT-147
 	NetPrice                                  decimal.Decimal   // BT-146
+	TaxInclusivePrice                         decimal.Decimal   // not in EN 16931: item price including VAT, see Invoice.UpdateTaxInclusive
 	NetBilledQuantity                         decimal.Decimal   // BT-149
 	NetBilledQuantityUnit                     string            // BT-150
 	BilledQuantity                            decimal.Decimal   // BT-129
@@ -224,6 +225,11 @@ type InvoiceLine struct {
 	TaxCategoryCode                           string            // BT-151
 	TaxRateApplicablePercent                  decimal.Decimal   // BT-152
 	Total                                     decimal.Decimal   // BT-131
+	PackageQuantity                           decimal.Decimal   // EXTENDED: number of packages
+	PackageQuantityUnit                       string            // EXTENDED: package unit code such as XCT
+	ActualDeliveryDate                        time.Time         // EXTENDED: actual delivery date of the line
+	DespatchAdviceReferencedDocument          *LineReference    // EXTENDED: despatch advice line reference
+	DeliveryNoteReferencedDocument            *LineReference    // EXTENDED: delivery note line reference
 
 	// Private fields for tracking XML element presence (BR-24, BR-26, BR-CO-20)
 	// These are set during parsing to distinguish between missing elements and zero values
@@ -266,6 +272,12 @@ type AllowanceCharge struct {
 	CategoryTradeTaxType                  string          // BT-95, BT-102
 	CategoryTradeTaxCategoryCode          string          // BT-95, BT-102
 	CategoryTradeTaxRateApplicablePercent decimal.Decimal // BT-96, BT-103
+	// LogisticsServiceCharge marks a document level charge as logistics
+	// service charge (EXTENDED). The writer outputs it as
+	// SpecifiedLogisticsServiceCharge in the Extended profile and as
+	// regular charge (BG-21) otherwise. It must not be set on allowances
+	// (BR-USER-11), these are written as regular allowance (BG-20).
+	LogisticsServiceCharge bool
 }
 
 // TradeTax is the VAT breakdown for each percentage.
@@ -313,9 +325,62 @@ type Document struct {
 
 // SpecifiedTradePaymentTerms is unbounded in extended.
 type SpecifiedTradePaymentTerms struct {
-	Description          string    // BT-20
-	DueDate              time.Time // BT-9
-	DirectDebitMandateID string    // BT-89
+	Description          string                  // BT-20
+	DueDate              time.Time               // BT-9
+	DirectDebitMandateID string                  // BT-89
+	PartialPaymentAmount decimal.Decimal         // EXTENDED: amount due with this payment term
+	PenaltyTerms         *PaymentAdjustmentTerms // EXTENDED: late payment penalty
+	DiscountTerms        *PaymentAdjustmentTerms // EXTENDED: early payment discount (Skonto)
+}
+
+// PaymentAdjustmentTerms are the discount or penalty terms of a payment term
+// (EXTENDED).
+type PaymentAdjustmentTerms struct {
+	BasisDate          time.Time       // start of the period, the invoice date if zero
+	BasisPeriodMeasure decimal.Decimal // length of the period
+	BasisPeriodUnit    string          // unit of the period such as DAY
+	BasisAmount        decimal.Decimal // amount the percentage applies to
+	CalculationPercent decimal.Decimal
+	ActualAmount       decimal.Decimal // discount or penalty amount
+}
+
+// LineReference references a line of another document such as a despatch
+// advice or a delivery note (EXTENDED).
+type LineReference struct {
+	ID     string    // document number
+	LineID string    // line number in the referenced document
+	Date   time.Time // issue date of the referenced document
+}
+
+// CurrencyExchange is the exchange rate from the invoice currency to the VAT
+// accounting currency (CII EXTENDED ram:TaxApplicableTradeCurrencyExchange,
+// UBL cac:TaxExchangeRate).
+type CurrencyExchange struct {
+	SourceCurrencyCode     string          // invoice currency (BT-5)
+	TargetCurrencyCode     string          // VAT accounting currency (BT-6)
+	ConversionRate         decimal.Decimal // units of the target currency per unit of the source currency, see MathematicOperatorCode
+	MathematicOperatorCode string          // UBL: "Divide" if the source amounts are divided by ConversionRate, "Multiply" or empty otherwise
+	ConversionRateDate     time.Time
+}
+
+// multiplyRate returns the rate the source currency amounts are multiplied
+// with, the reciprocal of ConversionRate for the operator "Divide".
+func (ce *CurrencyExchange) multiplyRate() decimal.Decimal {
+	if strings.EqualFold(ce.MathematicOperatorCode, "Divide") && !ce.ConversionRate.IsZero() {
+		return decimal.NewFromInt(1).Div(ce.ConversionRate)
+	}
+	return ce.ConversionRate
+}
+
+// AdvancePayment is a prepayment deducted in a final invoice with the VAT it
+// includes per category (CII EXTENDED ram:SpecifiedAdvancePayment). The paid
+// amounts add up to the paid amount (BT-113), see Invoice.DeductPrepayments.
+type AdvancePayment struct {
+	PaidAmount   decimal.Decimal // amount paid including VAT
+	ReceivedDate time.Time       // date the payment was received
+	TradeTaxes   []TradeTax      // net amount (BasisAmount) and VAT (CalculatedAmount) per category and rate
+	InvoiceID    string          // number of the prepayment invoice
+	InvoiceDate  time.Time       // issue date of the prepayment invoice
 }
 
 // ReferencedDocument links to a previous invoice BG-3.
@@ -369,6 +434,13 @@ type Invoice struct {
 	TradeTaxes                                 []TradeTax                   // BG-23
 	SpecifiedTradeAllowanceCharge              []AllowanceCharge            // BG-20, BG-21
 	ShipTo                                     *Party                       // BG-13
+	UltimateShipTo                             *Party                       // EXTENDED: final recipient of the goods
+	ShipFrom                                   *Party                       // EXTENDED: party the goods are shipped from
+	DeliveryNoteReferencedDocument             *ReferencedDocument          // EXTENDED: delivery note
+	InvoicerTradeParty                         *Party                       // EXTENDED: party issuing the invoice on behalf of the seller
+	InvoiceeTradeParty                         *Party                       // EXTENDED: party the invoice is addressed to
+	TaxCurrencyExchange                        *CurrencyExchange            // exchange rate for BT-6 (CII EXTENDED, UBL)
+	AdvancePayments                            []AdvancePayment             // EXTENDED: prepayments included in BT-113
 	SpecifiedTradePaymentTerms                 []SpecifiedTradePaymentTerms // BT-20
 	SchemaType                                 CodeSchemaType               // UBL or CII
 	InvoiceReferencedDocument                  []ReferencedDocument         // BG-3
@@ -389,6 +461,15 @@ type Invoice struct {
 	// Private field for tracking unexpected TaxTotalAmount currencies during parsing
 	unexpectedTaxCurrencies []string
 
+	// Private field holding the SBDH envelope header, if the invoice was unwrapped from one
+	sbdh *SBDH
+
+	// Private field holding the XMP Factur-X ConformanceLevel, if the invoice was read from a PDF
+	pdfConformanceLevel string
+
+	// Private field holding the numbers of the prepayment invoices deducted by DeductPrepayments
+	deductedPrepayments []string
+
 	violations []SemanticError // Private field - use Validate() and check error instead
 	warnings   []SemanticError // Private field - use Warnings() accessor
 }
@@ -1,36 +1,186 @@
 package einvoice
 
 import (
+	"bytes"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/shopspring/decimal"
 	"github.com/speedata/cxpath"
+	"github.com/speedata/goxml"
 )
 
-// getDecimal parses a decimal value from an XPath evaluation result.
+// Sentinel errors returned (wrapped) by ParseReader. Use errors.Is to check
+// for them.
+var (
+	// ErrMalformedXML is returned when the input is not well-formed XML.
+	ErrMalformedXML = errors.New("malformed XML")
+	// ErrEmptyNamespace is returned when the root element has no namespace.
+	ErrEmptyNamespace = errors.New("empty root element namespace")
+	// ErrUnknownNamespace is returned when the root element namespace is
+	// neither CII, UBL nor SBDH.
+	ErrUnknownNamespace = errors.New("unknown root element namespace")
+)
+
+// ParseError describes a value in the XML that could not be parsed. Use
+// errors.As to retrieve it from the error returned by ParseReader.
+type ParseError struct {
+	Field  string // Semantic field (business term), e.g. "BT-131"; empty if there is none
+	Path   string // XPath of the element, e.g. "/rsm:CrossIndustryInvoice/.../ram:LineTotalAmount"
+	Value  string // Raw value found in the XML
+	Line   int    // Line of the element in the XML (1-based, 0 if unknown)
+	Column int    // Column of the end of the element's start tag (1-based, 0 if unknown)
+	Err    error  // Underlying error
+}
+
+func (e *ParseError) Error() string {
+	var loc []string
+	if e.Field != "" {
+		loc = append(loc, e.Field)
+	}
+	if e.Path != "" {
+		loc = append(loc, "at "+e.Path)
+	}
+	if e.Line > 0 {
+		pos := fmt.Sprintf("line %d", e.Line)
+		if e.Column > 0 {
+			pos += fmt.Sprintf(", column %d", e.Column)
+		}
+		if len(loc) > 0 {
+			pos = "(" + pos + ")"
+		}
+		loc = append(loc, pos)
+	}
+	if len(loc) == 0 {
+		return e.Err.Error()
+	}
+	return strings.Join(loc, " ") + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError returns a ParseError for the value at eval (relative to ctx)
+// with the position of the node in the XML. err describes the problem.
+func newParseError(ctx *cxpath.Context, eval, field, value string, err error) *ParseError {
+	pe := &ParseError{
+		Field: field,
+		Path:  eval,
+		Value: value,
+		Err:   err,
+	}
+	seq := ctx.Eval(eval).Seq
+	if len(seq) == 0 {
+		return pe
+	}
+	switch n := seq[0].(type) {
+	case *goxml.Element:
+		pe.Path = elementPath(n)
+		pe.Line, pe.Column = n.Line, n.Pos
+	case *goxml.Attribute:
+		if elt, ok := n.Parent.(*goxml.Element); ok {
+			pe.Path = elementPath(elt) + "/@" + qualifiedName(n.Prefix, n.Name)
+			pe.Line, pe.Column = elt.Line, elt.Pos
+		}
+	}
+	return pe
+}
+
+// elementPath returns the absolute XPath of elt. A position predicate is
+// added for elements with siblings of the same name.
+func elementPath(elt *goxml.Element) string {
+	var steps []string
+	for elt != nil {
+		step := qualifiedName(elt.Prefix, elt.Name)
+		parent, _ := elt.Parent.(*goxml.Element)
+		if parent != nil {
+			pos, count := 0, 0
+			for _, c := range parent.Children() {
+				if sibling, ok := c.(*goxml.Element); ok && sibling.Name == elt.Name && sibling.Prefix == elt.Prefix {
+					count++
+					if sibling == elt {
+						pos = count
+					}
+				}
+			}
+			if count > 1 {
+				step += fmt.Sprintf("[%d]", pos)
+			}
+		}
+		steps = append(steps, step)
+		elt = parent
+	}
+	var sb strings.Builder
+	for i := len(steps) - 1; i >= 0; i-- {
+		sb.WriteString("/" + steps[i])
+	}
+	return sb.String()
+}
+
+func qualifiedName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + ":" + name
+}
+
+// getDecimal parses a decimal value from an XPath evaluation result. field
+// is the business term of the value, used in the ParseError.
 // Shared by both CII and UBL parsers.
-func getDecimal(ctx *cxpath.Context, eval string) (decimal.Decimal, error) {
+func getDecimal(ctx *cxpath.Context, eval, field string) (decimal.Decimal, error) {
 	a := ctx.Eval(eval).String()
 	if a == "" {
 		return decimal.Zero, nil
 	}
 	str, err := decimal.NewFromString(a)
 	if err != nil {
-		return decimal.Zero, fmt.Errorf("invalid decimal value '%s' at %s: %w", a, eval, err)
+		return decimal.Zero, newParseError(ctx, eval, field, a, fmt.Errorf("invalid decimal value '%s': %w", a, err))
 	}
 	return str, nil
 }
 
+// allowanceChargeField returns the business term of an allowance (BG-20,
+// BG-27) or charge (BG-21, BG-28) value.
+func allowanceChargeField(chargeIndicator bool, allowance, charge string) string {
+	if chargeIndicator {
+		return charge
+	}
+	return allowance
+}
+
+// taxTotalField returns the business term of a tax total amount in the
+// given currency: BT-110 for the invoice currency, otherwise BT-111.
+func taxTotalField(inv *Invoice, currency string) string {
+	if currency != "" && currency != inv.InvoiceCurrencyCode {
+		return "BT-111"
+	}
+	return "BT-110"
+}
+
 // ParseReader reads the XML from the reader and auto-detects the format (CII or UBL).
 // It detects the format by examining the root element namespace and routes to the
 // appropriate parser. Each parser handles its own namespace setup.
+//
+// Invoices wrapped in a PEPPOL StandardBusinessDocument are unwrapped; the
+// envelope header is available via Invoice.SBDH.
 func ParseReader(r io.Reader) (*Invoice, error) {
-	ctx, err := cxpath.NewFromReader(r)
+	data, err := io.ReadAll(r)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read from reader: %w", err)
 	}
+	ctx, err := cxpath.NewFromReader(bytes.NewReader(data))
+	if err != nil {
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &ParseError{Line: syntaxErr.Line, Err: fmt.Errorf("%w: %s", ErrMalformedXML, syntaxErr.Msg)}
+		}
+		return nil, fmt.Errorf("cannot read from reader: %w: %w", ErrMalformedXML, err)
+	}
 
 	// Detect format by checking root element namespace
 	root := ctx.Root()
@@ -40,7 +190,7 @@ func ParseReader(r io.Reader) (*Invoice, error) {
 
 	switch rootns {
 	case "":
-		return nil, fmt.Errorf("empty root element namespace")
+		return nil, ErrEmptyNamespace
 
 	// CII format (ZUGFeRD/Factur-X)
 	case nsCIIRootInvoice:
@@ -56,8 +206,12 @@ func ParseReader(r io.Reader) (*Invoice, error) {
 			return nil, fmt.Errorf("parse UBL: %w", err)
 		}
 
+	// PEPPOL envelope (Standard Business Document Header)
+	case nsSBDH:
+		return parseSBDH(data)
+
 	default:
-		return nil, fmt.Errorf("unknown root element namespace: %s", rootns)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownNamespace, rootns)
 	}
 
 	inv.isParsed = true
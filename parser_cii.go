@@ -11,8 +11,9 @@ import (
 // CII (ZUGFeRD/Factur-X) namespace URN for root element
 const nsCIIRootInvoice = "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100"
 
-// parseCIITime parses CII format dates (YYYYMMDD) into time.Time.
-func parseCIITime(ctx *cxpath.Context, path string) (time.Time, error) {
+// parseCIITime parses CII format dates (YYYYMMDD) into time.Time. field is
+// the business term of the date, used in the ParseError.
+func parseCIITime(ctx *cxpath.Context, path, field string) (time.Time, error) {
 	timestring := ctx.Eval(path).String()
 	if timestring == "" {
 		return time.Time{}, nil
@@ -20,7 +21,7 @@ func parseCIITime(ctx *cxpath.Context, path string) (time.Time, error) {
 
 	parsedDate, err := time.Parse("20060102", timestring)
 	if err != nil {
-		return parsedDate, fmt.Errorf("%w", err)
+		return parsedDate, newParseError(ctx, path, field, timestring, fmt.Errorf("invalid date %q: %w", timestring, err))
 	}
 
 	return parsedDate, nil
@@ -131,7 +132,7 @@ func parseCIIExchangedDocument(exchangedDocument *cxpath.Context, inv *Invoice)
 	inv.InvoiceNumber = exchangedDocument.Eval("ram:ID/text()").String()
 	inv.InvoiceTypeCode = CodeDocument(exchangedDocument.Eval("ram:TypeCode").Int())
 
-	invoiceDate, err := parseCIITime(exchangedDocument, "ram:IssueDateTime/udt:DateTimeString")
+	invoiceDate, err := parseCIITime(exchangedDocument, "ram:IssueDateTime/udt:DateTimeString", "BT-2")
 	if err != nil {
 		return err
 	}
@@ -165,38 +166,43 @@ func parseCIISupplyChainTradeTransaction(supplyChainTradeTransaction *cxpath.Con
 			return err
 		}
 
-		invoiceLine.BilledQuantity, err = getDecimal(lineItem, "ram:SpecifiedLineTradeDelivery/ram:BilledQuantity")
+		invoiceLine.BilledQuantity, err = getDecimal(lineItem, "ram:SpecifiedLineTradeDelivery/ram:BilledQuantity", "BT-129")
 		if err != nil {
 			return err
 		}
 		invoiceLine.BilledQuantityUnit = lineItem.Eval("ram:SpecifiedLineTradeDelivery/ram:BilledQuantity/@unitCode").String()
+		if err = parseCIILineTradeDelivery(lineItem.Eval("ram:SpecifiedLineTradeDelivery"), &invoiceLine); err != nil {
+			return err
+		}
 		// BR-24: Track XML element presence to validate later
 		invoiceLine.hasLineTotalInXML = lineItem.Eval("count(ram:SpecifiedLineTradeSettlement/ram:SpecifiedTradeSettlementLineMonetarySummation/ram:LineTotalAmount)").Int() > 0
-		invoiceLine.Total, err = getDecimal(lineItem, "ram:SpecifiedLineTradeSettlement/ram:SpecifiedTradeSettlementLineMonetarySummation/ram:LineTotalAmount")
+		invoiceLine.Total, err = getDecimal(lineItem, "ram:SpecifiedLineTradeSettlement/ram:SpecifiedTradeSettlementLineMonetarySummation/ram:LineTotalAmount", "BT-131")
 		if err != nil {
 			return err
 		}
 
 		for allowanceCharge := range lineItem.Each("ram:SpecifiedLineTradeSettlement/ram:SpecifiedTradeAllowanceCharge") {
-			basisAmount, err := getDecimal(allowanceCharge, "ram:BasisAmount")
+			chargeIndicator := allowanceCharge.Eval("string(ram:ChargeIndicator/udt:Indicator) = 'true'").Bool()
+
+			basisAmount, err := getDecimal(allowanceCharge, "ram:BasisAmount", allowanceChargeField(chargeIndicator, "BT-137", "BT-142"))
 			if err != nil {
 				return err
 			}
-			actualAmount, err := getDecimal(allowanceCharge, "ram:ActualAmount")
+			actualAmount, err := getDecimal(allowanceCharge, "ram:ActualAmount", allowanceChargeField(chargeIndicator, "BT-136", "BT-141"))
 			if err != nil {
 				return err
 			}
-			calculationPercent, err := getDecimal(allowanceCharge, "ram:CalculationPercent")
+			calculationPercent, err := getDecimal(allowanceCharge, "ram:CalculationPercent", allowanceChargeField(chargeIndicator, "BT-138", "BT-143"))
 			if err != nil {
 				return err
 			}
-			categoryTaxRate, err := getDecimal(allowanceCharge, "ram:CategoryTradeTax/ram:RateApplicablePercent")
+			categoryTaxRate, err := getDecimal(allowanceCharge, "ram:CategoryTradeTax/ram:RateApplicablePercent", "")
 			if err != nil {
 				return err
 			}
 
 			alc := AllowanceCharge{
-				ChargeIndicator:                       allowanceCharge.Eval("string(ram:ChargeIndicator/udt:Indicator) = 'true'").Bool(),
+				ChargeIndicator:                       chargeIndicator,
 				BasisAmount:                           basisAmount,
 				ActualAmount:                          actualAmount,
 				CalculationPercent:                    calculationPercent,
@@ -219,18 +225,18 @@ func parseCIISupplyChainTradeTransaction(supplyChainTradeTransaction *cxpath.Con
 		// BG-27, BG-28
 		invoiceLine.TaxTypeCode = taxInfo.Eval("ram:TypeCode").String()
 		invoiceLine.TaxCategoryCode = taxInfo.Eval("ram:CategoryCode").String()
-		invoiceLine.TaxRateApplicablePercent, err = getDecimal(taxInfo, "ram:RateApplicablePercent")
+		invoiceLine.TaxRateApplicablePercent, err = getDecimal(taxInfo, "ram:RateApplicablePercent", "BT-152")
 		if err != nil {
 			return err
 		}
 		invoiceLine.hasTaxRateApplicablePercent = taxInfo.Eval("count(ram:RateApplicablePercent)").Int() > 0
 		// BR-CO-20: Track BG-26 (INVOICE LINE PERIOD) presence to validate later
 		invoiceLine.linePeriodPresent = lineItem.Eval("count(ram:SpecifiedLineTradeSettlement/ram:BillingSpecifiedPeriod)").Int() > 0
-		invoiceLine.BillingSpecifiedPeriodStart, err = parseCIITime(lineItem, "ram:SpecifiedLineTradeSettlement/ram:BillingSpecifiedPeriod/ram:StartDateTime/udt:DateTimeString")
+		invoiceLine.BillingSpecifiedPeriodStart, err = parseCIITime(lineItem, "ram:SpecifiedLineTradeSettlement/ram:BillingSpecifiedPeriod/ram:StartDateTime/udt:DateTimeString", "BT-134")
 		if err != nil {
 			return fmt.Errorf("invalid line billing period start date for line %s: %w", invoiceLine.LineID, err)
 		}
-		invoiceLine.BillingSpecifiedPeriodEnd, err = parseCIITime(lineItem, "ram:SpecifiedLineTradeSettlement/ram:BillingSpecifiedPeriod/ram:EndDateTime/udt:DateTimeString")
+		invoiceLine.BillingSpecifiedPeriodEnd, err = parseCIITime(lineItem, "ram:SpecifiedLineTradeSettlement/ram:BillingSpecifiedPeriod/ram:EndDateTime/udt:DateTimeString", "BT-135")
 		if err != nil {
 			return fmt.Errorf("invalid line billing period end date for line %s: %w", invoiceLine.LineID, err)
 		}
@@ -308,7 +314,7 @@ func parseCIIApplicableHeaderTradeDelivery(applicableHeaderTradeDelivery *cxpath
 	inv.ReceivingAdviceReferencedDocument = applicableHeaderTradeDelivery.Eval("ram:ReceivingAdviceReferencedDocument/ram:IssuerAssignedID").String()
 	// BT-72
 	var err error
-	inv.OccurrenceDateTime, err = parseCIITime(applicableHeaderTradeDelivery, "ram:ActualDeliverySupplyChainEvent/ram:OccurrenceDateTime/udt:DateTimeString")
+	inv.OccurrenceDateTime, err = parseCIITime(applicableHeaderTradeDelivery, "ram:ActualDeliverySupplyChainEvent/ram:OccurrenceDateTime/udt:DateTimeString", "BT-72")
 	if err != nil {
 		return fmt.Errorf("invalid occurrence date time: %w", err)
 	}
@@ -317,9 +323,71 @@ func parseCIIApplicableHeaderTradeDelivery(applicableHeaderTradeDelivery *cxpath
 		st := parseCIIParty(applicableHeaderTradeDelivery.Eval("ram:ShipToTradeParty"))
 		inv.ShipTo = &st
 	}
+
+	// EXTENDED: ultimate ship to, ship from and delivery note
+	inv.UltimateShipTo = parseCIIPartyPtr(applicableHeaderTradeDelivery, "ram:UltimateShipToTradeParty")
+	inv.ShipFrom = parseCIIPartyPtr(applicableHeaderTradeDelivery, "ram:ShipFromTradeParty")
+	if applicableHeaderTradeDelivery.Eval("count(ram:DeliveryNoteReferencedDocument)").Int() > 0 {
+		dn := applicableHeaderTradeDelivery.Eval("ram:DeliveryNoteReferencedDocument")
+		date, err := parseCIITime(dn, "ram:FormattedIssueDateTime/qdt:DateTimeString", "")
+		if err != nil {
+			return err
+		}
+		inv.DeliveryNoteReferencedDocument = &ReferencedDocument{
+			ID:   dn.Eval("ram:IssuerAssignedID").String(),
+			Date: date,
+		}
+	}
+	return nil
+}
+
+// parseCIIPartyPtr parses the optional party at path, nil if it does not exist.
+func parseCIIPartyPtr(ctx *cxpath.Context, path string) *Party {
+	if ctx.Eval("count("+path+")").Int() == 0 {
+		return nil
+	}
+	p := parseCIIParty(ctx.Eval(path))
+	return &p
+}
+
+// parseCIILineTradeDelivery parses the Extended elements of the line delivery.
+func parseCIILineTradeDelivery(delivery *cxpath.Context, invoiceLine *InvoiceLine) error {
+	var err error
+	invoiceLine.PackageQuantity, err = getDecimal(delivery, "ram:PackageQuantity", "")
+	if err != nil {
+		return err
+	}
+	invoiceLine.PackageQuantityUnit = delivery.Eval("ram:PackageQuantity/@unitCode").String()
+	invoiceLine.ActualDeliveryDate, err = parseCIITime(delivery, "ram:ActualDeliverySupplyChainEvent/ram:OccurrenceDateTime/udt:DateTimeString", "")
+	if err != nil {
+		return err
+	}
+	if invoiceLine.DespatchAdviceReferencedDocument, err = parseCIILineReference(delivery, "ram:DespatchAdviceReferencedDocument"); err != nil {
+		return err
+	}
+	if invoiceLine.DeliveryNoteReferencedDocument, err = parseCIILineReference(delivery, "ram:DeliveryNoteReferencedDocument"); err != nil {
+		return err
+	}
 	return nil
 }
 
+// parseCIILineReference parses the optional line reference at path.
+func parseCIILineReference(ctx *cxpath.Context, path string) (*LineReference, error) {
+	if ctx.Eval("count("+path+")").Int() == 0 {
+		return nil, nil
+	}
+	refdoc := ctx.Eval(path)
+	date, err := parseCIITime(refdoc, "ram:FormattedIssueDateTime/qdt:DateTimeString", "")
+	if err != nil {
+		return nil, err
+	}
+	return &LineReference{
+		ID:     refdoc.Eval("ram:IssuerAssignedID").String(),
+		LineID: refdoc.Eval("ram:LineID").String(),
+		Date:   date,
+	}, nil
+}
+
 func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cxpath.Context, inv *Invoice) error {
 	var err error
 
@@ -330,12 +398,31 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 	inv.CreditorReferenceID = applicableHeaderTradeSettlement.Eval("ram:CreditorReferenceID").String()
 	// BT-83: Payment reference (remittance information)
 	inv.PaymentReference = applicableHeaderTradeSettlement.Eval("ram:PaymentReference").String()
+	// EXTENDED: invoicer and invoicee
+	inv.InvoicerTradeParty = parseCIIPartyPtr(applicableHeaderTradeSettlement, "ram:InvoicerTradeParty")
+	inv.InvoiceeTradeParty = parseCIIPartyPtr(applicableHeaderTradeSettlement, "ram:InvoiceeTradeParty")
 	// BG-10
 	if applicableHeaderTradeSettlement.Eval("count(ram:PayeeTradeParty)").Int() > 0 {
 		ptp := parseCIIParty(applicableHeaderTradeSettlement.Eval("ram:PayeeTradeParty"))
 		inv.PayeeTradeParty = &ptp
 	}
 
+	// EXTENDED: exchange rate to the VAT accounting currency
+	if applicableHeaderTradeSettlement.Eval("count(ram:TaxApplicableTradeCurrencyExchange)").Int() > 0 {
+		exchange := applicableHeaderTradeSettlement.Eval("ram:TaxApplicableTradeCurrencyExchange")
+		ce := CurrencyExchange{
+			SourceCurrencyCode: exchange.Eval("ram:SourceCurrencyCode").String(),
+			TargetCurrencyCode: exchange.Eval("ram:TargetCurrencyCode").String(),
+		}
+		if ce.ConversionRate, err = getDecimal(exchange, "ram:ConversionRate", ""); err != nil {
+			return err
+		}
+		if ce.ConversionRateDate, err = parseCIITime(exchange, "ram:ConversionRateDateTime/udt:DateTimeString", ""); err != nil {
+			return err
+		}
+		inv.TaxCurrencyExchange = &ce
+	}
+
 	for paymentMeans := range applicableHeaderTradeSettlement.Each("ram:SpecifiedTradeSettlementPaymentMeans") {
 		// BG-16
 		thisPaymentMeans := PaymentMeans{
@@ -360,25 +447,27 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 	}
 
 	for allowanceCharge := range applicableHeaderTradeSettlement.Each("ram:SpecifiedTradeAllowanceCharge") {
-		basisAmount, err := getDecimal(allowanceCharge, "ram:BasisAmount")
+		chargeIndicator := allowanceCharge.Eval("string(ram:ChargeIndicator/udt:Indicator) = 'true'").Bool()
+
+		basisAmount, err := getDecimal(allowanceCharge, "ram:BasisAmount", allowanceChargeField(chargeIndicator, "BT-93", "BT-100"))
 		if err != nil {
 			return err
 		}
-		actualAmount, err := getDecimal(allowanceCharge, "ram:ActualAmount")
+		actualAmount, err := getDecimal(allowanceCharge, "ram:ActualAmount", allowanceChargeField(chargeIndicator, "BT-92", "BT-99"))
 		if err != nil {
 			return err
 		}
-		calculationPercent, err := getDecimal(allowanceCharge, "ram:CalculationPercent")
+		calculationPercent, err := getDecimal(allowanceCharge, "ram:CalculationPercent", allowanceChargeField(chargeIndicator, "BT-94", "BT-101"))
 		if err != nil {
 			return err
 		}
-		categoryTaxRate, err := getDecimal(allowanceCharge, "ram:CategoryTradeTax/ram:RateApplicablePercent")
+		categoryTaxRate, err := getDecimal(allowanceCharge, "ram:CategoryTradeTax/ram:RateApplicablePercent", allowanceChargeField(chargeIndicator, "BT-96", "BT-103"))
 		if err != nil {
 			return err
 		}
 
 		allowanceCharge := AllowanceCharge{
-			ChargeIndicator:                       allowanceCharge.Eval("string(ram:ChargeIndicator/udt:Indicator) = 'true'").Bool(),
+			ChargeIndicator:                       chargeIndicator,
 			BasisAmount:                           basisAmount,
 			ActualAmount:                          actualAmount,
 			CalculationPercent:                    calculationPercent,
@@ -394,17 +483,18 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 	// Parse SpecifiedLogisticsServiceCharge and convert to document-level charges
 	// Per EN 16931, logistics service charges are document-level charges (BT-99)
 	for logisticsCharge := range applicableHeaderTradeSettlement.Each("ram:SpecifiedLogisticsServiceCharge") {
-		appliedAmount, err := getDecimal(logisticsCharge, "ram:AppliedAmount")
+		appliedAmount, err := getDecimal(logisticsCharge, "ram:AppliedAmount", "BT-99")
 		if err != nil {
 			return err
 		}
-		categoryTaxRate, err := getDecimal(logisticsCharge, "ram:AppliedTradeTax/ram:RateApplicablePercent")
+		categoryTaxRate, err := getDecimal(logisticsCharge, "ram:AppliedTradeTax/ram:RateApplicablePercent", "BT-103")
 		if err != nil {
 			return err
 		}
 
 		charge := AllowanceCharge{
 			ChargeIndicator:                       true, // Logistics charges are always charges, not allowances
+			LogisticsServiceCharge:                true,
 			ActualAmount:                          appliedAmount,
 			Reason:                                logisticsCharge.Eval("ram:Description").String(),
 			CategoryTradeTaxType:                  logisticsCharge.Eval("ram:AppliedTradeTax/ram:TypeCode").String(),
@@ -416,11 +506,11 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 
 	// BR-CO-19: Track BG-14 (INVOICING PERIOD) presence to validate later
 	inv.hasBillingPeriodInXML = applicableHeaderTradeSettlement.Eval("count(ram:BillingSpecifiedPeriod)").Int() > 0
-	inv.BillingSpecifiedPeriodStart, err = parseCIITime(applicableHeaderTradeSettlement, "ram:BillingSpecifiedPeriod/ram:StartDateTime/udt:DateTimeString")
+	inv.BillingSpecifiedPeriodStart, err = parseCIITime(applicableHeaderTradeSettlement, "ram:BillingSpecifiedPeriod/ram:StartDateTime/udt:DateTimeString", "BT-73")
 	if err != nil {
 		return fmt.Errorf("invalid billing period start date: %w", err)
 	}
-	inv.BillingSpecifiedPeriodEnd, err = parseCIITime(applicableHeaderTradeSettlement, "ram:BillingSpecifiedPeriod/ram:EndDateTime/udt:DateTimeString")
+	inv.BillingSpecifiedPeriodEnd, err = parseCIITime(applicableHeaderTradeSettlement, "ram:BillingSpecifiedPeriod/ram:EndDateTime/udt:DateTimeString", "BT-74")
 	if err != nil {
 		return fmt.Errorf("invalid billing period end date: %w", err)
 	}
@@ -429,22 +519,33 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 	for paymentTerm := range applicableHeaderTradeSettlement.Each("ram:SpecifiedTradePaymentTerms") {
 		spt := SpecifiedTradePaymentTerms{}
 		spt.Description = paymentTerm.Eval("ram:Description").String()
-		spt.DueDate, err = parseCIITime(paymentTerm, "ram:DueDateDateTime/udt:DateTimeString")
+		spt.DueDate, err = parseCIITime(paymentTerm, "ram:DueDateDateTime/udt:DateTimeString", "BT-9")
 		if err != nil {
 			return err
 		}
 
 		spt.DirectDebitMandateID = paymentTerm.Eval("ram:DirectDebitMandateID").String()
+		// EXTENDED: partial payment, penalty and discount terms
+		spt.PartialPaymentAmount, err = getDecimal(paymentTerm, "ram:PartialPaymentAmount", "")
+		if err != nil {
+			return err
+		}
+		if spt.PenaltyTerms, err = parseCIIPaymentAdjustmentTerms(paymentTerm, "ram:ApplicableTradePaymentPenaltyTerms", "ram:ActualPenaltyAmount"); err != nil {
+			return err
+		}
+		if spt.DiscountTerms, err = parseCIIPaymentAdjustmentTerms(paymentTerm, "ram:ApplicableTradePaymentDiscountTerms", "ram:ActualDiscountAmount"); err != nil {
+			return err
+		}
 		inv.SpecifiedTradePaymentTerms = append(inv.SpecifiedTradePaymentTerms, spt)
 	}
 
 	for att := range applicableHeaderTradeSettlement.Each("ram:ApplicableTradeTax") {
 		tradeTax := TradeTax{}
-		tradeTax.CalculatedAmount, err = getDecimal(att, "ram:CalculatedAmount")
+		tradeTax.CalculatedAmount, err = getDecimal(att, "ram:CalculatedAmount", "BT-117")
 		if err != nil {
 			return err
 		}
-		tradeTax.BasisAmount, err = getDecimal(att, "ram:BasisAmount")
+		tradeTax.BasisAmount, err = getDecimal(att, "ram:BasisAmount", "BT-116")
 		if err != nil {
 			return err
 		}
@@ -452,7 +553,7 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 		tradeTax.ExemptionReason = att.Eval("ram:ExemptionReason").String()
 		tradeTax.ExemptionReasonCode = att.Eval("ram:ExemptionReasonCode").String()
 		tradeTax.CategoryCode = att.Eval("ram:CategoryCode").String()
-		tradeTax.Percent, err = getDecimal(att, "ram:RateApplicablePercent") // BT-119
+		tradeTax.Percent, err = getDecimal(att, "ram:RateApplicablePercent", "BT-119") // BT-119
 		if err != nil {
 			return err
 		}
@@ -468,19 +569,19 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 	inv.hasGrandTotalInXML = summation.Eval("count(ram:GrandTotalAmount)").Int() > 0
 	inv.hasDuePayableAmountInXML = summation.Eval("count(ram:DuePayableAmount)").Int() > 0
 
-	inv.LineTotal, err = getDecimal(summation, "ram:LineTotalAmount")
+	inv.LineTotal, err = getDecimal(summation, "ram:LineTotalAmount", "BT-106")
 	if err != nil {
 		return err
 	}
-	inv.ChargeTotal, err = getDecimal(summation, "ram:ChargeTotalAmount")
+	inv.ChargeTotal, err = getDecimal(summation, "ram:ChargeTotalAmount", "BT-108")
 	if err != nil {
 		return err
 	}
-	inv.AllowanceTotal, err = getDecimal(summation, "ram:AllowanceTotalAmount")
+	inv.AllowanceTotal, err = getDecimal(summation, "ram:AllowanceTotalAmount", "BT-107")
 	if err != nil {
 		return err
 	}
-	inv.TaxBasisTotal, err = getDecimal(summation, "ram:TaxBasisTotalAmount")
+	inv.TaxBasisTotal, err = getDecimal(summation, "ram:TaxBasisTotalAmount", "BT-109")
 	if err != nil {
 		return err
 	}
@@ -489,7 +590,7 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 	// EN 16931 specifies which currency each total must be in, regardless of XML order
 	for taxTotal := range summation.Each("ram:TaxTotalAmount") {
 		currency := taxTotal.Eval("@currencyID").String()
-		amount, err := getDecimal(taxTotal, ".")
+		amount, err := getDecimal(taxTotal, ".", taxTotalField(inv, currency))
 		if err != nil {
 			return fmt.Errorf("invalid TaxTotalAmount with currency %s: %w", currency, err)
 		}
@@ -509,15 +610,19 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 		}
 	}
 
-	inv.GrandTotal, err = getDecimal(summation, "ram:GrandTotalAmount")
+	inv.RoundingAmount, err = getDecimal(summation, "ram:RoundingAmount", "BT-114")
+	if err != nil {
+		return err
+	}
+	inv.GrandTotal, err = getDecimal(summation, "ram:GrandTotalAmount", "BT-112")
 	if err != nil {
 		return err
 	}
-	inv.TotalPrepaid, err = getDecimal(summation, "ram:TotalPrepaidAmount")
+	inv.TotalPrepaid, err = getDecimal(summation, "ram:TotalPrepaidAmount", "BT-113")
 	if err != nil {
 		return err
 	}
-	inv.DuePayableAmount, err = getDecimal(summation, "ram:DuePayableAmount")
+	inv.DuePayableAmount, err = getDecimal(summation, "ram:DuePayableAmount", "BT-115")
 	if err != nil {
 		return err
 	}
@@ -526,7 +631,7 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 	for refdoc := range applicableHeaderTradeSettlement.Each("ram:InvoiceReferencedDocument") {
 		refDoc := ReferencedDocument{}
 
-		refDoc.Date, err = parseCIITime(refdoc, "ram:FormattedIssueDateTime/qdt:DateTimeString")
+		refDoc.Date, err = parseCIITime(refdoc, "ram:FormattedIssueDateTime/qdt:DateTimeString", "BT-26")
 		if err != nil {
 			return err
 		}
@@ -538,9 +643,70 @@ func parseCIIApplicableHeaderTradeSettlement(applicableHeaderTradeSettlement *cx
 	// BT-19: Buyer accounting reference
 	inv.ReceivableSpecifiedTradeAccountingAccount = applicableHeaderTradeSettlement.Eval("ram:ReceivableSpecifiedTradeAccountingAccount/ram:ID").String()
 
+	// EXTENDED: prepayments deducted in a final invoice
+	for advancePayment := range applicableHeaderTradeSettlement.Each("ram:SpecifiedAdvancePayment") {
+		ap := AdvancePayment{}
+		if ap.PaidAmount, err = getDecimal(advancePayment, "ram:PaidAmount", ""); err != nil {
+			return err
+		}
+		if ap.ReceivedDate, err = parseCIITime(advancePayment, "ram:FormattedReceivedDateTime/qdt:DateTimeString", ""); err != nil {
+			return err
+		}
+		for itt := range advancePayment.Each("ram:IncludedTradeTax") {
+			tradeTax := TradeTax{}
+			if tradeTax.CalculatedAmount, err = getDecimal(itt, "ram:CalculatedAmount", ""); err != nil {
+				return err
+			}
+			if tradeTax.BasisAmount, err = getDecimal(itt, "ram:BasisAmount", ""); err != nil {
+				return err
+			}
+			tradeTax.TypeCode = itt.Eval("ram:TypeCode").String()
+			tradeTax.ExemptionReason = itt.Eval("ram:ExemptionReason").String()
+			tradeTax.ExemptionReasonCode = itt.Eval("ram:ExemptionReasonCode").String()
+			tradeTax.CategoryCode = itt.Eval("ram:CategoryCode").String()
+			if tradeTax.Percent, err = getDecimal(itt, "ram:RateApplicablePercent", ""); err != nil {
+				return err
+			}
+			ap.TradeTaxes = append(ap.TradeTaxes, tradeTax)
+		}
+		ap.InvoiceID = advancePayment.Eval("ram:InvoiceSpecifiedReferencedDocument/ram:IssuerAssignedID").String()
+		if ap.InvoiceDate, err = parseCIITime(advancePayment, "ram:InvoiceSpecifiedReferencedDocument/ram:FormattedIssueDateTime/qdt:DateTimeString", ""); err != nil {
+			return err
+		}
+		inv.AdvancePayments = append(inv.AdvancePayments, ap)
+	}
+
 	return nil
 }
 
+// parseCIIPaymentAdjustmentTerms parses the optional penalty or discount terms
+// at path. actual is the element name of the penalty or discount amount.
+func parseCIIPaymentAdjustmentTerms(paymentTerm *cxpath.Context, path, actual string) (*PaymentAdjustmentTerms, error) {
+	if paymentTerm.Eval("count("+path+")").Int() == 0 {
+		return nil, nil
+	}
+	terms := paymentTerm.Eval(path)
+	var pat PaymentAdjustmentTerms
+	var err error
+	if pat.BasisDate, err = parseCIITime(terms, "ram:BasisDateTime/udt:DateTimeString", ""); err != nil {
+		return nil, err
+	}
+	if pat.BasisPeriodMeasure, err = getDecimal(terms, "ram:BasisPeriodMeasure", ""); err != nil {
+		return nil, err
+	}
+	pat.BasisPeriodUnit = terms.Eval("ram:BasisPeriodMeasure/@unitCode").String()
+	if pat.BasisAmount, err = getDecimal(terms, "ram:BasisAmount", ""); err != nil {
+		return nil, err
+	}
+	if pat.CalculationPercent, err = getDecimal(terms, "ram:CalculationPercent", ""); err != nil {
+		return nil, err
+	}
+	if pat.ActualAmount, err = getDecimal(terms, actual, ""); err != nil {
+		return nil, err
+	}
+	return &pat, nil
+}
+
 func parseSpecifiedLineTradeAgreement(specifiedLineTradeAgreement *cxpath.Context, invoiceLine *InvoiceLine) error {
 	var err error
 
@@ -549,36 +715,36 @@ func parseSpecifiedLineTradeAgreement(specifiedLineTradeAgreement *cxpath.Contex
 
 	// BR-26: Track XML element presence to validate later
 	invoiceLine.hasNetPriceInXML = specifiedLineTradeAgreement.Eval("count(ram:NetPriceProductTradePrice/ram:ChargeAmount)").Int() > 0
-	invoiceLine.NetPrice, err = getDecimal(specifiedLineTradeAgreement, "ram:NetPriceProductTradePrice/ram:ChargeAmount")
+	invoiceLine.NetPrice, err = getDecimal(specifiedLineTradeAgreement, "ram:NetPriceProductTradePrice/ram:ChargeAmount", "BT-146")
 	if err != nil {
 		return err
 	}
 	// BT-149: Item price base quantity with unit code (from NetPrice)
-	invoiceLine.BasisQuantity, err = getDecimal(specifiedLineTradeAgreement, "ram:NetPriceProductTradePrice/ram:BasisQuantity")
+	invoiceLine.BasisQuantity, err = getDecimal(specifiedLineTradeAgreement, "ram:NetPriceProductTradePrice/ram:BasisQuantity", "BT-149")
 	if err != nil {
 		return err
 	}
 	invoiceLine.BasisQuantityUnit = specifiedLineTradeAgreement.Eval("ram:NetPriceProductTradePrice/ram:BasisQuantity/@unitCode").String()
 
-	invoiceLine.GrossPrice, err = getDecimal(specifiedLineTradeAgreement, "ram:GrossPriceProductTradePrice/ram:ChargeAmount")
+	invoiceLine.GrossPrice, err = getDecimal(specifiedLineTradeAgreement, "ram:GrossPriceProductTradePrice/ram:ChargeAmount", "BT-148")
 	if err != nil {
 		return err
 	}
 	// ZUGFeRD extended has unbound BT-147
 	for allowanceCharge := range specifiedLineTradeAgreement.Each("ram:GrossPriceProductTradePrice/ram:AppliedTradeAllowanceCharge") {
-		basisAmount, err := getDecimal(allowanceCharge, "ram:BasisAmount")
+		basisAmount, err := getDecimal(allowanceCharge, "ram:BasisAmount", "")
 		if err != nil {
 			return err
 		}
-		actualAmount, err := getDecimal(allowanceCharge, "ram:ActualAmount")
+		actualAmount, err := getDecimal(allowanceCharge, "ram:ActualAmount", "BT-147")
 		if err != nil {
 			return err
 		}
-		calculationPercent, err := getDecimal(allowanceCharge, "ram:CalculationPercent")
+		calculationPercent, err := getDecimal(allowanceCharge, "ram:CalculationPercent", "")
 		if err != nil {
 			return err
 		}
-		categoryTaxRate, err := getDecimal(allowanceCharge, "ram:CategoryTradeTax/ram:RateApplicablePercent")
+		categoryTaxRate, err := getDecimal(allowanceCharge, "ram:CategoryTradeTax/ram:RateApplicablePercent", "")
 		if err != nil {
 			return err
 		}
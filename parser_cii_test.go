@@ -2,6 +2,7 @@ package einvoice
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -66,6 +67,22 @@ func TestInvalidDecimalValue(t *testing.T) {
 	if !strings.Contains(err.Error(), "invalid decimal value") {
 		t.Errorf("expected error message to contain 'invalid decimal value', got: %v", err)
 	}
+
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %T", err)
+	}
+	if pe.Field != "BT-106" || pe.Value != "INVALID" {
+		t.Errorf("ParseError field/value = %q/%q, want BT-106/INVALID", pe.Field, pe.Value)
+	}
+	wantPath := "/rsm:CrossIndustryInvoice/rsm:SupplyChainTradeTransaction/ram:ApplicableHeaderTradeSettlement/ram:SpecifiedTradeSettlementHeaderMonetarySummation/ram:LineTotalAmount"
+	if pe.Path != wantPath {
+		t.Errorf("ParseError path = %q, want %q", pe.Path, wantPath)
+	}
+	wantLine := strings.Count(xml[:strings.Index(xml, ">INVALID<")], "\n") + 1
+	if pe.Line != wantLine || pe.Column == 0 {
+		t.Errorf("ParseError position = %d:%d, want line %d", pe.Line, pe.Column, wantLine)
+	}
 }
 
 func TestCountrySubDivisionNameParsing(t *testing.T) {
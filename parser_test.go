@@ -0,0 +1,76 @@
+package einvoice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseReaderSentinelErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want error
+	}{
+		{"malformed", `<Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"><ID></Invoice>`, ErrMalformedXML},
+		{"empty namespace", `<Invoice><ID>1</ID></Invoice>`, ErrEmptyNamespace},
+		{"unknown namespace", `<Invoice xmlns="urn:example:invoice"/>`, ErrUnknownNamespace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseReader(strings.NewReader(tt.xml))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ParseReader() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReaderMalformedPosition(t *testing.T) {
+	_, err := ParseReader(strings.NewReader("<Invoice>\n  <ID>\n</Invoice>"))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ParseReader() error = %v, want ParseError", err)
+	}
+	if pe.Line != 3 {
+		t.Errorf("Line = %d, want 3", pe.Line)
+	}
+}
+
+func TestParseErrorPath(t *testing.T) {
+	xml := `<Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+    xmlns:cac="urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+    xmlns:cbc="urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2">
+  <cbc:ID>1</cbc:ID>
+  <cbc:IssueDate>2024-01-01</cbc:IssueDate>
+  <cbc:DocumentCurrencyCode>EUR</cbc:DocumentCurrencyCode>
+  <cac:InvoiceLine>
+    <cbc:ID>1</cbc:ID>
+    <cbc:InvoicedQuantity unitCode="C62">1</cbc:InvoicedQuantity>
+    <cbc:LineExtensionAmount currencyID="EUR">10.00</cbc:LineExtensionAmount>
+  </cac:InvoiceLine>
+  <cac:InvoiceLine>
+    <cbc:ID>2</cbc:ID>
+    <cbc:InvoicedQuantity unitCode="C62">1,5</cbc:InvoicedQuantity>
+    <cbc:LineExtensionAmount currencyID="EUR">10.00</cbc:LineExtensionAmount>
+  </cac:InvoiceLine>
+</Invoice>`
+
+	_, err := ParseReader(strings.NewReader(xml))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ParseReader() error = %v, want ParseError", err)
+	}
+	if pe.Field != "BT-129" || pe.Value != "1,5" {
+		t.Errorf("Field/Value = %q/%q, want BT-129/1,5", pe.Field, pe.Value)
+	}
+	if want := "/Invoice/cac:InvoiceLine[2]/cbc:InvoicedQuantity"; pe.Path != want {
+		t.Errorf("Path = %q, want %q", pe.Path, want)
+	}
+	if pe.Line != 14 {
+		t.Errorf("Line = %d, want 14", pe.Line)
+	}
+	if !strings.Contains(pe.Error(), "BT-129 at /Invoice/cac:InvoiceLine[2]/cbc:InvoicedQuantity (line 14") {
+		t.Errorf("Error() = %q", pe.Error())
+	}
+}
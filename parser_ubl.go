@@ -17,7 +17,8 @@ const (
 )
 
 // parseTimeUBL parses ISO 8601 date format (YYYY-MM-DD) used in UBL documents.
-func parseTimeUBL(ctx *cxpath.Context, path string) (time.Time, error) {
+// field is the business term of the date, used in the ParseError.
+func parseTimeUBL(ctx *cxpath.Context, path, field string) (time.Time, error) {
 	timestring := ctx.Eval(path).String()
 	if timestring == "" {
 		return time.Time{}, nil
@@ -25,7 +26,7 @@ func parseTimeUBL(ctx *cxpath.Context, path string) (time.Time, error) {
 
 	parsedDate, err := time.Parse("2006-01-02", timestring)
 	if err != nil {
-		return parsedDate, fmt.Errorf("invalid date %q at %s: %w", timestring, path, err)
+		return parsedDate, newParseError(ctx, path, field, timestring, fmt.Errorf("invalid date %q: %w", timestring, err))
 	}
 
 	return parsedDate, nil
@@ -67,6 +68,10 @@ func parseUBL(ctx *cxpath.Context) (*Invoice, error) {
 		return nil, fmt.Errorf("parse UBL allowances/charges: %w", err)
 	}
 
+	if err := parseUBLTaxExchangeRate(root, inv); err != nil {
+		return nil, fmt.Errorf("parse UBL tax exchange rate: %w", err)
+	}
+
 	if err := parseUBLTaxTotal(root, inv, prefix); err != nil {
 		return nil, fmt.Errorf("parse UBL tax total: %w", err)
 	}
@@ -114,13 +119,13 @@ func parseUBLHeader(root *cxpath.Context, inv *Invoice, prefix string) error {
 
 	// BT-2: Invoice date
 	var err error
-	inv.InvoiceDate, err = parseTimeUBL(root, "cbc:IssueDate")
+	inv.InvoiceDate, err = parseTimeUBL(root, "cbc:IssueDate", "BT-2")
 	if err != nil {
 		return err
 	}
 
 	// BT-72: Actual delivery date (optional, in cac:Delivery)
-	inv.OccurrenceDateTime, err = parseTimeUBL(root, "cac:Delivery/cbc:ActualDeliveryDate")
+	inv.OccurrenceDateTime, err = parseTimeUBL(root, "cac:Delivery/cbc:ActualDeliveryDate", "BT-72")
 	if err != nil {
 		return fmt.Errorf("invalid occurrence date time: %w", err)
 	}
@@ -176,7 +181,7 @@ func parseUBLHeader(root *cxpath.Context, inv *Invoice, prefix string) error {
 				ID: ref.Eval("cbc:ID").String(),
 			}
 
-			refDoc.Date, err = parseTimeUBL(ref, "cbc:IssueDate")
+			refDoc.Date, err = parseTimeUBL(ref, "cbc:IssueDate", "BT-26")
 			if err != nil {
 				return fmt.Errorf("invalid referenced document date: %w", err)
 			}
@@ -189,11 +194,11 @@ func parseUBLHeader(root *cxpath.Context, inv *Invoice, prefix string) error {
 	// BR-CO-19: Track BG-14 (INVOICING PERIOD) presence to validate later
 	if root.Eval("count(cac:InvoicePeriod)").Int() > 0 {
 		inv.hasBillingPeriodInXML = true
-		inv.BillingSpecifiedPeriodStart, err = parseTimeUBL(root, "cac:InvoicePeriod/cbc:StartDate")
+		inv.BillingSpecifiedPeriodStart, err = parseTimeUBL(root, "cac:InvoicePeriod/cbc:StartDate", "BT-73")
 		if err != nil {
 			return fmt.Errorf("invalid billing period start date: %w", err)
 		}
-		inv.BillingSpecifiedPeriodEnd, err = parseTimeUBL(root, "cac:InvoicePeriod/cbc:EndDate")
+		inv.BillingSpecifiedPeriodEnd, err = parseTimeUBL(root, "cac:InvoicePeriod/cbc:EndDate", "BT-74")
 		if err != nil {
 			return fmt.Errorf("invalid billing period end date: %w", err)
 		}
@@ -379,22 +384,22 @@ func parseUBLAllowanceCharge(root *cxpath.Context, inv *Invoice, prefix string)
 		for ac := range root.Each("cac:AllowanceCharge") {
 			chargeIndicator := ac.Eval("string(cbc:ChargeIndicator) = 'true'").Bool()
 
-			basisAmount, err := getDecimal(ac, "cbc:BaseAmount")
+			basisAmount, err := getDecimal(ac, "cbc:BaseAmount", allowanceChargeField(chargeIndicator, "BT-93", "BT-100"))
 			if err != nil {
 				return err
 			}
 
-			actualAmount, err := getDecimal(ac, "cbc:Amount")
+			actualAmount, err := getDecimal(ac, "cbc:Amount", allowanceChargeField(chargeIndicator, "BT-92", "BT-99"))
 			if err != nil {
 				return err
 			}
 
-			calculationPercent, err := getDecimal(ac, "cbc:MultiplierFactorNumeric")
+			calculationPercent, err := getDecimal(ac, "cbc:MultiplierFactorNumeric", allowanceChargeField(chargeIndicator, "BT-94", "BT-101"))
 			if err != nil {
 				return err
 			}
 
-			categoryTaxRate, err := getDecimal(ac, "cac:TaxCategory/cbc:Percent")
+			categoryTaxRate, err := getDecimal(ac, "cac:TaxCategory/cbc:Percent", allowanceChargeField(chargeIndicator, "BT-96", "BT-103"))
 			if err != nil {
 				return err
 			}
@@ -418,6 +423,29 @@ func parseUBLAllowanceCharge(root *cxpath.Context, inv *Invoice, prefix string)
 	return nil
 }
 
+// parseUBLTaxExchangeRate parses the exchange rate from the invoice currency
+// to the VAT accounting currency (cac:TaxExchangeRate).
+func parseUBLTaxExchangeRate(root *cxpath.Context, inv *Invoice) error {
+	if root.Eval("count(cac:TaxExchangeRate)").Int() == 0 {
+		return nil
+	}
+	exchange := root.Eval("cac:TaxExchangeRate")
+	ce := CurrencyExchange{
+		SourceCurrencyCode: exchange.Eval("cbc:SourceCurrencyCode").String(),
+		TargetCurrencyCode: exchange.Eval("cbc:TargetCurrencyCode").String(),
+	}
+	var err error
+	if ce.ConversionRate, err = getDecimal(exchange, "cbc:CalculationRate", ""); err != nil {
+		return err
+	}
+	ce.MathematicOperatorCode = exchange.Eval("cbc:MathematicOperatorCode").String()
+	if ce.ConversionRateDate, err = parseTimeUBL(exchange, "cbc:Date", ""); err != nil {
+		return err
+	}
+	inv.TaxCurrencyExchange = &ce
+	return nil
+}
+
 // parseUBLTaxTotal parses the tax breakdown (BG-23).
 func parseUBLTaxTotal(root *cxpath.Context, inv *Invoice, prefix string) error {
 	var err error
@@ -430,7 +458,7 @@ func parseUBLTaxTotal(root *cxpath.Context, inv *Invoice, prefix string) error {
 			currency = inv.InvoiceCurrencyCode // Default if missing
 		}
 
-		amount, err := getDecimal(taxTotal, "cbc:TaxAmount")
+		amount, err := getDecimal(taxTotal, "cbc:TaxAmount", taxTotalField(inv, currency))
 		if err != nil {
 			return fmt.Errorf("invalid TaxAmount with currency %s: %w", currency, err)
 		}
@@ -457,12 +485,12 @@ func parseUBLTaxTotal(root *cxpath.Context, inv *Invoice, prefix string) error {
 		for subtotal := range root.Each("cac:TaxTotal/cac:TaxSubtotal") {
 			tradeTax := TradeTax{}
 
-			tradeTax.BasisAmount, err = getDecimal(subtotal, "cbc:TaxableAmount")
+			tradeTax.BasisAmount, err = getDecimal(subtotal, "cbc:TaxableAmount", "BT-116")
 			if err != nil {
 				return err
 			}
 
-			tradeTax.CalculatedAmount, err = getDecimal(subtotal, "cbc:TaxAmount")
+			tradeTax.CalculatedAmount, err = getDecimal(subtotal, "cbc:TaxAmount", "BT-117")
 			if err != nil {
 				return err
 			}
@@ -474,7 +502,7 @@ func parseUBLTaxTotal(root *cxpath.Context, inv *Invoice, prefix string) error {
 
 			tradeTax.CategoryCode = subtotal.Eval("cac:TaxCategory/cbc:ID").String()
 
-			tradeTax.Percent, err = getDecimal(subtotal, "cac:TaxCategory/cbc:Percent")
+			tradeTax.Percent, err = getDecimal(subtotal, "cac:TaxCategory/cbc:Percent", "BT-119")
 			if err != nil {
 				return err
 			}
@@ -502,49 +530,49 @@ func parseUBLMonetarySummation(root *cxpath.Context, inv *Invoice, prefix string
 	var err error
 
 	// BT-106: Sum of Invoice line net amount
-	inv.LineTotal, err = getDecimal(legalMonetaryTotal, "cbc:LineExtensionAmount")
+	inv.LineTotal, err = getDecimal(legalMonetaryTotal, "cbc:LineExtensionAmount", "BT-106")
 	if err != nil {
 		return err
 	}
 
 	// BT-107: Sum of allowances on document level
-	inv.AllowanceTotal, err = getDecimal(legalMonetaryTotal, "cbc:AllowanceTotalAmount")
+	inv.AllowanceTotal, err = getDecimal(legalMonetaryTotal, "cbc:AllowanceTotalAmount", "BT-107")
 	if err != nil {
 		return err
 	}
 
 	// BT-108: Sum of charges on document level
-	inv.ChargeTotal, err = getDecimal(legalMonetaryTotal, "cbc:ChargeTotalAmount")
+	inv.ChargeTotal, err = getDecimal(legalMonetaryTotal, "cbc:ChargeTotalAmount", "BT-108")
 	if err != nil {
 		return err
 	}
 
 	// BT-109: Invoice total amount without VAT
-	inv.TaxBasisTotal, err = getDecimal(legalMonetaryTotal, "cbc:TaxExclusiveAmount")
+	inv.TaxBasisTotal, err = getDecimal(legalMonetaryTotal, "cbc:TaxExclusiveAmount", "BT-109")
 	if err != nil {
 		return err
 	}
 
 	// BT-112: Invoice total amount with VAT
-	inv.GrandTotal, err = getDecimal(legalMonetaryTotal, "cbc:TaxInclusiveAmount")
+	inv.GrandTotal, err = getDecimal(legalMonetaryTotal, "cbc:TaxInclusiveAmount", "BT-112")
 	if err != nil {
 		return err
 	}
 
 	// BT-113: Paid amount
-	inv.TotalPrepaid, err = getDecimal(legalMonetaryTotal, "cbc:PrepaidAmount")
+	inv.TotalPrepaid, err = getDecimal(legalMonetaryTotal, "cbc:PrepaidAmount", "BT-113")
 	if err != nil {
 		return err
 	}
 
 	// BT-114: Rounding amount
-	inv.RoundingAmount, err = getDecimal(legalMonetaryTotal, "cbc:PayableRoundingAmount")
+	inv.RoundingAmount, err = getDecimal(legalMonetaryTotal, "cbc:PayableRoundingAmount", "BT-114")
 	if err != nil {
 		return err
 	}
 
 	// BT-115: Amount due for payment
-	inv.DuePayableAmount, err = getDecimal(legalMonetaryTotal, "cbc:PayableAmount")
+	inv.DuePayableAmount, err = getDecimal(legalMonetaryTotal, "cbc:PayableAmount", "BT-115")
 	if err != nil {
 		return err
 	}
@@ -603,7 +631,7 @@ func parseUBLPaymentMeans(root *cxpath.Context, inv *Invoice, prefix string) err
 func parseUBLPaymentTerms(root *cxpath.Context, inv *Invoice, prefix string) error {
 	// BT-9: Payment due date at invoice level
 	// In UBL, DueDate is at the root Invoice/CreditNote level, not inside PaymentTerms
-	rootDueDate, err := parseTimeUBL(root, "cbc:DueDate")
+	rootDueDate, err := parseTimeUBL(root, "cbc:DueDate", "BT-9")
 	if err != nil {
 		return err
 	}
@@ -617,7 +645,7 @@ func parseUBLPaymentTerms(root *cxpath.Context, inv *Invoice, prefix string) err
 			}
 
 			// BT-9: Payment due date (prefer element-level DueDate if present)
-			paymentTerm.DueDate, err = parseTimeUBL(pt, "cbc:PaymentDueDate")
+			paymentTerm.DueDate, err = parseTimeUBL(pt, "cbc:PaymentDueDate", "BT-9")
 			if err != nil {
 				return err
 			}
@@ -671,11 +699,11 @@ func parseUBLLines(root *cxpath.Context, inv *Invoice, prefix string) error {
 		// BR-CO-20: Track BG-26 (INVOICE LINE PERIOD) presence to validate later
 		if lineItem.Eval("count(cac:InvoicePeriod)").Int() > 0 {
 			invoiceLine.linePeriodPresent = true
-			invoiceLine.BillingSpecifiedPeriodStart, err = parseTimeUBL(lineItem, "cac:InvoicePeriod/cbc:StartDate")
+			invoiceLine.BillingSpecifiedPeriodStart, err = parseTimeUBL(lineItem, "cac:InvoicePeriod/cbc:StartDate", "BT-134")
 			if err != nil {
 				return fmt.Errorf("invalid line billing period start date for line %s: %w", invoiceLine.LineID, err)
 			}
-			invoiceLine.BillingSpecifiedPeriodEnd, err = parseTimeUBL(lineItem, "cac:InvoicePeriod/cbc:EndDate")
+			invoiceLine.BillingSpecifiedPeriodEnd, err = parseTimeUBL(lineItem, "cac:InvoicePeriod/cbc:EndDate", "BT-135")
 			if err != nil {
 				return fmt.Errorf("invalid line billing period end date for line %s: %w", invoiceLine.LineID, err)
 			}
@@ -692,7 +720,7 @@ func parseUBLLines(root *cxpath.Context, inv *Invoice, prefix string) error {
 		invoiceLine.ReceivableSpecifiedTradeAccountingAccount = lineItem.Eval("cbc:AccountingCost").String()
 
 		// BT-129: Invoiced quantity (or Credited quantity for credit notes)
-		invoiceLine.BilledQuantity, err = getDecimal(lineItem, quantityElementName)
+		invoiceLine.BilledQuantity, err = getDecimal(lineItem, quantityElementName, "BT-129")
 		if err != nil {
 			return err
 		}
@@ -703,7 +731,7 @@ func parseUBLLines(root *cxpath.Context, inv *Invoice, prefix string) error {
 		// BT-131: Invoice line net amount
 		// Track XML element presence for BR-24 validation
 		invoiceLine.hasLineTotalInXML = lineItem.Eval("count(cbc:LineExtensionAmount)").Int() > 0
-		invoiceLine.Total, err = getDecimal(lineItem, "cbc:LineExtensionAmount")
+		invoiceLine.Total, err = getDecimal(lineItem, "cbc:LineExtensionAmount", "BT-131")
 		if err != nil {
 			return err
 		}
@@ -728,17 +756,17 @@ func parseUBLLines(root *cxpath.Context, inv *Invoice, prefix string) error {
 			for ac := range lineItem.Each("cac:AllowanceCharge") {
 				chargeIndicator := ac.Eval("string(cbc:ChargeIndicator) = 'true'").Bool()
 
-				basisAmount, err := getDecimal(ac, "cbc:BaseAmount")
+				basisAmount, err := getDecimal(ac, "cbc:BaseAmount", allowanceChargeField(chargeIndicator, "BT-137", "BT-142"))
 				if err != nil {
 					return err
 				}
 
-				actualAmount, err := getDecimal(ac, "cbc:Amount")
+				actualAmount, err := getDecimal(ac, "cbc:Amount", allowanceChargeField(chargeIndicator, "BT-136", "BT-141"))
 				if err != nil {
 					return err
 				}
 
-				calculationPercent, err := getDecimal(ac, "cbc:MultiplierFactorNumeric")
+				calculationPercent, err := getDecimal(ac, "cbc:MultiplierFactorNumeric", allowanceChargeField(chargeIndicator, "BT-138", "BT-143"))
 				if err != nil {
 					return err
 				}
@@ -767,7 +795,7 @@ func parseUBLLines(root *cxpath.Context, inv *Invoice, prefix string) error {
 			invoiceLine.TaxTypeCode = "VAT" // Default to VAT
 		}
 		invoiceLine.TaxCategoryCode = taxInfo.Eval("cbc:ID").String()
-		invoiceLine.TaxRateApplicablePercent, err = getDecimal(taxInfo, "cbc:Percent")
+		invoiceLine.TaxRateApplicablePercent, err = getDecimal(taxInfo, "cbc:Percent", "BT-152")
 		if err != nil {
 			return err
 		}
@@ -841,13 +869,13 @@ func parseUBLLinePrice(lineItem *cxpath.Context, invoiceLine *InvoiceLine) error
 	// BT-146: Item net price
 	// Track XML element presence for BR-26 validation
 	invoiceLine.hasNetPriceInXML = price.Eval("count(cbc:PriceAmount)").Int() > 0
-	invoiceLine.NetPrice, err = getDecimal(price, "cbc:PriceAmount")
+	invoiceLine.NetPrice, err = getDecimal(price, "cbc:PriceAmount", "BT-146")
 	if err != nil {
 		return err
 	}
 
 	// BT-149: Item price base quantity with unit code
-	invoiceLine.BasisQuantity, err = getDecimal(price, "cbc:BaseQuantity")
+	invoiceLine.BasisQuantity, err = getDecimal(price, "cbc:BaseQuantity", "BT-149")
 	if err != nil {
 		return err
 	}
@@ -862,17 +890,17 @@ func parseUBLLinePrice(lineItem *cxpath.Context, invoiceLine *InvoiceLine) error
 		for ac := range price.Each("cac:AllowanceCharge") {
 			chargeIndicator := ac.Eval("string(cbc:ChargeIndicator) = 'true'").Bool()
 
-			basisAmount, err := getDecimal(ac, "cbc:BaseAmount")
+			basisAmount, err := getDecimal(ac, "cbc:BaseAmount", "BT-148")
 			if err != nil {
 				return err
 			}
 
-			actualAmount, err := getDecimal(ac, "cbc:Amount")
+			actualAmount, err := getDecimal(ac, "cbc:Amount", "BT-147")
 			if err != nil {
 				return err
 			}
 
-			calculationPercent, err := getDecimal(ac, "cbc:MultiplierFactorNumeric")
+			calculationPercent, err := getDecimal(ac, "cbc:MultiplierFactorNumeric", "")
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,362 @@
+package einvoice
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/beevik/etree"
+	"github.com/speedata/einvoice/rules"
+	pdf "github.com/speedata/pdfdisassembler"
+)
+
+// knownInvoiceXMLNames lists the embedded XML filenames used by the common
+// hybrid invoice standards, in order of preference.
+var knownInvoiceXMLNames = []string{
+	"factur-x.xml",        // Factur-X / ZUGFeRD 2.1+
+	"ZUGFeRD-invoice.xml", // ZUGFeRD 1.0
+	"zugferd-invoice.xml", // ZUGFeRD 2.0
+	"xrechnung.xml",       // XRechnung
+}
+
+// XMP namespaces of the Factur-X / ZUGFeRD extension schema. The values
+// (DocumentType, DocumentFileName, Version, ConformanceLevel) are the same
+// in all versions, only the namespace differs.
+var facturXXMPNamespaces = []string{
+	"urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#", // Factur-X, ZUGFeRD 2.1+
+	"urn:zugferd:pdfa:CrossIndustryDocument:invoice:2p0#",  // ZUGFeRD 2.0
+	"urn:ferd:pdfa:CrossIndustryDocument:invoice:1p0#",     // ZUGFeRD 1.0
+}
+
+// XMP namespaces of the PDF/A identification and extension schemas.
+const (
+	nsXMPPDFAID       = "http://www.aiim.org/pdfa/ns/id/"
+	nsXMPPDFASchema   = "http://www.aiim.org/pdfa/ns/schema#"
+	nsXMPPDFAProperty = "http://www.aiim.org/pdfa/ns/property#"
+)
+
+var (
+	// ErrPDFNoEmbeddedFiles is returned when a PDF has no attachments at all.
+	ErrPDFNoEmbeddedFiles = errors.New("PDF contains no embedded files (not a ZUGFeRD/Factur-X invoice)")
+	// ErrPDFNoInvoice is returned when none of the attachments of a PDF is an invoice XML.
+	ErrPDFNoInvoice = errors.New("PDF contains no invoice XML attachment")
+)
+
+// PDFAttachment is a file embedded in a PDF (an entry of the catalog's
+// EmbeddedFiles name tree).
+type PDFAttachment struct {
+	Name           string // Key in the EmbeddedFiles name tree
+	Filename       string // /UF or /F of the file specification
+	Description    string // /Desc of the file specification
+	MimeType       string // /Subtype of the embedded file stream, e.g. "text/xml"
+	AFRelationship string // Data, Source, Alternative, Supplement or Unspecified
+	Data           []byte // Decoded file content
+}
+
+// PDFInfo holds the metadata of a hybrid (ZUGFeRD/Factur-X) PDF invoice.
+type PDFInfo struct {
+	Version         string          // PDF version, e.g. "1.7"
+	InvoiceFilename string          // Name of the attachment holding the invoice XML
+	Attachments     []PDFAttachment // All embedded files in name tree order
+
+	// Values from the document's XMP metadata
+	XMP                     []byte // Raw XMP packet from the catalog's /Metadata stream
+	PDFAPart                string // pdfaid:part, "3" for PDF/A-3
+	PDFAConformance         string // pdfaid:conformance, e.g. "B"
+	FacturXDocumentType     string // fx:DocumentType, usually "INVOICE"
+	FacturXDocumentFileName string // fx:DocumentFileName, e.g. "factur-x.xml"
+	FacturXVersion          string // fx:Version, e.g. "1.0"
+	FacturXConformanceLevel string // fx:ConformanceLevel, e.g. "EN 16931"
+
+	facturXNamespace string                        // namespace of the fx:* values found in the XMP
+	extensionSchemas map[string]xmpExtensionSchema // PDF/A extension schemas by namespace URI
+}
+
+// xmpExtensionSchema is a PDF/A extension schema declared in the XMP
+// metadata (pdfaExtension:schemas).
+type xmpExtensionSchema struct {
+	prefix     string
+	properties []string
+}
+
+// Attachment returns the attachment with the given name or nil.
+func (info *PDFInfo) Attachment(name string) *PDFAttachment {
+	for i := range info.Attachments {
+		if info.Attachments[i].Name == name {
+			return &info.Attachments[i]
+		}
+	}
+	return nil
+}
+
+// AdditionalAttachments returns all attachments except the invoice XML.
+func (info *PDFInfo) AdditionalAttachments() []PDFAttachment {
+	var ret []PDFAttachment
+	for _, a := range info.Attachments {
+		if a.Name != info.InvoiceFilename {
+			ret = append(ret, a)
+		}
+	}
+	return ret
+}
+
+// ReadPDF reads the attachments and XMP metadata of a PDF without parsing
+// the invoice. Attachments whose content cannot be decoded are skipped.
+//
+// ZUGFeRD/Factur-X PDFs embed the invoice XML as a PDF attachment (PDF/A-3)
+// stored in the catalog's EmbeddedFiles name tree. We use the read-only
+// pdfdisassembler parser, which walks that name tree directly without the
+// strict PDF-version validation that rejects PDF/A-3 features (e.g.
+// AFRelationship) on files declaring an older header version.
+func ReadPDF(r io.Reader) (*PDFInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	pr, err := pdf.Open(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer func() { _ = pr.Close() }()
+
+	return readPDFInfo(pr), nil
+}
+
+// readPDFInfo collects the attachments and XMP metadata of an opened PDF.
+func readPDFInfo(pr *pdf.Reader) *PDFInfo {
+	info := &PDFInfo{Version: pr.Version()}
+
+	for _, f := range pr.EmbeddedFiles() {
+		content, mimeType, err := readEmbeddedFile(f.Spec)
+		if err != nil {
+			continue
+		}
+		att := PDFAttachment{
+			Name:     f.Name,
+			MimeType: mimeType,
+			Data:     content,
+		}
+		for _, key := range []string{"UF", "F"} {
+			if fn, ok := f.Spec.String(key); ok && fn != "" {
+				att.Filename = fn
+				break
+			}
+		}
+		att.Description, _ = f.Spec.String("Desc")
+		if rel, ok := f.Spec.Name("AFRelationship"); ok {
+			att.AFRelationship = string(rel)
+		}
+		info.Attachments = append(info.Attachments, att)
+	}
+	info.InvoiceFilename = findInvoiceAttachment(info.Attachments)
+
+	if cat, err := pr.Catalog(); err == nil {
+		if md, ok := cat.Stream("Metadata"); ok {
+			if xmp, err := md.Content(); err == nil {
+				info.XMP = xmp
+				parseXMP(xmp, info)
+			}
+		}
+	}
+
+	return info
+}
+
+// findInvoiceAttachment returns the name of the attachment holding the
+// invoice XML: a known invoice filename first, any .xml file as fallback.
+func findInvoiceAttachment(attachments []PDFAttachment) string {
+	for _, name := range knownInvoiceXMLNames {
+		for _, a := range attachments {
+			if a.Name == name {
+				return name
+			}
+		}
+	}
+	for _, a := range attachments {
+		if strings.HasSuffix(strings.ToLower(a.Name), ".xml") {
+			return a.Name
+		}
+	}
+	return ""
+}
+
+// readEmbeddedFile returns the decoded content and MIME type of an embedded
+// file from its file specification dictionary. The embedded stream lives in
+// /EF, preferring /F (the standard file) over /UF (the Unicode file name
+// variant).
+func readEmbeddedFile(fileSpec *pdf.Dict) ([]byte, string, error) {
+	ef, ok := fileSpec.Dict("EF")
+	if !ok {
+		return nil, "", fmt.Errorf("file specification has no embedded file stream")
+	}
+	for _, key := range []string{"F", "UF"} {
+		if stream, ok := ef.Stream(key); ok {
+			data, err := stream.Content()
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to decode embedded file: %w", err)
+			}
+			subtype, _ := stream.Dict.Name("Subtype")
+			return data, string(subtype), nil
+		}
+	}
+	return nil, "", fmt.Errorf("file specification has no embedded file stream")
+}
+
+// parseXMP extracts the PDF/A identification and the Factur-X extension
+// schema values from an XMP packet. The values can be written as elements
+// or as attributes of rdf:Description, both forms are recognized.
+func parseXMP(xmp []byte, info *PDFInfo) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(xmp); err != nil {
+		return
+	}
+	set := func(ns, key, value string) {
+		value = strings.TrimSpace(value)
+		if ns == nsXMPPDFAID {
+			switch key {
+			case "part":
+				info.PDFAPart = value
+			case "conformance":
+				info.PDFAConformance = value
+			}
+			return
+		}
+		for _, fxns := range facturXXMPNamespaces {
+			if ns != fxns {
+				continue
+			}
+			info.facturXNamespace = ns
+			switch key {
+			case "DocumentType":
+				info.FacturXDocumentType = value
+			case "DocumentFileName":
+				info.FacturXDocumentFileName = value
+			case "Version":
+				info.FacturXVersion = value
+			case "ConformanceLevel":
+				info.FacturXConformanceLevel = value
+			}
+		}
+	}
+	for _, elt := range doc.FindElements("//*") {
+		if len(elt.ChildElements()) == 0 {
+			set(elt.NamespaceURI(), elt.Tag, elt.Text())
+		}
+		for _, attr := range elt.Attr {
+			set(attr.NamespaceURI(), attr.Key, attr.Value)
+		}
+	}
+
+	// Extension schemas: each schema is an rdf:li element containing
+	// pdfaSchema:namespaceURI, pdfaSchema:prefix and the pdfaProperty:name
+	// of the declared properties.
+	for _, elt := range doc.FindElements("//namespaceURI") {
+		if elt.NamespaceURI() != nsXMPPDFASchema || elt.Parent() == nil {
+			continue
+		}
+		schema := elt.Parent()
+		var ext xmpExtensionSchema
+		for _, c := range schema.FindElements(".//*") {
+			switch {
+			case c.Tag == "prefix" && c.NamespaceURI() == nsXMPPDFASchema:
+				ext.prefix = strings.TrimSpace(c.Text())
+			case c.Tag == "name" && c.NamespaceURI() == nsXMPPDFAProperty:
+				ext.properties = append(ext.properties, strings.TrimSpace(c.Text()))
+			}
+		}
+		if info.extensionSchemas == nil {
+			info.extensionSchemas = make(map[string]xmpExtensionSchema)
+		}
+		info.extensionSchemas[strings.TrimSpace(elt.Text())] = ext
+	}
+}
+
+// ParsePDF reads a ZUGFeRD/Factur-X PDF, parses the embedded invoice XML and
+// returns the invoice together with the PDF metadata. All attachments of the
+// PDF are available in PDFInfo.Attachments.
+//
+// If the XMP metadata declares a Factur-X conformance level, Validate reports
+// a warning when it does not match the specification identifier (BT-24).
+func ParsePDF(r io.Reader) (*Invoice, *PDFInfo, error) {
+	info, err := ReadPDF(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(info.Attachments) == 0 {
+		return nil, info, ErrPDFNoEmbeddedFiles
+	}
+	att := info.Attachment(info.InvoiceFilename)
+	if att == nil {
+		return nil, info, ErrPDFNoInvoice
+	}
+	inv, err := ParseReader(bytes.NewReader(att.Data))
+	if err != nil {
+		return nil, info, fmt.Errorf("parse %s: %w", att.Name, err)
+	}
+	inv.pdfConformanceLevel = info.FacturXConformanceLevel
+	return inv, info, nil
+}
+
+// ParseFile reads an invoice from an XML file or a ZUGFeRD/Factur-X PDF. The
+// format is detected from the file content, not from the file name. For XML
+// files the returned PDFInfo is nil.
+func ParseFile(filename string) (*Invoice, *PDFInfo, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("einvoice: cannot open file (%w)", err)
+	}
+	if isPDF(data) {
+		return ParsePDF(bytes.NewReader(data))
+	}
+	inv, err := ParseReader(bytes.NewReader(data))
+	return inv, nil, err
+}
+
+// isPDF reports whether data starts with a PDF header. The header may be
+// preceded by up to 1024 bytes of arbitrary data.
+func isPDF(data []byte) bool {
+	head := data[:min(len(data), 1024+5)]
+	return bytes.Contains(head, []byte("%PDF-"))
+}
+
+// facturXConformanceLevel returns the XMP conformance level expected for the
+// profile of the invoice, or an empty string for unknown profiles.
+func facturXConformanceLevel(inv *Invoice) string {
+	switch {
+	case inv.IsXRechnung():
+		return "XRECHNUNG"
+	case inv.IsExtended():
+		return "EXTENDED"
+	case inv.ProfileLevel() == levelEN16931:
+		return "EN 16931"
+	case inv.IsBasic():
+		return "BASIC"
+	case inv.IsBasicWL():
+		return "BASIC WL"
+	case inv.IsMinimum():
+		return "MINIMUM"
+	}
+	return ""
+}
+
+// validatePDFConformanceLevel compares the Factur-X conformance level of the
+// PDF's XMP metadata with the specification identifier (BT-24).
+func (inv *Invoice) validatePDFConformanceLevel() {
+	if inv.pdfConformanceLevel == "" {
+		return
+	}
+	want := facturXConformanceLevel(inv)
+	got := strings.ToUpper(inv.pdfConformanceLevel)
+	if got == "COMFORT" {
+		// ZUGFeRD 1.0 name of the EN 16931 profile
+		got = "EN 16931"
+	}
+	if want != "" && got != want {
+		inv.addWarning(rules.FACTURX_CONFORMANCE_LEVEL, fmt.Sprintf(
+			"XMP ConformanceLevel '%s' does not match specification identifier (BT-24) '%s' (expected '%s')",
+			inv.pdfConformanceLevel, inv.GuidelineSpecifiedDocumentContextParameter, want))
+	}
+}
@@ -0,0 +1,355 @@
+package einvoice
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/speedata/einvoice/rules"
+)
+
+// embeddedFile describes one attachment to embed in a synthetic test PDF.
+type embeddedFile struct {
+	name           string
+	content        string
+	afRelationship string // optional /AFRelationship of the file specification
+	mimeType       string // MIME type of the stream, defaults to text/xml
+}
+
+// testPDF describes the catalog entries of a synthetic test PDF.
+type testPDF struct {
+	files        []embeddedFile
+	nested       bool   // use an intermediate /Kids node in the name tree
+	xmp          string // XMP metadata stream, omitted when empty
+	af           bool   // list all file specifications in the catalog's /AF array
+	outputIntent bool   // add a PDF/A output intent with an ICC profile stream
+}
+
+// buildTestPDF assembles a minimal, valid PDF/A-3-style document with the
+// given files embedded in the catalog's EmbeddedFiles name tree. When nested
+// is true the name tree uses an intermediate /Kids node to exercise the
+// recursive walk in collectEmbeddedFiles. When files is empty the catalog has
+// no /Names entry at all (a plain PDF without attachments).
+func buildTestPDF(files []embeddedFile, nested bool) []byte {
+	return buildTestPDFWithXMP(files, nested, "")
+}
+
+// buildTestPDFWithXMP is buildTestPDF with an XMP metadata stream attached
+// to the catalog when xmp is not empty.
+func buildTestPDFWithXMP(files []embeddedFile, nested bool, xmp string) []byte {
+	return testPDF{files: files, nested: nested, xmp: xmp}.build()
+}
+
+// build assembles the PDF described by p.
+func (p testPDF) build() []byte {
+	files, nested, xmp := p.files, p.nested, p.xmp
+	var buf bytes.Buffer
+
+	// Object numbering:
+	//   1 Catalog, 2 Pages, then for each file a Filespec and an EmbeddedFile
+	//   stream, then optionally name-tree nodes and the metadata stream.
+	numObjs := 2 + len(files)*2
+	if len(files) > 0 {
+		numObjs++ // leaf node holding the /Names array
+		if nested {
+			numObjs++ // intermediate node holding /Kids
+		}
+	}
+	metadataObj := 0
+	if xmp != "" {
+		numObjs++
+		metadataObj = numObjs
+	}
+	iccObj := 0
+	if p.outputIntent {
+		numObjs++
+		iccObj = numObjs
+	}
+	offsets := make([]int, numObjs+1)
+
+	writeObj := func(n int, body string) {
+		offsets[n] = buf.Len()
+		buf.WriteString(strconv.Itoa(n) + " 0 obj\n" + body + "\nendobj\n")
+	}
+	writeStream := func(n int, dict string, content string) {
+		offsets[n] = buf.Len()
+		buf.WriteString(strconv.Itoa(n) + " 0 obj\n<< " + dict +
+			" /Length " + strconv.Itoa(len(content)) + " >>\nstream\n")
+		buf.WriteString(content)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	// Filespec + EmbeddedFile stream pairs start at object 3.
+	fileSpecObj := func(i int) int { return 3 + i*2 }
+	streamObj := func(i int) int { return 4 + i*2 }
+	// Name-tree objects follow the file pairs.
+	leafObj := 3 + len(files)*2
+	rootObj := leafObj // when not nested, the leaf is the tree root
+	if nested {
+		rootObj = leafObj + 1
+	}
+
+	catalogNames := ""
+	if len(files) > 0 {
+		catalogNames = fmt.Sprintf(" /Names << /EmbeddedFiles %d 0 R >>", rootObj)
+	}
+	if metadataObj > 0 {
+		catalogNames += fmt.Sprintf(" /Metadata %d 0 R", metadataObj)
+	}
+	if p.af {
+		var af bytes.Buffer
+		for i := range files {
+			fmt.Fprintf(&af, "%d 0 R ", fileSpecObj(i))
+		}
+		catalogNames += " /AF [ " + af.String() + "]"
+	}
+	if iccObj > 0 {
+		catalogNames += fmt.Sprintf(" /OutputIntents [ << /Type /OutputIntent /S /GTS_PDFA1"+
+			" /OutputConditionIdentifier (sRGB) /DestOutputProfile %d 0 R >> ]", iccObj)
+	}
+	writeObj(1, "<< /Type /Catalog /Pages 2 0 R"+catalogNames+" >>")
+	writeObj(2, "<< /Type /Pages /Kids [] /Count 0 >>")
+
+	for i, f := range files {
+		rel := ""
+		if f.afRelationship != "" {
+			rel = " /AFRelationship /" + f.afRelationship
+		}
+		writeObj(fileSpecObj(i), fmt.Sprintf(
+			"<< /Type /Filespec /F (%s) /UF (%s)%s /EF << /F %d 0 R >> >>",
+			f.name, f.name, rel, streamObj(i)))
+		mime := f.mimeType
+		if mime == "" {
+			mime = "text/xml"
+		}
+		writeStream(streamObj(i), "/Type /EmbeddedFile /Subtype /"+strings.ReplaceAll(mime, "/", "#2F"), f.content)
+	}
+
+	if len(files) > 0 {
+		var names bytes.Buffer
+		for i, f := range files {
+			fmt.Fprintf(&names, "(%s) %d 0 R ", f.name, fileSpecObj(i))
+		}
+		writeObj(leafObj, "<< /Names [ "+names.String()+"] >>")
+		if nested {
+			writeObj(rootObj, fmt.Sprintf("<< /Kids [ %d 0 R ] >>", leafObj))
+		}
+	}
+	if metadataObj > 0 {
+		writeStream(metadataObj, "/Type /Metadata /Subtype /XML", xmp)
+	}
+	if iccObj > 0 {
+		writeStream(iccObj, "/N 3", "fake ICC profile")
+	}
+
+	xrefOff := buf.Len()
+	buf.WriteString("xref\n0 " + strconv.Itoa(numObjs+1) + "\n")
+	buf.WriteString("0000000000 65535 f \n")
+	for i := 1; i <= numObjs; i++ {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offsets[i])
+	}
+	buf.WriteString("trailer\n<< /Size " + strconv.Itoa(numObjs+1) +
+		" /Root 1 0 R >>\nstartxref\n" + strconv.Itoa(xrefOff) + "\n%%EOF\n")
+	return buf.Bytes()
+}
+
+// testXMP returns an XMP packet declaring PDF/A-3B and the given Factur-X
+// conformance level, with the values written as rdf:Description attributes
+// (pdfaid) and as elements (fx), the two forms found in the wild.
+func testXMP(level string) string {
+	return `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+  <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+    <rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/" pdfaid:part="3" pdfaid:conformance="B"/>
+    <rdf:Description rdf:about="" xmlns:fx="urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#">
+      <fx:DocumentType>INVOICE</fx:DocumentType>
+      <fx:DocumentFileName>factur-x.xml</fx:DocumentFileName>
+      <fx:Version>1.0</fx:Version>
+      <fx:ConformanceLevel>` + level + `</fx:ConformanceLevel>
+    </rdf:Description>
+  </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+}
+
+func readTestPDF(t *testing.T, files []embeddedFile, nested bool) *PDFInfo {
+	t.Helper()
+	info, err := ReadPDF(bytes.NewReader(buildTestPDF(files, nested)))
+	if err != nil {
+		t.Fatalf("ReadPDF() error = %v", err)
+	}
+	return info
+}
+
+func TestReadPDF_KnownName(t *testing.T) {
+	want := `<?xml version="1.0"?><rsm:CrossIndustryInvoice>known</rsm:CrossIndustryInvoice>`
+	info := readTestPDF(t, []embeddedFile{
+		{name: "other.xml", content: "<wrong/>"},
+		{name: "factur-x.xml", content: want, afRelationship: "Alternative"},
+	}, false)
+
+	if info.InvoiceFilename != "factur-x.xml" {
+		t.Fatalf("InvoiceFilename = %q, want factur-x.xml", info.InvoiceFilename)
+	}
+	att := info.Attachment(info.InvoiceFilename)
+	if string(att.Data) != want {
+		t.Errorf("Data = %q, want %q", att.Data, want)
+	}
+	if att.AFRelationship != "Alternative" {
+		t.Errorf("AFRelationship = %q, want Alternative", att.AFRelationship)
+	}
+	if att.MimeType != "text/xml" {
+		t.Errorf("MimeType = %q, want text/xml", att.MimeType)
+	}
+	if att.Filename != "factur-x.xml" {
+		t.Errorf("Filename = %q, want factur-x.xml", att.Filename)
+	}
+	additional := info.AdditionalAttachments()
+	if len(additional) != 1 || additional[0].Name != "other.xml" {
+		t.Errorf("AdditionalAttachments() = %v, want [other.xml]", additional)
+	}
+}
+
+func TestReadPDF_NestedNameTree(t *testing.T) {
+	want := `<rsm:CrossIndustryInvoice>nested</rsm:CrossIndustryInvoice>`
+	info := readTestPDF(t, []embeddedFile{{name: "ZUGFeRD-invoice.xml", content: want}}, true)
+
+	if info.InvoiceFilename != "ZUGFeRD-invoice.xml" {
+		t.Fatalf("InvoiceFilename = %q, want ZUGFeRD-invoice.xml", info.InvoiceFilename)
+	}
+	if got := info.Attachment(info.InvoiceFilename).Data; string(got) != want {
+		t.Errorf("Data = %q, want %q", got, want)
+	}
+}
+
+func TestReadPDF_XMLFallback(t *testing.T) {
+	// No known invoice filename present; the .xml fallback must pick it up.
+	info := readTestPDF(t, []embeddedFile{
+		{name: "logo.png", content: "PNGDATA"},
+		{name: "custom-invoice.xml", content: `<Invoice>fallback</Invoice>`},
+	}, false)
+
+	if info.InvoiceFilename != "custom-invoice.xml" {
+		t.Errorf("InvoiceFilename = %q, want custom-invoice.xml", info.InvoiceFilename)
+	}
+}
+
+func TestReadPDF_XMP(t *testing.T) {
+	pdfData := buildTestPDFWithXMP([]embeddedFile{{name: "factur-x.xml", content: "<x/>"}}, false, testXMP("EN 16931"))
+	info, err := ReadPDF(bytes.NewReader(pdfData))
+	if err != nil {
+		t.Fatalf("ReadPDF() error = %v", err)
+	}
+	if info.PDFAPart != "3" || info.PDFAConformance != "B" {
+		t.Errorf("PDF/A = %q%q, want 3B", info.PDFAPart, info.PDFAConformance)
+	}
+	if info.FacturXConformanceLevel != "EN 16931" {
+		t.Errorf("FacturXConformanceLevel = %q", info.FacturXConformanceLevel)
+	}
+	if info.FacturXDocumentType != "INVOICE" || info.FacturXDocumentFileName != "factur-x.xml" || info.FacturXVersion != "1.0" {
+		t.Errorf("Factur-X XMP = %q %q %q", info.FacturXDocumentType, info.FacturXDocumentFileName, info.FacturXVersion)
+	}
+}
+
+func TestParsePDF_NoEmbeddedFiles(t *testing.T) {
+	_, _, err := ParsePDF(bytes.NewReader(buildTestPDF(nil, false)))
+	if !errors.Is(err, ErrPDFNoEmbeddedFiles) {
+		t.Fatalf("ParsePDF() error = %v, want ErrPDFNoEmbeddedFiles", err)
+	}
+}
+
+func TestParsePDF_NoXMLAttachment(t *testing.T) {
+	// An embedded file that is not XML and has no known invoice name.
+	_, info, err := ParsePDF(bytes.NewReader(buildTestPDF([]embeddedFile{{name: "logo.png", content: "PNGDATA"}}, false)))
+	if !errors.Is(err, ErrPDFNoInvoice) {
+		t.Fatalf("ParsePDF() error = %v, want ErrPDFNoInvoice", err)
+	}
+	if info == nil || len(info.Attachments) != 1 {
+		t.Errorf("ParsePDF() info = %v, want the attachment list", info)
+	}
+}
+
+func TestParsePDF_ConformanceLevel(t *testing.T) {
+	xml, err := os.ReadFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []embeddedFile{{name: "factur-x.xml", content: string(xml), afRelationship: "Data"}}
+
+	tests := []struct {
+		level       string
+		wantWarning bool
+	}{
+		{"EN 16931", false},
+		{"COMFORT", false},
+		{"", false},
+		{"BASIC", true},
+		{"EXTENDED", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			xmp := ""
+			if tt.level != "" {
+				xmp = testXMP(tt.level)
+			}
+			inv, info, err := ParsePDF(bytes.NewReader(buildTestPDFWithXMP(files, false, xmp)))
+			if err != nil {
+				t.Fatalf("ParsePDF() error = %v", err)
+			}
+			if info.InvoiceFilename != "factur-x.xml" {
+				t.Errorf("InvoiceFilename = %q", info.InvoiceFilename)
+			}
+			_ = inv.Validate()
+			got := false
+			for _, w := range inv.Warnings() {
+				if w.Rule.Code == rules.FACTURX_CONFORMANCE_LEVEL.Code {
+					got = true
+				}
+			}
+			if got != tt.wantWarning {
+				t.Errorf("conformance level warning = %v, want %v (warnings: %v)", got, tt.wantWarning, inv.Warnings())
+			}
+		})
+	}
+}
+
+func TestParseFile_DetectsFormat(t *testing.T) {
+	xml, err := os.ReadFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The file name deliberately does not reveal the format.
+	path := filepath.Join(t.TempDir(), "invoice.bin")
+	if err := os.WriteFile(path, buildTestPDF([]embeddedFile{{name: "factur-x.xml", content: string(xml)}}, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	inv, info, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile(PDF) error = %v", err)
+	}
+	if info == nil {
+		t.Error("ParseFile(PDF) info = nil, want PDF metadata")
+	}
+	if inv.InvoiceNumber == "" {
+		t.Error("ParseFile(PDF) returned invoice without number")
+	}
+
+	inv, info, err = ParseFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatalf("ParseFile(XML) error = %v", err)
+	}
+	if info != nil {
+		t.Errorf("ParseFile(XML) info = %v, want nil", info)
+	}
+	if inv.InvoiceNumber == "" {
+		t.Error("ParseFile(XML) returned invoice without number")
+	}
+}
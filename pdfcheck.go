@@ -0,0 +1,233 @@
+package einvoice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/speedata/einvoice/rules"
+	pdf "github.com/speedata/pdfdisassembler"
+)
+
+// facturXConformanceLevels lists the valid values of fx:ConformanceLevel.
+// COMFORT is the ZUGFeRD 1.0 name of the EN 16931 profile.
+var facturXConformanceLevels = []string{"MINIMUM", "BASIC WL", "BASIC", "EN 16931", "COMFORT", "EXTENDED", "XRECHNUNG"}
+
+// facturXProperties are the properties the Factur-X extension schema must declare.
+var facturXProperties = []string{"DocumentFileName", "DocumentType", "Version", "ConformanceLevel"}
+
+// CheckPDF inspects a hybrid ZUGFeRD/Factur-X PDF for conformance with the
+// PDF/A-3 and Factur-X requirements on the PDF container:
+//
+//   - FX-PDF-01, FX-PDF-02: XMP metadata with PDF/A-3 identification
+//   - FX-PDF-03 to FX-PDF-06: Factur-X extension schema and its values
+//   - FX-PDF-07: invoice file name matching the profile
+//   - FX-PDF-08, FX-PDF-09: /AF array and /AFRelationship of the invoice file
+//   - FX-PDF-10: MIME subtype text/xml of the invoice file
+//   - FX-PDF-11: PDF/A output intent
+//   - FX-PDF-12: embedded invoice XML present and parseable
+//
+// The content of the embedded invoice is not validated, use ParsePDF and
+// Validate for that. CheckPDF does not perform a full PDF/A validation
+// (fonts, color spaces, ...).
+//
+// CheckPDF returns the PDF metadata and a *ValidationError if any check
+// fails. Other errors are returned when the PDF cannot be read.
+func CheckPDF(r io.Reader) (*PDFInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	pr, err := pdf.Open(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer func() { _ = pr.Close() }()
+
+	cat, err := pr.Catalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF catalog: %w", err)
+	}
+
+	info := readPDFInfo(pr)
+	ve := &ValidationError{}
+	add := func(rule rules.Rule, format string, a ...any) {
+		ve.violations = append(ve.violations, SemanticError{Rule: rule, Text: fmt.Sprintf(format, a...)})
+	}
+
+	// Embedded invoice
+	var inv *Invoice
+	att := info.Attachment(info.InvoiceFilename)
+	if att == nil {
+		add(rules.FXPDF12, "PDF contains no invoice XML attachment")
+	} else if inv, err = ParseReader(bytes.NewReader(att.Data)); err != nil {
+		add(rules.FXPDF12, "cannot parse %s: %v", att.Name, err)
+	}
+
+	// XMP metadata
+	if info.XMP == nil {
+		add(rules.FXPDF01, "document catalog has no /Metadata stream")
+	} else {
+		checkPDFXMP(info, inv, add)
+	}
+
+	if att != nil {
+		if want := expectedInvoiceFilename(info); want != "" && att.Name != want {
+			add(rules.FXPDF07, "embedded invoice file is named '%s', expected '%s'", att.Name, want)
+		}
+		if !pdfAFReferences(pr, cat, att.Name) {
+			add(rules.FXPDF08, "document catalog /AF array does not reference '%s'", att.Name)
+		}
+		switch att.AFRelationship {
+		case "Data", "Alternative", "Source":
+		case "":
+			add(rules.FXPDF09, "file specification of '%s' has no /AFRelationship", att.Name)
+		default:
+			add(rules.FXPDF09, "file specification of '%s' has /AFRelationship /%s", att.Name, att.AFRelationship)
+		}
+		if att.MimeType != "text/xml" {
+			add(rules.FXPDF10, "embedded file '%s' has MIME subtype '%s', expected 'text/xml'", att.Name, att.MimeType)
+		}
+	}
+
+	if !pdfHasOutputIntent(pr, cat) {
+		add(rules.FXPDF11, "document catalog has no /OutputIntents entry with /S /GTS_PDFA1 and /DestOutputProfile")
+	}
+
+	if len(ve.violations) > 0 {
+		return info, ve
+	}
+	return info, nil
+}
+
+// checkPDFXMP checks the PDF/A identification and the Factur-X extension
+// schema of the XMP metadata. inv may be nil if the invoice could not be parsed.
+func checkPDFXMP(info *PDFInfo, inv *Invoice, add func(rules.Rule, string, ...any)) {
+	if info.PDFAPart != "3" || !slices.Contains([]string{"A", "B", "U"}, strings.ToUpper(info.PDFAConformance)) {
+		add(rules.FXPDF02, "XMP declares pdfaid:part '%s' and pdfaid:conformance '%s', expected PDF/A-3 (A, B or U)",
+			info.PDFAPart, info.PDFAConformance)
+	}
+
+	ns := info.facturXNamespace
+	if ns == "" {
+		ns = facturXXMPNamespaces[0]
+	}
+	wantPrefix := "fx"
+	if ns == facturXXMPNamespaces[2] {
+		// ZUGFeRD 1.0
+		wantPrefix = "zf"
+	}
+	if ext, ok := info.extensionSchemas[ns]; !ok {
+		add(rules.FXPDF03, "XMP has no PDF/A extension schema for namespace '%s'", ns)
+	} else {
+		if ext.prefix != wantPrefix {
+			add(rules.FXPDF03, "Factur-X extension schema has prefix '%s', expected '%s'", ext.prefix, wantPrefix)
+		}
+		for _, p := range facturXProperties {
+			if !slices.Contains(ext.properties, p) {
+				add(rules.FXPDF03, "Factur-X extension schema does not declare property '%s'", p)
+			}
+		}
+	}
+
+	if info.FacturXDocumentType != "INVOICE" {
+		add(rules.FXPDF04, "XMP fx:DocumentType is '%s', expected 'INVOICE'", info.FacturXDocumentType)
+	}
+	if info.FacturXVersion == "" {
+		add(rules.FXPDF04, "XMP has no fx:Version")
+	}
+
+	level := strings.ToUpper(info.FacturXConformanceLevel)
+	switch {
+	case level == "":
+		add(rules.FXPDF05, "XMP has no fx:ConformanceLevel")
+	case !slices.Contains(facturXConformanceLevels, level):
+		add(rules.FXPDF05, "XMP fx:ConformanceLevel '%s' is not a valid conformance level", info.FacturXConformanceLevel)
+	case inv != nil:
+		if level == "COMFORT" {
+			level = "EN 16931"
+		}
+		if want := facturXConformanceLevel(inv); want != "" && level != want {
+			add(rules.FXPDF05, "XMP fx:ConformanceLevel '%s' does not match specification identifier (BT-24) '%s' (expected '%s')",
+				info.FacturXConformanceLevel, inv.GuidelineSpecifiedDocumentContextParameter, want)
+		}
+	}
+
+	switch {
+	case info.FacturXDocumentFileName == "":
+		add(rules.FXPDF06, "XMP has no fx:DocumentFileName")
+	case info.InvoiceFilename != "" && info.FacturXDocumentFileName != info.InvoiceFilename:
+		add(rules.FXPDF06, "XMP fx:DocumentFileName is '%s', but the embedded invoice is '%s'",
+			info.FacturXDocumentFileName, info.InvoiceFilename)
+	}
+}
+
+// expectedInvoiceFilename returns the file name of the embedded invoice
+// required by the Factur-X/ZUGFeRD version and conformance level declared in
+// the XMP metadata. Without XMP values, factur-x.xml and xrechnung.xml are
+// both accepted.
+func expectedInvoiceFilename(info *PDFInfo) string {
+	switch info.facturXNamespace {
+	case facturXXMPNamespaces[0]:
+		if strings.EqualFold(info.FacturXConformanceLevel, "XRECHNUNG") {
+			return "xrechnung.xml"
+		}
+		return "factur-x.xml"
+	case facturXXMPNamespaces[1]:
+		return "zugferd-invoice.xml"
+	case facturXXMPNamespaces[2]:
+		return "ZUGFeRD-invoice.xml"
+	}
+	if info.InvoiceFilename == "factur-x.xml" || info.InvoiceFilename == "xrechnung.xml" {
+		return ""
+	}
+	return "factur-x.xml"
+}
+
+// pdfAFReferences reports whether the catalog's /AF array contains the file
+// specification of the embedded file with the given name.
+func pdfAFReferences(pr *pdf.Reader, cat *pdf.Dict, name string) bool {
+	af, ok := cat.Array("AF")
+	if !ok {
+		return false
+	}
+	var spec *pdf.Dict
+	for _, f := range pr.EmbeddedFiles() {
+		if f.Name == name {
+			spec = f.Spec
+			break
+		}
+	}
+	if spec == nil {
+		return false
+	}
+	for _, obj := range af {
+		if d, err := pr.ResolveDict(obj); err == nil && d == spec {
+			return true
+		}
+	}
+	return false
+}
+
+// pdfHasOutputIntent reports whether the catalog contains a PDF/A output
+// intent with an ICC destination profile.
+func pdfHasOutputIntent(pr *pdf.Reader, cat *pdf.Dict) bool {
+	intents, ok := cat.Array("OutputIntents")
+	if !ok {
+		return false
+	}
+	for _, obj := range intents {
+		oi, err := pr.ResolveDict(obj)
+		if err != nil {
+			continue
+		}
+		if s, ok := oi.Name("S"); ok && s == "GTS_PDFA1" {
+			if _, ok := oi.Stream("DestOutputProfile"); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package einvoice
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/speedata/einvoice/rules"
+)
+
+// testFacturXXMP returns complete Factur-X XMP metadata including the PDF/A
+// extension schema declaration.
+func testFacturXXMP(level, filename string) string {
+	return `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+  <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+    <rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/">
+      <pdfaid:part>3</pdfaid:part>
+      <pdfaid:conformance>B</pdfaid:conformance>
+    </rdf:Description>
+    <rdf:Description rdf:about="" xmlns:pdfaExtension="http://www.aiim.org/pdfa/ns/extension/"
+        xmlns:pdfaSchema="http://www.aiim.org/pdfa/ns/schema#" xmlns:pdfaProperty="http://www.aiim.org/pdfa/ns/property#">
+      <pdfaExtension:schemas>
+        <rdf:Bag>
+          <rdf:li rdf:parseType="Resource">
+            <pdfaSchema:schema>Factur-X PDFA Extension Schema</pdfaSchema:schema>
+            <pdfaSchema:namespaceURI>urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#</pdfaSchema:namespaceURI>
+            <pdfaSchema:prefix>fx</pdfaSchema:prefix>
+            <pdfaSchema:property>
+              <rdf:Seq>
+                <rdf:li rdf:parseType="Resource"><pdfaProperty:name>DocumentFileName</pdfaProperty:name></rdf:li>
+                <rdf:li rdf:parseType="Resource"><pdfaProperty:name>DocumentType</pdfaProperty:name></rdf:li>
+                <rdf:li rdf:parseType="Resource"><pdfaProperty:name>Version</pdfaProperty:name></rdf:li>
+                <rdf:li rdf:parseType="Resource"><pdfaProperty:name>ConformanceLevel</pdfaProperty:name></rdf:li>
+              </rdf:Seq>
+            </pdfaSchema:property>
+          </rdf:li>
+        </rdf:Bag>
+      </pdfaExtension:schemas>
+    </rdf:Description>
+    <rdf:Description rdf:about="" xmlns:fx="urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#">
+      <fx:DocumentType>INVOICE</fx:DocumentType>
+      <fx:DocumentFileName>` + filename + `</fx:DocumentFileName>
+      <fx:Version>1.0</fx:Version>
+      <fx:ConformanceLevel>` + level + `</fx:ConformanceLevel>
+    </rdf:Description>
+  </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+}
+
+// validTestFacturXPDF returns the description of a conforming Factur-X PDF
+// embedding the EN 16931 example CII_example1.xml.
+func validTestFacturXPDF(t *testing.T) testPDF {
+	t.Helper()
+	xml, err := os.ReadFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testPDF{
+		files:        []embeddedFile{{name: "factur-x.xml", content: string(xml), afRelationship: "Data"}},
+		xmp:          testFacturXXMP("EN 16931", "factur-x.xml"),
+		af:           true,
+		outputIntent: true,
+	}
+}
+
+func TestCheckPDF_Valid(t *testing.T) {
+	info, err := CheckPDF(bytes.NewReader(validTestFacturXPDF(t).build()))
+	if err != nil {
+		t.Fatalf("CheckPDF() error = %v", err)
+	}
+	if info.InvoiceFilename != "factur-x.xml" {
+		t.Errorf("InvoiceFilename = %q", info.InvoiceFilename)
+	}
+}
+
+func TestCheckPDF_Violations(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *testPDF)
+		want   rules.Rule
+	}{
+		{"no XMP", func(p *testPDF) { p.xmp = "" }, rules.FXPDF01},
+		{"PDF/A-2", func(p *testPDF) {
+			p.xmp = strings.Replace(p.xmp, "<pdfaid:part>3<", "<pdfaid:part>2<", 1)
+		}, rules.FXPDF02},
+		{"no extension schema", func(p *testPDF) {
+			p.xmp = strings.Replace(p.xmp, "<pdfaSchema:namespaceURI>urn:factur-x", "<pdfaSchema:namespaceURI>urn:other", 1)
+		}, rules.FXPDF03},
+		{"extension schema prefix", func(p *testPDF) {
+			p.xmp = strings.Replace(p.xmp, "<pdfaSchema:prefix>fx<", "<pdfaSchema:prefix>zf<", 1)
+		}, rules.FXPDF03},
+		{"extension schema property missing", func(p *testPDF) {
+			p.xmp = strings.Replace(p.xmp, "<pdfaProperty:name>Version<", "<pdfaProperty:name>Versions<", 1)
+		}, rules.FXPDF03},
+		{"document type", func(p *testPDF) {
+			p.xmp = strings.Replace(p.xmp, ">INVOICE<", ">ORDER<", 1)
+		}, rules.FXPDF04},
+		{"invalid level", func(p *testPDF) { p.xmp = testFacturXXMP("PREMIUM", "factur-x.xml") }, rules.FXPDF05},
+		{"level mismatch", func(p *testPDF) { p.xmp = testFacturXXMP("BASIC", "factur-x.xml") }, rules.FXPDF05},
+		{"document file name", func(p *testPDF) {
+			p.xmp = testFacturXXMP("EN 16931", "invoice.xml")
+		}, rules.FXPDF06},
+		{"file name", func(p *testPDF) {
+			p.files[0].name = "ZUGFeRD-invoice.xml"
+			p.xmp = testFacturXXMP("EN 16931", "ZUGFeRD-invoice.xml")
+		}, rules.FXPDF07},
+		{"xrechnung file name", func(p *testPDF) { p.xmp = testFacturXXMP("XRECHNUNG", "factur-x.xml") }, rules.FXPDF07},
+		{"no AF", func(p *testPDF) { p.af = false }, rules.FXPDF08},
+		{"no AFRelationship", func(p *testPDF) { p.files[0].afRelationship = "" }, rules.FXPDF09},
+		{"AFRelationship Supplement", func(p *testPDF) { p.files[0].afRelationship = "Supplement" }, rules.FXPDF09},
+		{"MIME type", func(p *testPDF) { p.files[0].mimeType = "application/xml" }, rules.FXPDF10},
+		{"no output intent", func(p *testPDF) { p.outputIntent = false }, rules.FXPDF11},
+		{"unparseable invoice", func(p *testPDF) { p.files[0].content = "<foo/>" }, rules.FXPDF12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validTestFacturXPDF(t)
+			tt.modify(&p)
+			_, err := CheckPDF(bytes.NewReader(p.build()))
+			var valErr *ValidationError
+			if !errors.As(err, &valErr) {
+				t.Fatalf("CheckPDF() error = %v, want ValidationError", err)
+			}
+			if !valErr.HasRule(tt.want) {
+				t.Errorf("expected %s violation, got %v", tt.want.Code, valErr.Violations())
+			}
+		})
+	}
+}
+
+func TestCheckPDF_NotAPDF(t *testing.T) {
+	_, err := CheckPDF(strings.NewReader("<Invoice/>"))
+	var valErr *ValidationError
+	if err == nil || errors.As(err, &valErr) {
+		t.Errorf("CheckPDF() error = %v, want read error", err)
+	}
+}
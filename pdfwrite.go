@@ -0,0 +1,522 @@
+package einvoice
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pdf "github.com/speedata/pdfdisassembler"
+)
+
+// ErrPDFEncrypted is returned by WritePDF for encrypted visual PDFs.
+var ErrPDFEncrypted = errors.New("encrypted PDFs are not supported")
+
+// WritePDF writes a hybrid ZUGFeRD/Factur-X PDF to w. The invoice is written
+// as CII XML and embedded into the PDF read from visual, which must be the
+// human readable representation of the invoice. The visual PDF is not
+// modified, the attachment, the /AF entry and the XMP metadata with the
+// Factur-X extension schema are appended as an incremental update.
+//
+// The file name and the XMP conformance level are derived from the
+// specification identifier (BT-24): xrechnung.xml for XRechnung, factur-x.xml
+// for all other profiles. Existing attachments of the visual PDF are kept,
+// an existing invoice file with the same name is replaced. Existing XMP
+// metadata is replaced, the values of the document information dictionary
+// are carried over.
+//
+// WritePDF does not convert the visual PDF to PDF/A-3. The result is a
+// conforming Factur-X file only if the visual PDF already is PDF/A-3 (or
+// PDF/A-1/2 with embedded fonts and an output intent). Use CheckPDF to
+// verify the result.
+func (inv *Invoice) WritePDF(w io.Writer, visual io.Reader) error {
+	if inv.SchemaType == UBL {
+		return errors.New("invoice is UBL, Factur-X PDFs require the CII syntax")
+	}
+	level := facturXConformanceLevel(inv)
+	if level == "" {
+		return fmt.Errorf("cannot determine Factur-X conformance level of specification identifier (BT-24) '%s'",
+			inv.GuidelineSpecifiedDocumentContextParameter)
+	}
+	filename := "factur-x.xml"
+	if level == "XRECHNUNG" {
+		filename = "xrechnung.xml"
+	}
+	relationship := "Alternative"
+	if level == "MINIMUM" || level == "BASIC WL" {
+		// The profiles below EN 16931 are not a legal invoice in all countries.
+		relationship = "Data"
+	}
+
+	var invoiceXML bytes.Buffer
+	if err := inv.Write(&invoiceXML); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(visual)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	pr, err := pdf.Open(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer func() { _ = pr.Close() }()
+
+	trailer := pr.Trailer()
+	if trailer.Has("Encrypt") {
+		return ErrPDFEncrypted
+	}
+	rootObj, ok := trailer.Get("Root")
+	root, isRef := rootObj.(pdf.Reference)
+	if !ok || !isRef {
+		return errors.New("PDF trailer has no /Root reference")
+	}
+	size, ok := trailer.Int("Size")
+	if !ok {
+		return errors.New("PDF trailer has no /Size")
+	}
+	prevXref, err := pdfStartXref(data)
+	if err != nil {
+		return err
+	}
+	cat, err := pr.Catalog()
+	if err != nil {
+		return fmt.Errorf("failed to read PDF catalog: %w", err)
+	}
+
+	u := pdfUpdate{buf: bytes.NewBuffer(slices.Clip(data)), offsets: map[int]int{}, gens: map[int]int{}}
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		u.buf.WriteString("\n")
+	}
+	next := int(size)
+	fileObj, specObj, namesObj, metadataObj := next, next+1, next+2, next+3
+	next += 4
+
+	// Embedded file stream and file specification
+	modDate := pdfDate(time.Now())
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, _ = zw.Write(invoiceXML.Bytes())
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	u.writeStream(fileObj, fmt.Sprintf("/Type /EmbeddedFile /Subtype /text#2Fxml /Filter /FlateDecode /Params << /ModDate %s /Size %d >>",
+		pdfString(modDate), invoiceXML.Len()), compressed.Bytes())
+	u.writeObject(specObj, fmt.Sprintf("<< /Type /Filespec /F %s /UF %s /Desc %s /AFRelationship /%s /EF << /F %d 0 R /UF %d 0 R >> >>",
+		pdfString(filename), pdfString(filename), pdfString("Factur-X invoice"), relationship, fileObj, fileObj))
+
+	// EmbeddedFiles name tree: the existing entries plus the invoice, in one
+	// leaf node. A previous invoice file with the same name is dropped.
+	type nameEntry struct {
+		key   string
+		value pdf.Object
+	}
+	var entries []nameEntry
+	var replacedSpec *pdf.Dict
+	var namesDict *pdf.Dict
+	if obj, ok := cat.Get("Names"); ok {
+		if namesDict, err = pr.ResolveDict(obj); err != nil {
+			return fmt.Errorf("failed to read /Names: %w", err)
+		}
+	}
+	if obj, ok := namesDict.Get("EmbeddedFiles"); ok {
+		err = pdfNameTreeEntries(pr, obj, 0, func(key string, value pdf.Object) {
+			if key == filename {
+				replacedSpec, _ = pr.ResolveDict(value)
+				return
+			}
+			entries = append(entries, nameEntry{key, value})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read embedded files: %w", err)
+		}
+	}
+	entries = append(entries, nameEntry{filename, pdf.Reference{Number: specObj}})
+	slices.SortFunc(entries, func(a, b nameEntry) int { return strings.Compare(a.key, b.key) })
+	var tree strings.Builder
+	tree.WriteString("<< /Names [")
+	for _, e := range entries {
+		tree.WriteString(" " + pdfString(e.key) + " ")
+		if err := writePDFObject(&tree, e.value); err != nil {
+			return err
+		}
+	}
+	tree.WriteString(" ] >>")
+	u.writeObject(namesObj, tree.String())
+
+	u.writeStream(metadataObj, "/Type /Metadata /Subtype /XML",
+		[]byte(facturXXMP(pr.DocumentInfo(), filename, level)))
+
+	// Updated catalog
+	var catalog strings.Builder
+	catalog.WriteString("<<")
+	for key, value := range cat.Iter() {
+		switch key {
+		case "Names", "AF", "Metadata":
+			continue
+		}
+		catalog.WriteString(" " + pdfName(key) + " ")
+		if err := writePDFObject(&catalog, value); err != nil {
+			return fmt.Errorf("catalog entry /%s: %w", key, err)
+		}
+	}
+	catalog.WriteString(" /Names <<")
+	for key, value := range namesDict.Iter() {
+		if key == "EmbeddedFiles" {
+			continue
+		}
+		catalog.WriteString(" " + pdfName(key) + " ")
+		if err := writePDFObject(&catalog, value); err != nil {
+			return fmt.Errorf("name dictionary entry /%s: %w", key, err)
+		}
+	}
+	fmt.Fprintf(&catalog, " /EmbeddedFiles %d 0 R >>", namesObj)
+	catalog.WriteString(" /AF [")
+	if obj, ok := cat.Get("AF"); ok {
+		af, err := pr.ResolveArray(obj)
+		if err != nil {
+			return fmt.Errorf("failed to read /AF: %w", err)
+		}
+		for _, spec := range af {
+			if d, err := pr.ResolveDict(spec); err == nil && replacedSpec != nil && d == replacedSpec {
+				continue
+			}
+			catalog.WriteString(" ")
+			if err := writePDFObject(&catalog, spec); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintf(&catalog, " %d 0 R ] /Metadata %d 0 R >>", specObj, metadataObj)
+	u.writeObjectGen(root.Number, root.Generation, catalog.String())
+
+	if err := u.writeXref(trailer, root, next, prevXref, data); err != nil {
+		return err
+	}
+	_, err = w.Write(u.buf.Bytes())
+	return err
+}
+
+// facturXXMP returns the XMP metadata of a Factur-X PDF with the PDF/A-3B
+// identification, the Factur-X extension schema and the values of the
+// document information dictionary.
+func facturXXMP(info pdf.DocInfo, filename, level string) string {
+	var b strings.Builder
+	esc := func(s string) string {
+		var e strings.Builder
+		_ = xml.EscapeText(&e, []byte(s))
+		return e.String()
+	}
+	b.WriteString(`<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:pdfaid="` + nsXMPPDFAID + `">
+<pdfaid:part>3</pdfaid:part>
+<pdfaid:conformance>B</pdfaid:conformance>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:pdf="http://ns.adobe.com/pdf/1.3/" xmlns:xmp="http://ns.adobe.com/xap/1.0/">
+`)
+	if info.Title != "" {
+		b.WriteString(`<dc:title><rdf:Alt><rdf:li xml:lang="x-default">` + esc(info.Title) + "</rdf:li></rdf:Alt></dc:title>\n")
+	}
+	if info.Author != "" {
+		b.WriteString("<dc:creator><rdf:Seq><rdf:li>" + esc(info.Author) + "</rdf:li></rdf:Seq></dc:creator>\n")
+	}
+	if info.Subject != "" {
+		b.WriteString(`<dc:description><rdf:Alt><rdf:li xml:lang="x-default">` + esc(info.Subject) + "</rdf:li></rdf:Alt></dc:description>\n")
+	}
+	if info.Keywords != "" {
+		b.WriteString("<pdf:Keywords>" + esc(info.Keywords) + "</pdf:Keywords>\n")
+	}
+	if info.Producer != "" {
+		b.WriteString("<pdf:Producer>" + esc(info.Producer) + "</pdf:Producer>\n")
+	}
+	if info.Creator != "" {
+		b.WriteString("<xmp:CreatorTool>" + esc(info.Creator) + "</xmp:CreatorTool>\n")
+	}
+	if !info.CreationDate.IsZero() {
+		b.WriteString("<xmp:CreateDate>" + info.CreationDate.Format(time.RFC3339) + "</xmp:CreateDate>\n")
+	}
+	if !info.ModDate.IsZero() {
+		b.WriteString("<xmp:ModifyDate>" + info.ModDate.Format(time.RFC3339) + "</xmp:ModifyDate>\n")
+	}
+	b.WriteString(`</rdf:Description>
+<rdf:Description rdf:about="" xmlns:pdfaExtension="http://www.aiim.org/pdfa/ns/extension/" xmlns:pdfaSchema="` + nsXMPPDFASchema + `" xmlns:pdfaProperty="` + nsXMPPDFAProperty + `">
+<pdfaExtension:schemas>
+<rdf:Bag>
+<rdf:li rdf:parseType="Resource">
+<pdfaSchema:schema>Factur-X PDFA Extension Schema</pdfaSchema:schema>
+<pdfaSchema:namespaceURI>` + facturXXMPNamespaces[0] + `</pdfaSchema:namespaceURI>
+<pdfaSchema:prefix>fx</pdfaSchema:prefix>
+<pdfaSchema:property>
+<rdf:Seq>
+`)
+	for _, p := range []struct{ name, desc string }{
+		{"DocumentFileName", "The name of the embedded XML document"},
+		{"DocumentType", "The type of the hybrid document in capital letters, e.g. INVOICE or ORDER"},
+		{"Version", "The actual version of the standard applying to the embedded XML document"},
+		{"ConformanceLevel", "The conformance level of the embedded XML document"},
+	} {
+		b.WriteString(`<rdf:li rdf:parseType="Resource">
+<pdfaProperty:name>` + p.name + `</pdfaProperty:name>
+<pdfaProperty:valueType>Text</pdfaProperty:valueType>
+<pdfaProperty:category>external</pdfaProperty:category>
+<pdfaProperty:description>` + p.desc + `</pdfaProperty:description>
+</rdf:li>
+`)
+	}
+	b.WriteString(`</rdf:Seq>
+</pdfaSchema:property>
+</rdf:li>
+</rdf:Bag>
+</pdfaExtension:schemas>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:fx="` + facturXXMPNamespaces[0] + `">
+<fx:DocumentType>INVOICE</fx:DocumentType>
+<fx:DocumentFileName>` + filename + `</fx:DocumentFileName>
+<fx:Version>1.0</fx:Version>
+<fx:ConformanceLevel>` + level + `</fx:ConformanceLevel>
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`)
+	return b.String()
+}
+
+// pdfUpdate collects the objects of an incremental update appended to a PDF.
+type pdfUpdate struct {
+	buf     *bytes.Buffer
+	offsets map[int]int // object number → byte offset
+	gens    map[int]int // object number → generation, if not 0
+}
+
+func (u *pdfUpdate) writeObject(num int, body string) {
+	u.writeObjectGen(num, 0, body)
+}
+
+func (u *pdfUpdate) writeObjectGen(num, gen int, body string) {
+	u.offsets[num] = u.buf.Len()
+	if gen != 0 {
+		u.gens[num] = gen
+	}
+	fmt.Fprintf(u.buf, "%d %d obj\n%s\nendobj\n", num, gen, body)
+}
+
+func (u *pdfUpdate) writeStream(num int, dict string, content []byte) {
+	u.offsets[num] = u.buf.Len()
+	fmt.Fprintf(u.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", num, dict, len(content))
+	u.buf.Write(content)
+	u.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// writeXref writes the cross-reference section and the trailer of the
+// update. The section uses the format of the previous section: a
+// cross-reference stream if the PDF uses one, a table otherwise.
+func (u *pdfUpdate) writeXref(trailer *pdf.Dict, root pdf.Reference, size, prev int, data []byte) error {
+	var extra strings.Builder
+	for _, key := range []string{"Info", "ID"} {
+		if obj, ok := trailer.Get(key); ok {
+			extra.WriteString(" " + pdfName(key) + " ")
+			if err := writePDFObject(&extra, obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	useStream := !bytes.HasPrefix(bytes.TrimLeft(data[prev:], " \t\r\n"), []byte("xref"))
+	xrefObj := 0
+	if useStream {
+		xrefObj = size
+		size++
+		u.offsets[xrefObj] = u.buf.Len()
+	}
+	nums := make([]int, 0, len(u.offsets))
+	for n := range u.offsets {
+		nums = append(nums, n)
+	}
+	slices.Sort(nums)
+	// Subsections of consecutive object numbers
+	var sections [][]int
+	for _, n := range nums {
+		if l := len(sections); l > 0 && sections[l-1][len(sections[l-1])-1] == n-1 {
+			sections[l-1] = append(sections[l-1], n)
+		} else {
+			sections = append(sections, []int{n})
+		}
+	}
+
+	if !useStream {
+		xrefOffset := u.buf.Len()
+		u.buf.WriteString("xref\n")
+		for _, s := range sections {
+			fmt.Fprintf(u.buf, "%d %d\n", s[0], len(s))
+			for _, n := range s {
+				fmt.Fprintf(u.buf, "%010d %05d n \n", u.offsets[n], u.gens[n])
+			}
+		}
+		fmt.Fprintf(u.buf, "trailer\n<< /Size %d /Root %d %d R /Prev %d%s >>\nstartxref\n%d\n%%%%EOF\n",
+			size, root.Number, root.Generation, prev, extra.String(), xrefOffset)
+		return nil
+	}
+
+	var index strings.Builder
+	var entries bytes.Buffer
+	for _, s := range sections {
+		fmt.Fprintf(&index, " %d %d", s[0], len(s))
+		for _, n := range s {
+			off, gen := u.offsets[n], u.gens[n]
+			entries.Write([]byte{1, byte(off >> 24), byte(off >> 16), byte(off >> 8), byte(off), byte(gen >> 8), byte(gen)})
+		}
+	}
+	u.writeStream(xrefObj, fmt.Sprintf("/Type /XRef /Size %d /W [ 1 4 2 ] /Index [%s ] /Root %d %d R /Prev %d%s",
+		size, index.String(), root.Number, root.Generation, prev, extra.String()), entries.Bytes())
+	fmt.Fprintf(u.buf, "startxref\n%d\n%%%%EOF\n", u.offsets[xrefObj])
+	return nil
+}
+
+// pdfStartXref returns the offset of the last cross-reference section.
+func pdfStartXref(data []byte) (int, error) {
+	i := bytes.LastIndex(data, []byte("startxref"))
+	if i < 0 {
+		return 0, errors.New("PDF has no startxref")
+	}
+	fields := bytes.Fields(data[i+len("startxref"):])
+	if len(fields) == 0 {
+		return 0, errors.New("PDF has no startxref offset")
+	}
+	offset, err := strconv.Atoi(string(fields[0]))
+	if err != nil || offset < 0 || offset >= len(data) {
+		return 0, fmt.Errorf("invalid startxref offset %q", fields[0])
+	}
+	return offset, nil
+}
+
+// pdfNameTreeEntries calls fn for all key/value pairs of the name tree node
+// obj and its descendants.
+func pdfNameTreeEntries(pr *pdf.Reader, obj pdf.Object, depth int, fn func(string, pdf.Object)) error {
+	if depth > 32 {
+		return errors.New("name tree too deep")
+	}
+	node, err := pr.ResolveDict(obj)
+	if err != nil {
+		return err
+	}
+	if names, ok := node.Array("Names"); ok {
+		for i := 0; i+1 < len(names); i += 2 {
+			key, err := pr.Resolve(names[i])
+			if err != nil {
+				return err
+			}
+			if s, ok := key.(pdf.String); ok {
+				fn(string(s), names[i+1])
+			}
+		}
+	}
+	if kids, ok := node.Array("Kids"); ok {
+		for _, kid := range kids {
+			if err := pdfNameTreeEntries(pr, kid, depth+1, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writePDFObject serializes a direct PDF object. Streams are always indirect
+// objects and cannot be serialized.
+func writePDFObject(b *strings.Builder, obj pdf.Object) error {
+	switch o := obj.(type) {
+	case pdf.Name:
+		b.WriteString(pdfName(string(o)))
+	case pdf.Integer:
+		b.WriteString(strconv.FormatInt(int64(o), 10))
+	case pdf.Real:
+		b.WriteString(strconv.FormatFloat(float64(o), 'f', -1, 64))
+	case pdf.Bool:
+		b.WriteString(strconv.FormatBool(bool(o)))
+	case pdf.String:
+		b.WriteString("<" + hex.EncodeToString(o) + ">")
+	case pdf.Reference:
+		fmt.Fprintf(b, "%d %d R", o.Number, o.Generation)
+	case pdf.Null:
+		b.WriteString("null")
+	case pdf.Array:
+		b.WriteString("[")
+		for _, elt := range o {
+			b.WriteString(" ")
+			if err := writePDFObject(b, elt); err != nil {
+				return err
+			}
+		}
+		b.WriteString(" ]")
+	case *pdf.Dict:
+		b.WriteString("<<")
+		for key, value := range o.Iter() {
+			b.WriteString(" " + pdfName(key) + " ")
+			if err := writePDFObject(b, value); err != nil {
+				return err
+			}
+		}
+		b.WriteString(" >>")
+	default:
+		return fmt.Errorf("cannot write PDF object of type %T", obj)
+	}
+	return nil
+}
+
+// pdfName returns the PDF name /name with delimiters and non-regular
+// characters escaped as #xx.
+func pdfName(name string) string {
+	var b strings.Builder
+	b.WriteString("/")
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < 0x21 || c > 0x7e || strings.IndexByte("#()<>[]{}/%", c) >= 0 {
+			fmt.Fprintf(&b, "#%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// pdfString returns s as a PDF literal string. Non-ASCII text is written as
+// UTF-16BE with byte order mark.
+func pdfString(s string) string {
+	for _, r := range s {
+		if r > 0x7e {
+			var u bytes.Buffer
+			u.Write([]byte{0xfe, 0xff})
+			for _, r := range s {
+				if r > 0xffff {
+					r1, r2 := 0xd800+((r-0x10000)>>10), 0xdc00+((r-0x10000)&0x3ff)
+					u.Write([]byte{byte(r1 >> 8), byte(r1), byte(r2 >> 8), byte(r2)})
+				} else {
+					u.Write([]byte{byte(r >> 8), byte(r)})
+				}
+			}
+			return "<" + hex.EncodeToString(u.Bytes()) + ">"
+		}
+	}
+	r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", `\r`, "\n", `\n`)
+	return "(" + r.Replace(s) + ")"
+}
+
+// pdfDate returns t in the PDF date format D:YYYYMMDDHHmmSS+HH'mm'.
+func pdfDate(t time.Time) string {
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("D:%s%s%02d'%02d'", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,161 @@
+package einvoice
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// writeTestPDF embeds the invoice from fixture into visual and returns the
+// resulting PDF.
+func writeTestPDF(t *testing.T, fixture string, visual []byte) []byte {
+	t.Helper()
+	inv, err := ParseXMLFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := inv.WritePDF(&buf, bytes.NewReader(visual)); err != nil {
+		t.Fatalf("WritePDF() error = %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), visual) {
+		t.Error("WritePDF() modified the visual PDF instead of appending an update")
+	}
+	return buf.Bytes()
+}
+
+// buildTestPDFXrefStream assembles a minimal PDF with a cross-reference
+// stream instead of a cross-reference table.
+func buildTestPDFXrefStream() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := []int{buf.Len()}
+	buf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	offsets = append(offsets, buf.Len())
+	buf.WriteString("2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n")
+	xrefOff := buf.Len()
+	offsets = append(offsets, xrefOff)
+	var entries bytes.Buffer
+	entries.Write([]byte{0, 0, 0, 0, 0, 0xff, 0xff})
+	for _, off := range offsets {
+		entries.Write([]byte{1, byte(off >> 24), byte(off >> 16), byte(off >> 8), byte(off), 0, 0})
+	}
+	fmt.Fprintf(&buf, "3 0 obj\n<< /Type /XRef /Size 4 /W [1 4 2] /Root 1 0 R /Length %d >>\nstream\n", entries.Len())
+	buf.Write(entries.Bytes())
+	buf.WriteString("\nendstream\nendobj\nstartxref\n" + strconv.Itoa(xrefOff) + "\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func TestWritePDF(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		visual   []byte
+		filename string
+		level    string
+	}{
+		{"EN 16931", "testdata/cii/en16931/CII_example1.xml", testPDF{outputIntent: true}.build(), "factur-x.xml", "EN 16931"},
+		{"XRechnung", "testdata/cii/xrechnung/zugferd-xrechnung-einfach.xml", testPDF{outputIntent: true}.build(), "xrechnung.xml", "XRECHNUNG"},
+		{"xref stream", "testdata/cii/en16931/CII_example1.xml", buildTestPDFXrefStream(), "factur-x.xml", "EN 16931"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := writeTestPDF(t, tt.fixture, tt.visual)
+			info, err := CheckPDF(bytes.NewReader(out))
+			var valErr *ValidationError
+			if errors.As(err, &valErr) {
+				// The xref stream test PDF has no output intent.
+				for _, v := range valErr.Violations() {
+					if v.Rule.Code != "FX-PDF-11" {
+						t.Errorf("CheckPDF() violation %s: %s", v.Rule.Code, v.Text)
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("CheckPDF() error = %v", err)
+			}
+			if info.InvoiceFilename != tt.filename {
+				t.Errorf("InvoiceFilename = %q, want %q", info.InvoiceFilename, tt.filename)
+			}
+			if info.FacturXConformanceLevel != tt.level {
+				t.Errorf("ConformanceLevel = %q, want %q", info.FacturXConformanceLevel, tt.level)
+			}
+			if att := info.Attachment(tt.filename); att == nil || att.AFRelationship != "Alternative" {
+				t.Errorf("attachment = %+v, want /AFRelationship /Alternative", att)
+			}
+
+			inv, _, err := ParsePDF(bytes.NewReader(out))
+			if err != nil {
+				t.Fatalf("ParsePDF() error = %v", err)
+			}
+			orig, _ := ParseXMLFile(tt.fixture)
+			if inv.InvoiceNumber != orig.InvoiceNumber {
+				t.Errorf("InvoiceNumber = %q, want %q", inv.InvoiceNumber, orig.InvoiceNumber)
+			}
+		})
+	}
+}
+
+func TestWritePDF_KeepsAttachments(t *testing.T) {
+	visual := testPDF{
+		files:        []embeddedFile{{name: "logo.png", content: "PNGDATA", mimeType: "image/png", afRelationship: "Supplement"}},
+		af:           true,
+		outputIntent: true,
+	}.build()
+	out := writeTestPDF(t, "testdata/cii/en16931/CII_example1.xml", visual)
+	// Writing again replaces the invoice file.
+	out = writeTestPDF(t, "testdata/cii/en16931/CII_example1.xml", out)
+
+	info, err := CheckPDF(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("CheckPDF() error = %v", err)
+	}
+	if len(info.Attachments) != 2 {
+		t.Fatalf("got %d attachments, want 2", len(info.Attachments))
+	}
+	extra := info.AdditionalAttachments()
+	if len(extra) != 1 || extra[0].Name != "logo.png" || string(extra[0].Data) != "PNGDATA" {
+		t.Errorf("AdditionalAttachments() = %+v", extra)
+	}
+}
+
+func TestWritePDF_Errors(t *testing.T) {
+	visual := testPDF{}.build()
+
+	ubl, err := ParseXMLFile("testdata/ubl/invoice/UBL-Invoice-2.1-Example.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ubl.WritePDF(&bytes.Buffer{}, bytes.NewReader(visual)); err == nil {
+		t.Error("expected error for UBL invoice")
+	}
+
+	inv, err := ParseXMLFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.WritePDF(&bytes.Buffer{}, bytes.NewReader([]byte("not a pdf"))); err == nil {
+		t.Error("expected error for invalid PDF")
+	}
+	inv.GuidelineSpecifiedDocumentContextParameter = "urn:example:unknown"
+	if err := inv.WritePDF(&bytes.Buffer{}, bytes.NewReader(visual)); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	tests := map[string]string{
+		"factur-x.xml": "(factur-x.xml)",
+		`a(b)\c`:       `(a\(b\)\\c)`,
+		"Ä":            "<feff00c4>",
+	}
+	for in, want := range tests {
+		if got := pdfString(in); got != want {
+			t.Errorf("pdfString(%q) = %s, want %s", in, got, want)
+		}
+	}
+	if got := pdfName("text/xml"); got != "/text#2Fxml" {
+		t.Errorf("pdfName() = %s", got)
+	}
+}
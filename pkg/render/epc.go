@@ -0,0 +1,77 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice"
+)
+
+// epcMaxAmount is the largest amount allowed in an EPC QR code.
+var epcMaxAmount = decimal.RequireFromString("999999999.99")
+
+// epcPayload returns the content of an EPC QR code (EPC069-12, version 002)
+// for a SEPA credit transfer of the amount due (BT-115). The second return
+// value is false if the invoice is not payable by SEPA credit transfer in
+// euro: the currency is not EUR, the amount due is not positive or there is no
+// credit transfer (payment means 30 or 58) with an IBAN.
+func epcPayload(inv *einvoice.Invoice) (string, bool) {
+	if inv.InvoiceCurrencyCode != "EUR" || !inv.DuePayableAmount.IsPositive() || inv.DuePayableAmount.GreaterThan(epcMaxAmount) {
+		return "", false
+	}
+	var pm *einvoice.PaymentMeans
+	for i := range inv.PaymentMeans {
+		if p := &inv.PaymentMeans[i]; (p.TypeCode == 30 || p.TypeCode == 58) && p.PayeePartyCreditorFinancialAccountIBAN != "" {
+			pm = p
+			break
+		}
+	}
+	if pm == nil {
+		return "", false
+	}
+
+	name := inv.Seller.Name
+	if inv.PayeeTradeParty != nil && inv.PayeeTradeParty.Name != "" {
+		name = inv.PayeeTradeParty.Name
+	}
+	if pm.PayeePartyCreditorFinancialAccountName != "" {
+		name = pm.PayeePartyCreditorFinancialAccountName
+	}
+
+	// BT-83 is used as structured creditor reference if it is an ISO 11649
+	// reference, otherwise as unstructured remittance information.
+	var structured, unstructured string
+	ref := strings.TrimSpace(inv.PaymentReference)
+	switch {
+	case strings.HasPrefix(ref, "RF") && len(ref) <= 25 && !strings.Contains(ref, " "):
+		structured = ref
+	case ref != "":
+		unstructured = ref
+	default:
+		unstructured = inv.InvoiceNumber
+	}
+
+	lines := []string{
+		"BCD",
+		"002",
+		"1", // UTF-8
+		"SCT",
+		strings.ReplaceAll(pm.PayeeSpecifiedCreditorFinancialInstitutionBIC, " ", ""),
+		truncateRunes(name, 70),
+		strings.ReplaceAll(pm.PayeePartyCreditorFinancialAccountIBAN, " ", ""),
+		"EUR" + inv.DuePayableAmount.StringFixed(2),
+		"", // purpose
+		structured,
+		truncateRunes(unstructured, 140),
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n"), true
+}
+
+// truncateRunes returns s shortened to at most n characters.
+func truncateRunes(s string, n int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) > n {
+		r = r[:n]
+	}
+	return string(r)
+}
@@ -0,0 +1,263 @@
+// Package render creates human readable representations of electronic
+// invoices.
+package render
+
+import (
+	"embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice"
+	"github.com/speedata/einvoice/pkg/codelists"
+)
+
+//go:embed templates/*.gohtml
+var templates embed.FS
+
+// HTMLOptions controls the HTML output.
+type HTMLOptions struct {
+	// Template is the source of a user template. It is parsed after the
+	// built-in templates, so it can either replace the complete document by
+	// defining "invoice" or override single blocks such as "style", "header",
+	// "parties", "lines", "vat", "totals", "payment", "attachments" or
+	// "validation". The template data is HTMLData.
+	Template string
+	// SkipValidation omits the validation results.
+	SkipValidation bool
+}
+
+// HTMLData is the data passed to the HTML templates.
+type HTMLData struct {
+	Invoice     *einvoice.Invoice
+	Lines       []Line       // Invoice lines in hierarchical order
+	Attachments []Attachment // Additional supporting documents (BG-24)
+	Violations  []einvoice.SemanticError
+	Warnings    []einvoice.SemanticError
+	Validated   bool // false if validation was skipped
+}
+
+// Line is an invoice line with its depth in the sub line hierarchy
+// (BT-X-304 ParentLineID). Top level lines have depth 0.
+type Line struct {
+	*einvoice.InvoiceLine
+	Depth int
+}
+
+// Attachment is an additional supporting document (BG-24) with a link to
+// the embedded content (BT-125) or the external location (BT-124).
+type Attachment struct {
+	*einvoice.Document
+	URL  template.URL // data: URL of the content or the external location, empty if none
+	Size int          // size of the embedded content in bytes
+}
+
+// HTML writes a HTML representation of the invoice to w. Unless
+// opts.SkipValidation is set, the invoice is validated and the violations
+// and warnings are shown in the document. A nil opts uses the defaults.
+func HTML(w io.Writer, inv *einvoice.Invoice, opts *HTMLOptions) error {
+	if opts == nil {
+		opts = &HTMLOptions{}
+	}
+	tpl, err := template.New("").Funcs(templateFuncs).ParseFS(templates, "templates/*.gohtml")
+	if err != nil {
+		return err
+	}
+	if opts.Template != "" {
+		if tpl, err = tpl.New("user").Parse(opts.Template); err != nil {
+			return fmt.Errorf("template parse error: %w", err)
+		}
+	}
+
+	data := HTMLData{
+		Invoice:     inv,
+		Lines:       lineHierarchy(inv.InvoiceLines),
+		Attachments: attachments(inv.AdditionalReferencedDocument),
+	}
+	if !opts.SkipValidation {
+		data.Validated = true
+		if err := inv.Validate(); err != nil {
+			var ve *einvoice.ValidationError
+			if !errors.As(err, &ve) {
+				return err
+			}
+			data.Violations = ve.Violations()
+		}
+		data.Warnings = inv.Warnings()
+	}
+	return tpl.ExecuteTemplate(w, "invoice", data)
+}
+
+// lineHierarchy returns the lines in depth-first order of the sub line
+// hierarchy. Lines with an unknown parent and lines in a cycle are treated
+// as top level lines.
+func lineHierarchy(lines []einvoice.InvoiceLine) []Line {
+	ids := make(map[string]bool, len(lines))
+	for _, l := range lines {
+		ids[l.LineID] = true
+	}
+	children := map[string][]int{}
+	var roots []int
+	for i, l := range lines {
+		if l.ParentLineID != "" && l.ParentLineID != l.LineID && ids[l.ParentLineID] {
+			children[l.ParentLineID] = append(children[l.ParentLineID], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	ret := make([]Line, 0, len(lines))
+	visited := make([]bool, len(lines))
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		ret = append(ret, Line{InvoiceLine: &lines[i], Depth: depth})
+		for _, c := range children[lines[i].LineID] {
+			walk(c, depth+1)
+		}
+	}
+	for _, i := range roots {
+		walk(i, 0)
+	}
+	for i := range lines {
+		walk(i, 0)
+	}
+	return ret
+}
+
+func attachments(docs []einvoice.Document) []Attachment {
+	var ret []Attachment
+	for i := range docs {
+		doc := &docs[i]
+		a := Attachment{Document: doc, Size: len(doc.AttachmentBinaryObject)}
+		switch {
+		case len(doc.AttachmentBinaryObject) > 0:
+			mime := doc.AttachmentMimeCode
+			if !mimeType.MatchString(mime) {
+				mime = "application/octet-stream"
+			}
+			a.URL = template.URL("data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(doc.AttachmentBinaryObject))
+		case doc.URIID != "":
+			a.URL = externalURL(doc.URIID)
+		}
+		ret = append(ret, a)
+	}
+	return ret
+}
+
+// mimeType matches the MIME codes allowed in data: URLs.
+var mimeType = regexp.MustCompile(`^[a-zA-Z0-9.+-]+/[a-zA-Z0-9.+-]+$`)
+
+// externalURL returns uri if it is a http(s) URL, otherwise an empty URL.
+func externalURL(uri string) template.URL {
+	if strings.HasPrefix(uri, "https://") || strings.HasPrefix(uri, "http://") {
+		return template.URL(uri)
+	}
+	return ""
+}
+
+// vatCategories are the names of the VAT category codes (UNTDID 5305 subset
+// used by EN 16931).
+var vatCategories = map[string]string{
+	"S":  "Standard rate",
+	"Z":  "Zero rated goods",
+	"E":  "Exempt from tax",
+	"AE": "VAT Reverse Charge",
+	"K":  "Intra-community supply",
+	"G":  "Export outside the EU",
+	"O":  "Not subject to VAT",
+	"L":  "Canary Islands general indirect tax",
+	"M":  "Tax for production, services and importation in Ceuta and Melilla",
+}
+
+// paymentMeans are the names of the common payment means codes (UNTDID 4461).
+var paymentMeans = map[int]string{
+	1:  "Instrument not defined",
+	10: "In cash",
+	20: "Cheque",
+	30: "Credit transfer",
+	31: "Debit transfer",
+	42: "Payment to bank account",
+	48: "Bank card",
+	49: "Direct debit",
+	57: "Standing agreement",
+	58: "SEPA credit transfer",
+	59: "SEPA direct debit",
+	97: "Clearing between partners",
+}
+
+var templateFuncs = template.FuncMap{
+	"amount": formatAmount,
+	"decimal": func(d decimal.Decimal) string {
+		return d.String()
+	},
+	"percent": func(d decimal.Decimal) string {
+		return d.String() + " %"
+	},
+	"date": formatDate,
+	"unit": codelists.UnitCode,
+	"documenttype": func(code einvoice.CodeDocument) string {
+		return codelists.DocumentType(strconv.Itoa(int(code)))
+	},
+	"notesubject": codelists.TextSubjectQualifier,
+	"profile":     einvoice.GetProfileName,
+	"vatcategory": func(code string) string {
+		if name, ok := vatCategories[code]; ok {
+			return name
+		}
+		return code
+	},
+	"paymentmeans": func(code int) string {
+		if name, ok := paymentMeans[code]; ok {
+			return name
+		}
+		return strconv.Itoa(code)
+	},
+	"indent": func(depth int) string {
+		return strconv.FormatFloat(float64(depth)*1.5, 'f', -1, 64) + "em"
+	},
+	"iszero": func(d decimal.Decimal) bool {
+		return d.IsZero()
+	},
+	"bytes": formatBytes,
+	"list": func(v ...any) []any {
+		return v
+	},
+}
+
+// formatAmount formats an amount with at least two decimal places.
+func formatAmount(d decimal.Decimal) string {
+	if d.Exponent() < -2 {
+		return d.String()
+	}
+	return d.StringFixed(2)
+}
+
+// formatDate formats t as ISO 8601 date, a zero time as empty string.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f kB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
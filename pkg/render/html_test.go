@@ -0,0 +1,135 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice"
+)
+
+func renderHTML(t *testing.T, inv *einvoice.Invoice, opts *HTMLOptions) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := HTML(&buf, inv, opts); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string
+	}{
+		{"../../testdata/cii/en16931/CII_example1.xml", []string{
+			"<!DOCTYPE html>",
+			"12115118",
+			"Validation: no violations",
+			"VAT breakdown",
+			"Payment instructions",
+			"Standard rate",
+		}},
+		{"../../testdata/ubl/invoice/UBL-Invoice-2.1-Example.xml", []string{
+			"<!DOCTYPE html>",
+			"TOSL108",
+			"Document totals",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			inv, err := einvoice.ParseXMLFile(tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := renderHTML(t, inv, nil)
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output does not contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestHTML_Validation(t *testing.T) {
+	inv, err := einvoice.ParseXMLFile("../../testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.InvoiceNumber = ""
+	out := renderHTML(t, inv, nil)
+	if !strings.Contains(out, "BR-02") {
+		t.Error("output does not contain violation BR-02")
+	}
+	out = renderHTML(t, inv, &HTMLOptions{SkipValidation: true})
+	if strings.Contains(out, "Validation:") {
+		t.Error("output contains validation results with SkipValidation")
+	}
+}
+
+func TestHTML_Template(t *testing.T) {
+	inv, err := einvoice.ParseXMLFile("../../testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := renderHTML(t, inv, &HTMLOptions{Template: `{{ define "style" }}body { color: red }{{ end }}`})
+	if !strings.Contains(out, "body { color: red }") || !strings.Contains(out, "12115118") {
+		t.Error("block override not applied")
+	}
+	out = renderHTML(t, inv, &HTMLOptions{Template: `{{ define "invoice" }}Invoice {{ .Invoice.InvoiceNumber }}: {{ amount .Invoice.DuePayableAmount }}{{ end }}`})
+	if out != "Invoice 12115118: 250.33" {
+		t.Errorf("got %q", out)
+	}
+	if err := HTML(&bytes.Buffer{}, inv, &HTMLOptions{Template: "{{ end }}"}); err == nil {
+		t.Error("expected error for invalid template")
+	}
+}
+
+func TestHTML_Attachments(t *testing.T) {
+	inv, err := einvoice.ParseXMLFile("../../testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.AdditionalReferencedDocument = []einvoice.Document{
+		{IssuerAssignedID: "A1", TypeCode: "916", AttachmentMimeCode: "text/csv", AttachmentFilename: "hours.csv", AttachmentBinaryObject: []byte("a;b")},
+		{IssuerAssignedID: "A2", TypeCode: "916", URIID: "javascript:alert(1)"},
+	}
+	out := renderHTML(t, inv, &HTMLOptions{SkipValidation: true})
+	if !strings.Contains(out, `href="data:text/csv;base64,YTti" download="hours.csv"`) {
+		t.Error("output does not contain the data URL of the attachment")
+	}
+	if strings.Contains(out, `href="javascript`) {
+		t.Error("output contains a javascript link")
+	}
+}
+
+func TestLineHierarchy(t *testing.T) {
+	lines := []einvoice.InvoiceLine{
+		{LineID: "1"},
+		{LineID: "1.1", ParentLineID: "1"},
+		{LineID: "2"},
+		{LineID: "1.1.1", ParentLineID: "1.1"},
+		{LineID: "3", ParentLineID: "unknown"},
+		{LineID: "4", ParentLineID: "5"},
+		{LineID: "5", ParentLineID: "4"},
+	}
+	var got []string
+	for _, l := range lineHierarchy(lines) {
+		got = append(got, strings.Repeat(">", l.Depth)+l.LineID)
+	}
+	want := "1 >1.1 >>1.1.1 2 3 4 >5"
+	if strings.Join(got, " ") != want {
+		t.Errorf("lineHierarchy() = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := map[string]string{"1": "1.00", "1.5": "1.50", "1.2345": "1.2345", "-3": "-3.00"}
+	for in, want := range tests {
+		if got := formatAmount(decimal.RequireFromString(in)); got != want {
+			t.Errorf("formatAmount(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
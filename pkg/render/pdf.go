@@ -0,0 +1,622 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/speedata/einvoice"
+	"github.com/speedata/einvoice/pkg/codelists"
+)
+
+// PDFOptions controls the PDF output.
+type PDFOptions struct {
+	// PaymentQR adds an EPC QR code ("GiroCode") with the payment data if the
+	// invoice is payable by SEPA credit transfer in euro.
+	PaymentQR bool
+}
+
+// Page layout in points.
+const (
+	marginLeft   = 50.0
+	marginRight  = pageWidth - 50
+	marginTop    = 50.0
+	contentLimit = pageHeight - 75 // bottom of the content area, above the footer
+	fontSize     = 9.0
+	smallSize    = 7.5
+	lineHeight   = 11.0
+)
+
+// PDF writes a human readable PDF representation of the invoice to w. The
+// PDF has an A4 page format and contains the header data, the parties, the
+// invoice lines, the VAT breakdown, the totals, the payment instructions and
+// the notes of the invoice. A nil opts uses the defaults.
+//
+// The text is set in the standard fonts Helvetica and Helvetica-Bold, which
+// are not embedded. Characters outside of the Windows-1252 character set are
+// replaced by a question mark. As the fonts are not embedded, the PDF is not
+// PDF/A and not suitable as visual part of a ZUGFeRD/Factur-X PDF.
+func PDF(w io.Writer, inv *einvoice.Invoice, opts *PDFOptions) error {
+	if opts == nil {
+		opts = &PDFOptions{}
+	}
+	l := &pdfLayout{
+		doc: &pdfDoc{
+			title: documentTitle(inv),
+			date:  time.Now(),
+		},
+		inv: inv,
+	}
+	l.newPage()
+	l.header()
+	l.lines()
+	l.allowancesCharges()
+	l.totals()
+	l.vatBreakdown()
+	if err := l.payment(opts.PaymentQR); err != nil {
+		return err
+	}
+	l.notes()
+	l.footers()
+	return l.doc.write(w)
+}
+
+// pdfLayout places the invoice content on the pages. y is the current
+// vertical position from the top of the page.
+type pdfLayout struct {
+	doc     *pdfDoc
+	page    *pdfPage
+	inv     *einvoice.Invoice
+	y       float64
+	pageTop float64 // y of the first content on the current page
+}
+
+func documentTitle(inv *einvoice.Invoice) string {
+	return codelists.DocumentType(strconv.Itoa(int(inv.InvoiceTypeCode))) + " " + inv.InvoiceNumber
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = l.doc.newPage()
+	l.y = marginTop
+	if len(l.doc.pages) > 1 {
+		l.page.text(marginLeft, l.y, fontRegular, smallSize, l.inv.Seller.Name+" – "+documentTitle(l.inv))
+		l.y += 2 * lineHeight
+	}
+	l.pageTop = l.y
+}
+
+// ensure starts a new page if less than h points are left on the current
+// page. It reports whether a new page was started.
+func (l *pdfLayout) ensure(h float64) bool {
+	if l.y+h <= contentLimit || l.y == l.pageTop {
+		return false
+	}
+	l.newPage()
+	return true
+}
+
+// heading writes a section heading. It starts a new page unless the heading
+// and at least need points of the content fit on the current page.
+func (l *pdfLayout) heading(s string, need float64) {
+	l.y += lineHeight
+	l.ensure(2*lineHeight + need)
+	l.page.text(marginLeft, l.y, fontBold, 10, s)
+	l.y += 4
+	l.page.line(marginLeft, l.y, marginRight, l.y, 0.5)
+	l.y += lineHeight + 2
+}
+
+// paragraph writes wrapped text over the full width.
+func (l *pdfLayout) paragraph(s string, font *pdfFont, size float64) {
+	for _, line := range wrapText(s, font, size, marginRight-marginLeft) {
+		l.ensure(lineHeight)
+		l.page.text(marginLeft, l.y, font, size, line)
+		l.y += lineHeight
+	}
+}
+
+func (l *pdfLayout) header() {
+	inv := l.inv
+	seller := inv.Seller
+	l.page.text(marginLeft, l.y+6, fontBold, 14, seller.Name)
+	l.y += 40
+
+	// Sender line and buyer address
+	var sender []string
+	if seller.Name != "" {
+		sender = append(sender, seller.Name)
+	}
+	sender = append(sender, addressLines(seller.PostalAddress)...)
+	l.page.text(marginLeft, l.y, fontRegular, 6.5, strings.Join(sender, " · "))
+	y := l.y + 1.5*lineHeight
+	buyer := append([]string{inv.Buyer.Name}, addressLines(inv.Buyer.PostalAddress)...)
+	for _, s := range buyer {
+		for _, line := range wrapText(s, fontRegular, fontSize+1, 240) {
+			l.page.text(marginLeft, y, fontRegular, fontSize+1, line)
+			y += lineHeight + 1
+		}
+	}
+
+	// Invoice data
+	var fields [][2]string
+	add := func(label, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{label, value})
+		}
+	}
+	add("Invoice number", inv.InvoiceNumber)
+	add("Invoice date", formatDate(inv.InvoiceDate))
+	add("Delivery date", formatDate(inv.OccurrenceDateTime))
+	if !inv.BillingSpecifiedPeriodStart.IsZero() || !inv.BillingSpecifiedPeriodEnd.IsZero() {
+		add("Invoicing period", formatDate(inv.BillingSpecifiedPeriodStart)+" – "+formatDate(inv.BillingSpecifiedPeriodEnd))
+	}
+	for _, pt := range inv.SpecifiedTradePaymentTerms {
+		add("Due date", formatDate(pt.DueDate))
+	}
+	add("Buyer reference", inv.BuyerReference)
+	add("Purchase order", inv.BuyerOrderReferencedDocument)
+	add("Sales order", inv.SellerOrderReferencedDocument)
+	add("Contract", inv.ContractReferencedDocument)
+	add("Project", strings.TrimSpace(inv.SpecifiedProcuringProjectID+" "+inv.SpecifiedProcuringProjectName))
+	add("Despatch advice", inv.DespatchAdviceReferencedDocument)
+	add("Receiving advice", inv.ReceivingAdviceReferencedDocument)
+	add("Buyer VAT ID", inv.Buyer.VATaxRegistration)
+	for _, ref := range inv.InvoiceReferencedDocument {
+		add("Preceding invoice", strings.TrimSpace(ref.ID+" "+formatDate(ref.Date)))
+	}
+	fy := l.y
+	for _, f := range fields {
+		l.page.text(340, fy, fontRegular, fontSize, f[0])
+		for _, line := range wrapText(f[1], fontRegular, fontSize, marginRight-430) {
+			l.page.text(430, fy, fontRegular, fontSize, line)
+			fy += lineHeight
+		}
+	}
+	l.y = max(y, fy) + lineHeight
+
+	if ship := inv.ShipTo; ship != nil {
+		delivery := append([]string{ship.Name}, addressLines(ship.PostalAddress)...)
+		l.page.text(marginLeft, l.y, fontBold, fontSize, "Delivery address")
+		l.page.text(marginLeft+90, l.y, fontRegular, fontSize, strings.Join(delivery, ", "))
+		l.y += 2 * lineHeight
+	}
+
+	l.y += lineHeight
+	l.page.text(marginLeft, l.y, fontBold, 16, documentTitle(inv))
+	l.y += 2 * lineHeight
+}
+
+// Columns of the line table: right edges for numbers, left edges for
+// text.
+const (
+	colPos      = marginLeft
+	colItem     = marginLeft + 35
+	colItemEnd  = 300.0
+	colQuantity = 350.0
+	colUnit     = 355.0
+	colPrice    = 450.0
+	colVAT      = 490.0
+	colAmount   = marginRight
+)
+
+func (l *pdfLayout) lineTableHeader() {
+	l.page.rect(marginLeft, l.y-lineHeight+2, marginRight-marginLeft, lineHeight+2, 0.9)
+	l.page.text(colPos+2, l.y, fontBold, fontSize, "Pos.")
+	l.page.text(colItem, l.y, fontBold, fontSize, "Description")
+	l.page.textRight(colQuantity, l.y, fontBold, fontSize, "Quantity")
+	l.page.text(colUnit, l.y, fontBold, fontSize, "Unit")
+	l.page.textRight(colPrice, l.y, fontBold, fontSize, "Unit price")
+	l.page.textRight(colVAT, l.y, fontBold, fontSize, "VAT")
+	l.page.textRight(colAmount, l.y, fontBold, fontSize, "Amount")
+	l.y += lineHeight + 4
+}
+
+func (l *pdfLayout) lines() {
+	if len(l.inv.InvoiceLines) == 0 {
+		return
+	}
+	l.ensure(4 * lineHeight)
+	l.lineTableHeader()
+	for _, line := range lineHierarchy(l.inv.InvoiceLines) {
+		indent := float64(line.Depth) * 8
+		width := colItemEnd - colItem - indent
+
+		type text struct {
+			font *pdfFont
+			size float64
+			s    string
+		}
+		var texts []text
+		for _, s := range wrapText(line.ItemName, fontBold, fontSize, width) {
+			texts = append(texts, text{fontBold, fontSize, s})
+		}
+		for _, s := range lineDetails(line.InvoiceLine) {
+			for _, w := range wrapText(s, fontRegular, smallSize, width) {
+				texts = append(texts, text{fontRegular, smallSize, w})
+			}
+		}
+		height := float64(max(len(texts), 1)) * lineHeight
+		if l.ensure(height) {
+			l.lineTableHeader()
+		}
+
+		y := l.y
+		l.page.text(colPos+2+indent, y, fontRegular, fontSize, line.LineID)
+		l.page.textRight(colQuantity, y, fontRegular, fontSize, line.BilledQuantity.String())
+		l.page.text(colUnit, y, fontRegular, fontSize, truncateWidth(codelists.UnitCode(line.BilledQuantityUnit), fontRegular, fontSize, colPrice-colUnit-45))
+		price := formatAmount(line.NetPrice)
+		if !line.BasisQuantity.IsZero() && !line.BasisQuantity.Equal(decimal.NewFromInt(1)) {
+			price += " / " + line.BasisQuantity.String()
+		}
+		l.page.textRight(colPrice, y, fontRegular, fontSize, price)
+		l.page.textRight(colVAT, y, fontRegular, fontSize, formatPercent(line.TaxRateApplicablePercent))
+		l.page.textRight(colAmount, y, fontRegular, fontSize, formatAmount(line.Total))
+		for _, t := range texts {
+			l.page.text(colItem+indent, y, t.font, t.size, t.s)
+			y += lineHeight
+		}
+		l.y += height + 3
+		l.page.line(marginLeft, l.y-lineHeight+2, marginRight, l.y-lineHeight+2, 0.25)
+	}
+}
+
+// lineDetails returns the additional information of an invoice line.
+func lineDetails(line *einvoice.InvoiceLine) []string {
+	var ret []string
+	add := func(format, value string) {
+		if value != "" {
+			ret = append(ret, fmt.Sprintf(format, value))
+		}
+	}
+	add("%s", line.Description)
+	add("%s", line.Note)
+	add("Item no.: %s", line.ArticleNumber)
+	add("Buyer item no.: %s", line.ArticleNumberBuyer)
+	add("Order line: %s", line.BuyerOrderReferencedDocument)
+	if !line.BillingSpecifiedPeriodStart.IsZero() || !line.BillingSpecifiedPeriodEnd.IsZero() {
+		add("Period: %s", formatDate(line.BillingSpecifiedPeriodStart)+" – "+formatDate(line.BillingSpecifiedPeriodEnd))
+	}
+	for _, c := range line.Characteristics {
+		add("%s", c.Description+": "+c.Value)
+	}
+	for _, ac := range line.InvoiceLineAllowances {
+		add("%s", allowanceChargeText(ac)+": −"+formatAmount(ac.ActualAmount))
+	}
+	for _, ac := range line.InvoiceLineCharges {
+		add("%s", allowanceChargeText(ac)+": +"+formatAmount(ac.ActualAmount))
+	}
+	return ret
+}
+
+func allowanceChargeText(ac einvoice.AllowanceCharge) string {
+	s := "Allowance"
+	if ac.ChargeIndicator {
+		s = "Charge"
+	}
+	if ac.Reason != "" {
+		s += " " + ac.Reason
+	}
+	if !ac.CalculationPercent.IsZero() {
+		s += fmt.Sprintf(" (%s of %s)", formatPercent(ac.CalculationPercent), formatAmount(ac.BasisAmount))
+	}
+	return s
+}
+
+func (l *pdfLayout) allowancesCharges() {
+	if len(l.inv.SpecifiedTradeAllowanceCharge) == 0 {
+		return
+	}
+	l.heading("Allowances and charges", lineHeight)
+	for _, ac := range l.inv.SpecifiedTradeAllowanceCharge {
+		l.ensure(lineHeight)
+		amount := formatAmount(ac.ActualAmount)
+		if !ac.ChargeIndicator {
+			amount = "−" + amount
+		}
+		l.page.text(marginLeft, l.y, fontRegular, fontSize, truncateWidth(allowanceChargeText(ac), fontRegular, fontSize, colVAT-marginLeft-50))
+		l.page.textRight(colVAT, l.y, fontRegular, fontSize, ac.CategoryTradeTaxCategoryCode+" "+formatPercent(ac.CategoryTradeTaxRateApplicablePercent))
+		l.page.textRight(colAmount, l.y, fontRegular, fontSize, amount)
+		l.y += lineHeight
+	}
+}
+
+func (l *pdfLayout) totals() {
+	inv := l.inv
+	type row struct {
+		label    string
+		value    decimal.Decimal
+		currency string
+		show     bool
+		bold     bool
+	}
+	cur := inv.InvoiceCurrencyCode
+	acc := inv.TaxTotalAccountingCurrency
+	rows := []row{
+		{"Sum of line amounts", inv.LineTotal, cur, true, false},
+		{"Allowances", inv.AllowanceTotal, cur, !inv.AllowanceTotal.IsZero(), false},
+		{"Charges", inv.ChargeTotal, cur, !inv.ChargeTotal.IsZero(), false},
+		{"Total without VAT", inv.TaxBasisTotal, cur, true, false},
+		{"VAT", inv.TaxTotal, cur, true, false},
+		{"VAT in accounting currency", inv.TaxTotalAccounting, acc, acc != "" && acc != cur, false},
+		{"Total with VAT", inv.GrandTotal, cur, true, true},
+		{"Paid amount", inv.TotalPrepaid, cur, !inv.TotalPrepaid.IsZero(), false},
+		{"Rounding", inv.RoundingAmount, cur, !inv.RoundingAmount.IsZero(), false},
+		{"Amount due", inv.DuePayableAmount, cur, true, true},
+	}
+	l.y += lineHeight
+	l.ensure(8 * lineHeight)
+	for _, r := range rows {
+		if !r.show {
+			continue
+		}
+		l.ensure(lineHeight)
+		font := fontRegular
+		if r.bold {
+			font = fontBold
+			l.page.line(340, l.y-lineHeight+2, marginRight, l.y-lineHeight+2, 0.5)
+		}
+		l.page.text(340, l.y, font, fontSize, r.label)
+		l.page.textRight(colAmount, l.y, font, fontSize, formatAmount(r.value)+" "+r.currency)
+		l.y += lineHeight + 2
+	}
+}
+
+func (l *pdfLayout) vatBreakdown() {
+	if len(l.inv.TradeTaxes) == 0 {
+		return
+	}
+	l.heading("VAT breakdown", 2*lineHeight)
+	l.page.text(marginLeft, l.y, fontBold, fontSize, "Category")
+	l.page.textRight(350, l.y, fontBold, fontSize, "Rate")
+	l.page.textRight(colPrice, l.y, fontBold, fontSize, "Taxable amount")
+	l.page.textRight(colAmount, l.y, fontBold, fontSize, "VAT amount")
+	l.y += lineHeight
+	for _, tt := range l.inv.TradeTaxes {
+		l.ensure(lineHeight)
+		name := tt.CategoryCode
+		if n, ok := vatCategories[tt.CategoryCode]; ok {
+			name += " – " + n
+		}
+		l.page.text(marginLeft, l.y, fontRegular, fontSize, name)
+		l.page.textRight(350, l.y, fontRegular, fontSize, formatPercent(tt.Percent))
+		l.page.textRight(colPrice, l.y, fontRegular, fontSize, formatAmount(tt.BasisAmount))
+		l.page.textRight(colAmount, l.y, fontRegular, fontSize, formatAmount(tt.CalculatedAmount))
+		l.y += lineHeight
+		if reason := strings.TrimSpace(tt.ExemptionReason + " " + tt.ExemptionReasonCode); reason != "" {
+			for _, s := range wrapText(reason, fontRegular, smallSize, 280) {
+				l.ensure(lineHeight)
+				l.page.text(marginLeft+10, l.y, fontRegular, smallSize, s)
+				l.y += lineHeight
+			}
+		}
+	}
+}
+
+// qrSize is the size of the EPC QR code including the quiet zone.
+const qrSize = 100.0
+
+func (l *pdfLayout) payment(withQR bool) error {
+	inv := l.inv
+	var rows [][2]string
+	add := func(label, value string) {
+		if value != "" {
+			rows = append(rows, [2]string{label, value})
+		}
+	}
+	for _, pt := range inv.SpecifiedTradePaymentTerms {
+		add("Payment terms", pt.Description)
+		add("Due date", formatDate(pt.DueDate))
+		add("Mandate reference", pt.DirectDebitMandateID)
+	}
+	for _, pm := range inv.PaymentMeans {
+		means := strconv.Itoa(pm.TypeCode)
+		if n, ok := paymentMeans[pm.TypeCode]; ok {
+			means = n
+		}
+		if pm.Information != "" {
+			means += " (" + pm.Information + ")"
+		}
+		add("Payment means", means)
+		add("Account holder", pm.PayeePartyCreditorFinancialAccountName)
+		add("IBAN", formatIBAN(pm.PayeePartyCreditorFinancialAccountIBAN))
+		add("Account", pm.PayeePartyCreditorFinancialAccountProprietaryID)
+		add("BIC", pm.PayeeSpecifiedCreditorFinancialInstitutionBIC)
+		add("Card", pm.ApplicableTradeSettlementFinancialCardID)
+		add("Card holder", pm.ApplicableTradeSettlementFinancialCardCardholderName)
+		add("Debited account", formatIBAN(pm.PayerPartyDebtorFinancialAccountIBAN))
+	}
+	add("Payment reference", inv.PaymentReference)
+	add("Creditor ID", inv.CreditorReferenceID)
+	if inv.PayeeTradeParty != nil {
+		add("Payee", inv.PayeeTradeParty.Name)
+	}
+
+	var qr [][]bool
+	if withQR {
+		if payload, ok := epcPayload(inv); ok {
+			code, err := qrcode.New(payload, qrcode.Medium)
+			if err != nil {
+				return err
+			}
+			qr = code.Bitmap()
+		}
+	}
+	if len(rows) == 0 && qr == nil {
+		return nil
+	}
+
+	need := lineHeight
+	if qr != nil {
+		need = qrSize + lineHeight
+	}
+	l.heading("Payment", need)
+	top := l.y
+	for _, r := range rows {
+		l.ensure(lineHeight)
+		l.page.text(marginLeft, l.y, fontRegular, fontSize, r[0])
+		for _, s := range wrapText(r[1], fontRegular, fontSize, 260) {
+			l.page.text(marginLeft+100, l.y, fontRegular, fontSize, s)
+			l.y += lineHeight
+		}
+	}
+	if qr != nil {
+		x := marginRight - qrSize
+		l.drawQR(qr, x, top-lineHeight+2)
+		caption := "Scan to pay"
+		l.page.text(x+(qrSize-fontRegular.width(caption, smallSize))/2, top+qrSize, fontRegular, smallSize, caption)
+		l.y = max(l.y, top+qrSize+lineHeight)
+	}
+	return nil
+}
+
+// drawQR draws the QR code modules (including the quiet zone) at (x, y).
+// modules[y][x] is true for dark modules.
+func (l *pdfLayout) drawQR(modules [][]bool, x, y float64) {
+	size := len(modules)
+	module := qrSize / float64(size)
+	for row := range size {
+		for col := 0; col < size; col++ {
+			if !modules[row][col] {
+				continue
+			}
+			// Combine horizontal runs of dark modules.
+			run := col
+			for run+1 < size && modules[row][run+1] {
+				run++
+			}
+			l.page.rect(x+float64(col)*module, y+float64(row)*module, float64(run-col+1)*module, module, 0)
+			col = run
+		}
+	}
+}
+
+func (l *pdfLayout) notes() {
+	if len(l.inv.Notes) == 0 {
+		return
+	}
+	l.heading("Notes", lineHeight)
+	for _, n := range l.inv.Notes {
+		for _, para := range strings.Split(n.Text, "\n") {
+			l.paragraph(para, fontRegular, fontSize)
+		}
+		l.y += 3
+	}
+}
+
+// footers writes the seller information and the page numbers.
+func (l *pdfLayout) footers() {
+	seller := l.inv.Seller
+	var first, second []string
+	if seller.Name != "" {
+		first = append(first, seller.Name)
+	}
+	first = append(first, addressLines(seller.PostalAddress)...)
+	for _, c := range seller.DefinedTradeContact {
+		for _, s := range []string{c.PhoneNumber, c.EMail} {
+			if s != "" {
+				first = append(first, s)
+			}
+		}
+	}
+	if seller.VATaxRegistration != "" {
+		second = append(second, "VAT ID "+seller.VATaxRegistration)
+	}
+	if seller.FCTaxRegistration != "" {
+		second = append(second, "Tax number "+seller.FCTaxRegistration)
+	}
+	if org := seller.SpecifiedLegalOrganization; org != nil && org.ID != "" {
+		second = append(second, "Registration "+org.ID)
+	}
+	if seller.Description != "" {
+		second = append(second, seller.Description)
+	}
+
+	width := marginRight - marginLeft - 60
+	for i, p := range l.doc.pages {
+		y := pageHeight - 55
+		p.line(marginLeft, y, marginRight, y, 0.5)
+		y += lineHeight
+		p.text(marginLeft, y, fontRegular, 6.5, truncateWidth(strings.Join(first, " · "), fontRegular, 6.5, width))
+		p.textRight(marginRight, y, fontRegular, 6.5, fmt.Sprintf("Page %d of %d", i+1, len(l.doc.pages)))
+		p.text(marginLeft, y+9, fontRegular, 6.5, truncateWidth(strings.Join(second, " · "), fontRegular, 6.5, width))
+	}
+}
+
+// addressLines returns the non-empty lines of a postal address.
+func addressLines(a *einvoice.PostalAddress) []string {
+	if a == nil {
+		return nil
+	}
+	var ret []string
+	for _, s := range []string{a.Line1, a.Line2, a.Line3, strings.TrimSpace(a.PostcodeCode + " " + a.City), a.CountrySubDivisionName, a.CountryID} {
+		if s = strings.TrimSpace(s); s != "" {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func formatPercent(d decimal.Decimal) string {
+	return d.String() + " %"
+}
+
+// formatIBAN inserts a space after every four characters.
+func formatIBAN(iban string) string {
+	iban = strings.ReplaceAll(iban, " ", "")
+	var b strings.Builder
+	for i, r := range iban {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// wrapText breaks s into lines not wider than width. Words longer than a
+// line are broken at any character.
+func wrapText(s string, font *pdfFont, size, width float64) []string {
+	var lines []string
+	var cur string
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if cur != "" {
+			candidate = cur + " " + word
+		}
+		if font.width(candidate, size) <= width {
+			cur = candidate
+			continue
+		}
+		if cur != "" {
+			lines = append(lines, cur)
+		}
+		cur = ""
+		for _, r := range word {
+			if cur != "" && font.width(cur+string(r), size) > width {
+				lines = append(lines, cur)
+				cur = ""
+			}
+			cur += string(r)
+		}
+	}
+	if cur != "" {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+// truncateWidth shortens s to fit into width.
+func truncateWidth(s string, font *pdfFont, size, width float64) string {
+	if font.width(s, size) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && font.width(string(r)+"…", size) > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
@@ -0,0 +1,183 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice"
+	pdf "github.com/speedata/pdfdisassembler"
+)
+
+// renderPDF renders the invoice and returns the decoded content of all pages.
+func renderPDF(t *testing.T, inv *einvoice.Invoice, opts *PDFOptions) ([]byte, []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := PDF(&buf, inv, opts); err != nil {
+		t.Fatalf("PDF() error = %v", err)
+	}
+	r, err := pdf.Open(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("written PDF does not open: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+	pages, err := r.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, p := range pages {
+		c, err := p.Content()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(c))
+	}
+	return buf.Bytes(), contents
+}
+
+func TestPDF(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string
+	}{
+		{"../../testdata/cii/en16931/CII_example1.xml", []string{"(12115118)", "(Amount due)", "(VAT breakdown)", "(Page 1 of "}},
+		{"../../testdata/ubl/invoice/UBL-Invoice-2.1-Example.xml", []string{"(TOSL108)", "(Payment)"}},
+		{"../../testdata/cii/extended/zf25-subline-group-hardware.xml", []string{"(0101)", "(Notes)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			inv, err := einvoice.ParseXMLFile(tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, pages := renderPDF(t, inv, nil)
+			content := strings.Join(pages, "\n")
+			for _, s := range tt.want {
+				if !strings.Contains(content, s) {
+					t.Errorf("content does not contain %s", s)
+				}
+			}
+		})
+	}
+}
+
+func TestPDF_PageBreaks(t *testing.T) {
+	inv, err := einvoice.ParseXMLFile("../../testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := inv.InvoiceLines[0]
+	for range 100 {
+		inv.InvoiceLines = append(inv.InvoiceLines, line)
+	}
+	_, pages := renderPDF(t, inv, nil)
+	if len(pages) < 3 {
+		t.Fatalf("got %d pages, want at least 3", len(pages))
+	}
+	for i, p := range pages {
+		if i < len(pages)-1 && !strings.Contains(p, "(Unit price)") {
+			t.Errorf("page %d has no table header", i+1)
+		}
+		if !strings.Contains(p, "(Page ") {
+			t.Errorf("page %d has no page number", i+1)
+		}
+	}
+}
+
+func TestPDF_PaymentQR(t *testing.T) {
+	inv, err := einvoice.ParseXMLFile("../../testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasQR := func(opts *PDFOptions) bool {
+		_, pages := renderPDF(t, inv, opts)
+		return strings.Contains(strings.Join(pages, "\n"), "(Scan to pay)")
+	}
+	if !hasQR(&PDFOptions{PaymentQR: true}) || hasQR(nil) {
+		t.Error("QR code not added with PaymentQR")
+	}
+	inv.InvoiceCurrencyCode = "USD"
+	if hasQR(&PDFOptions{PaymentQR: true}) {
+		t.Error("QR code added for a non-euro invoice")
+	}
+}
+
+func TestPDF_NotPDFA(t *testing.T) {
+	inv, err := einvoice.ParseXMLFile("../../testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	visual, _ := renderPDF(t, inv, nil)
+	var out bytes.Buffer
+	if err := inv.WritePDF(&out, bytes.NewReader(visual)); err != nil {
+		t.Fatalf("WritePDF() error = %v", err)
+	}
+	// The fonts are not embedded, so the PDF must not claim PDF/A conformance
+	_, err = einvoice.CheckPDF(bytes.NewReader(out.Bytes()))
+	var ve *einvoice.ValidationError
+	if !errors.As(err, &ve) || !slices.ContainsFunc(ve.Violations(), func(v einvoice.SemanticError) bool { return v.Rule.Code == "FX-PDF-11" }) {
+		t.Errorf("CheckPDF() = %v, want FX-PDF-11 (no PDF/A output intent)", err)
+	}
+}
+
+func TestEPCPayload(t *testing.T) {
+	inv, err := einvoice.ParseXMLFile("../../testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.PaymentMeans[0].PayeePartyCreditorFinancialAccountName = "De Koksmaat"
+	got, ok := epcPayload(inv)
+	if !ok {
+		t.Fatal("epcPayload() = false")
+	}
+	want := "BCD\n002\n1\nSCT\n\nDe Koksmaat\nNL57RABO0107307510\nEUR250.33\n\n\nDeb. 10202 / Fact. 12115118"
+	if got != want {
+		t.Errorf("epcPayload() = %q, want %q", got, want)
+	}
+
+	inv.PaymentReference = "RF18539007547034"
+	if got, _ := epcPayload(inv); !strings.HasSuffix(got, "\n\nRF18539007547034") {
+		t.Errorf("structured reference: %q", got)
+	}
+
+	inv.DuePayableAmount = decimal.Zero
+	if _, ok := epcPayload(inv); ok {
+		t.Error("epcPayload() = true for zero amount due")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("The quick brown fox jumps over the lazy dog", fontRegular, 10, 100)
+	if len(lines) < 2 {
+		t.Fatalf("wrapText() = %q", lines)
+	}
+	for _, l := range lines {
+		if w := fontRegular.width(l, 10); w > 100 {
+			t.Errorf("line %q is %.1f points wide", l, w)
+		}
+	}
+	if got := wrapText(strings.Repeat("x", 100), fontRegular, 10, 50); len(got) != 10 {
+		t.Errorf("long word wrapped into %d lines, want 10", len(got))
+	}
+	if got := truncateWidth("abcdefghijklmnop", fontRegular, 10, 30); got != "abc…" {
+		t.Errorf("truncateWidth() = %q", got)
+	}
+}
+
+func TestPDFTextString(t *testing.T) {
+	tests := map[string]string{
+		"a(b)":  `(a\(b\))`,
+		"Größe": "(Gr\xf6\xdfe)",
+		"10 €":  "(10 \x80)",
+		"日本":    "(??)",
+	}
+	for in, want := range tests {
+		if got := pdfTextString(in); got != want {
+			t.Errorf("pdfTextString(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
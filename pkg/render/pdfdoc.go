@@ -0,0 +1,155 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A4 page size in points.
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+)
+
+// pdfDoc is a minimal PDF writer for text, lines and filled rectangles with
+// the standard fonts.
+type pdfDoc struct {
+	pages []*pdfPage
+	title string
+	date  time.Time
+}
+
+// pdfPage collects the content stream of a page. The coordinates passed to
+// the drawing methods are measured from the top left corner.
+type pdfPage struct {
+	content strings.Builder
+}
+
+func (d *pdfDoc) newPage() *pdfPage {
+	p := &pdfPage{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// text draws s with the baseline at (x, y).
+func (p *pdfPage) text(x, y float64, font *pdfFont, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td %s Tj ET\n", font.name, pdfNum(size), pdfNum(x), pdfNum(pageHeight-y), pdfTextString(s))
+}
+
+// textRight draws s right aligned at x.
+func (p *pdfPage) textRight(x, y float64, font *pdfFont, size float64, s string) {
+	p.text(x-font.width(s, size), y, font, size, s)
+}
+
+// line draws a line from (x1, y1) to (x2, y2).
+func (p *pdfPage) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", pdfNum(width), pdfNum(x1), pdfNum(pageHeight-y1), pdfNum(x2), pdfNum(pageHeight-y2))
+}
+
+// rect fills a rectangle with the top left corner (x, y) in the given gray
+// level (0 black, 1 white).
+func (p *pdfPage) rect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "%s g %s %s %s %s re f 0 g\n", pdfNum(gray), pdfNum(x), pdfNum(pageHeight-y-h), pdfNum(w), pdfNum(h))
+}
+
+// pdfNum formats a number with at most two decimal places.
+func pdfNum(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// pdfDate formats t as PDF date string.
+func pdfDate(t time.Time) string {
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("D:%s%s%02d'%02d'", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
+}
+
+// pdfTextString encodes s in WinAnsiEncoding as a PDF string literal.
+func pdfTextString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		c, _ := winAnsi(r)
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// write writes the PDF to w.
+func (d *pdfDoc) write(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	// Object numbers: 1 catalog, 2 page tree, 3-4 fonts, 5 info, then page
+	// and content stream per page.
+	const firstPage = 6
+	numObjects := firstPage - 1 + 2*len(d.pages)
+	offsets := make([]int, numObjects+1)
+	obj := func(num int, body string) {
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", num, body)
+	}
+	stream := func(num int, dict string, data []byte) error {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(data); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n<< %s /Filter /FlateDecode /Length %d >>\nstream\n", num, dict, z.Len())
+		buf.Write(z.Bytes())
+		buf.WriteString("\nendstream\nendobj\n")
+		return nil
+	}
+
+	obj(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	obj(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for i, f := range []*pdfFont{fontRegular, fontBold} {
+		obj(3+i, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.base))
+	}
+	date := pdfDate(d.date)
+	obj(5, fmt.Sprintf("<< /Title %s /Producer (speedata einvoice) /CreationDate (%s) /ModDate (%s) >>", pdfTextString(d.title), date, date))
+	for i, p := range d.pages {
+		num := firstPage + 2*i
+		obj(num, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfNum(pageWidth), pdfNum(pageHeight), num+1))
+		if err := stream(num+1, "", []byte(p.content.String())); err != nil {
+			return err
+		}
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", numObjects+1)
+	for _, off := range offsets[1:] {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	id := fmt.Sprintf("%x", md5.Sum(buf.Bytes()))
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R /ID [<%s> <%s>] >>\nstartxref\n%d\n%%%%EOF\n", numObjects+1, id, id, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
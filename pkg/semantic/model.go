@@ -0,0 +1,454 @@
+package semantic
+
+// Locations of the CII header structures.
+const (
+	ciiRoot        = "/rsm:CrossIndustryInvoice"
+	ciiContext     = ciiRoot + "/rsm:ExchangedDocumentContext"
+	ciiDocument    = ciiRoot + "/rsm:ExchangedDocument"
+	ciiTransaction = ciiRoot + "/rsm:SupplyChainTradeTransaction"
+	ciiAgreement   = ciiTransaction + "/ram:ApplicableHeaderTradeAgreement"
+	ciiDelivery    = ciiTransaction + "/ram:ApplicableHeaderTradeDelivery"
+	ciiSettlement  = ciiTransaction + "/ram:ApplicableHeaderTradeSettlement"
+	ciiSummation   = ciiSettlement + "/ram:SpecifiedTradeSettlementHeaderMonetarySummation"
+	ublRoot        = "/Invoice"
+)
+
+// model is the EN 16931 semantic data model in the order of EN 16931-1,
+// table 2, followed by the EXTENDED terms used by this library. Groups precede
+// their terms. Paths of terms with a parent group are relative to the path of
+// the group unless they start with "/".
+var model = []Term{
+	// Invoice (BG-0)
+	{ID: "BT-1", Name: "Invoice number", DataType: Identifier, Cardinality: "1..1", Profile: Minimum,
+		Field: "InvoiceNumber", CII: ciiDocument + "/ram:ID", UBL: ublRoot + "/cbc:ID"},
+	{ID: "BT-2", Name: "Invoice issue date", DataType: Date, Cardinality: "1..1", Profile: Minimum,
+		Field: "InvoiceDate", CII: ciiDocument + "/ram:IssueDateTime/udt:DateTimeString", UBL: ublRoot + "/cbc:IssueDate"},
+	{ID: "BT-3", Name: "Invoice type code", DataType: Code, Cardinality: "1..1", Profile: Minimum,
+		Field: "InvoiceTypeCode", CII: ciiDocument + "/ram:TypeCode", UBL: ublRoot + "/cbc:InvoiceTypeCode"},
+	{ID: "BT-5", Name: "Invoice currency code", DataType: Code, Cardinality: "1..1", Profile: Minimum,
+		Field: "InvoiceCurrencyCode", CII: ciiSettlement + "/ram:InvoiceCurrencyCode", UBL: ublRoot + "/cbc:DocumentCurrencyCode"},
+	{ID: "BT-6", Name: "VAT accounting currency code", DataType: Code, Cardinality: "0..1", Profile: BasicWL,
+		Field: "TaxCurrencyCode", CII: ciiSettlement + "/ram:TaxCurrencyCode", UBL: ublRoot + "/cbc:TaxCurrencyCode"},
+	{ID: "BT-7", Name: "Value added tax point date", DataType: Date, Cardinality: "0..1", Profile: BasicWL,
+		Field: "TradeTaxes[].TaxPointDate", CII: ciiSettlement + "/ram:ApplicableTradeTax/ram:TaxPointDate/udt:DateString", UBL: ublRoot + "/cbc:TaxPointDate"},
+	{ID: "BT-8", Name: "Value added tax point date code", DataType: Code, Cardinality: "0..1", Profile: BasicWL,
+		Field: "TradeTaxes[].DueDateTypeCode", CII: ciiSettlement + "/ram:ApplicableTradeTax/ram:DueDateTypeCode", UBL: ublRoot + "/cac:InvoicePeriod/cbc:DescriptionCode"},
+	{ID: "BT-9", Name: "Payment due date", DataType: Date, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradePaymentTerms[].DueDate", CII: ciiSettlement + "/ram:SpecifiedTradePaymentTerms/ram:DueDateDateTime/udt:DateTimeString", UBL: ublRoot + "/cbc:DueDate"},
+	{ID: "BT-10", Name: "Buyer reference", DataType: Text, Cardinality: "0..1", Profile: Minimum,
+		Field: "BuyerReference", CII: ciiAgreement + "/ram:BuyerReference", UBL: ublRoot + "/cbc:BuyerReference"},
+	{ID: "BT-11", Name: "Project reference", DataType: DocumentReference, Cardinality: "0..1", Profile: EN16931,
+		Field: "SpecifiedProcuringProjectID", CII: ciiAgreement + "/ram:SpecifiedProcuringProject/ram:ID", UBL: ublRoot + "/cac:ProjectReference/cbc:ID"},
+	{ID: "BT-12", Name: "Contract reference", DataType: DocumentReference, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ContractReferencedDocument", CII: ciiAgreement + "/ram:ContractReferencedDocument/ram:IssuerAssignedID", UBL: ublRoot + "/cac:ContractDocumentReference/cbc:ID"},
+	{ID: "BT-13", Name: "Purchase order reference", DataType: DocumentReference, Cardinality: "0..1", Profile: Minimum,
+		Field: "BuyerOrderReferencedDocument", CII: ciiAgreement + "/ram:BuyerOrderReferencedDocument/ram:IssuerAssignedID", UBL: ublRoot + "/cac:OrderReference/cbc:ID"},
+	{ID: "BT-14", Name: "Sales order reference", DataType: DocumentReference, Cardinality: "0..1", Profile: EN16931,
+		Field: "SellerOrderReferencedDocument", CII: ciiAgreement + "/ram:SellerOrderReferencedDocument/ram:IssuerAssignedID", UBL: ublRoot + "/cac:OrderReference/cbc:SalesOrderID"},
+	{ID: "BT-15", Name: "Receiving advice reference", DataType: DocumentReference, Cardinality: "0..1", Profile: EN16931,
+		Field: "ReceivingAdviceReferencedDocument", CII: ciiDelivery + "/ram:ReceivingAdviceReferencedDocument/ram:IssuerAssignedID", UBL: ublRoot + "/cac:ReceiptDocumentReference/cbc:ID"},
+	{ID: "BT-16", Name: "Despatch advice reference", DataType: DocumentReference, Cardinality: "0..1", Profile: BasicWL,
+		Field: "DespatchAdviceReferencedDocument", CII: ciiDelivery + "/ram:DespatchAdviceReferencedDocument/ram:IssuerAssignedID", UBL: ublRoot + "/cac:DespatchDocumentReference/cbc:ID"},
+	{ID: "BT-17", Name: "Tender or lot reference", DataType: DocumentReference, Cardinality: "0..1", Profile: EN16931,
+		Field: "AdditionalReferencedDocument[].IssuerAssignedID", CII: ciiAgreement + "/ram:AdditionalReferencedDocument[ram:TypeCode='50']/ram:IssuerAssignedID", UBL: ublRoot + "/cac:OriginatorDocumentReference/cbc:ID"},
+	{ID: "BT-18", Name: "Invoiced object identifier", DataType: Identifier, Cardinality: "0..1", Profile: EN16931,
+		Field: "AdditionalReferencedDocument[].IssuerAssignedID", CII: ciiAgreement + "/ram:AdditionalReferencedDocument[ram:TypeCode='130']/ram:IssuerAssignedID", UBL: ublRoot + "/cac:AdditionalDocumentReference[cbc:DocumentTypeCode='130']/cbc:ID"},
+	{ID: "BT-19", Name: "Buyer accounting reference", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ReceivableSpecifiedTradeAccountingAccount", CII: ciiSettlement + "/ram:ReceivableSpecifiedTradeAccountingAccount/ram:ID", UBL: ublRoot + "/cbc:AccountingCost"},
+	{ID: "BT-20", Name: "Payment terms", DataType: Text, Cardinality: "0..1", Profile: BasicWL, cardinalities: map[Profile]string{Extended: "0..n"},
+		Field: "SpecifiedTradePaymentTerms[].Description", CII: ciiSettlement + "/ram:SpecifiedTradePaymentTerms/ram:Description", UBL: ublRoot + "/cac:PaymentTerms/cbc:Note"},
+
+	{ID: "BG-1", Name: "INVOICE NOTE", Cardinality: "0..n", Profile: BasicWL,
+		Field: "Notes[]", CII: ciiDocument + "/ram:IncludedNote", UBL: ublRoot + "/cbc:Note"},
+	{ID: "BT-21", Name: "Invoice note subject code", Parent: "BG-1", DataType: Code, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Notes[].SubjectCode", CII: "ram:SubjectCode", UBL: "."},
+	{ID: "BT-22", Name: "Invoice note", Parent: "BG-1", DataType: Text, Cardinality: "1..1", Profile: BasicWL,
+		Field: "Notes[].Text", CII: "ram:Content", UBL: "."},
+
+	{ID: "BG-2", Name: "PROCESS CONTROL", Cardinality: "1..1", Profile: Minimum,
+		CII: ciiContext, UBL: ublRoot},
+	{ID: "BT-23", Name: "Business process type", Parent: "BG-2", DataType: Text, Cardinality: "0..1", Profile: Minimum, cardinalities: map[Profile]string{Extended: "1..1"},
+		Field: "BPSpecifiedDocumentContextParameter", CII: "ram:BusinessProcessSpecifiedDocumentContextParameter/ram:ID", UBL: "cbc:ProfileID"},
+	{ID: "BT-24", Name: "Specification identifier", Parent: "BG-2", DataType: Identifier, Cardinality: "1..1", Profile: Minimum,
+		Field: "GuidelineSpecifiedDocumentContextParameter", CII: "ram:GuidelineSpecifiedDocumentContextParameter/ram:ID", UBL: "cbc:CustomizationID"},
+
+	{ID: "BG-3", Name: "PRECEDING INVOICE REFERENCE", Cardinality: "0..n", Profile: BasicWL,
+		Field: "InvoiceReferencedDocument[]", CII: ciiSettlement + "/ram:InvoiceReferencedDocument", UBL: ublRoot + "/cac:BillingReference/cac:InvoiceDocumentReference"},
+	{ID: "BT-25", Name: "Preceding Invoice reference", Parent: "BG-3", DataType: DocumentReference, Cardinality: "1..1", Profile: BasicWL,
+		Field: "InvoiceReferencedDocument[].ID", CII: "ram:IssuerAssignedID", UBL: "cbc:ID"},
+	{ID: "BT-26", Name: "Preceding Invoice issue date", Parent: "BG-3", DataType: Date, Cardinality: "0..1", Profile: BasicWL,
+		Field: "InvoiceReferencedDocument[].Date", CII: "ram:FormattedIssueDateTime/qdt:DateTimeString", UBL: "cbc:IssueDate"},
+
+	{ID: "BG-4", Name: "SELLER", Cardinality: "1..1", Profile: Minimum,
+		Field: "Seller", CII: ciiAgreement + "/ram:SellerTradeParty", UBL: ublRoot + "/cac:AccountingSupplierParty/cac:Party"},
+	{ID: "BT-27", Name: "Seller name", Parent: "BG-4", DataType: Text, Cardinality: "1..1", Profile: Minimum,
+		Field: "Seller.Name", CII: "ram:Name", UBL: "cac:PartyLegalEntity/cbc:RegistrationName"},
+	{ID: "BT-28", Name: "Seller trading name", Parent: "BG-4", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Seller.SpecifiedLegalOrganization.TradingBusinessName", CII: "ram:SpecifiedLegalOrganization/ram:TradingBusinessName", UBL: "cac:PartyName/cbc:Name"},
+	{ID: "BT-29", Name: "Seller identifier", Parent: "BG-4", DataType: Identifier, Cardinality: "0..n", Profile: BasicWL,
+		Field: "Seller.ID", CII: "ram:ID", UBL: "cac:PartyIdentification/cbc:ID"},
+	{ID: "BT-30", Name: "Seller legal registration identifier", Parent: "BG-4", DataType: Identifier, Cardinality: "0..1", Profile: Minimum,
+		Field: "Seller.SpecifiedLegalOrganization.ID", CII: "ram:SpecifiedLegalOrganization/ram:ID", UBL: "cac:PartyLegalEntity/cbc:CompanyID"},
+	{ID: "BT-31", Name: "Seller VAT identifier", Parent: "BG-4", DataType: Identifier, Cardinality: "0..1", Profile: Minimum,
+		Field: "Seller.VATaxRegistration", CII: "ram:SpecifiedTaxRegistration/ram:ID[@schemeID='VA']", UBL: "cac:PartyTaxScheme[cac:TaxScheme/cbc:ID='VAT']/cbc:CompanyID"},
+	{ID: "BT-32", Name: "Seller tax registration identifier", Parent: "BG-4", DataType: Identifier, Cardinality: "0..1", Profile: Minimum,
+		Field: "Seller.FCTaxRegistration", CII: "ram:SpecifiedTaxRegistration/ram:ID[@schemeID='FC']", UBL: "cac:PartyTaxScheme[cac:TaxScheme/cbc:ID!='VAT']/cbc:CompanyID"},
+	{ID: "BT-33", Name: "Seller additional legal information", Parent: "BG-4", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "Seller.Description", CII: "ram:Description", UBL: "cac:PartyLegalEntity/cbc:CompanyLegalForm"},
+	{ID: "BT-34", Name: "Seller electronic address", Parent: "BG-4", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Seller.URIUniversalCommunication", CII: "ram:URIUniversalCommunication/ram:URIID", UBL: "cbc:EndpointID"},
+
+	{ID: "BG-5", Name: "SELLER POSTAL ADDRESS", Parent: "BG-4", Cardinality: "1..1", Profile: Minimum,
+		Field: "Seller.PostalAddress", CII: "ram:PostalTradeAddress", UBL: "cac:PostalAddress"},
+	{ID: "BT-35", Name: "Seller address line 1", Parent: "BG-5", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Seller.PostalAddress.Line1", CII: "ram:LineOne", UBL: "cbc:StreetName"},
+	{ID: "BT-36", Name: "Seller address line 2", Parent: "BG-5", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Seller.PostalAddress.Line2", CII: "ram:LineTwo", UBL: "cbc:AdditionalStreetName"},
+	{ID: "BT-162", Name: "Seller address line 3", Parent: "BG-5", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Seller.PostalAddress.Line3", CII: "ram:LineThree", UBL: "cac:AddressLine/cbc:Line"},
+	{ID: "BT-37", Name: "Seller city", Parent: "BG-5", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Seller.PostalAddress.City", CII: "ram:CityName", UBL: "cbc:CityName"},
+	{ID: "BT-38", Name: "Seller post code", Parent: "BG-5", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Seller.PostalAddress.PostcodeCode", CII: "ram:PostcodeCode", UBL: "cbc:PostalZone"},
+	{ID: "BT-39", Name: "Seller country subdivision", Parent: "BG-5", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Seller.PostalAddress.CountrySubDivisionName", CII: "ram:CountrySubDivisionName", UBL: "cbc:CountrySubentity"},
+	{ID: "BT-40", Name: "Seller country code", Parent: "BG-5", DataType: Code, Cardinality: "1..1", Profile: Minimum,
+		Field: "Seller.PostalAddress.CountryID", CII: "ram:CountryID", UBL: "cac:Country/cbc:IdentificationCode"},
+
+	{ID: "BG-6", Name: "SELLER CONTACT", Parent: "BG-4", Cardinality: "0..1", Profile: EN16931,
+		Field: "Seller.DefinedTradeContact[]", CII: "ram:DefinedTradeContact", UBL: "cac:Contact"},
+	{ID: "BT-41", Name: "Seller contact point", Parent: "BG-6", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "Seller.DefinedTradeContact[].PersonName", CII: "ram:PersonName", UBL: "cbc:Name"},
+	{ID: "BT-42", Name: "Seller contact telephone number", Parent: "BG-6", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "Seller.DefinedTradeContact[].PhoneNumber", CII: "ram:TelephoneUniversalCommunication/ram:CompleteNumber", UBL: "cbc:Telephone"},
+	{ID: "BT-43", Name: "Seller contact email address", Parent: "BG-6", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "Seller.DefinedTradeContact[].EMail", CII: "ram:EmailURIUniversalCommunication/ram:URIID", UBL: "cbc:ElectronicMail"},
+
+	{ID: "BG-7", Name: "BUYER", Cardinality: "1..1", Profile: Minimum,
+		Field: "Buyer", CII: ciiAgreement + "/ram:BuyerTradeParty", UBL: ublRoot + "/cac:AccountingCustomerParty/cac:Party"},
+	{ID: "BT-44", Name: "Buyer name", Parent: "BG-7", DataType: Text, Cardinality: "1..1", Profile: Minimum,
+		Field: "Buyer.Name", CII: "ram:Name", UBL: "cac:PartyLegalEntity/cbc:RegistrationName"},
+	{ID: "BT-45", Name: "Buyer trading name", Parent: "BG-7", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.SpecifiedLegalOrganization.TradingBusinessName", CII: "ram:SpecifiedLegalOrganization/ram:TradingBusinessName", UBL: "cac:PartyName/cbc:Name"},
+	{ID: "BT-46", Name: "Buyer identifier", Parent: "BG-7", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.ID", CII: "ram:ID", UBL: "cac:PartyIdentification/cbc:ID"},
+	{ID: "BT-47", Name: "Buyer legal registration identifier", Parent: "BG-7", DataType: Identifier, Cardinality: "0..1", Profile: Minimum,
+		Field: "Buyer.SpecifiedLegalOrganization.ID", CII: "ram:SpecifiedLegalOrganization/ram:ID", UBL: "cac:PartyLegalEntity/cbc:CompanyID"},
+	{ID: "BT-48", Name: "Buyer VAT identifier", Parent: "BG-7", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.VATaxRegistration", CII: "ram:SpecifiedTaxRegistration/ram:ID[@schemeID='VA']", UBL: "cac:PartyTaxScheme/cbc:CompanyID"},
+	{ID: "BT-49", Name: "Buyer electronic address", Parent: "BG-7", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.URIUniversalCommunication", CII: "ram:URIUniversalCommunication/ram:URIID", UBL: "cbc:EndpointID"},
+
+	{ID: "BG-8", Name: "BUYER POSTAL ADDRESS", Parent: "BG-7", Cardinality: "1..1", Profile: BasicWL,
+		Field: "Buyer.PostalAddress", CII: "ram:PostalTradeAddress", UBL: "cac:PostalAddress"},
+	{ID: "BT-50", Name: "Buyer address line 1", Parent: "BG-8", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.PostalAddress.Line1", CII: "ram:LineOne", UBL: "cbc:StreetName"},
+	{ID: "BT-51", Name: "Buyer address line 2", Parent: "BG-8", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.PostalAddress.Line2", CII: "ram:LineTwo", UBL: "cbc:AdditionalStreetName"},
+	{ID: "BT-163", Name: "Buyer address line 3", Parent: "BG-8", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.PostalAddress.Line3", CII: "ram:LineThree", UBL: "cac:AddressLine/cbc:Line"},
+	{ID: "BT-52", Name: "Buyer city", Parent: "BG-8", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.PostalAddress.City", CII: "ram:CityName", UBL: "cbc:CityName"},
+	{ID: "BT-53", Name: "Buyer post code", Parent: "BG-8", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.PostalAddress.PostcodeCode", CII: "ram:PostcodeCode", UBL: "cbc:PostalZone"},
+	{ID: "BT-54", Name: "Buyer country subdivision", Parent: "BG-8", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "Buyer.PostalAddress.CountrySubDivisionName", CII: "ram:CountrySubDivisionName", UBL: "cbc:CountrySubentity"},
+	{ID: "BT-55", Name: "Buyer country code", Parent: "BG-8", DataType: Code, Cardinality: "1..1", Profile: BasicWL,
+		Field: "Buyer.PostalAddress.CountryID", CII: "ram:CountryID", UBL: "cac:Country/cbc:IdentificationCode"},
+
+	{ID: "BG-9", Name: "BUYER CONTACT", Parent: "BG-7", Cardinality: "0..1", Profile: EN16931,
+		Field: "Buyer.DefinedTradeContact[]", CII: "ram:DefinedTradeContact", UBL: "cac:Contact"},
+	{ID: "BT-56", Name: "Buyer contact point", Parent: "BG-9", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "Buyer.DefinedTradeContact[].PersonName", CII: "ram:PersonName", UBL: "cbc:Name"},
+	{ID: "BT-57", Name: "Buyer contact telephone number", Parent: "BG-9", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "Buyer.DefinedTradeContact[].PhoneNumber", CII: "ram:TelephoneUniversalCommunication/ram:CompleteNumber", UBL: "cbc:Telephone"},
+	{ID: "BT-58", Name: "Buyer contact email address", Parent: "BG-9", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "Buyer.DefinedTradeContact[].EMail", CII: "ram:EmailURIUniversalCommunication/ram:URIID", UBL: "cbc:ElectronicMail"},
+
+	{ID: "BG-10", Name: "PAYEE", Cardinality: "0..1", Profile: BasicWL,
+		Field: "PayeeTradeParty", CII: ciiSettlement + "/ram:PayeeTradeParty", UBL: ublRoot + "/cac:PayeeParty"},
+	{ID: "BT-59", Name: "Payee name", Parent: "BG-10", DataType: Text, Cardinality: "1..1", Profile: BasicWL,
+		Field: "PayeeTradeParty.Name", CII: "ram:Name", UBL: "cac:PartyName/cbc:Name"},
+	{ID: "BT-60", Name: "Payee identifier", Parent: "BG-10", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "PayeeTradeParty.ID", CII: "ram:ID", UBL: "cac:PartyIdentification/cbc:ID"},
+	{ID: "BT-61", Name: "Payee legal registration identifier", Parent: "BG-10", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "PayeeTradeParty.SpecifiedLegalOrganization.ID", CII: "ram:SpecifiedLegalOrganization/ram:ID", UBL: "cac:PartyLegalEntity/cbc:CompanyID"},
+
+	{ID: "BG-11", Name: "SELLER TAX REPRESENTATIVE PARTY", Cardinality: "0..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty", CII: ciiAgreement + "/ram:SellerTaxRepresentativeTradeParty", UBL: ublRoot + "/cac:TaxRepresentativeParty"},
+	{ID: "BT-62", Name: "Seller tax representative name", Parent: "BG-11", DataType: Text, Cardinality: "1..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.Name", CII: "ram:Name", UBL: "cac:PartyName/cbc:Name"},
+	{ID: "BT-63", Name: "Seller tax representative VAT identifier", Parent: "BG-11", DataType: Identifier, Cardinality: "1..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.VATaxRegistration", CII: "ram:SpecifiedTaxRegistration/ram:ID[@schemeID='VA']", UBL: "cac:PartyTaxScheme/cbc:CompanyID"},
+
+	{ID: "BG-12", Name: "SELLER TAX REPRESENTATIVE POSTAL ADDRESS", Parent: "BG-11", Cardinality: "1..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.PostalAddress", CII: "ram:PostalTradeAddress", UBL: "cac:PostalAddress"},
+	{ID: "BT-64", Name: "Tax representative address line 1", Parent: "BG-12", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.PostalAddress.Line1", CII: "ram:LineOne", UBL: "cbc:StreetName"},
+	{ID: "BT-65", Name: "Tax representative address line 2", Parent: "BG-12", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.PostalAddress.Line2", CII: "ram:LineTwo", UBL: "cbc:AdditionalStreetName"},
+	{ID: "BT-164", Name: "Tax representative address line 3", Parent: "BG-12", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.PostalAddress.Line3", CII: "ram:LineThree", UBL: "cac:AddressLine/cbc:Line"},
+	{ID: "BT-66", Name: "Tax representative city", Parent: "BG-12", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.PostalAddress.City", CII: "ram:CityName", UBL: "cbc:CityName"},
+	{ID: "BT-67", Name: "Tax representative post code", Parent: "BG-12", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.PostalAddress.PostcodeCode", CII: "ram:PostcodeCode", UBL: "cbc:PostalZone"},
+	{ID: "BT-68", Name: "Tax representative country subdivision", Parent: "BG-12", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.PostalAddress.CountrySubDivisionName", CII: "ram:CountrySubDivisionName", UBL: "cbc:CountrySubentity"},
+	{ID: "BT-69", Name: "Tax representative country code", Parent: "BG-12", DataType: Code, Cardinality: "1..1", Profile: BasicWL,
+		Field: "SellerTaxRepresentativeTradeParty.PostalAddress.CountryID", CII: "ram:CountryID", UBL: "cac:Country/cbc:IdentificationCode"},
+
+	{ID: "BG-13", Name: "DELIVERY INFORMATION", Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo", CII: ciiDelivery, UBL: ublRoot + "/cac:Delivery"},
+	{ID: "BT-70", Name: "Deliver to party name", Parent: "BG-13", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.Name", CII: "ram:ShipToTradeParty/ram:Name", UBL: "cac:DeliveryParty/cac:PartyName/cbc:Name"},
+	{ID: "BT-71", Name: "Deliver to location identifier", Parent: "BG-13", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.ID", CII: "ram:ShipToTradeParty/ram:ID", UBL: "cac:DeliveryLocation/cbc:ID"},
+	{ID: "BT-72", Name: "Actual delivery date", Parent: "BG-13", DataType: Date, Cardinality: "0..1", Profile: BasicWL,
+		Field: "OccurrenceDateTime", CII: "ram:ActualDeliverySupplyChainEvent/ram:OccurrenceDateTime/udt:DateTimeString", UBL: "cbc:ActualDeliveryDate"},
+
+	{ID: "BG-14", Name: "INVOICING PERIOD", Parent: "BG-13", Cardinality: "0..1", Profile: BasicWL,
+		CII: ciiSettlement + "/ram:BillingSpecifiedPeriod", UBL: ublRoot + "/cac:InvoicePeriod"},
+	{ID: "BT-73", Name: "Invoicing period start date", Parent: "BG-14", DataType: Date, Cardinality: "0..1", Profile: BasicWL,
+		Field: "BillingSpecifiedPeriodStart", CII: "ram:StartDateTime/udt:DateTimeString", UBL: "cbc:StartDate"},
+	{ID: "BT-74", Name: "Invoicing period end date", Parent: "BG-14", DataType: Date, Cardinality: "0..1", Profile: BasicWL,
+		Field: "BillingSpecifiedPeriodEnd", CII: "ram:EndDateTime/udt:DateTimeString", UBL: "cbc:EndDate"},
+
+	{ID: "BG-15", Name: "DELIVER TO ADDRESS", Parent: "BG-13", Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.PostalAddress", CII: "ram:ShipToTradeParty/ram:PostalTradeAddress", UBL: "cac:DeliveryLocation/cac:Address"},
+	{ID: "BT-75", Name: "Deliver to address line 1", Parent: "BG-15", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.PostalAddress.Line1", CII: "ram:LineOne", UBL: "cbc:StreetName"},
+	{ID: "BT-76", Name: "Deliver to address line 2", Parent: "BG-15", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.PostalAddress.Line2", CII: "ram:LineTwo", UBL: "cbc:AdditionalStreetName"},
+	{ID: "BT-165", Name: "Deliver to address line 3", Parent: "BG-15", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.PostalAddress.Line3", CII: "ram:LineThree", UBL: "cac:AddressLine/cbc:Line"},
+	{ID: "BT-77", Name: "Deliver to city", Parent: "BG-15", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.PostalAddress.City", CII: "ram:CityName", UBL: "cbc:CityName"},
+	{ID: "BT-78", Name: "Deliver to post code", Parent: "BG-15", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.PostalAddress.PostcodeCode", CII: "ram:PostcodeCode", UBL: "cbc:PostalZone"},
+	{ID: "BT-79", Name: "Deliver to country subdivision", Parent: "BG-15", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ShipTo.PostalAddress.CountrySubDivisionName", CII: "ram:CountrySubDivisionName", UBL: "cbc:CountrySubentity"},
+	{ID: "BT-80", Name: "Deliver to country code", Parent: "BG-15", DataType: Code, Cardinality: "1..1", Profile: BasicWL,
+		Field: "ShipTo.PostalAddress.CountryID", CII: "ram:CountryID", UBL: "cac:Country/cbc:IdentificationCode"},
+
+	{ID: "BG-16", Name: "PAYMENT INSTRUCTIONS", Cardinality: "0..1", Profile: BasicWL,
+		Field: "PaymentMeans[]", CII: ciiSettlement + "/ram:SpecifiedTradeSettlementPaymentMeans", UBL: ublRoot + "/cac:PaymentMeans"},
+	{ID: "BT-81", Name: "Payment means type code", Parent: "BG-16", DataType: Code, Cardinality: "1..1", Profile: BasicWL,
+		Field: "PaymentMeans[].TypeCode", CII: "ram:TypeCode", UBL: "cbc:PaymentMeansCode"},
+	{ID: "BT-82", Name: "Payment means text", Parent: "BG-16", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "PaymentMeans[].Information", CII: "ram:Information", UBL: "cbc:PaymentMeansCode/@name"},
+	{ID: "BT-83", Name: "Remittance information", Parent: "BG-16", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "PaymentReference", CII: ciiSettlement + "/ram:PaymentReference", UBL: "cbc:PaymentID"},
+
+	{ID: "BG-17", Name: "CREDIT TRANSFER", Parent: "BG-16", Cardinality: "0..n", Profile: BasicWL,
+		CII: "ram:PayeePartyCreditorFinancialAccount", UBL: "cac:PayeeFinancialAccount"},
+	{ID: "BT-84", Name: "Payment account identifier", Parent: "BG-17", DataType: Identifier, Cardinality: "1..1", Profile: BasicWL,
+		Field: "PaymentMeans[].PayeePartyCreditorFinancialAccountIBAN", CII: "ram:IBANID", UBL: "cbc:ID"},
+	{ID: "BT-85", Name: "Payment account name", Parent: "BG-17", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "PaymentMeans[].PayeePartyCreditorFinancialAccountName", CII: "ram:AccountName", UBL: "cbc:Name"},
+	{ID: "BT-86", Name: "Payment service provider identifier", Parent: "BG-17", DataType: Identifier, Cardinality: "0..1", Profile: EN16931,
+		Field: "PaymentMeans[].PayeeSpecifiedCreditorFinancialInstitutionBIC", CII: ciiSettlement + "/ram:SpecifiedTradeSettlementPaymentMeans/ram:PayeeSpecifiedCreditorFinancialInstitution/ram:BICID", UBL: "cac:FinancialInstitutionBranch/cbc:ID"},
+
+	{ID: "BG-18", Name: "PAYMENT CARD INFORMATION", Parent: "BG-16", Cardinality: "0..1", Profile: EN16931,
+		CII: "ram:ApplicableTradeSettlementFinancialCard", UBL: "cac:CardAccount"},
+	{ID: "BT-87", Name: "Payment card primary account number", Parent: "BG-18", DataType: Text, Cardinality: "1..1", Profile: EN16931,
+		Field: "PaymentMeans[].ApplicableTradeSettlementFinancialCardID", CII: "ram:ID", UBL: "cbc:PrimaryAccountNumberID"},
+	{ID: "BT-88", Name: "Payment card holder name", Parent: "BG-18", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "PaymentMeans[].ApplicableTradeSettlementFinancialCardCardholderName", CII: "ram:CardholderName", UBL: "cbc:HolderName"},
+
+	{ID: "BG-19", Name: "DIRECT DEBIT", Parent: "BG-16", Cardinality: "0..1", Profile: BasicWL,
+		UBL: "cac:PaymentMandate"},
+	{ID: "BT-89", Name: "Mandate reference identifier", Parent: "BG-19", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradePaymentTerms[].DirectDebitMandateID", CII: ciiSettlement + "/ram:SpecifiedTradePaymentTerms/ram:DirectDebitMandateID", UBL: "cbc:ID"},
+	{ID: "BT-90", Name: "Bank assigned creditor identifier", Parent: "BG-19", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "CreditorReferenceID", CII: ciiSettlement + "/ram:CreditorReferenceID", UBL: ublRoot + "/cac:AccountingSupplierParty/cac:Party/cac:PartyIdentification/cbc:ID[@schemeID='SEPA']"},
+	{ID: "BT-91", Name: "Debited account identifier", Parent: "BG-19", DataType: Identifier, Cardinality: "0..1", Profile: BasicWL,
+		Field: "PaymentMeans[].PayerPartyDebtorFinancialAccountIBAN", CII: ciiSettlement + "/ram:SpecifiedTradeSettlementPaymentMeans/ram:PayerPartyDebtorFinancialAccount/ram:IBANID", UBL: "cac:PayerFinancialAccount/cbc:ID"},
+
+	{ID: "BG-20", Name: "DOCUMENT LEVEL ALLOWANCES", Cardinality: "0..n", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[]", CII: ciiSettlement + "/ram:SpecifiedTradeAllowanceCharge[ram:ChargeIndicator/udt:Indicator='false']", UBL: ublRoot + "/cac:AllowanceCharge[cbc:ChargeIndicator='false']"},
+	{ID: "BT-92", Name: "Document level allowance amount", Parent: "BG-20", DataType: Amount, Cardinality: "1..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].ActualAmount", CII: "ram:ActualAmount", UBL: "cbc:Amount"},
+	{ID: "BT-93", Name: "Document level allowance base amount", Parent: "BG-20", DataType: Amount, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].BasisAmount", CII: "ram:BasisAmount", UBL: "cbc:BaseAmount"},
+	{ID: "BT-94", Name: "Document level allowance percentage", Parent: "BG-20", DataType: Percentage, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].CalculationPercent", CII: "ram:CalculationPercent", UBL: "cbc:MultiplierFactorNumeric"},
+	{ID: "BT-95", Name: "Document level allowance VAT category code", Parent: "BG-20", DataType: Code, Cardinality: "1..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].CategoryTradeTaxCategoryCode", CII: "ram:CategoryTradeTax/ram:CategoryCode", UBL: "cac:TaxCategory/cbc:ID"},
+	{ID: "BT-96", Name: "Document level allowance VAT rate", Parent: "BG-20", DataType: Percentage, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].CategoryTradeTaxRateApplicablePercent", CII: "ram:CategoryTradeTax/ram:RateApplicablePercent", UBL: "cac:TaxCategory/cbc:Percent"},
+	{ID: "BT-97", Name: "Document level allowance reason", Parent: "BG-20", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].Reason", CII: "ram:Reason", UBL: "cbc:AllowanceChargeReason"},
+	{ID: "BT-98", Name: "Document level allowance reason code", Parent: "BG-20", DataType: Code, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].ReasonCode", CII: "ram:ReasonCode", UBL: "cbc:AllowanceChargeReasonCode"},
+
+	{ID: "BG-21", Name: "DOCUMENT LEVEL CHARGES", Cardinality: "0..n", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[]", CII: ciiSettlement + "/ram:SpecifiedTradeAllowanceCharge[ram:ChargeIndicator/udt:Indicator='true']", UBL: ublRoot + "/cac:AllowanceCharge[cbc:ChargeIndicator='true']"},
+	{ID: "BT-99", Name: "Document level charge amount", Parent: "BG-21", DataType: Amount, Cardinality: "1..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].ActualAmount", CII: "ram:ActualAmount", UBL: "cbc:Amount"},
+	{ID: "BT-100", Name: "Document level charge base amount", Parent: "BG-21", DataType: Amount, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].BasisAmount", CII: "ram:BasisAmount", UBL: "cbc:BaseAmount"},
+	{ID: "BT-101", Name: "Document level charge percentage", Parent: "BG-21", DataType: Percentage, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].CalculationPercent", CII: "ram:CalculationPercent", UBL: "cbc:MultiplierFactorNumeric"},
+	{ID: "BT-102", Name: "Document level charge VAT category code", Parent: "BG-21", DataType: Code, Cardinality: "1..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].CategoryTradeTaxCategoryCode", CII: "ram:CategoryTradeTax/ram:CategoryCode", UBL: "cac:TaxCategory/cbc:ID"},
+	{ID: "BT-103", Name: "Document level charge VAT rate", Parent: "BG-21", DataType: Percentage, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].CategoryTradeTaxRateApplicablePercent", CII: "ram:CategoryTradeTax/ram:RateApplicablePercent", UBL: "cac:TaxCategory/cbc:Percent"},
+	{ID: "BT-104", Name: "Document level charge reason", Parent: "BG-21", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].Reason", CII: "ram:Reason", UBL: "cbc:AllowanceChargeReason"},
+	{ID: "BT-105", Name: "Document level charge reason code", Parent: "BG-21", DataType: Code, Cardinality: "0..1", Profile: BasicWL,
+		Field: "SpecifiedTradeAllowanceCharge[].ReasonCode", CII: "ram:ReasonCode", UBL: "cbc:AllowanceChargeReasonCode"},
+
+	{ID: "BG-22", Name: "DOCUMENT TOTALS", Cardinality: "1..1", Profile: Minimum,
+		CII: ciiSummation, UBL: ublRoot + "/cac:LegalMonetaryTotal"},
+	{ID: "BT-106", Name: "Sum of Invoice line net amount", Parent: "BG-22", DataType: Amount, Cardinality: "1..1", Profile: BasicWL,
+		Field: "LineTotal", CII: "ram:LineTotalAmount", UBL: "cbc:LineExtensionAmount"},
+	{ID: "BT-107", Name: "Sum of allowances on document level", Parent: "BG-22", DataType: Amount, Cardinality: "0..1", Profile: BasicWL,
+		Field: "AllowanceTotal", CII: "ram:AllowanceTotalAmount", UBL: "cbc:AllowanceTotalAmount"},
+	{ID: "BT-108", Name: "Sum of charges on document level", Parent: "BG-22", DataType: Amount, Cardinality: "0..1", Profile: BasicWL,
+		Field: "ChargeTotal", CII: "ram:ChargeTotalAmount", UBL: "cbc:ChargeTotalAmount"},
+	{ID: "BT-109", Name: "Invoice total amount without VAT", Parent: "BG-22", DataType: Amount, Cardinality: "1..1", Profile: Minimum,
+		Field: "TaxBasisTotal", CII: "ram:TaxBasisTotalAmount", UBL: "cbc:TaxExclusiveAmount"},
+	{ID: "BT-110", Name: "Invoice total VAT amount", Parent: "BG-22", DataType: Amount, Cardinality: "0..1", Profile: Minimum,
+		Field: "TaxTotal", CII: "ram:TaxTotalAmount[@currencyID=../../ram:InvoiceCurrencyCode]", UBL: ublRoot + "/cac:TaxTotal/cbc:TaxAmount[@currencyID=/Invoice/cbc:DocumentCurrencyCode]"},
+	{ID: "BT-111", Name: "Invoice total VAT amount in accounting currency", Parent: "BG-22", DataType: Amount, Cardinality: "0..1", Profile: BasicWL,
+		Field: "TaxTotalAccounting", CII: "ram:TaxTotalAmount[@currencyID=../../ram:TaxCurrencyCode]", UBL: ublRoot + "/cac:TaxTotal/cbc:TaxAmount[@currencyID=/Invoice/cbc:TaxCurrencyCode]"},
+	{ID: "BT-112", Name: "Invoice total amount with VAT", Parent: "BG-22", DataType: Amount, Cardinality: "1..1", Profile: Minimum,
+		Field: "GrandTotal", CII: "ram:GrandTotalAmount", UBL: "cbc:TaxInclusiveAmount"},
+	{ID: "BT-113", Name: "Paid amount", Parent: "BG-22", DataType: Amount, Cardinality: "0..1", Profile: BasicWL,
+		Field: "TotalPrepaid", CII: "ram:TotalPrepaidAmount", UBL: "cbc:PrepaidAmount"},
+	{ID: "BT-114", Name: "Rounding amount", Parent: "BG-22", DataType: Amount, Cardinality: "0..1", Profile: EN16931,
+		Field: "RoundingAmount", CII: "ram:RoundingAmount", UBL: "cbc:PayableRoundingAmount"},
+	{ID: "BT-115", Name: "Amount due for payment", Parent: "BG-22", DataType: Amount, Cardinality: "1..1", Profile: Minimum,
+		Field: "DuePayableAmount", CII: "ram:DuePayableAmount", UBL: "cbc:PayableAmount"},
+
+	{ID: "BG-23", Name: "VAT BREAKDOWN", Cardinality: "1..n", Profile: BasicWL,
+		Field: "TradeTaxes[]", CII: ciiSettlement + "/ram:ApplicableTradeTax", UBL: ublRoot + "/cac:TaxTotal/cac:TaxSubtotal"},
+	{ID: "BT-116", Name: "VAT category taxable amount", Parent: "BG-23", DataType: Amount, Cardinality: "1..1", Profile: BasicWL,
+		Field: "TradeTaxes[].BasisAmount", CII: "ram:BasisAmount", UBL: "cbc:TaxableAmount"},
+	{ID: "BT-117", Name: "VAT category tax amount", Parent: "BG-23", DataType: Amount, Cardinality: "1..1", Profile: BasicWL,
+		Field: "TradeTaxes[].CalculatedAmount", CII: "ram:CalculatedAmount", UBL: "cbc:TaxAmount"},
+	{ID: "BT-118", Name: "VAT category code", Parent: "BG-23", DataType: Code, Cardinality: "1..1", Profile: BasicWL,
+		Field: "TradeTaxes[].CategoryCode", CII: "ram:CategoryCode", UBL: "cac:TaxCategory/cbc:ID"},
+	{ID: "BT-119", Name: "VAT category rate", Parent: "BG-23", DataType: Percentage, Cardinality: "0..1", Profile: BasicWL,
+		Field: "TradeTaxes[].Percent", CII: "ram:RateApplicablePercent", UBL: "cac:TaxCategory/cbc:Percent"},
+	{ID: "BT-120", Name: "VAT exemption reason text", Parent: "BG-23", DataType: Text, Cardinality: "0..1", Profile: BasicWL,
+		Field: "TradeTaxes[].ExemptionReason", CII: "ram:ExemptionReason", UBL: "cac:TaxCategory/cbc:TaxExemptionReason"},
+	{ID: "BT-121", Name: "VAT exemption reason code", Parent: "BG-23", DataType: Code, Cardinality: "0..1", Profile: BasicWL,
+		Field: "TradeTaxes[].ExemptionReasonCode", CII: "ram:ExemptionReasonCode", UBL: "cac:TaxCategory/cbc:TaxExemptionReasonCode"},
+
+	{ID: "BG-24", Name: "ADDITIONAL SUPPORTING DOCUMENTS", Cardinality: "0..n", Profile: EN16931,
+		Field: "AdditionalReferencedDocument[]", CII: ciiAgreement + "/ram:AdditionalReferencedDocument[ram:TypeCode='916']", UBL: ublRoot + "/cac:AdditionalDocumentReference"},
+	{ID: "BT-122", Name: "Supporting document reference", Parent: "BG-24", DataType: DocumentReference, Cardinality: "1..1", Profile: EN16931,
+		Field: "AdditionalReferencedDocument[].IssuerAssignedID", CII: "ram:IssuerAssignedID", UBL: "cbc:ID"},
+	{ID: "BT-123", Name: "Supporting document description", Parent: "BG-24", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "AdditionalReferencedDocument[].Name", CII: "ram:Name", UBL: "cbc:DocumentDescription"},
+	{ID: "BT-124", Name: "External document location", Parent: "BG-24", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "AdditionalReferencedDocument[].URIID", CII: "ram:URIID", UBL: "cac:Attachment/cac:ExternalReference/cbc:URI"},
+	{ID: "BT-125", Name: "Attached document", Parent: "BG-24", DataType: BinaryObject, Cardinality: "0..1", Profile: EN16931,
+		Field: "AdditionalReferencedDocument[].AttachmentBinaryObject", CII: "ram:AttachmentBinaryObject", UBL: "cac:Attachment/cbc:EmbeddedDocumentBinaryObject"},
+
+	{ID: "BG-25", Name: "INVOICE LINE", Cardinality: "1..n", Profile: Basic,
+		Field: "InvoiceLines[]", CII: ciiTransaction + "/ram:IncludedSupplyChainTradeLineItem", UBL: ublRoot + "/cac:InvoiceLine"},
+	{ID: "BT-126", Name: "Invoice line identifier", Parent: "BG-25", DataType: Identifier, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].LineID", CII: "ram:AssociatedDocumentLineDocument/ram:LineID", UBL: "cbc:ID"},
+	{ID: "BT-127", Name: "Invoice line note", Parent: "BG-25", DataType: Text, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].Note", CII: "ram:AssociatedDocumentLineDocument/ram:IncludedNote/ram:Content", UBL: "cbc:Note"},
+	{ID: "BT-128", Name: "Invoice line object identifier", Parent: "BG-25", DataType: Identifier, Cardinality: "0..1", Profile: EN16931,
+		Field: "InvoiceLines[].AdditionalReferencedDocumentID", CII: "ram:SpecifiedLineTradeSettlement/ram:AdditionalReferencedDocument/ram:IssuerAssignedID", UBL: "cac:DocumentReference/cbc:ID"},
+	{ID: "BT-129", Name: "Invoiced quantity", Parent: "BG-25", DataType: Quantity, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].BilledQuantity", CII: "ram:SpecifiedLineTradeDelivery/ram:BilledQuantity", UBL: "cbc:InvoicedQuantity"},
+	{ID: "BT-130", Name: "Invoiced quantity unit of measure code", Parent: "BG-25", DataType: Code, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].BilledQuantityUnit", CII: "ram:SpecifiedLineTradeDelivery/ram:BilledQuantity/@unitCode", UBL: "cbc:InvoicedQuantity/@unitCode"},
+	{ID: "BT-131", Name: "Invoice line net amount", Parent: "BG-25", DataType: Amount, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].Total", CII: "ram:SpecifiedLineTradeSettlement/ram:SpecifiedTradeSettlementLineMonetarySummation/ram:LineTotalAmount", UBL: "cbc:LineExtensionAmount"},
+	{ID: "BT-132", Name: "Referenced purchase order line reference", Parent: "BG-25", DataType: DocumentReference, Cardinality: "0..1", Profile: EN16931,
+		Field: "InvoiceLines[].BuyerOrderReferencedDocument", CII: "ram:SpecifiedLineTradeAgreement/ram:BuyerOrderReferencedDocument/ram:LineID", UBL: "cac:OrderLineReference/cbc:LineID"},
+	{ID: "BT-133", Name: "Invoice line Buyer accounting reference", Parent: "BG-25", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "InvoiceLines[].ReceivableSpecifiedTradeAccountingAccount", CII: "ram:SpecifiedLineTradeSettlement/ram:ReceivableSpecifiedTradeAccountingAccount/ram:ID", UBL: "cbc:AccountingCost"},
+
+	{ID: "BG-26", Name: "INVOICE LINE PERIOD", Parent: "BG-25", Cardinality: "0..1", Profile: Basic,
+		CII: "ram:SpecifiedLineTradeSettlement/ram:BillingSpecifiedPeriod", UBL: "cac:InvoicePeriod"},
+	{ID: "BT-134", Name: "Invoice line period start date", Parent: "BG-26", DataType: Date, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].BillingSpecifiedPeriodStart", CII: "ram:StartDateTime/udt:DateTimeString", UBL: "cbc:StartDate"},
+	{ID: "BT-135", Name: "Invoice line period end date", Parent: "BG-26", DataType: Date, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].BillingSpecifiedPeriodEnd", CII: "ram:EndDateTime/udt:DateTimeString", UBL: "cbc:EndDate"},
+
+	{ID: "BG-27", Name: "INVOICE LINE ALLOWANCES", Parent: "BG-25", Cardinality: "0..n", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineAllowances[]", CII: "ram:SpecifiedLineTradeSettlement/ram:SpecifiedTradeAllowanceCharge[ram:ChargeIndicator/udt:Indicator='false']", UBL: "cac:AllowanceCharge[cbc:ChargeIndicator='false']"},
+	{ID: "BT-136", Name: "Invoice line allowance amount", Parent: "BG-27", DataType: Amount, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineAllowances[].ActualAmount", CII: "ram:ActualAmount", UBL: "cbc:Amount"},
+	{ID: "BT-137", Name: "Invoice line allowance base amount", Parent: "BG-27", DataType: Amount, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineAllowances[].BasisAmount", CII: "ram:BasisAmount", UBL: "cbc:BaseAmount"},
+	{ID: "BT-138", Name: "Invoice line allowance percentage", Parent: "BG-27", DataType: Percentage, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineAllowances[].CalculationPercent", CII: "ram:CalculationPercent", UBL: "cbc:MultiplierFactorNumeric"},
+	{ID: "BT-139", Name: "Invoice line allowance reason", Parent: "BG-27", DataType: Text, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineAllowances[].Reason", CII: "ram:Reason", UBL: "cbc:AllowanceChargeReason"},
+	{ID: "BT-140", Name: "Invoice line allowance reason code", Parent: "BG-27", DataType: Code, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineAllowances[].ReasonCode", CII: "ram:ReasonCode", UBL: "cbc:AllowanceChargeReasonCode"},
+
+	{ID: "BG-28", Name: "INVOICE LINE CHARGES", Parent: "BG-25", Cardinality: "0..n", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineCharges[]", CII: "ram:SpecifiedLineTradeSettlement/ram:SpecifiedTradeAllowanceCharge[ram:ChargeIndicator/udt:Indicator='true']", UBL: "cac:AllowanceCharge[cbc:ChargeIndicator='true']"},
+	{ID: "BT-141", Name: "Invoice line charge amount", Parent: "BG-28", DataType: Amount, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineCharges[].ActualAmount", CII: "ram:ActualAmount", UBL: "cbc:Amount"},
+	{ID: "BT-142", Name: "Invoice line charge base amount", Parent: "BG-28", DataType: Amount, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineCharges[].BasisAmount", CII: "ram:BasisAmount", UBL: "cbc:BaseAmount"},
+	{ID: "BT-143", Name: "Invoice line charge percentage", Parent: "BG-28", DataType: Percentage, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineCharges[].CalculationPercent", CII: "ram:CalculationPercent", UBL: "cbc:MultiplierFactorNumeric"},
+	{ID: "BT-144", Name: "Invoice line charge reason", Parent: "BG-28", DataType: Text, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineCharges[].Reason", CII: "ram:Reason", UBL: "cbc:AllowanceChargeReason"},
+	{ID: "BT-145", Name: "Invoice line charge reason code", Parent: "BG-28", DataType: Code, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].InvoiceLineCharges[].ReasonCode", CII: "ram:ReasonCode", UBL: "cbc:AllowanceChargeReasonCode"},
+
+	{ID: "BG-29", Name: "PRICE DETAILS", Parent: "BG-25", Cardinality: "1..1", Profile: Basic,
+		CII: "ram:SpecifiedLineTradeAgreement", UBL: "cac:Price"},
+	{ID: "BT-146", Name: "Item net price", Parent: "BG-29", DataType: UnitPriceAmount, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].NetPrice", CII: "ram:NetPriceProductTradePrice/ram:ChargeAmount", UBL: "cbc:PriceAmount"},
+	{ID: "BT-147", Name: "Item price discount", Parent: "BG-29", DataType: UnitPriceAmount, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].AppliedTradeAllowanceCharge[].ActualAmount", CII: "ram:GrossPriceProductTradePrice/ram:AppliedTradeAllowanceCharge/ram:ActualAmount", UBL: "cac:AllowanceCharge/cbc:Amount"},
+	{ID: "BT-148", Name: "Item gross price", Parent: "BG-29", DataType: UnitPriceAmount, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].GrossPrice", CII: "ram:GrossPriceProductTradePrice/ram:ChargeAmount", UBL: "cac:AllowanceCharge/cbc:BaseAmount"},
+	{ID: "BT-149", Name: "Item price base quantity", Parent: "BG-29", DataType: Quantity, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].BasisQuantity", CII: "ram:NetPriceProductTradePrice/ram:BasisQuantity", UBL: "cbc:BaseQuantity"},
+	{ID: "BT-150", Name: "Item price base quantity unit of measure code", Parent: "BG-29", DataType: Code, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].BasisQuantityUnit", CII: "ram:NetPriceProductTradePrice/ram:BasisQuantity/@unitCode", UBL: "cbc:BaseQuantity/@unitCode"},
+
+	{ID: "BG-30", Name: "LINE VAT INFORMATION", Parent: "BG-25", Cardinality: "1..1", Profile: Basic,
+		CII: "ram:SpecifiedLineTradeSettlement/ram:ApplicableTradeTax", UBL: "cac:Item/cac:ClassifiedTaxCategory"},
+	{ID: "BT-151", Name: "Invoiced item VAT category code", Parent: "BG-30", DataType: Code, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].TaxCategoryCode", CII: "ram:CategoryCode", UBL: "cbc:ID"},
+	{ID: "BT-152", Name: "Invoiced item VAT rate", Parent: "BG-30", DataType: Percentage, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].TaxRateApplicablePercent", CII: "ram:RateApplicablePercent", UBL: "cbc:Percent"},
+
+	{ID: "BG-31", Name: "ITEM INFORMATION", Parent: "BG-25", Cardinality: "1..1", Profile: Basic,
+		CII: "ram:SpecifiedTradeProduct", UBL: "cac:Item"},
+	{ID: "BT-153", Name: "Item name", Parent: "BG-31", DataType: Text, Cardinality: "1..1", Profile: Basic,
+		Field: "InvoiceLines[].ItemName", CII: "ram:Name", UBL: "cbc:Name"},
+	{ID: "BT-154", Name: "Item description", Parent: "BG-31", DataType: Text, Cardinality: "0..1", Profile: EN16931,
+		Field: "InvoiceLines[].Description", CII: "ram:Description", UBL: "cbc:Description"},
+	{ID: "BT-155", Name: "Item Seller's identifier", Parent: "BG-31", DataType: Identifier, Cardinality: "0..1", Profile: EN16931,
+		Field: "InvoiceLines[].ArticleNumber", CII: "ram:SellerAssignedID", UBL: "cac:SellersItemIdentification/cbc:ID"},
+	{ID: "BT-156", Name: "Item Buyer's identifier", Parent: "BG-31", DataType: Identifier, Cardinality: "0..1", Profile: EN16931,
+		Field: "InvoiceLines[].ArticleNumberBuyer", CII: "ram:BuyerAssignedID", UBL: "cac:BuyersItemIdentification/cbc:ID"},
+	{ID: "BT-157", Name: "Item standard identifier", Parent: "BG-31", DataType: Identifier, Cardinality: "0..1", Profile: Basic,
+		Field: "InvoiceLines[].GlobalID", CII: "ram:GlobalID", UBL: "cac:StandardItemIdentification/cbc:ID"},
+	{ID: "BT-158", Name: "Item classification identifier", Parent: "BG-31", DataType: Identifier, Cardinality: "0..n", Profile: EN16931,
+		Field: "InvoiceLines[].ProductClassification[].ClassCode", CII: "ram:DesignatedProductClassification/ram:ClassCode", UBL: "cac:CommodityClassification/cbc:ItemClassificationCode"},
+	{ID: "BT-159", Name: "Item country of origin", Parent: "BG-31", DataType: Code, Cardinality: "0..1", Profile: EN16931,
+		Field: "InvoiceLines[].OriginTradeCountry", CII: "ram:OriginTradeCountry/ram:ID", UBL: "cac:OriginCountry/cbc:IdentificationCode"},
+
+	{ID: "BG-32", Name: "ITEM ATTRIBUTES", Parent: "BG-31", Cardinality: "0..n", Profile: EN16931,
+		Field: "InvoiceLines[].Characteristics[]", CII: "ram:ApplicableProductCharacteristic", UBL: "cac:AdditionalItemProperty"},
+	{ID: "BT-160", Name: "Item attribute name", Parent: "BG-32", DataType: Text, Cardinality: "1..1", Profile: EN16931,
+		Field: "InvoiceLines[].Characteristics[].Description", CII: "ram:Description", UBL: "cbc:Name"},
+	{ID: "BT-161", Name: "Item attribute value", Parent: "BG-32", DataType: Text, Cardinality: "1..1", Profile: EN16931,
+		Field: "InvoiceLines[].Characteristics[].Value", CII: "ram:Value", UBL: "cbc:Value"},
+
+	// EXTENDED terms for sub invoice lines, not part of EN 16931 and UBL.
+	{ID: "BT-X-7", Name: "Invoice line status code", Parent: "BG-25", DataType: Code, Cardinality: "0..1", Profile: Extended,
+		Field: "InvoiceLines[].LineStatusCode", CII: "ram:AssociatedDocumentLineDocument/ram:LineStatusCode"},
+	{ID: "BT-X-8", Name: "Invoice line status reason code", Parent: "BG-25", DataType: Code, Cardinality: "0..1", Profile: Extended,
+		Field: "InvoiceLines[].LineStatusReasonCode", CII: "ram:AssociatedDocumentLineDocument/ram:LineStatusReasonCode"},
+	{ID: "BT-X-304", Name: "Parent line identifier", Parent: "BG-25", DataType: Identifier, Cardinality: "0..1", Profile: Extended,
+		Field: "InvoiceLines[].ParentLineID", CII: "ram:AssociatedDocumentLineDocument/ram:ParentLineID"},
+}
@@ -0,0 +1,246 @@
+// Package semantic provides a machine-readable registry of the EN 16931
+// semantic data model: every business term (BT) and business group (BG) with
+// its name, data type, cardinality per Factur-X profile, the field of the
+// einvoice.Invoice struct and the location in CII and UBL documents.
+//
+// The registry can be used to build mappers, to locate errors reported by the
+// business rules (see the Fields of rules.Rule) and to label user interfaces.
+//
+// The UBL paths refer to an Invoice document. For a CreditNote replace
+// Invoice by CreditNote, cac:InvoiceLine by cac:CreditNoteLine and
+// cbc:InvoicedQuantity by cbc:CreditedQuantity.
+package semantic
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Profile is a Factur-X / ZUGFeRD profile, ordered from the smallest to the
+// most comprehensive one.
+type Profile int
+
+// The Factur-X profiles. EN16931 also covers XRechnung and PEPPOL BIS Billing
+// 3.0 which are based on the full EN 16931 data model.
+const (
+	Minimum Profile = iota + 1
+	BasicWL
+	Basic
+	EN16931
+	Extended
+)
+
+// Profiles lists all profiles in ascending order.
+var Profiles = []Profile{Minimum, BasicWL, Basic, EN16931, Extended}
+
+// String returns the name of the profile.
+func (p Profile) String() string {
+	switch p {
+	case Minimum:
+		return "Minimum"
+	case BasicWL:
+		return "Basic WL"
+	case Basic:
+		return "Basic"
+	case EN16931:
+		return "EN 16931"
+	case Extended:
+		return "Extended"
+	}
+	return "Unknown"
+}
+
+// DataType is the semantic data type of a business term (EN 16931-1, 6.5).
+type DataType string
+
+// The semantic data types. Business groups have the data type Group.
+const (
+	Group             DataType = "Group"
+	Amount            DataType = "Amount"
+	UnitPriceAmount   DataType = "Unit Price Amount"
+	Quantity          DataType = "Quantity"
+	Percentage        DataType = "Percentage"
+	Code              DataType = "Code"
+	Identifier        DataType = "Identifier"
+	DocumentReference DataType = "Document Reference"
+	Date              DataType = "Date"
+	Text              DataType = "Text"
+	BinaryObject      DataType = "Binary Object"
+)
+
+// Term is a business term or business group of the semantic data model.
+type Term struct {
+	ID       string   // "BT-27" or "BG-4"
+	Name     string   // "Seller name"
+	Parent   string   // enclosing business group, empty for the invoice itself
+	DataType DataType // Group for business groups
+	// Cardinality is the cardinality in EN 16931 such as "1..1" or "0..n".
+	Cardinality string
+	// Profile is the smallest profile the term is part of.
+	Profile Profile
+	// Field is the path of the field in einvoice.Invoice such as
+	// "Seller.PostalAddress.City". Repeated structs are marked with [].
+	// It is empty if the term has no field of its own.
+	Field string
+	// CII and UBL are the XPath expressions of the term, empty if the syntax
+	// has no element for the term.
+	CII string
+	UBL string
+
+	// cardinalities overrides Cardinality for single profiles.
+	cardinalities map[Profile]string
+}
+
+// IsGroup reports whether t is a business group.
+func (t Term) IsGroup() bool {
+	return strings.HasPrefix(t.ID, "BG-")
+}
+
+// CardinalityIn returns the cardinality of the term in profile p. It returns
+// "0..0" if the term is not part of the profile.
+func (t Term) CardinalityIn(p Profile) string {
+	if p < t.Profile || p > Extended {
+		return "0..0"
+	}
+	if c, ok := t.cardinalities[p]; ok {
+		return c
+	}
+	return t.Cardinality
+}
+
+// Mandatory reports whether the term must be present in profile p as long
+// as its parent group is present.
+func (t Term) Mandatory(p Profile) bool {
+	return strings.HasPrefix(t.CardinalityIn(p), "1")
+}
+
+// Lookup returns the term with the identifier id ("BT-27", "BG-4"). The
+// identifier is case insensitive and may omit the hyphen ("bt27").
+func Lookup(id string) (Term, bool) {
+	i, ok := index[normalize(id)]
+	if !ok {
+		return Term{}, false
+	}
+	return terms[i], true
+}
+
+// Name returns the name of the term with the identifier id.
+// Returns "Unknown" if the term is not found.
+func Name(id string) string {
+	if t, ok := Lookup(id); ok {
+		return t.Name
+	}
+	return "Unknown"
+}
+
+// All returns all terms in the order of the semantic data model.
+func All() []Term {
+	return append([]Term(nil), terms...)
+}
+
+// Children returns the terms and groups directly contained in group id.
+func Children(id string) []Term {
+	id = normalize(id)
+	var children []Term
+	for _, t := range terms {
+		if t.Parent == id {
+			children = append(children, t)
+		}
+	}
+	return children
+}
+
+// InProfile returns the terms which are part of profile p.
+func InProfile(p Profile) []Term {
+	var ret []Term
+	for _, t := range terms {
+		if t.CardinalityIn(p) != "0..0" {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+// ByField returns the terms mapped to the einvoice.Invoice field path. Indexes
+// of repeated structs are ignored, "InvoiceLines[2].ItemName" finds BT-153.
+func ByField(field string) []Term {
+	field = stripIndexes(field)
+	var ret []Term
+	for _, t := range terms {
+		if t.Field != "" && t.Field == field {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+// normalize converts the identifier to the canonical form "BT-27".
+func normalize(id string) string {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	for _, prefix := range []string{"BT-X", "BT", "BG"} {
+		if rest, ok := strings.CutPrefix(id, prefix); ok {
+			return prefix + "-" + strings.TrimPrefix(rest, "-")
+		}
+	}
+	return id
+}
+
+// stripIndexes removes the indexes from a field path: "A[1].B" becomes "A[].B".
+func stripIndexes(field string) string {
+	var sb strings.Builder
+	for {
+		open := strings.IndexByte(field, '[')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(field[open:], ']')
+		if end < 0 {
+			break
+		}
+		sb.WriteString(field[:open+1])
+		if _, err := strconv.Atoi(field[open+1 : open+end]); err != nil {
+			sb.WriteString(field[open+1 : open+end])
+		}
+		field = field[open+end:]
+	}
+	sb.WriteString(field)
+	return sb.String()
+}
+
+// terms is the registry in model order, index maps the identifiers to terms.
+var (
+	terms []Term
+	index map[string]int
+)
+
+func init() {
+	terms = make([]Term, 0, len(model))
+	index = make(map[string]int, len(model))
+	for _, t := range model {
+		// Relative paths are resolved against the parent group.
+		if t.Parent != "" {
+			parent := terms[index[t.Parent]]
+			t.CII = resolve(parent.CII, t.CII)
+			t.UBL = resolve(parent.UBL, t.UBL)
+		}
+		if t.DataType == "" {
+			t.DataType = Group
+		}
+		index[t.ID] = len(terms)
+		terms = append(terms, t)
+	}
+}
+
+// resolve returns the XPath rel relative to base. Absolute and empty paths are
+// returned unchanged, "." stands for base itself.
+func resolve(base, rel string) string {
+	switch {
+	case rel == "" || strings.HasPrefix(rel, "/"):
+		return rel
+	case rel == ".":
+		return base
+	case base == "":
+		return rel
+	}
+	return base + "/" + rel
+}
@@ -0,0 +1,255 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/speedata/cxpath"
+	"github.com/speedata/einvoice"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		id     string
+		name   string
+		parent string
+		cii    string
+		ubl    string
+	}{
+		{"BT-1", "Invoice number", "", "/rsm:CrossIndustryInvoice/rsm:ExchangedDocument/ram:ID", "/Invoice/cbc:ID"},
+		{"bt27", "Seller name", "BG-4", "/rsm:CrossIndustryInvoice/rsm:SupplyChainTradeTransaction/ram:ApplicableHeaderTradeAgreement/ram:SellerTradeParty/ram:Name", "/Invoice/cac:AccountingSupplierParty/cac:Party/cac:PartyLegalEntity/cbc:RegistrationName"},
+		{"BT-40", "Seller country code", "BG-5", "/rsm:CrossIndustryInvoice/rsm:SupplyChainTradeTransaction/ram:ApplicableHeaderTradeAgreement/ram:SellerTradeParty/ram:PostalTradeAddress/ram:CountryID", "/Invoice/cac:AccountingSupplierParty/cac:Party/cac:PostalAddress/cac:Country/cbc:IdentificationCode"},
+		{"BT-22", "Invoice note", "BG-1", "/rsm:CrossIndustryInvoice/rsm:ExchangedDocument/ram:IncludedNote/ram:Content", "/Invoice/cbc:Note"},
+		{"BT-153", "Item name", "BG-31", "/rsm:CrossIndustryInvoice/rsm:SupplyChainTradeTransaction/ram:IncludedSupplyChainTradeLineItem/ram:SpecifiedTradeProduct/ram:Name", "/Invoice/cac:InvoiceLine/cac:Item/cbc:Name"},
+		{"BT-X-8", "Invoice line status reason code", "BG-25", "/rsm:CrossIndustryInvoice/rsm:SupplyChainTradeTransaction/ram:IncludedSupplyChainTradeLineItem/ram:AssociatedDocumentLineDocument/ram:LineStatusReasonCode", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			term, ok := Lookup(tt.id)
+			if !ok {
+				t.Fatalf("Lookup(%q) not found", tt.id)
+			}
+			if term.Name != tt.name || term.Parent != tt.parent {
+				t.Errorf("Lookup(%q) = %q in %q, want %q in %q", tt.id, term.Name, term.Parent, tt.name, tt.parent)
+			}
+			if term.CII != tt.cii {
+				t.Errorf("CII = %q, want %q", term.CII, tt.cii)
+			}
+			if term.UBL != tt.ubl {
+				t.Errorf("UBL = %q, want %q", term.UBL, tt.ubl)
+			}
+		})
+	}
+
+	if _, ok := Lookup("BT-999"); ok {
+		t.Error("Lookup(BT-999) found")
+	}
+	if got := Name("BT-999"); got != "Unknown" {
+		t.Errorf("Name(BT-999) = %q, want Unknown", got)
+	}
+}
+
+func TestCardinalityIn(t *testing.T) {
+	tests := []struct {
+		id   string
+		want [5]string // Minimum, BasicWL, Basic, EN16931, Extended
+	}{
+		{"BT-1", [5]string{"1..1", "1..1", "1..1", "1..1", "1..1"}},
+		{"BT-23", [5]string{"0..1", "0..1", "0..1", "0..1", "1..1"}},
+		{"BT-20", [5]string{"0..0", "0..1", "0..1", "0..1", "0..n"}},
+		{"BG-23", [5]string{"0..0", "1..n", "1..n", "1..n", "1..n"}},
+		{"BG-25", [5]string{"0..0", "0..0", "1..n", "1..n", "1..n"}},
+		{"BT-154", [5]string{"0..0", "0..0", "0..0", "0..1", "0..1"}},
+		{"BT-X-304", [5]string{"0..0", "0..0", "0..0", "0..0", "0..1"}},
+	}
+	for _, tt := range tests {
+		term, ok := Lookup(tt.id)
+		if !ok {
+			t.Fatalf("Lookup(%q) not found", tt.id)
+		}
+		for i, p := range Profiles {
+			if got := term.CardinalityIn(p); got != tt.want[i] {
+				t.Errorf("%s in %s = %s, want %s", tt.id, p, got, tt.want[i])
+			}
+		}
+	}
+	if term, _ := Lookup("BT-27"); !term.Mandatory(Minimum) {
+		t.Error("BT-27 not mandatory in Minimum")
+	}
+}
+
+func TestModelConsistency(t *testing.T) {
+	seen := map[string]bool{}
+	for _, term := range All() {
+		if seen[term.ID] {
+			t.Errorf("%s defined twice", term.ID)
+		}
+		seen[term.ID] = true
+		if term.Name == "" || term.Cardinality == "" || term.Profile == 0 {
+			t.Errorf("%s incomplete: %+v", term.ID, term)
+		}
+		if term.IsGroup() != (term.DataType == Group) {
+			t.Errorf("%s has data type %s", term.ID, term.DataType)
+		}
+		if term.Parent != "" {
+			parent, ok := Lookup(term.Parent)
+			switch {
+			case !ok || !parent.IsGroup():
+				t.Errorf("%s: parent %s is not a group", term.ID, term.Parent)
+			case term.Profile < parent.Profile:
+				t.Errorf("%s is part of %s but its group %s is not", term.ID, term.Profile, term.Parent)
+			}
+		}
+	}
+	// BT-1 to BT-165 and BG-1 to BG-32
+	for i := 1; i <= 165; i++ {
+		if id := "BT-" + strconv.Itoa(i); i != 4 && !seen[id] {
+			t.Errorf("%s missing", id)
+		}
+	}
+	for i := 1; i <= 32; i++ {
+		if id := "BG-" + strconv.Itoa(i); !seen[id] {
+			t.Errorf("%s missing", id)
+		}
+	}
+}
+
+// TestRuleFields makes sure that every term referenced by a business rule
+// can be explained.
+func TestRuleFields(t *testing.T) {
+	files, err := filepath.Glob("../../rules/*.go")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no rule files: %v", err)
+	}
+	re := regexp.MustCompile(`"(B[TG]-[0-9X-]+)"`)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range re.FindAllStringSubmatch(string(data), -1) {
+			if _, ok := Lookup(m[1]); !ok {
+				t.Errorf("%s: %s not in the registry", filepath.Base(file), m[1])
+			}
+		}
+	}
+}
+
+// TestFields makes sure that every field path resolves to a field of
+// einvoice.Invoice.
+func TestFields(t *testing.T) {
+	for _, term := range All() {
+		if term.Field == "" {
+			continue
+		}
+		typ := reflect.TypeFor[einvoice.Invoice]()
+		for name := range strings.SplitSeq(term.Field, ".") {
+			name, repeated := strings.CutSuffix(name, "[]")
+			if typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+			if typ.Kind() != reflect.Struct {
+				t.Errorf("%s: %s is not a struct", term.ID, typ)
+				break
+			}
+			f, ok := typ.FieldByName(name)
+			if !ok || !f.IsExported() {
+				t.Errorf("%s: %s has no field %s", term.ID, typ, name)
+				break
+			}
+			typ = f.Type
+			if repeated {
+				if typ.Kind() != reflect.Slice {
+					t.Errorf("%s: %s is %s, not a slice", term.ID, name, typ)
+					break
+				}
+				typ = typ.Elem()
+			}
+		}
+	}
+}
+
+func TestByField(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"InvoiceNumber", "BT-1"},
+		{"InvoiceLines[2].ItemName", "BT-153"},
+		{"InvoiceLines[0].Characteristics[1].Value", "BT-161"},
+		{"Seller.PostalAddress.CountryID", "BT-40"},
+	}
+	for _, tt := range tests {
+		got := ByField(tt.field)
+		if len(got) != 1 || got[0].ID != tt.want {
+			t.Errorf("ByField(%q) = %v, want %s", tt.field, got, tt.want)
+		}
+	}
+	if got := ByField("SpecifiedTradeAllowanceCharge[1].ActualAmount"); len(got) != 2 {
+		t.Errorf("ByField(allowance amount) = %d terms, want BT-92 and BT-99", len(got))
+	}
+}
+
+// TestPaths evaluates the paths against the test fixtures. Every path must
+// match at least once unless the fixtures lack the term.
+func TestPaths(t *testing.T) {
+	notInFixtures := map[string]bool{
+		"CII BT-164": true, "CII BT-165": true, "CII BT-114": true, "CII BT-X-7": true,
+		"CII BG-18": true, "CII BT-87": true, "CII BT-88": true,
+		"UBL BG-18": true, "UBL BT-87": true, "UBL BT-88": true,
+		"UBL BT-90": true, "UBL BT-162": true, "UBL BT-163": true,
+	}
+	var cii, ubl []*cxpath.Context
+	err := filepath.WalkDir("../../testdata", func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".xml") {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		ctx, err := cxpath.NewFromReader(f)
+		if err != nil {
+			return nil // not well-formed on purpose
+		}
+		ctx.SetNamespace("rsm", "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100")
+		ctx.SetNamespace("ram", "urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100")
+		ctx.SetNamespace("udt", "urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100")
+		ctx.SetNamespace("qdt", "urn:un:unece:uncefact:data:standard:QualifiedDataType:100")
+		ctx.SetNamespace("inv", "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2")
+		ctx.SetNamespace("cac", "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2")
+		ctx.SetNamespace("cbc", "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2")
+		switch {
+		case ctx.Eval("count(/rsm:CrossIndustryInvoice)").Int() > 0:
+			cii = append(cii, ctx)
+		case ctx.Eval("count(/inv:Invoice)").Int() > 0:
+			ubl = append(ubl, ctx)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := func(docs []*cxpath.Context, path string) int {
+		n := 0
+		for _, doc := range docs {
+			n += doc.Eval("count(" + path + ")").Int()
+		}
+		return n
+	}
+	for _, term := range All() {
+		if term.CII != "" && count(cii, term.CII) == 0 && !notInFixtures["CII "+term.ID] {
+			t.Errorf("%s: CII path %s not found", term.ID, term.CII)
+		}
+		// The UBL paths use the unprefixed root element.
+		if term.UBL != "" && count(ubl, strings.ReplaceAll(term.UBL, "/Invoice", "/inv:Invoice")) == 0 && !notInFixtures["UBL "+term.ID] {
+			t.Errorf("%s: UBL path %s not found", term.ID, term.UBL)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package einvoice
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice/rules"
+)
+
+// DeductPrepayments deducts prepayment invoices (type code 386) from the final
+// invoice: the invoice total amounts with VAT (BT-112) of the prepayment
+// invoices are added to the paid amount (BT-113), the prepayment invoices are
+// referenced as preceding invoices (BG-3) and the amount due for payment
+// (BT-115) is updated. In the Extended profile each prepayment is also added
+// to AdvancePayments with its net amount and VAT per category and rate, so
+// that the receiver can deduct the prepaid VAT per rate.
+//
+// The final invoice invoices the whole supply, its VAT breakdown (BG-23) is
+// not reduced by the prepayments. Call DeductPrepayments before the totals are
+// calculated with UpdateTotalsWithOptions if the amount due is cash rounded.
+// ValidatePrepayments checks a final invoice against the prepayment invoices.
+//
+// A prepayment invoice can only be deducted once. DeductPrepayments returns an
+// error for a prepayment invoice it has deducted before or that is listed in
+// AdvancePayments.
+func (inv *Invoice) DeductPrepayments(prepayments ...*Invoice) error {
+	for i, p := range prepayments {
+		if p.InvoiceTypeCode != 386 {
+			return fmt.Errorf("invoice %s has type code %d, not a prepayment invoice (386)", p.InvoiceNumber, p.InvoiceTypeCode)
+		}
+		if p.InvoiceNumber == "" {
+			return fmt.Errorf("prepayment invoice has no invoice number (BT-1)")
+		}
+		if p.InvoiceCurrencyCode != inv.InvoiceCurrencyCode {
+			return fmt.Errorf("prepayment invoice %s has currency %s, the final invoice %s", p.InvoiceNumber, p.InvoiceCurrencyCode, inv.InvoiceCurrencyCode)
+		}
+		if slices.Contains(inv.deductedPrepayments, p.InvoiceNumber) ||
+			slices.ContainsFunc(inv.AdvancePayments, func(ap AdvancePayment) bool { return ap.InvoiceID == p.InvoiceNumber }) ||
+			slices.ContainsFunc(prepayments[:i], func(o *Invoice) bool { return o.InvoiceNumber == p.InvoiceNumber }) {
+			return fmt.Errorf("prepayment invoice %s is already deducted", p.InvoiceNumber)
+		}
+	}
+
+	for _, p := range prepayments {
+		if !slices.ContainsFunc(inv.InvoiceReferencedDocument, func(ref ReferencedDocument) bool { return ref.ID == p.InvoiceNumber }) {
+			inv.InvoiceReferencedDocument = append(inv.InvoiceReferencedDocument, ReferencedDocument{ID: p.InvoiceNumber, Date: p.InvoiceDate})
+		}
+		if inv.IsExtended() {
+			ap := AdvancePayment{
+				PaidAmount:  p.GrandTotal,
+				InvoiceID:   p.InvoiceNumber,
+				InvoiceDate: p.InvoiceDate,
+			}
+			for _, tt := range p.TradeTaxes {
+				ap.TradeTaxes = append(ap.TradeTaxes, TradeTax{
+					BasisAmount:         tt.BasisAmount,
+					CalculatedAmount:    tt.CalculatedAmount,
+					TypeCode:            tt.TypeCode,
+					CategoryCode:        tt.CategoryCode,
+					Percent:             tt.Percent,
+					ExemptionReason:     tt.ExemptionReason,
+					ExemptionReasonCode: tt.ExemptionReasonCode,
+				})
+			}
+			inv.AdvancePayments = append(inv.AdvancePayments, ap)
+		}
+		inv.TotalPrepaid = inv.TotalPrepaid.Add(p.GrandTotal)
+		inv.deductedPrepayments = append(inv.deductedPrepayments, p.InvoiceNumber)
+	}
+	// BR-CO-16
+	inv.DuePayableAmount = roundHalfUp(inv.GrandTotal.Sub(inv.TotalPrepaid).Add(inv.RoundingAmount), 2)
+	return nil
+}
+
+// ValidatePrepayments checks that the final invoice deducts exactly the given
+// prepayment invoices (BR-USER-10): the paid amount (BT-113) is the sum of
+// their invoice total amounts with VAT, each of them is referenced as
+// preceding invoice (BG-3) and its VAT categories occur in the VAT breakdown
+// of the final invoice. Prepayments given per category (AdvancePayments) must
+// match the amounts and the VAT of the prepayment invoices. It returns a
+// *ValidationError with the violations or nil.
+func (inv *Invoice) ValidatePrepayments(prepayments ...*Invoice) error {
+	ve := &ValidationError{}
+	add := func(format string, a ...any) {
+		ve.violations = append(ve.violations, SemanticError{Rule: rules.BRUSER10, Text: fmt.Sprintf(format, a...)})
+	}
+
+	sum := decimal.Zero
+	for _, p := range prepayments {
+		sum = sum.Add(p.GrandTotal)
+		if p.InvoiceTypeCode != 386 {
+			add("Invoice %s has type code %d, not a prepayment invoice (386)", p.InvoiceNumber, p.InvoiceTypeCode)
+		}
+		if p.InvoiceCurrencyCode != inv.InvoiceCurrencyCode {
+			add("Prepayment invoice %s has currency %s, the final invoice %s", p.InvoiceNumber, p.InvoiceCurrencyCode, inv.InvoiceCurrencyCode)
+		}
+		if !slices.ContainsFunc(inv.InvoiceReferencedDocument, func(ref ReferencedDocument) bool { return ref.ID == p.InvoiceNumber }) {
+			add("Prepayment invoice %s is not referenced as preceding invoice (BG-3)", p.InvoiceNumber)
+		}
+		for _, tt := range p.TradeTaxes {
+			if len(inv.TradeTaxes) > 0 && !slices.ContainsFunc(inv.TradeTaxes, tt.sameCategory) {
+				add("VAT category %s of prepayment invoice %s is not in the VAT breakdown of the final invoice", tt.categoryName(), p.InvoiceNumber)
+			}
+		}
+
+		if len(inv.AdvancePayments) == 0 {
+			continue
+		}
+		i := slices.IndexFunc(inv.AdvancePayments, func(ap AdvancePayment) bool { return ap.InvoiceID == p.InvoiceNumber })
+		if i < 0 {
+			add("No prepayment given for prepayment invoice %s", p.InvoiceNumber)
+			continue
+		}
+		ap := inv.AdvancePayments[i]
+		if !ap.PaidAmount.Equal(p.GrandTotal) {
+			add("Prepayment %s paid amount %s does not match the invoice total amount with VAT %s of the prepayment invoice", p.InvoiceNumber, ap.PaidAmount.StringFixed(2), p.GrandTotal.StringFixed(2))
+		}
+		for _, tt := range p.TradeTaxes {
+			j := slices.IndexFunc(ap.TradeTaxes, tt.sameCategory)
+			if j < 0 {
+				add("Prepayment %s has no VAT for category %s", p.InvoiceNumber, tt.categoryName())
+				continue
+			}
+			if !ap.TradeTaxes[j].CalculatedAmount.Equal(tt.CalculatedAmount) {
+				add("Prepayment %s VAT %s for category %s does not match the VAT %s of the prepayment invoice", p.InvoiceNumber, ap.TradeTaxes[j].CalculatedAmount.StringFixed(2), tt.categoryName(), tt.CalculatedAmount.StringFixed(2))
+			}
+		}
+	}
+	for _, ap := range inv.AdvancePayments {
+		if !slices.ContainsFunc(prepayments, func(p *Invoice) bool { return p.InvoiceNumber == ap.InvoiceID }) {
+			add("Prepayment %s does not belong to the given prepayment invoices", ap.InvoiceID)
+		}
+	}
+	if !inv.TotalPrepaid.Equal(sum) {
+		add("Paid amount %s does not match the sum %s of the prepayment invoices", inv.TotalPrepaid.StringFixed(2), sum.StringFixed(2))
+	}
+
+	if len(ve.violations) > 0 {
+		return ve
+	}
+	return nil
+}
+
+// validateAdvancePayments checks that the prepayments add up to the paid
+// amount (BR-USER-09).
+func (inv *Invoice) validateAdvancePayments() {
+	if len(inv.AdvancePayments) == 0 {
+		return
+	}
+	sum := decimal.Zero
+	for _, ap := range inv.AdvancePayments {
+		sum = sum.Add(ap.PaidAmount)
+	}
+	if !sum.Equal(inv.TotalPrepaid) {
+		inv.addViolation(rules.BRUSER09, fmt.Sprintf("Paid amount %s does not match the sum %s of the prepayments", inv.TotalPrepaid.StringFixed(2), sum.StringFixed(2)))
+	}
+}
+
+// sameCategory reports whether o has the VAT category and rate of tt.
+func (tt TradeTax) sameCategory(o TradeTax) bool {
+	return tt.CategoryCode == o.CategoryCode && tt.Percent.Equal(o.Percent)
+}
+
+// categoryName returns the VAT category code and rate such as "S 19%".
+func (tt TradeTax) categoryName() string {
+	return tt.CategoryCode + " " + formatPercent(tt.Percent) + "%"
+}
@@ -0,0 +1,205 @@
+package einvoice
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// prepaymentInvoices returns two prepayment invoices (386): 1000.00 at 19 %
+// and 500.00 at 19 % plus 200.00 at 7 %.
+func prepaymentInvoices(t *testing.T, profile string) (*Invoice, *Invoice) {
+	t.Helper()
+	p1, err := builderInvoice(profile).Number("AR-2025-001").TypeCode(386).
+		VAT(VATStandard(decimal.NewFromInt(19))).
+		AddLine(InvoiceLine{ItemName: "First down payment", BilledQuantity: decimal.NewFromInt(1), NetPrice: decimal.NewFromInt(1000)}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	p2, err := builderInvoice(profile).Number("AR-2025-002").TypeCode(386).
+		VAT(VATStandard(decimal.NewFromInt(19))).
+		AddLine(InvoiceLine{ItemName: "Second down payment", BilledQuantity: decimal.NewFromInt(1), NetPrice: decimal.NewFromInt(500)}).
+		VAT(VATStandard(decimal.NewFromInt(7))).
+		AddLine(InvoiceLine{ItemName: "Second down payment books", BilledQuantity: decimal.NewFromInt(1), NetPrice: decimal.NewFromInt(200)}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	return p1, p2
+}
+
+// finalInvoice returns a builder for the final invoice over 4000.00 at 19 %
+// and 1000.00 at 7 %.
+func finalInvoice(profile string) *InvoiceBuilder {
+	return builderInvoice(profile).Number("RE-2025-010").
+		VAT(VATStandard(decimal.NewFromInt(19))).
+		AddLine(InvoiceLine{ItemName: "Construction work", BilledQuantity: decimal.NewFromInt(8), NetPrice: decimal.NewFromInt(500)}).
+		VAT(VATStandard(decimal.NewFromInt(7))).
+		AddLine(InvoiceLine{ItemName: "Books", BilledQuantity: decimal.NewFromInt(10), NetPrice: decimal.NewFromInt(100)})
+}
+
+func TestDeductPrepayments(t *testing.T) {
+	p1, p2 := prepaymentInvoices(t, SpecFacturXExtended)
+	inv, err := finalInvoice(SpecFacturXExtended).DeductPrepayments(p1, p2).Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+
+	// 1190.00 + 595.00 + 214.00
+	if !inv.TotalPrepaid.Equal(decimal.RequireFromString("1999")) {
+		t.Errorf("TotalPrepaid = %s, want 1999.00", inv.TotalPrepaid)
+	}
+	// 4760.00 + 1070.00 - 1999.00
+	if !inv.DuePayableAmount.Equal(decimal.RequireFromString("3831")) {
+		t.Errorf("DuePayableAmount = %s, want 3831.00", inv.DuePayableAmount)
+	}
+	// The VAT breakdown covers the whole supply
+	if !inv.TaxTotal.Equal(decimal.NewFromInt(830)) {
+		t.Errorf("TaxTotal = %s, want 830.00", inv.TaxTotal)
+	}
+	if len(inv.InvoiceReferencedDocument) != 2 || inv.InvoiceReferencedDocument[1].ID != "AR-2025-002" || !inv.InvoiceReferencedDocument[1].Date.Equal(p2.InvoiceDate) {
+		t.Errorf("InvoiceReferencedDocument = %+v", inv.InvoiceReferencedDocument)
+	}
+	if len(inv.AdvancePayments) != 2 || len(inv.AdvancePayments[1].TradeTaxes) != 2 {
+		t.Fatalf("AdvancePayments = %+v", inv.AdvancePayments)
+	}
+	if ap := inv.AdvancePayments[1]; !ap.PaidAmount.Equal(decimal.NewFromInt(809)) || !ap.TradeTaxes[1].CalculatedAmount.Equal(decimal.NewFromInt(14)) {
+		t.Errorf("AdvancePayments[1] = %+v", ap)
+	}
+
+	// The prepayments survive the CII round trip
+	parsed, err := ParseReader(strings.NewReader(writeWithOptions(t, inv, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Errorf("Validate() after round trip = %v", err)
+	}
+	if len(parsed.AdvancePayments) != 2 || parsed.AdvancePayments[0].InvoiceID != "AR-2025-001" || !parsed.AdvancePayments[1].TradeTaxes[1].Percent.Equal(decimal.NewFromInt(7)) {
+		t.Errorf("parsed AdvancePayments = %+v", parsed.AdvancePayments)
+	}
+	if err := parsed.ValidatePrepayments(p1, p2); err != nil {
+		t.Errorf("ValidatePrepayments() after round trip = %v", err)
+	}
+
+	// Content of the Extended profile only
+	report, err := parsed.ConvertProfile(SpecEN16931)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.AdvancePayments != nil || !slices.ContainsFunc(report.Changes, func(c ProfileChange) bool { return c.Field == "AdvancePayments" }) {
+		t.Errorf("ConvertProfile() kept AdvancePayments, changes %v", report.Changes)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Errorf("Validate() after conversion = %v", err)
+	}
+}
+
+func TestDeductPrepayments_EN16931(t *testing.T) {
+	p1, p2 := prepaymentInvoices(t, SpecEN16931)
+	inv, err := finalInvoice(SpecEN16931).DeductPrepayments(p1, p2).Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	if len(inv.AdvancePayments) != 0 || len(inv.InvoiceReferencedDocument) != 2 || !inv.TotalPrepaid.Equal(decimal.RequireFromString("1999")) {
+		t.Errorf("prepaid %s, references %+v, advance payments %+v", inv.TotalPrepaid, inv.InvoiceReferencedDocument, inv.AdvancePayments)
+	}
+	if xml := writeWithOptions(t, inv, nil); strings.Contains(xml, "SpecifiedAdvancePayment") {
+		t.Error("SpecifiedAdvancePayment written in EN 16931 profile")
+	}
+}
+
+func TestDeductPrepayments_Twice(t *testing.T) {
+	p1, p2 := prepaymentInvoices(t, SpecEN16931)
+	inv, err := finalInvoice(SpecEN16931).DeductPrepayments(p1).Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	if err := inv.DeductPrepayments(p2, p1); err == nil {
+		t.Error("DeductPrepayments() deducted AR-2025-001 twice")
+	}
+	// 1190.00, the rejected call changes nothing
+	if !inv.TotalPrepaid.Equal(decimal.RequireFromString("1190")) || len(inv.InvoiceReferencedDocument) != 1 {
+		t.Errorf("prepaid %s, references %+v", inv.TotalPrepaid, inv.InvoiceReferencedDocument)
+	}
+	if err := inv.DeductPrepayments(p2); err != nil {
+		t.Errorf("DeductPrepayments(AR-2025-002) = %v", err)
+	}
+	if !inv.TotalPrepaid.Equal(decimal.RequireFromString("1999")) {
+		t.Errorf("TotalPrepaid = %s, want 1999.00", inv.TotalPrepaid)
+	}
+}
+
+func TestDeductPrepayments_Errors(t *testing.T) {
+	p1, _ := prepaymentInvoices(t, SpecFacturXExtended)
+	invoice := p1.clone()
+	invoice.InvoiceTypeCode = 380
+	usd := p1.clone()
+	usd.InvoiceCurrencyCode = "USD"
+
+	for _, prepayments := range [][]*Invoice{{invoice}, {usd}, {p1, p1}} {
+		inv, err := finalInvoice(SpecFacturXExtended).Build()
+		if err != nil {
+			t.Fatalf("Build() = %v", err)
+		}
+		if err := inv.DeductPrepayments(prepayments...); err == nil {
+			t.Errorf("DeductPrepayments(%s) succeeded, want error", prepayments[0].InvoiceNumber)
+		}
+	}
+}
+
+func TestValidatePrepayments(t *testing.T) {
+	p1, p2 := prepaymentInvoices(t, SpecFacturXExtended)
+
+	tests := []struct {
+		name     string
+		modify   func(*Invoice)
+		payments []*Invoice
+		want     string
+	}{
+		{"prepayment missing", nil, []*Invoice{p1}, "Paid amount 1999.00 does not match the sum 1190.00"},
+		{"unknown prepayment", nil, []*Invoice{p1}, "Prepayment AR-2025-002 does not belong"},
+		{"reference missing", func(inv *Invoice) { inv.InvoiceReferencedDocument = inv.InvoiceReferencedDocument[:1] }, []*Invoice{p1, p2}, "AR-2025-002 is not referenced"},
+		{"VAT mismatch", func(inv *Invoice) { inv.AdvancePayments[1].TradeTaxes[1].CalculatedAmount = decimal.NewFromInt(38) }, []*Invoice{p1, p2}, "VAT 38.00 for category S 7% does not match"},
+		{"category missing", func(inv *Invoice) {
+			inv.TradeTaxes = inv.TradeTaxes[:1]
+		}, []*Invoice{p1, p2}, "VAT category S 7% of prepayment invoice AR-2025-002 is not in the VAT breakdown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := finalInvoice(SpecFacturXExtended).DeductPrepayments(p1, p2).Build()
+			if err != nil {
+				t.Fatalf("Build() = %v", err)
+			}
+			if tt.modify != nil {
+				tt.modify(inv)
+			}
+			err = inv.ValidatePrepayments(tt.payments...)
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("ValidatePrepayments() = %v, want *ValidationError", err)
+			}
+			if !slices.ContainsFunc(ve.Violations(), func(v SemanticError) bool {
+				return v.Rule.Code == "BR-USER-10" && strings.Contains(v.Text, tt.want)
+			}) {
+				t.Errorf("violations = %v, want %q", ve.Violations(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAdvancePayments(t *testing.T) {
+	p1, p2 := prepaymentInvoices(t, SpecFacturXExtended)
+	inv, err := finalInvoice(SpecFacturXExtended).DeductPrepayments(p1, p2).Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	inv.AdvancePayments = inv.AdvancePayments[:1]
+	if err := inv.Validate(); err == nil || !hasViolationCode(inv, "BR-USER-09") {
+		t.Errorf("Validate() = %v, want BR-USER-09", err)
+	}
+}
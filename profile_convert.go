@@ -0,0 +1,483 @@
+package einvoice
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrUnknownProfile is returned by ConvertProfile if the target is not a
+// known specification identifier.
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// ProfileChangeKind tells what ConvertProfile did with a field.
+type ProfileChangeKind int
+
+const (
+	// ProfileChangeDropped means the content was removed.
+	ProfileChangeDropped ProfileChangeKind = iota
+	// ProfileChangeAltered means the content was changed or merged.
+	ProfileChangeAltered
+	// ProfileChangeAdded means a profile specific default was filled in.
+	ProfileChangeAdded
+)
+
+func (k ProfileChangeKind) String() string {
+	switch k {
+	case ProfileChangeDropped:
+		return "dropped"
+	case ProfileChangeAltered:
+		return "altered"
+	case ProfileChangeAdded:
+		return "added"
+	default:
+		return "unknown"
+	}
+}
+
+// ProfileChange is one entry of the conversion report.
+type ProfileChange struct {
+	Kind  ProfileChangeKind
+	Field string // field of the Invoice such as "InvoiceLines[2].Description"
+	Term  string // business term or group such as "BT-154", empty for EXTENDED only content
+	Text  string // human-readable description with the affected values
+}
+
+func (c ProfileChange) String() string {
+	if c.Term == "" {
+		return fmt.Sprintf("%s %s: %s", c.Kind, c.Field, c.Text)
+	}
+	return fmt.Sprintf("%s %s (%s): %s", c.Kind, c.Field, c.Term, c.Text)
+}
+
+// ProfileConversion is the report of ConvertProfile.
+type ProfileConversion struct {
+	From    string // specification identifier (BT-24) before the conversion
+	To      string // specification identifier (BT-24) after the conversion
+	Changes []ProfileChange
+}
+
+// Lossless reports whether the conversion kept all content of the invoice,
+// that is no field was dropped or altered.
+func (pc *ProfileConversion) Lossless() bool {
+	for _, c := range pc.Changes {
+		if c.Kind != ProfileChangeAdded {
+			return false
+		}
+	}
+	return true
+}
+
+// profileChange is a change found by a profile restriction check together
+// with the modification of the checked invoice that makes it.
+type profileChange struct {
+	ProfileChange
+	apply func() // nil if the change is made by another change
+}
+
+// profileChanges collects the changes found by a profile restriction check.
+// Finding a change does not modify the invoice, only ConvertProfile applies
+// the changes. The changes of a check are applied in order, so changes which
+// restructure a slice come after the changes of its elements.
+type profileChanges []profileChange
+
+func (cs *profileChanges) add(kind ProfileChangeKind, field, term string, apply func(), format string, args ...any) {
+	*cs = append(*cs, profileChange{
+		ProfileChange: ProfileChange{Kind: kind, Field: field, Term: term, Text: fmt.Sprintf(format, args...)},
+		apply:         apply,
+	})
+}
+
+func (cs *profileChanges) dropString(s *string, field, term string) {
+	if *s != "" {
+		cs.add(ProfileChangeDropped, field, term, func() { *s = "" }, "%q", *s)
+	}
+}
+
+func (cs *profileChanges) dropDecimal(d *decimal.Decimal, field, term string) {
+	if !d.IsZero() {
+		cs.add(ProfileChangeDropped, field, term, func() { *d = decimal.Zero }, "%s", d.String())
+	}
+}
+
+func (cs *profileChanges) dropTime(t *time.Time, field, term string) {
+	if !t.IsZero() {
+		cs.add(ProfileChangeDropped, field, term, func() { *t = time.Time{} }, "%s", t.Format("2006-01-02"))
+	}
+}
+
+// dropAmount drops an amount of the totals. Without it the amount due for
+// payment (BT-115) no longer matches the other totals.
+func (cs *profileChanges) dropAmount(d *decimal.Decimal, field, term string) {
+	if !d.IsZero() {
+		cs.add(ProfileChangeDropped, field, term, func() { *d = decimal.Zero }, "%s, included in the amount due for payment (BT-115)", d.String())
+	}
+}
+
+func dropPtr[T any](cs *profileChanges, p **T, field, term string) {
+	if *p != nil {
+		cs.add(ProfileChangeDropped, field, term, func() { *p = nil }, "%s", describe(*p))
+	}
+}
+
+// describe returns a short text for the content of a dropped pointer field.
+func describe(v any) string {
+	switch v := v.(type) {
+	case *Party:
+		return fmt.Sprintf("%q", v.Name)
+	case *PostalAddress:
+		return strings.Join(slices.DeleteFunc([]string{v.Line1, v.PostcodeCode, v.City, v.CountryID}, func(s string) bool { return s == "" }), ", ")
+	case *LineReference:
+		return fmt.Sprintf("%q line %q", v.ID, v.LineID)
+	case *ReferencedDocument:
+		return fmt.Sprintf("%q", v.ID)
+	case *CurrencyExchange:
+		return fmt.Sprintf("%s → %s %s", v.SourceCurrencyCode, v.TargetCurrencyCode, v.multiplyRate().String())
+	case *PaymentAdjustmentTerms:
+		return fmt.Sprintf("%s%% of %s, amount %s", formatPercent(v.CalculationPercent), v.BasisAmount.StringFixed(2), v.ActualAmount.StringFixed(2))
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
+
+func dropSlice[T any](cs *profileChanges, s *[]T, field, term string) {
+	switch len(*s) {
+	case 0:
+	case 1:
+		cs.add(ProfileChangeDropped, field, term, func() { *s = nil }, "1 entry")
+	default:
+		cs.add(ProfileChangeDropped, field, term, func() { *s = nil }, "%d entries", len(*s))
+	}
+}
+
+// ConvertProfile converts the invoice to the profile with the specification
+// identifier target (BT-24), for example SpecFacturXBasic or SpecXRechnung30.
+// Content the target profile does not support is removed, sub invoice lines
+// are flattened to their detail lines, multiple payment terms are merged for
+// profiles allowing only one and profile specific defaults (BT-23 for PEPPOL)
+// are filled in. The returned report lists every dropped, altered and added
+// field.
+//
+// ConvertProfile does not recalculate the VAT breakdown or the totals and
+// does not validate the result. Upgrading to a more comprehensive profile
+// only changes BT-24; call Validate() to find missing mandatory content.
+//
+// Returns ErrUnknownProfile if target is not a known profile.
+func (inv *Invoice) ConvertProfile(target string) (*ProfileConversion, error) {
+	level := (&Invoice{GuidelineSpecifiedDocumentContextParameter: target}).ProfileLevel()
+	if level == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProfile, target)
+	}
+	pc := &ProfileConversion{From: inv.GuidelineSpecifiedDocumentContextParameter, To: target}
+
+	// The conversion works on a copy. Each check runs on the result of the
+	// previous one, so content removed before is not reported again.
+	conv := inv.clone()
+	conv.GuidelineSpecifiedDocumentContextParameter = target
+	checks := []struct {
+		below int
+		check func() profileChanges
+	}{
+		{levelExtended, conv.extendedContent},
+		{levelEN16931, conv.en16931Content},
+		{levelBasic, conv.lineContent},
+		{levelBasicWL, conv.basicWLContent},
+	}
+	for _, c := range checks {
+		if level < c.below {
+			pc.apply(c.check())
+		}
+	}
+	if conv.isPEPPOL() {
+		pc.apply(conv.peppolContent())
+	}
+
+	*inv = *conv
+	return pc, nil
+}
+
+// apply makes the changes and adds them to the report.
+func (pc *ProfileConversion) apply(changes profileChanges) {
+	for _, c := range changes {
+		if c.apply != nil {
+			c.apply()
+		}
+		pc.Changes = append(pc.Changes, c.ProfileChange)
+	}
+}
+
+// peppolContent finds the defaults and restrictions of PEPPOL BIS Billing 3.0.
+func (inv *Invoice) peppolContent() profileChanges {
+	var cs profileChanges
+	// PEPPOL-EN16931-R001: business process is mandatory
+	if inv.BPSpecifiedDocumentContextParameter == "" {
+		cs.add(ProfileChangeAdded, "BPSpecifiedDocumentContextParameter", "BT-23",
+			func() { inv.BPSpecifiedDocumentContextParameter = BPPEPPOLBilling01 }, "%q", BPPEPPOLBilling01)
+	}
+	// PEPPOL-EN16931-R002: no more than one note on document level. The
+	// merged note keeps the first subject code (BT-21).
+	if len(inv.Notes) > 1 {
+		merge := func() {
+			merged := Note{}
+			texts := make([]string, len(inv.Notes))
+			for i, n := range inv.Notes {
+				texts[i] = n.Text
+				if merged.SubjectCode == "" {
+					merged.SubjectCode = n.SubjectCode
+				}
+			}
+			merged.Text = strings.Join(texts, "\n")
+			inv.Notes = []Note{merged}
+		}
+		subject := ""
+		for i, n := range inv.Notes {
+			switch {
+			case subject == "":
+				subject = n.SubjectCode
+			case n.SubjectCode != "" && n.SubjectCode != subject:
+				cs.add(ProfileChangeDropped, fmt.Sprintf("Notes[%d].SubjectCode", i), "BT-21", nil, "%q", n.SubjectCode)
+			}
+		}
+		cs.add(ProfileChangeAltered, "Notes", "BG-1", merge, "%d notes merged into one", len(inv.Notes))
+	}
+	return cs
+}
+
+// lineContent finds the invoice lines, which the Basic WL and Minimum
+// profiles do not have.
+func (inv *Invoice) lineContent() profileChanges {
+	var cs profileChanges
+	if n := len(inv.InvoiceLines); n > 0 {
+		cs.add(ProfileChangeDropped, "InvoiceLines", "BG-25", func() { inv.InvoiceLines = nil }, "%d invoice lines", n)
+	}
+	return cs
+}
+
+// extendedContent finds the EXTENDED only content.
+func (inv *Invoice) extendedContent() profileChanges {
+	var cs profileChanges
+	for i := range inv.InvoiceLines {
+		line := &inv.InvoiceLines[i]
+		if !line.isDetailLine() {
+			continue
+		}
+		f := fmt.Sprintf("InvoiceLines[%d].", i)
+		cs.dropString(&line.ParentLineID, f+"ParentLineID", "BT-X-304")
+		cs.dropString(&line.LineStatusCode, f+"LineStatusCode", "BT-X-7")
+		cs.dropString(&line.LineStatusReasonCode, f+"LineStatusReasonCode", "BT-X-8")
+		if !line.PackageQuantity.IsZero() {
+			cs.add(ProfileChangeDropped, f+"PackageQuantity", "", func() {
+				line.PackageQuantity = decimal.Zero
+				line.PackageQuantityUnit = ""
+			}, "%s", line.PackageQuantity.String())
+		}
+		cs.dropTime(&line.ActualDeliveryDate, f+"ActualDeliveryDate", "")
+		dropPtr(&cs, &line.DespatchAdviceReferencedDocument, f+"DespatchAdviceReferencedDocument", "")
+		dropPtr(&cs, &line.DeliveryNoteReferencedDocument, f+"DeliveryNoteReferencedDocument", "")
+	}
+
+	dropPtr(&cs, &inv.UltimateShipTo, "UltimateShipTo", "")
+	dropPtr(&cs, &inv.ShipFrom, "ShipFrom", "")
+	dropPtr(&cs, &inv.DeliveryNoteReferencedDocument, "DeliveryNoteReferencedDocument", "")
+	dropPtr(&cs, &inv.InvoicerTradeParty, "InvoicerTradeParty", "")
+	dropPtr(&cs, &inv.InvoiceeTradeParty, "InvoiceeTradeParty", "")
+	// UBL writes the exchange rate (cac:TaxExchangeRate) in every profile.
+	if inv.SchemaType != UBL {
+		dropPtr(&cs, &inv.TaxCurrencyExchange, "TaxCurrencyExchange", "")
+	}
+
+	// The paid amount (BT-113) is kept, only its breakdown per prepayment is dropped.
+	dropSlice(&cs, &inv.AdvancePayments, "AdvancePayments", "")
+
+	for i := range inv.SpecifiedTradeAllowanceCharge {
+		ac := &inv.SpecifiedTradeAllowanceCharge[i]
+		if ac.LogisticsServiceCharge {
+			cs.add(ProfileChangeAltered, fmt.Sprintf("SpecifiedTradeAllowanceCharge[%d]", i), "BG-21",
+				func() { ac.LogisticsServiceCharge = false }, "logistics service charge %q, a document level charge in this profile", ac.Reason)
+		}
+	}
+
+	for i := range inv.SpecifiedTradePaymentTerms {
+		pt := &inv.SpecifiedTradePaymentTerms[i]
+		f := fmt.Sprintf("SpecifiedTradePaymentTerms[%d].", i)
+		cs.dropDecimal(&pt.PartialPaymentAmount, f+"PartialPaymentAmount", "")
+		dropPtr(&cs, &pt.PenaltyTerms, f+"PenaltyTerms", "")
+		dropPtr(&cs, &pt.DiscountTerms, f+"DiscountTerms", "")
+	}
+	// EN 16931 has one payment terms description (BT-20), one due date (BT-9)
+	// and one mandate reference (BT-89).
+	if len(inv.SpecifiedTradePaymentTerms) > 1 {
+		merge := func() {
+			merged := SpecifiedTradePaymentTerms{}
+			var descriptions []string
+			for _, pt := range inv.SpecifiedTradePaymentTerms {
+				if pt.Description != "" {
+					descriptions = append(descriptions, pt.Description)
+				}
+				if merged.DueDate.IsZero() {
+					merged.DueDate = pt.DueDate
+				}
+				if merged.DirectDebitMandateID == "" {
+					merged.DirectDebitMandateID = pt.DirectDebitMandateID
+				}
+			}
+			merged.Description = strings.Join(descriptions, "\n")
+			inv.SpecifiedTradePaymentTerms = []SpecifiedTradePaymentTerms{merged}
+		}
+		cs.add(ProfileChangeAltered, "SpecifiedTradePaymentTerms", "BT-20", merge, "%d payment terms merged into one", len(inv.SpecifiedTradePaymentTerms))
+	}
+
+	// Sub invoice lines: the GROUP and INFORMATION lines do not contribute to
+	// the totals (see isDetailLine), so only the detail lines are kept. They
+	// are removed last, the changes above point into the lines.
+	removeAggregationLines := func() {
+		inv.InvoiceLines = slices.DeleteFunc(inv.InvoiceLines, func(line InvoiceLine) bool { return !line.isDetailLine() })
+	}
+	for _, line := range inv.InvoiceLines {
+		if !line.isDetailLine() {
+			cs.add(ProfileChangeDropped, "InvoiceLines", "BG-25", removeAggregationLines, "%s line %s %q", strings.ToLower(line.LineStatusReasonCode), line.LineID, line.ItemName)
+		}
+	}
+	return cs
+}
+
+// en16931Content finds the content which is in EN 16931 but not in the
+// Factur-X Basic and Basic WL profiles.
+func (inv *Invoice) en16931Content() profileChanges {
+	var cs profileChanges
+	for i := range inv.InvoiceLines {
+		line := &inv.InvoiceLines[i]
+		f := fmt.Sprintf("InvoiceLines[%d].", i)
+		cs.dropString(&line.ArticleNumber, f+"ArticleNumber", "BT-155")
+		cs.dropString(&line.ArticleNumberBuyer, f+"ArticleNumberBuyer", "BT-156")
+		cs.dropString(&line.Description, f+"Description", "BT-154")
+		dropSlice(&cs, &line.Characteristics, f+"Characteristics", "BG-32")
+		dropSlice(&cs, &line.ProductClassification, f+"ProductClassification", "BT-158")
+		cs.dropString(&line.OriginTradeCountry, f+"OriginTradeCountry", "BT-159")
+		cs.dropString(&line.BuyerOrderReferencedDocument, f+"BuyerOrderReferencedDocument", "BT-132")
+		if line.AdditionalReferencedDocumentID != "" {
+			cs.add(ProfileChangeDropped, f+"AdditionalReferencedDocumentID", "BT-128", func() {
+				line.AdditionalReferencedDocumentID = ""
+				line.AdditionalReferencedDocumentTypeCode = ""
+				line.AdditionalReferencedDocumentRefTypeCode = ""
+			}, "%q", line.AdditionalReferencedDocumentID)
+		}
+		cs.dropString(&line.ReceivableSpecifiedTradeAccountingAccount, f+"ReceivableSpecifiedTradeAccountingAccount", "BT-133")
+	}
+
+	cs.dropString(&inv.SpecifiedProcuringProjectID, "SpecifiedProcuringProjectID", "BT-11")
+	cs.dropString(&inv.SpecifiedProcuringProjectName, "SpecifiedProcuringProjectName", "BT-11")
+	cs.dropString(&inv.SellerOrderReferencedDocument, "SellerOrderReferencedDocument", "BT-14")
+	cs.dropString(&inv.ReceivingAdviceReferencedDocument, "ReceivingAdviceReferencedDocument", "BT-15")
+	dropSlice(&cs, &inv.AdditionalReferencedDocument, "AdditionalReferencedDocument", "BG-24")
+
+	cs.dropString(&inv.Seller.Description, "Seller.Description", "BT-33")
+	dropSlice(&cs, &inv.Seller.DefinedTradeContact, "Seller.DefinedTradeContact", "BG-6")
+	dropSlice(&cs, &inv.Buyer.DefinedTradeContact, "Buyer.DefinedTradeContact", "BG-9")
+
+	for i := range inv.PaymentMeans {
+		pm := &inv.PaymentMeans[i]
+		f := fmt.Sprintf("PaymentMeans[%d].", i)
+		cs.dropString(&pm.Information, f+"Information", "BT-82")
+		cs.dropString(&pm.ApplicableTradeSettlementFinancialCardID, f+"ApplicableTradeSettlementFinancialCardID", "BT-87")
+		cs.dropString(&pm.ApplicableTradeSettlementFinancialCardCardholderName, f+"ApplicableTradeSettlementFinancialCardCardholderName", "BT-88")
+		cs.dropString(&pm.PayeePartyCreditorFinancialAccountName, f+"PayeePartyCreditorFinancialAccountName", "BT-85")
+		cs.dropString(&pm.PayeeSpecifiedCreditorFinancialInstitutionBIC, f+"PayeeSpecifiedCreditorFinancialInstitutionBIC", "BT-86")
+	}
+
+	cs.dropAmount(&inv.RoundingAmount, "RoundingAmount", "BT-114")
+	return cs
+}
+
+// basicWLContent finds the content which is not in the Minimum profile. The
+// lines are found by lineContent.
+func (inv *Invoice) basicWLContent() profileChanges {
+	var cs profileChanges
+	dropSlice(&cs, &inv.Notes, "Notes", "BG-1")
+
+	// Seller: name, legal registration, VAT identifiers and country
+	dropSlice(&cs, &inv.Seller.ID, "Seller.ID", "BT-29")
+	dropSlice(&cs, &inv.Seller.GlobalID, "Seller.GlobalID", "BT-29")
+	dropURI(&cs, &inv.Seller, "Seller.URIUniversalCommunication", "BT-34")
+	if slo := inv.Seller.SpecifiedLegalOrganization; slo != nil {
+		cs.dropString(&slo.TradingBusinessName, "Seller.SpecifiedLegalOrganization.TradingBusinessName", "BT-28")
+	}
+	if pa := inv.Seller.PostalAddress; pa != nil && *pa != (PostalAddress{CountryID: pa.CountryID}) {
+		cs.add(ProfileChangeAltered, "Seller.PostalAddress", "BG-5",
+			func() { inv.Seller.PostalAddress = &PostalAddress{CountryID: pa.CountryID} },
+			"%s, only the country code %q is kept", describe(pa), pa.CountryID)
+	}
+
+	// Buyer: name and legal registration
+	dropSlice(&cs, &inv.Buyer.ID, "Buyer.ID", "BT-46")
+	dropSlice(&cs, &inv.Buyer.GlobalID, "Buyer.GlobalID", "BT-46")
+	dropURI(&cs, &inv.Buyer, "Buyer.URIUniversalCommunication", "BT-49")
+	dropPtr(&cs, &inv.Buyer.PostalAddress, "Buyer.PostalAddress", "BG-8")
+	cs.dropString(&inv.Buyer.VATaxRegistration, "Buyer.VATaxRegistration", "BT-48")
+	if slo := inv.Buyer.SpecifiedLegalOrganization; slo != nil {
+		cs.dropString(&slo.TradingBusinessName, "Buyer.SpecifiedLegalOrganization.TradingBusinessName", "BT-45")
+	}
+
+	dropPtr(&cs, &inv.SellerTaxRepresentativeTradeParty, "SellerTaxRepresentativeTradeParty", "BG-11")
+	cs.dropString(&inv.ContractReferencedDocument, "ContractReferencedDocument", "BT-12")
+	dropPtr(&cs, &inv.ShipTo, "ShipTo", "BG-13")
+	cs.dropTime(&inv.OccurrenceDateTime, "OccurrenceDateTime", "BT-72")
+	cs.dropString(&inv.DespatchAdviceReferencedDocument, "DespatchAdviceReferencedDocument", "BT-16")
+
+	cs.dropString(&inv.CreditorReferenceID, "CreditorReferenceID", "BT-90")
+	cs.dropString(&inv.PaymentReference, "PaymentReference", "BT-83")
+	cs.dropString(&inv.TaxCurrencyCode, "TaxCurrencyCode", "BT-6")
+	if !inv.TaxTotalAccounting.IsZero() {
+		cs.add(ProfileChangeDropped, "TaxTotalAccounting", "BT-111", func() {
+			inv.TaxTotalAccounting = decimal.Zero
+			inv.TaxTotalAccountingCurrency = ""
+		}, "%s", inv.TaxTotalAccounting.String())
+	}
+	dropPtr(&cs, &inv.PayeeTradeParty, "PayeeTradeParty", "BG-10")
+	dropSlice(&cs, &inv.PaymentMeans, "PaymentMeans", "BG-16")
+	dropSlice(&cs, &inv.TradeTaxes, "TradeTaxes", "BG-23")
+	for _, p := range []struct {
+		t     *time.Time
+		field string
+		term  string
+	}{
+		{&inv.BillingSpecifiedPeriodStart, "BillingSpecifiedPeriodStart", "BT-73"},
+		{&inv.BillingSpecifiedPeriodEnd, "BillingSpecifiedPeriodEnd", "BT-74"},
+	} {
+		if !p.t.IsZero() {
+			cs.add(ProfileChangeDropped, p.field, p.term, func() {
+				*p.t = time.Time{}
+				inv.hasBillingPeriodInXML = false
+			}, "%s", p.t.Format("2006-01-02"))
+		}
+	}
+	dropSlice(&cs, &inv.SpecifiedTradeAllowanceCharge, "SpecifiedTradeAllowanceCharge", "BG-20, BG-21")
+	dropSlice(&cs, &inv.SpecifiedTradePaymentTerms, "SpecifiedTradePaymentTerms", "BT-20")
+	dropSlice(&cs, &inv.InvoiceReferencedDocument, "InvoiceReferencedDocument", "BG-3")
+	cs.dropString(&inv.ReceivableSpecifiedTradeAccountingAccount, "ReceivableSpecifiedTradeAccountingAccount", "BT-19")
+
+	if !inv.LineTotal.IsZero() {
+		cs.add(ProfileChangeDropped, "LineTotal", "BT-106", func() {
+			inv.LineTotal = decimal.Zero
+			inv.hasLineTotalInXML = false
+		}, "%s", inv.LineTotal.String())
+	}
+	cs.dropDecimal(&inv.AllowanceTotal, "AllowanceTotal", "BT-107")
+	cs.dropDecimal(&inv.ChargeTotal, "ChargeTotal", "BT-108")
+	cs.dropAmount(&inv.TotalPrepaid, "TotalPrepaid", "BT-113")
+	return cs
+}
+
+// dropURI drops the electronic address of the party with its scheme.
+func dropURI(cs *profileChanges, p *Party, field, term string) {
+	if p.URIUniversalCommunication != "" {
+		cs.add(ProfileChangeDropped, field, term, func() {
+			p.URIUniversalCommunication = ""
+			p.URIUniversalCommunicationScheme = ""
+		}, "%q", p.URIUniversalCommunication)
+	}
+}
@@ -0,0 +1,217 @@
+package einvoice
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// hasChange reports whether the conversion report contains a change of the
+// given kind for the field.
+func hasChange(pc *ProfileConversion, kind ProfileChangeKind, field string) bool {
+	for _, c := range pc.Changes {
+		if c.Kind == kind && c.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// convertAndReparse converts the fixture to target, writes and re-parses it.
+func convertAndReparse(t *testing.T, fixture, target string) (*Invoice, *ProfileConversion, string) {
+	t.Helper()
+	inv, err := ParseXMLFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := inv.ConvertProfile(target)
+	if err != nil {
+		t.Fatalf("ConvertProfile() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := inv.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := buf.String()
+	parsed, err := ParseReader(&buf)
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	assertInvoiceEqual(t, inv, parsed)
+	return parsed, pc, xml
+}
+
+func TestConvertProfile_SubLines(t *testing.T) {
+	t.Parallel()
+
+	orig, err := ParseXMLFile("testdata/cii/extended/zf25-subline-group-bundle.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	detail := 0
+	for _, line := range orig.InvoiceLines {
+		if line.isDetailLine() {
+			detail++
+		}
+	}
+
+	inv, pc, _ := convertAndReparse(t, "testdata/cii/extended/zf25-subline-group-bundle.xml", SpecEN16931)
+	if pc.From != orig.GuidelineSpecifiedDocumentContextParameter || pc.To != SpecEN16931 {
+		t.Errorf("report From/To = %q/%q", pc.From, pc.To)
+	}
+	if len(inv.InvoiceLines) != detail {
+		t.Errorf("got %d lines, want the %d detail lines", len(inv.InvoiceLines), detail)
+	}
+	for _, line := range inv.InvoiceLines {
+		if line.ParentLineID != "" || line.LineStatusReasonCode != "" {
+			t.Errorf("line %s still has sub line data", line.LineID)
+		}
+	}
+	if !hasChange(pc, ProfileChangeDropped, "InvoiceLines") || pc.Lossless() {
+		t.Errorf("aggregation lines not reported: %v", pc.Changes)
+	}
+	if !inv.LineTotal.Equal(orig.LineTotal) || !inv.DuePayableAmount.Equal(orig.DuePayableAmount) {
+		t.Error("totals changed")
+	}
+	if err := inv.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConvertProfile_ExtendedToBasic(t *testing.T) {
+	t.Parallel()
+
+	inv, pc, xml := convertAndReparse(t, "testdata/cii/extended/zugferd-extended-1.xml", SpecFacturXBasic)
+	for _, field := range []string{"InvoiceeTradeParty", "SpecifiedTradePaymentTerms[0].DiscountTerms", "SpecifiedTradePaymentTerms[0].PenaltyTerms"} {
+		if !hasChange(pc, ProfileChangeDropped, field) {
+			t.Errorf("%s not reported as dropped", field)
+		}
+	}
+	if !hasChange(pc, ProfileChangeAltered, "SpecifiedTradeAllowanceCharge[1]") {
+		t.Errorf("logistics service charge not reported as altered: %v", pc.Changes)
+	}
+	if strings.Contains(xml, "SpecifiedLogisticsServiceCharge") || strings.Contains(xml, "InvoiceeTradeParty") {
+		t.Error("output contains EXTENDED elements")
+	}
+	for _, line := range inv.InvoiceLines {
+		if line.ArticleNumber != "" || line.Description != "" || len(line.ProductClassification) > 0 {
+			t.Errorf("line %s has EN 16931 content", line.LineID)
+		}
+	}
+	if err := inv.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConvertProfile_Minimum(t *testing.T) {
+	t.Parallel()
+
+	inv, pc, xml := convertAndReparse(t, "testdata/cii/en16931/CII_example1.xml", SpecFacturXMinimum)
+	if len(inv.InvoiceLines) != 0 || len(inv.TradeTaxes) != 0 || len(inv.Notes) != 0 {
+		t.Error("lines, VAT breakdown or notes not removed")
+	}
+	if inv.Seller.PostalAddress == nil || inv.Seller.PostalAddress.City != "" || inv.Seller.PostalAddress.CountryID != "NL" {
+		t.Errorf("seller address = %+v, want the country only", inv.Seller.PostalAddress)
+	}
+	for _, field := range []string{"InvoiceLines", "TradeTaxes", "Notes", "PaymentMeans", "LineTotal"} {
+		if !hasChange(pc, ProfileChangeDropped, field) {
+			t.Errorf("%s not reported as dropped", field)
+		}
+	}
+	if !hasChange(pc, ProfileChangeAltered, "Seller.PostalAddress") {
+		t.Error("seller address not reported as altered")
+	}
+	for _, elt := range []string{"IncludedSupplyChainTradeLineItem", "IncludedNote", "ApplicableTradeTax", "SpecifiedTradeSettlementPaymentMeans"} {
+		if strings.Contains(xml, elt) {
+			t.Errorf("Minimum output contains %s", elt)
+		}
+	}
+	if err := inv.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConvertProfile_Copy(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/extended/zf25-subline-group-bundle.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.Seller.PostalAddress = &PostalAddress{City: "Berlin", CountryID: "DE"}
+	address := inv.Seller.PostalAddress
+	line := &inv.InvoiceLines[1]
+	if _, err := inv.ConvertProfile(SpecFacturXMinimum); err != nil {
+		t.Fatal(err)
+	}
+	// The conversion works on a copy, content shared with the caller is kept
+	if address.City != "Berlin" || line.LineStatusReasonCode != "DETAIL" {
+		t.Errorf("ConvertProfile() modified the original content: address %+v, line %s %q", address, line.LineID, line.LineStatusReasonCode)
+	}
+	if inv.Seller.PostalAddress.City != "" || len(inv.InvoiceLines) != 0 {
+		t.Errorf("converted invoice: seller address %+v, %d lines", inv.Seller.PostalAddress, len(inv.InvoiceLines))
+	}
+}
+
+func TestConvertProfile_PEPPOL(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.BPSpecifiedDocumentContextParameter = ""
+	inv.Notes = append(inv.Notes, Note{Text: "second note", SubjectCode: "REG"})
+	notes := len(inv.Notes)
+	subject := inv.Notes[0].SubjectCode
+	pc, err := inv.ConvertProfile(SpecPEPPOLBilling30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.BPSpecifiedDocumentContextParameter != BPPEPPOLBilling01 || !hasChange(pc, ProfileChangeAdded, "BPSpecifiedDocumentContextParameter") {
+		t.Errorf("BT-23 = %q, want default", inv.BPSpecifiedDocumentContextParameter)
+	}
+	if len(inv.Notes) != 1 || strings.Count(inv.Notes[0].Text, "\n") != notes-1 || !hasChange(pc, ProfileChangeAltered, "Notes") {
+		t.Errorf("notes not merged: %q", inv.Notes)
+	}
+	// The first subject code is kept, the others are reported
+	if inv.Notes[0].SubjectCode != subject || !hasChange(pc, ProfileChangeDropped, fmt.Sprintf("Notes[%d].SubjectCode", notes-1)) {
+		t.Errorf("subject code = %q, changes %v", inv.Notes[0].SubjectCode, pc.Changes)
+	}
+}
+
+func TestConvertProfile_Upgrade(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/basic/zugferd-basic-1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := ParseXMLFile("testdata/cii/basic/zugferd-basic-1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := inv.ConvertProfile(SpecFacturXExtended)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pc.Lossless() || len(pc.Changes) != 0 {
+		t.Errorf("upgrade changed content: %v", pc.Changes)
+	}
+	orig.GuidelineSpecifiedDocumentContextParameter = SpecFacturXExtended
+	assertInvoiceEqual(t, orig, inv)
+}
+
+func TestConvertProfile_UnknownProfile(t *testing.T) {
+	t.Parallel()
+
+	inv := &Invoice{GuidelineSpecifiedDocumentContextParameter: SpecEN16931}
+	if _, err := inv.ConvertProfile("urn:example:unknown"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("error = %v, want ErrUnknownProfile", err)
+	}
+	if inv.GuidelineSpecifiedDocumentContextParameter != SpecEN16931 {
+		t.Error("BT-24 changed on error")
+	}
+}
@@ -0,0 +1,98 @@
+package einvoice
+
+// ProfileRequirement is content of the invoice which needs a profile above
+// Factur-X Minimum.
+type ProfileRequirement struct {
+	Profile string // smallest profile (BT-24) that allows the content
+	Field   string // field of the Invoice such as "InvoiceLines[2].Description"
+	Term    string // business term or group such as "BT-154", empty for EXTENDED only content
+	Text    string // human-readable description of the content
+}
+
+// ProfileInference is the result of InferProfile.
+type ProfileInference struct {
+	// Profile is the smallest Factur-X profile (SpecFacturXMinimum,
+	// SpecFacturXBasicWL, SpecFacturXBasic, SpecEN16931 or
+	// SpecFacturXExtended) which can represent the invoice without losing
+	// content.
+	Profile string
+	// Requirements lists the content which forces a profile above Minimum.
+	Requirements []ProfileRequirement
+	// EN16931, XRechnung and PEPPOL contain the violations of the invoice
+	// declared as EN 16931, XRechnung 3.0 and PEPPOL BIS Billing 3.0 invoice.
+	// They are empty if the requirements are met.
+	EN16931   []SemanticError
+	XRechnung []SemanticError
+	PEPPOL    []SemanticError
+}
+
+// MeetsEN16931 reports whether the invoice meets the EN 16931 requirements.
+func (pi *ProfileInference) MeetsEN16931() bool {
+	return len(pi.EN16931) == 0
+}
+
+// MeetsXRechnung reports whether the invoice meets the XRechnung 3.0
+// requirements.
+func (pi *ProfileInference) MeetsXRechnung() bool {
+	return len(pi.XRechnung) == 0
+}
+
+// MeetsPEPPOL reports whether the invoice meets the PEPPOL BIS Billing 3.0
+// requirements.
+func (pi *ProfileInference) MeetsPEPPOL() bool {
+	return len(pi.PEPPOL) == 0
+}
+
+// InferProfile determines the smallest Factur-X profile that can represent
+// the populated fields of the invoice and checks whether the invoice meets
+// the EN 16931, XRechnung and PEPPOL requirements. The specification
+// identifier (BT-24) of the invoice is ignored and the invoice is not
+// modified. For the PEPPOL check a missing business process (BT-23) is
+// assumed to be the default BPPEPPOLBilling01 as ConvertProfile fills it in.
+//
+// The totals and the VAT breakdown should be calculated before, see
+// UpdateApplicableTradeTax() and UpdateTotals().
+func (inv *Invoice) InferProfile() *ProfileInference {
+	pi := &ProfileInference{Profile: SpecFacturXMinimum}
+
+	// The restriction checks of ConvertProfile, from the most comprehensive
+	// profile down. Content found by a check needs the profile of the check.
+	checks := []struct {
+		profile string
+		check   func() profileChanges
+	}{
+		{SpecFacturXExtended, inv.extendedContent},
+		{SpecEN16931, inv.en16931Content},
+		{SpecFacturXBasic, inv.lineContent},
+		{SpecFacturXBasicWL, inv.basicWLContent},
+	}
+	for _, c := range checks {
+		changes := c.check()
+		if len(changes) > 0 && pi.Profile == SpecFacturXMinimum {
+			pi.Profile = c.profile
+		}
+		for _, ch := range changes {
+			pi.Requirements = append(pi.Requirements, ProfileRequirement{Profile: c.profile, Field: ch.Field, Term: ch.Term, Text: ch.Text})
+		}
+	}
+
+	pi.EN16931 = inv.violationsAs(SpecEN16931)
+	pi.XRechnung = inv.violationsAs(SpecXRechnung30)
+	pi.PEPPOL = inv.violationsAs(SpecPEPPOLBilling30)
+	return pi
+}
+
+// violationsAs validates a copy of the invoice declared with the
+// specification identifier urn and returns the violations.
+func (inv *Invoice) violationsAs(urn string) []SemanticError {
+	cp := *inv
+	cp.GuidelineSpecifiedDocumentContextParameter = urn
+	if cp.isPEPPOL() && cp.BPSpecifiedDocumentContextParameter == "" {
+		cp.BPSpecifiedDocumentContextParameter = BPPEPPOLBilling01
+	}
+	// The envelope and the PDF belong to the original document.
+	cp.sbdh = nil
+	cp.pdfConformanceLevel = ""
+	_ = cp.Validate()
+	return cp.violations
+}
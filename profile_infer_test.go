@@ -0,0 +1,85 @@
+package einvoice
+
+import (
+	"testing"
+)
+
+func TestInferProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fixture   string
+		want      string
+		en16931   bool
+		xrechnung bool
+		peppol    bool
+	}{
+		{"testdata/cii/minimum/zugferd-minimum-rechnung.xml", SpecFacturXMinimum, false, false, false},
+		{"testdata/cii/basicwl/zugferd-basicwl-einfach.xml", SpecFacturXBasicWL, false, false, false},
+		{"testdata/cii/basic/zugferd-basic-einfach.xml", SpecFacturXBasic, true, false, false},
+		{"testdata/cii/en16931/CII_example1.xml", SpecEN16931, true, false, false},
+		{"testdata/cii/xrechnung/zugferd-xrechnung-einfach.xml", SpecEN16931, true, true, false},
+		{"testdata/peppol/valid/base-example.xml", SpecEN16931, true, false, true},
+		{"testdata/cii/extended/zf25-subline-nested.xml", SpecFacturXExtended, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			t.Parallel()
+
+			inv, err := ParseXMLFile(tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			orig, err := ParseXMLFile(tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pi := inv.InferProfile()
+			if pi.Profile != tt.want {
+				t.Errorf("Profile = %s, want %s", GetProfileName(pi.Profile), GetProfileName(tt.want))
+			}
+			if pi.MeetsEN16931() != tt.en16931 || pi.MeetsXRechnung() != tt.xrechnung || pi.MeetsPEPPOL() != tt.peppol {
+				t.Errorf("EN 16931/XRechnung/PEPPOL = %t/%t/%t, want %t/%t/%t", pi.MeetsEN16931(), pi.MeetsXRechnung(), pi.MeetsPEPPOL(), tt.en16931, tt.xrechnung, tt.peppol)
+			}
+			if (tt.want == SpecFacturXMinimum) != (len(pi.Requirements) == 0) {
+				t.Errorf("got %d requirements", len(pi.Requirements))
+			}
+			// InferProfile must not modify the invoice.
+			assertInvoiceEqual(t, orig, inv)
+		})
+	}
+}
+
+func TestInferProfile_Requirements(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/basic/zugferd-basic-einfach.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.GuidelineSpecifiedDocumentContextParameter = ""
+	inv.SchemaType = SchemaTypeUnknown
+	if pi := inv.InferProfile(); pi.Profile != SpecFacturXBasic {
+		t.Fatalf("Profile = %s, want Basic", GetProfileName(pi.Profile))
+	}
+
+	inv.InvoiceLines[0].Description = "EN 16931 content"
+	pi := inv.InferProfile()
+	if pi.Profile != SpecEN16931 {
+		t.Errorf("Profile = %s, want EN 16931", GetProfileName(pi.Profile))
+	}
+	found := false
+	for _, r := range pi.Requirements {
+		if r.Field == "InvoiceLines[0].Description" && r.Term == "BT-154" && r.Profile == SpecEN16931 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("BT-154 not in requirements: %+v", pi.Requirements)
+	}
+
+	inv.InvoiceeTradeParty = &Party{Name: "Invoicee"}
+	if pi := inv.InferProfile(); pi.Profile != SpecFacturXExtended || pi.MeetsEN16931() {
+		t.Errorf("Profile = %s, EN 16931 = %t, want Extended and not EN 16931", GetProfileName(pi.Profile), pi.MeetsEN16931())
+	}
+}
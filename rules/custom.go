@@ -36,6 +36,31 @@ var (
 		Fields:      []string{"BT-X-8"},
 		Description: `Invoice line subtype (BT-X-8, ram:LineStatusReasonCode) must be one of "DETAIL", "GROUP" or "INFORMATION" when present. An unknown value would be silently treated as an aggregation line and dropped from the totals.`,
 	}
+	BRUSER07 = Rule{
+		Code:        "BR-USER-07",
+		Fields:      []string{"BT-111", "BT-110", "BT-6"},
+		Description: `If an exchange rate to the VAT accounting currency (BT-6) is given, the Invoice total VAT amount in accounting currency (BT-111) must equal the Invoice total VAT amount (BT-110) multiplied by the exchange rate, with a tolerance of 0,01 per VAT breakdown (BG-23) for the rounding of the converted VAT category tax amounts.`,
+	}
+	BRUSER08 = Rule{
+		Code:        "BR-USER-08",
+		Fields:      []string{"BT-3", "BT-112"},
+		Description: `A commercial invoice (BT-3 = 380) should not have a negative Invoice total amount with VAT (BT-112) but be sent as credit note (381) with positive amounts, and a credit note should not have a negative total. The same applies to self-billed invoices (389) and self-billed credit notes (261). Receivers, in particular in UBL based networks, reject such documents. Reported as a warning.`,
+	}
+	BRUSER09 = Rule{
+		Code:        "BR-USER-09",
+		Fields:      []string{"BT-113"},
+		Description: `If prepayments (EXTENDED, ram:SpecifiedAdvancePayment) are given, the Paid amount (BT-113) must equal the sum of their paid amounts.`,
+	}
+	BRUSER10 = Rule{
+		Code:        "BR-USER-10",
+		Fields:      []string{"BT-113", "BT-25", "BT-26", "BT-3"},
+		Description: `The Paid amount (BT-113) of a final invoice must equal the sum of the Invoice total amounts with VAT (BT-112) of the deducted prepayment invoices (BT-3 = 386) in the invoice currency. Each prepayment invoice must be referenced as preceding invoice (BG-3), its VAT categories must occur in the VAT breakdown of the final invoice and, for prepayments given per category (EXTENDED), the paid amount and VAT must match the prepayment invoice.`,
+	}
+	BRUSER11 = Rule{
+		Code:        "BR-USER-11",
+		Fields:      []string{"BG-20"},
+		Description: `A logistics service charge (EXTENDED, ram:SpecifiedLogisticsServiceCharge) is always a charge. A Document level allowance (BG-20) must not be marked as logistics service charge.`,
+	}
 
 	// BR-FXEXT-*: Factur-X EXTENDED profile rules (Factur-X 1.09 / ZUGFeRD 2.5)
 	// that replace the corresponding EN 16931 base rules to support sub invoice
@@ -92,4 +117,100 @@ var (
 		Fields:      []string{"BT-110", "BT-111"},
 		Description: `TaxTotalAmount with unexpected currency (expected invoice currency BT-5 or accounting currency BT-6).`,
 	}
+
+	// SBDH_PROCESS_MISMATCH: Validates that the PROCESSID business scope of a
+	// PEPPOL Standard Business Document Header matches the business process
+	// (BT-23) of the enclosed invoice.
+	SBDH_PROCESS_MISMATCH = Rule{
+		Code:        "SBDH-PROCESS-MISMATCH",
+		Fields:      []string{"BT-23"},
+		Description: `The process identifier of the Standard Business Document Header must match the Business process type (BT-23) of the invoice.`,
+	}
+
+	// FACTURX_CONFORMANCE_LEVEL: Checks that the fx:ConformanceLevel of the XMP
+	// metadata of a ZUGFeRD/Factur-X PDF matches the specification identifier
+	// (BT-24) of the embedded invoice. Reported as a warning.
+	FACTURX_CONFORMANCE_LEVEL = Rule{
+		Code:        "FACTURX-CONFORMANCE-LEVEL",
+		Fields:      []string{"BT-24"},
+		Description: `The Factur-X ConformanceLevel of the PDF's XMP metadata should match the Specification identifier (BT-24) of the embedded invoice.`,
+	}
+
+	// FX-PROFILE-*: Content not allowed in the profile declared in BT-24. The
+	// writer omits such content, so it would be lost. Source: Factur-X 1.07
+	// profile XSDs and schematron (MINIMUM, BASIC WL, BASIC, EN 16931).
+	FXPROFILE01 = Rule{
+		Code:        "FX-PROFILE-01",
+		Fields:      []string{"BT-24", "BT-X-304", "BT-X-8", "BT-20"},
+		Description: `EXTENDED only content (sub invoice lines, additional parties and delivery references, logistics service charges, currency exchange, partial payments, discount and penalty terms, more than one payment terms) must not be used below the Extended profile.`,
+	}
+	FXPROFILE02 = Rule{
+		Code:        "FX-PROFILE-02",
+		Fields:      []string{"BT-24"},
+		Description: `Content of the EN 16931 profile which is not part of Factur-X Basic and Basic WL (for example BT-11, BT-14, BG-24, BG-6, BT-82, BT-114, BT-154, BT-155) must not be used in these profiles.`,
+	}
+	FXPROFILE03 = Rule{
+		Code:        "FX-PROFILE-03",
+		Fields:      []string{"BT-24", "BG-25"},
+		Description: `An invoice in the Basic WL or Minimum profile must not contain invoice lines (BG-25).`,
+	}
+	FXPROFILE04 = Rule{
+		Code:        "FX-PROFILE-04",
+		Fields:      []string{"BT-24"},
+		Description: `An invoice in the Minimum profile must contain only the header data, the seller and buyer identification and the totals BT-109, BT-110, BT-112 and BT-115.`,
+	}
+
+	// FX-PDF-*: Factur-X / ZUGFeRD hybrid PDF (PDF/A-3) conformance checks.
+	// Source: Factur-X 1.0 specification, chapter 6 (PDF/A-3 requirements),
+	// and ISO 19005-3.
+	FXPDF01 = Rule{
+		Code:        "FX-PDF-01",
+		Description: `The PDF must contain XMP metadata (/Metadata stream in the document catalog).`,
+	}
+	FXPDF02 = Rule{
+		Code:        "FX-PDF-02",
+		Description: `The XMP metadata must identify the document as PDF/A-3 (pdfaid:part = 3, pdfaid:conformance A, B or U).`,
+	}
+	FXPDF03 = Rule{
+		Code:        "FX-PDF-03",
+		Description: `The XMP metadata must declare the Factur-X PDF/A extension schema with prefix "fx" and the properties DocumentFileName, DocumentType, Version and ConformanceLevel.`,
+	}
+	FXPDF04 = Rule{
+		Code:        "FX-PDF-04",
+		Description: `The XMP metadata must contain fx:DocumentType "INVOICE" and fx:Version.`,
+	}
+	FXPDF05 = Rule{
+		Code:        "FX-PDF-05",
+		Fields:      []string{"BT-24"},
+		Description: `The XMP fx:ConformanceLevel must be one of MINIMUM, BASIC WL, BASIC, EN 16931, EXTENDED or XRECHNUNG and match the Specification identifier (BT-24) of the embedded invoice.`,
+	}
+	FXPDF06 = Rule{
+		Code:        "FX-PDF-06",
+		Description: `The XMP fx:DocumentFileName must be the name of the embedded invoice XML file.`,
+	}
+	FXPDF07 = Rule{
+		Code:        "FX-PDF-07",
+		Fields:      []string{"BT-24"},
+		Description: `The embedded invoice XML file name must match the profile ("factur-x.xml", "xrechnung.xml" for XRechnung, "zugferd-invoice.xml" for ZUGFeRD 2.0, "ZUGFeRD-invoice.xml" for ZUGFeRD 1.0).`,
+	}
+	FXPDF08 = Rule{
+		Code:        "FX-PDF-08",
+		Description: `The document catalog must contain an /AF (associated files) array referencing the file specification of the embedded invoice XML.`,
+	}
+	FXPDF09 = Rule{
+		Code:        "FX-PDF-09",
+		Description: `The /AFRelationship of the embedded invoice XML must be Data, Alternative or Source.`,
+	}
+	FXPDF10 = Rule{
+		Code:        "FX-PDF-10",
+		Description: `The embedded invoice XML file stream must have the MIME subtype "text/xml".`,
+	}
+	FXPDF11 = Rule{
+		Code:        "FX-PDF-11",
+		Description: `The document catalog must contain a PDF/A output intent (/OutputIntents with /S /GTS_PDFA1 and a /DestOutputProfile).`,
+	}
+	FXPDF12 = Rule{
+		Code:        "FX-PDF-12",
+		Description: `The PDF must contain an embedded invoice XML that can be parsed.`,
+	}
 )
@@ -0,0 +1,391 @@
+package einvoice
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/speedata/einvoice/rules"
+)
+
+// nsSBDH is the namespace of the UN/CEFACT Standard Business Document Header
+// used by PEPPOL access points to wrap business documents.
+const nsSBDH = "http://www.unece.org/cefact/namespaces/StandardBusinessDocumentHeader"
+
+// PEPPOL identifier schemes used in the SBDH.
+const (
+	// SBDHParticipantScheme is the Authority of the sender and receiver identifiers.
+	SBDHParticipantScheme = "iso6523-actorid-upis"
+	// SBDHDocumentTypeScheme is the scheme of the DOCUMENTID business scope.
+	SBDHDocumentTypeScheme = "busdox-docid-qns"
+	// SBDHProcessScheme is the scheme of the PROCESSID business scope.
+	SBDHProcessScheme = "cenbii-procid-ubl"
+)
+
+// SBDH business scope types.
+const (
+	sbdhScopeDocumentID = "DOCUMENTID"
+	sbdhScopeProcessID  = "PROCESSID"
+	sbdhScopeCountryC1  = "COUNTRY_C1"
+)
+
+// ErrNoSBDHPayload is returned when a StandardBusinessDocument does not
+// contain a business document next to its header.
+var ErrNoSBDHPayload = errors.New("standard business document contains no payload")
+
+// SBDHParticipant identifies the sender or receiver of a standard business
+// document, for example Authority "iso6523-actorid-upis" and ID "0088:4035811991014".
+type SBDHParticipant struct {
+	Authority string
+	ID        string
+}
+
+// SBDHScope is a business scope entry of the SBDH, such as the document type
+// identifier (DOCUMENTID), the process identifier (PROCESSID) or the country
+// of the sender (COUNTRY_C1).
+type SBDHScope struct {
+	Type               string
+	InstanceIdentifier string
+	Identifier         string
+}
+
+// SBDH is the Standard Business Document Header of a PEPPOL envelope.
+//
+// Invoices wrapped in a StandardBusinessDocument are unwrapped transparently
+// by ParseReader and ParseXMLFile. The header is available via Invoice.SBDH.
+type SBDH struct {
+	HeaderVersion       string
+	Sender              SBDHParticipant
+	Receiver            SBDHParticipant
+	Standard            string // DocumentIdentification/Standard (payload namespace)
+	TypeVersion         string // DocumentIdentification/TypeVersion
+	InstanceIdentifier  string // DocumentIdentification/InstanceIdentifier
+	Type                string // DocumentIdentification/Type (payload root element)
+	CreationDateAndTime time.Time
+	Scopes              []SBDHScope
+}
+
+// DocumentTypeIdentifier returns the PEPPOL document type identifier
+// (business scope DOCUMENTID) or an empty string.
+func (h *SBDH) DocumentTypeIdentifier() string {
+	return h.scope(sbdhScopeDocumentID)
+}
+
+// ProcessIdentifier returns the PEPPOL process identifier (business scope
+// PROCESSID) or an empty string. It corresponds to BT-23 of the payload.
+func (h *SBDH) ProcessIdentifier() string {
+	return h.scope(sbdhScopeProcessID)
+}
+
+func (h *SBDH) scope(typ string) string {
+	for _, s := range h.Scopes {
+		if s.Type == typ {
+			return s.InstanceIdentifier
+		}
+	}
+	return ""
+}
+
+// SBDH returns the Standard Business Document Header the invoice was
+// unwrapped from, or nil if the invoice was not read from an SBDH envelope.
+func (inv *Invoice) SBDH() *SBDH {
+	return inv.sbdh
+}
+
+// parseSBDH reads a StandardBusinessDocument, parses the header and the
+// invoice it contains.
+func parseSBDH(data []byte) (*Invoice, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("cannot read from reader: %w", err)
+	}
+	root := doc.Root()
+
+	hdr := &SBDH{}
+	var payload *etree.Element
+	for _, child := range root.ChildElements() {
+		if child.NamespaceURI() != nsSBDH {
+			payload = child
+			break
+		}
+		if child.Tag == "StandardBusinessDocumentHeader" {
+			if err := parseSBDHHeader(child, hdr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if payload == nil {
+		return nil, ErrNoSBDHPayload
+	}
+
+	// The payload may rely on namespace declarations of the envelope.
+	inner := payload.Copy()
+	for p := payload.Parent(); p != nil; p = p.Parent() {
+		for _, attr := range p.Attr {
+			if (attr.Space == "xmlns" || attr.Space == "" && attr.Key == "xmlns") && inner.SelectAttr(attr.FullKey()) == nil {
+				inner.CreateAttr(attr.FullKey(), attr.Value)
+			}
+		}
+	}
+	innerDoc := etree.NewDocument()
+	innerDoc.SetRoot(inner)
+	buf, err := innerDoc.WriteToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read SBDH payload: %w", err)
+	}
+
+	inv, err := ParseReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("parse SBDH payload: %w", err)
+	}
+	inv.sbdh = hdr
+	return inv, nil
+}
+
+// parseSBDHHeader fills hdr from the StandardBusinessDocumentHeader element.
+func parseSBDHHeader(elt *etree.Element, hdr *SBDH) error {
+	child := func(e *etree.Element, tag string) *etree.Element {
+		if e == nil {
+			return nil
+		}
+		for _, c := range e.ChildElements() {
+			if c.Tag == tag {
+				return c
+			}
+		}
+		return nil
+	}
+	text := func(e *etree.Element, tag string) string {
+		if c := child(e, tag); c != nil {
+			return strings.TrimSpace(c.Text())
+		}
+		return ""
+	}
+	participant := func(tag string) SBDHParticipant {
+		id := child(child(elt, tag), "Identifier")
+		if id == nil {
+			return SBDHParticipant{}
+		}
+		return SBDHParticipant{
+			Authority: id.SelectAttrValue("Authority", ""),
+			ID:        strings.TrimSpace(id.Text()),
+		}
+	}
+
+	hdr.HeaderVersion = text(elt, "HeaderVersion")
+	hdr.Sender = participant("Sender")
+	hdr.Receiver = participant("Receiver")
+
+	docID := child(elt, "DocumentIdentification")
+	hdr.Standard = text(docID, "Standard")
+	hdr.TypeVersion = text(docID, "TypeVersion")
+	hdr.InstanceIdentifier = text(docID, "InstanceIdentifier")
+	hdr.Type = text(docID, "Type")
+	if created := text(docID, "CreationDateAndTime"); created != "" {
+		t, err := parseSBDHTime(created)
+		if err != nil {
+			return fmt.Errorf("invalid SBDH CreationDateAndTime '%s': %w", created, err)
+		}
+		hdr.CreationDateAndTime = t
+	}
+
+	if bs := child(elt, "BusinessScope"); bs != nil {
+		for _, s := range bs.ChildElements() {
+			if s.Tag != "Scope" {
+				continue
+			}
+			hdr.Scopes = append(hdr.Scopes, SBDHScope{
+				Type:               text(s, "Type"),
+				InstanceIdentifier: text(s, "InstanceIdentifier"),
+				Identifier:         text(s, "Identifier"),
+			})
+		}
+	}
+	return nil
+}
+
+// parseSBDHTime parses an xs:dateTime value with or without time zone.
+func parseSBDHTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05.999999999", s)
+}
+
+// NewSBDH creates a PEPPOL Standard Business Document Header for the invoice.
+//
+// The sender and receiver are taken from the seller (BT-34) and buyer (BT-49)
+// electronic addresses, which must be set together with their schemes. The
+// process identifier is BT-23 and the document type identifier is derived
+// from the syntax, the document type and the specification identifier
+// (BT-24). The instance identifier is a random UUID and the creation time is
+// the current time.
+func NewSBDH(inv *Invoice) (*SBDH, error) {
+	sender, err := sbdhParticipant(inv.Seller, "seller (BT-34)")
+	if err != nil {
+		return nil, err
+	}
+	receiver, err := sbdhParticipant(inv.Buyer, "buyer (BT-49)")
+	if err != nil {
+		return nil, err
+	}
+	if inv.BPSpecifiedDocumentContextParameter == "" {
+		return nil, fmt.Errorf("sbdh: business process (BT-23) is required")
+	}
+	if inv.GuidelineSpecifiedDocumentContextParameter == "" {
+		return nil, fmt.Errorf("sbdh: specification identifier (BT-24) is required")
+	}
+
+	var standard, typ, version string
+	switch inv.SchemaType {
+	case UBL:
+		standard, typ, version = nsUBLInvoice, "Invoice", "2.1"
+		if inv.InvoiceTypeCode == 381 {
+			standard, typ = nsUBLCreditNote, "CreditNote"
+		}
+	case CII, SchemaTypeUnknown:
+		standard, typ, version = nsCIIRootInvoice, "CrossIndustryInvoice", "D16B"
+	default:
+		return nil, ErrUnsupportedSchema
+	}
+
+	instanceID, err := newUUID()
+	if err != nil {
+		return nil, fmt.Errorf("sbdh: %w", err)
+	}
+
+	hdr := &SBDH{
+		HeaderVersion:       "1.0",
+		Sender:              sender,
+		Receiver:            receiver,
+		Standard:            standard,
+		TypeVersion:         version,
+		InstanceIdentifier:  instanceID,
+		Type:                typ,
+		CreationDateAndTime: time.Now().UTC().Truncate(time.Second),
+		Scopes: []SBDHScope{
+			{
+				Type:               sbdhScopeDocumentID,
+				InstanceIdentifier: fmt.Sprintf("%s::%s##%s::%s", standard, typ, inv.GuidelineSpecifiedDocumentContextParameter, version),
+				Identifier:         SBDHDocumentTypeScheme,
+			},
+			{
+				Type:               sbdhScopeProcessID,
+				InstanceIdentifier: inv.BPSpecifiedDocumentContextParameter,
+				Identifier:         SBDHProcessScheme,
+			},
+		},
+	}
+	if inv.Seller.PostalAddress != nil && inv.Seller.PostalAddress.CountryID != "" {
+		hdr.Scopes = append(hdr.Scopes, SBDHScope{
+			Type:               sbdhScopeCountryC1,
+			InstanceIdentifier: inv.Seller.PostalAddress.CountryID,
+		})
+	}
+	return hdr, nil
+}
+
+func sbdhParticipant(p Party, role string) (SBDHParticipant, error) {
+	if p.URIUniversalCommunication == "" || p.URIUniversalCommunicationScheme == "" {
+		return SBDHParticipant{}, fmt.Errorf("sbdh: electronic address and scheme of the %s are required", role)
+	}
+	return SBDHParticipant{
+		Authority: SBDHParticipantScheme,
+		ID:        p.URIUniversalCommunicationScheme + ":" + p.URIUniversalCommunication,
+	}, nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// WriteSBDH writes the invoice wrapped in a PEPPOL StandardBusinessDocument.
+// If hdr is nil, the header is created with NewSBDH.
+//
+// WriteSBDH does not perform validation, see Write.
+func (inv *Invoice) WriteSBDH(w io.Writer, hdr *SBDH) error {
+	var err error
+	if hdr == nil {
+		if hdr, err = NewSBDH(inv); err != nil {
+			return err
+		}
+	}
+
+	var payload bytes.Buffer
+	if err = inv.Write(&payload); err != nil {
+		return err
+	}
+	payloadDoc := etree.NewDocument()
+	if _, err = payloadDoc.ReadFrom(&payload); err != nil {
+		return fmt.Errorf("write SBDH: %w", err)
+	}
+
+	doc := etree.NewDocument()
+	doc.CreateProcInst("xml", `version="1.0" encoding="UTF-8"`)
+	root := doc.CreateElement("StandardBusinessDocument")
+	root.CreateAttr("xmlns", nsSBDH)
+
+	sbdh := root.CreateElement("StandardBusinessDocumentHeader")
+	sbdh.CreateElement("HeaderVersion").SetText(hdr.HeaderVersion)
+	writeSBDHParticipant(sbdh, "Sender", hdr.Sender)
+	writeSBDHParticipant(sbdh, "Receiver", hdr.Receiver)
+
+	docID := sbdh.CreateElement("DocumentIdentification")
+	docID.CreateElement("Standard").SetText(hdr.Standard)
+	docID.CreateElement("TypeVersion").SetText(hdr.TypeVersion)
+	docID.CreateElement("InstanceIdentifier").SetText(hdr.InstanceIdentifier)
+	docID.CreateElement("Type").SetText(hdr.Type)
+	docID.CreateElement("CreationDateAndTime").SetText(hdr.CreationDateAndTime.Format(time.RFC3339))
+
+	if len(hdr.Scopes) > 0 {
+		bs := sbdh.CreateElement("BusinessScope")
+		for _, s := range hdr.Scopes {
+			scope := bs.CreateElement("Scope")
+			scope.CreateElement("Type").SetText(s.Type)
+			scope.CreateElement("InstanceIdentifier").SetText(s.InstanceIdentifier)
+			if s.Identifier != "" {
+				scope.CreateElement("Identifier").SetText(s.Identifier)
+			}
+		}
+	}
+
+	root.AddChild(payloadDoc.Root())
+
+	doc.Indent(2)
+	if _, err = doc.WriteTo(w); err != nil {
+		return fmt.Errorf("write SBDH: failed to write to the writer: %w", err)
+	}
+	return nil
+}
+
+func writeSBDHParticipant(parent *etree.Element, tag string, p SBDHParticipant) {
+	id := parent.CreateElement(tag).CreateElement("Identifier")
+	id.CreateAttr("Authority", p.Authority)
+	id.SetText(p.ID)
+}
+
+// validateSBDH checks the envelope the invoice was unwrapped from against the
+// invoice content.
+func (inv *Invoice) validateSBDH() {
+	if inv.sbdh == nil {
+		return
+	}
+	if pid := inv.sbdh.ProcessIdentifier(); pid != "" && pid != inv.BPSpecifiedDocumentContextParameter {
+		inv.addViolation(rules.SBDH_PROCESS_MISMATCH, fmt.Sprintf(
+			"SBDH process identifier '%s' does not match business process (BT-23) '%s'",
+			pid, inv.BPSpecifiedDocumentContextParameter))
+	}
+}
@@ -0,0 +1,226 @@
+package einvoice
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/speedata/einvoice/rules"
+)
+
+const sbdhTestHeader = `<StandardBusinessDocumentHeader>
+    <HeaderVersion>1.0</HeaderVersion>
+    <Sender><Identifier Authority="iso6523-actorid-upis">0088:9482348239847239874</Identifier></Sender>
+    <Receiver><Identifier Authority="iso6523-actorid-upis">0002:FR23342</Identifier></Receiver>
+    <DocumentIdentification>
+      <Standard>urn:oasis:names:specification:ubl:schema:xsd:Invoice-2</Standard>
+      <TypeVersion>2.1</TypeVersion>
+      <InstanceIdentifier>123e4567-e89b-12d3-a456-426614174000</InstanceIdentifier>
+      <Type>Invoice</Type>
+      <CreationDateAndTime>2017-11-13T10:15:00+01:00</CreationDateAndTime>
+    </DocumentIdentification>
+    <BusinessScope>
+      <Scope>
+        <Type>DOCUMENTID</Type>
+        <InstanceIdentifier>urn:oasis:names:specification:ubl:schema:xsd:Invoice-2::Invoice##urn:cen.eu:en16931:2017#compliant#urn:fdc:peppol.eu:2017:poacc:billing:3.0::2.1</InstanceIdentifier>
+        <Identifier>busdox-docid-qns</Identifier>
+      </Scope>
+      <Scope>
+        <Type>PROCESSID</Type>
+        <InstanceIdentifier>%PROCESS%</InstanceIdentifier>
+        <Identifier>cenbii-procid-ubl</Identifier>
+      </Scope>
+    </BusinessScope>
+  </StandardBusinessDocumentHeader>`
+
+// wrapSBDH wraps the given fixture in a StandardBusinessDocument with the
+// given process identifier.
+func wrapSBDH(t *testing.T, fixture, process string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	payload := string(data)
+	if i := strings.Index(payload, "?>"); i >= 0 {
+		payload = payload[i+2:]
+	}
+	header := strings.ReplaceAll(sbdhTestHeader, "%PROCESS%", process)
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<StandardBusinessDocument xmlns="http://www.unece.org/cefact/namespaces/StandardBusinessDocumentHeader">
+  ` + header + payload + `</StandardBusinessDocument>`)
+}
+
+func TestParseSBDH(t *testing.T) {
+	data := wrapSBDH(t, "testdata/peppol/valid/base-example.xml", BPPEPPOLBilling01)
+
+	inv, err := ParseReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	if inv.InvoiceNumber != "Snippet1" {
+		t.Errorf("InvoiceNumber = %q, want Snippet1", inv.InvoiceNumber)
+	}
+	if inv.SchemaType != UBL {
+		t.Errorf("SchemaType = %v, want UBL", inv.SchemaType)
+	}
+
+	hdr := inv.SBDH()
+	if hdr == nil {
+		t.Fatal("SBDH() = nil, want header")
+	}
+	if hdr.Sender.ID != "0088:9482348239847239874" || hdr.Sender.Authority != SBDHParticipantScheme {
+		t.Errorf("Sender = %+v", hdr.Sender)
+	}
+	if hdr.Receiver.ID != "0002:FR23342" {
+		t.Errorf("Receiver = %+v", hdr.Receiver)
+	}
+	if hdr.InstanceIdentifier != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("InstanceIdentifier = %q", hdr.InstanceIdentifier)
+	}
+	if hdr.Type != "Invoice" || hdr.TypeVersion != "2.1" || hdr.Standard != nsUBLInvoice {
+		t.Errorf("DocumentIdentification = %q %q %q", hdr.Standard, hdr.Type, hdr.TypeVersion)
+	}
+	want := time.Date(2017, 11, 13, 9, 15, 0, 0, time.UTC)
+	if !hdr.CreationDateAndTime.Equal(want) {
+		t.Errorf("CreationDateAndTime = %v, want %v", hdr.CreationDateAndTime, want)
+	}
+	if hdr.ProcessIdentifier() != BPPEPPOLBilling01 {
+		t.Errorf("ProcessIdentifier() = %q", hdr.ProcessIdentifier())
+	}
+	if !strings.HasSuffix(hdr.DocumentTypeIdentifier(), "::Invoice##"+SpecPEPPOLBilling30+"::2.1") {
+		t.Errorf("DocumentTypeIdentifier() = %q", hdr.DocumentTypeIdentifier())
+	}
+
+	if err := inv.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestParseSBDHProcessMismatch(t *testing.T) {
+	data := wrapSBDH(t, "testdata/peppol/valid/base-example.xml", "urn:fdc:peppol.eu:2017:poacc:billing:02:1.0")
+
+	inv, err := ParseReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	err = inv.Validate()
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) {
+		t.Fatalf("Validate() error = %v, want ValidationError", err)
+	}
+	if !valErr.HasRule(rules.SBDH_PROCESS_MISMATCH) {
+		t.Errorf("expected %s violation, got %v", rules.SBDH_PROCESS_MISMATCH.Code, valErr.Violations())
+	}
+}
+
+func TestParseSBDHInheritedNamespaces(t *testing.T) {
+	data := `<sh:StandardBusinessDocument xmlns:sh="http://www.unece.org/cefact/namespaces/StandardBusinessDocumentHeader"
+    xmlns:cbc="urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2">
+  <sh:StandardBusinessDocumentHeader><sh:HeaderVersion>1.0</sh:HeaderVersion></sh:StandardBusinessDocumentHeader>
+  <Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2">
+    <cbc:ID>INV-1</cbc:ID>
+    <cbc:IssueDate>2024-01-01</cbc:IssueDate>
+  </Invoice>
+</sh:StandardBusinessDocument>`
+
+	inv, err := ParseReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	if inv.InvoiceNumber != "INV-1" {
+		t.Errorf("InvoiceNumber = %q, want INV-1", inv.InvoiceNumber)
+	}
+	if inv.SBDH().HeaderVersion != "1.0" {
+		t.Errorf("HeaderVersion = %q", inv.SBDH().HeaderVersion)
+	}
+}
+
+func TestParseSBDHNoPayload(t *testing.T) {
+	data := `<StandardBusinessDocument xmlns="http://www.unece.org/cefact/namespaces/StandardBusinessDocumentHeader">
+  <StandardBusinessDocumentHeader><HeaderVersion>1.0</HeaderVersion></StandardBusinessDocumentHeader>
+</StandardBusinessDocument>`
+
+	_, err := ParseReader(strings.NewReader(data))
+	if !errors.Is(err, ErrNoSBDHPayload) {
+		t.Errorf("ParseReader() error = %v, want ErrNoSBDHPayload", err)
+	}
+}
+
+func TestWriteSBDHRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		docType string
+	}{
+		{"UBL", "testdata/peppol/valid/base-example.xml", "Invoice"},
+		{"UBL CreditNote", "testdata/peppol/valid/base-creditnote-correction.xml", "CreditNote"},
+		{"CII", "testdata/cii/en16931/CII_example2.xml", "CrossIndustryInvoice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := ParseXMLFile(tt.fixture)
+			if err != nil {
+				t.Fatalf("ParseXMLFile() error = %v", err)
+			}
+			if inv.BPSpecifiedDocumentContextParameter == "" {
+				inv.BPSpecifiedDocumentContextParameter = BPPEPPOLBilling01
+			}
+			if inv.Seller.URIUniversalCommunication == "" {
+				inv.Seller.URIUniversalCommunication = "4000001123452"
+				inv.Seller.URIUniversalCommunicationScheme = EAS0088
+			}
+			if inv.Buyer.URIUniversalCommunication == "" {
+				inv.Buyer.URIUniversalCommunication = "DE123456789"
+				inv.Buyer.URIUniversalCommunicationScheme = EAS9930
+			}
+
+			var buf bytes.Buffer
+			if err := inv.WriteSBDH(&buf, nil); err != nil {
+				t.Fatalf("WriteSBDH() error = %v", err)
+			}
+
+			inv2, err := ParseReader(&buf)
+			if err != nil {
+				t.Fatalf("ParseReader() error = %v\n%s", err, buf.String())
+			}
+			hdr := inv2.SBDH()
+			if hdr == nil {
+				t.Fatal("SBDH() = nil after round trip")
+			}
+			wantSender := inv.Seller.URIUniversalCommunicationScheme + ":" + inv.Seller.URIUniversalCommunication
+			if hdr.Sender.ID != wantSender {
+				t.Errorf("Sender.ID = %q, want %q", hdr.Sender.ID, wantSender)
+			}
+			wantReceiver := inv.Buyer.URIUniversalCommunicationScheme + ":" + inv.Buyer.URIUniversalCommunication
+			if hdr.Receiver.ID != wantReceiver {
+				t.Errorf("Receiver.ID = %q, want %q", hdr.Receiver.ID, wantReceiver)
+			}
+			if hdr.Type != tt.docType {
+				t.Errorf("Type = %q, want %q", hdr.Type, tt.docType)
+			}
+			if hdr.ProcessIdentifier() != inv.BPSpecifiedDocumentContextParameter {
+				t.Errorf("ProcessIdentifier() = %q, want %q", hdr.ProcessIdentifier(), inv.BPSpecifiedDocumentContextParameter)
+			}
+			if len(hdr.InstanceIdentifier) != 36 {
+				t.Errorf("InstanceIdentifier = %q, want UUID", hdr.InstanceIdentifier)
+			}
+			assertInvoiceEqual(t, inv, inv2)
+		})
+	}
+}
+
+func TestNewSBDHMissingElectronicAddress(t *testing.T) {
+	inv := &Invoice{
+		GuidelineSpecifiedDocumentContextParameter: SpecPEPPOLBilling30,
+		BPSpecifiedDocumentContextParameter:        BPPEPPOLBilling01,
+		Seller:                                     Party{URIUniversalCommunication: "123", URIUniversalCommunicationScheme: EAS0088},
+	}
+	if _, err := NewSBDH(inv); err == nil {
+		t.Error("NewSBDH() error = nil, want error for missing buyer electronic address")
+	}
+}
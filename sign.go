@@ -0,0 +1,118 @@
+package einvoice
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/speedata/einvoice/rules"
+)
+
+// Negate reverses the sign of the document: the invoiced quantities (BT-129),
+// the line net amounts (BT-131), the allowances and charges on document and
+// line level, the VAT breakdown (BG-23), the document totals (BG-22), the
+// prepayments and the partial payment amounts of the payment terms. Prices
+// (BT-146, BT-148) stay positive as required by BR-27 and BR-28. A commercial
+// invoice (380) becomes a credit note (381) and a self-billed invoice (389) a
+// self-billed credit note (261) and vice versa, other type codes are kept.
+//
+// Negate converts a negative invoice, as produced by many billing systems for
+// refunds, into a credit note with positive amounts and back.
+func (inv *Invoice) Negate() {
+	if code, ok := signCounterparts[inv.InvoiceTypeCode]; ok {
+		inv.InvoiceTypeCode = code
+	}
+
+	for i := range inv.InvoiceLines {
+		line := &inv.InvoiceLines[i]
+		line.BilledQuantity = line.BilledQuantity.Neg()
+		line.Total = line.Total.Neg()
+		negateAllowanceCharges(line.InvoiceLineAllowances)
+		negateAllowanceCharges(line.InvoiceLineCharges)
+	}
+	negateAllowanceCharges(inv.SpecifiedTradeAllowanceCharge)
+	for i := range inv.TradeTaxes {
+		tt := &inv.TradeTaxes[i]
+		tt.BasisAmount = tt.BasisAmount.Neg()
+		tt.CalculatedAmount = tt.CalculatedAmount.Neg()
+	}
+	for i := range inv.AdvancePayments {
+		ap := &inv.AdvancePayments[i]
+		ap.PaidAmount = ap.PaidAmount.Neg()
+		for j := range ap.TradeTaxes {
+			ap.TradeTaxes[j].BasisAmount = ap.TradeTaxes[j].BasisAmount.Neg()
+			ap.TradeTaxes[j].CalculatedAmount = ap.TradeTaxes[j].CalculatedAmount.Neg()
+		}
+	}
+	for i := range inv.SpecifiedTradePaymentTerms {
+		pt := &inv.SpecifiedTradePaymentTerms[i]
+		pt.PartialPaymentAmount = pt.PartialPaymentAmount.Neg()
+		for _, adj := range []*PaymentAdjustmentTerms{pt.DiscountTerms, pt.PenaltyTerms} {
+			if adj != nil {
+				adj.BasisAmount = adj.BasisAmount.Neg()
+				adj.ActualAmount = adj.ActualAmount.Neg()
+			}
+		}
+	}
+
+	for _, d := range []*decimal.Decimal{
+		&inv.LineTotal, &inv.AllowanceTotal, &inv.ChargeTotal, &inv.TaxBasisTotal,
+		&inv.TaxTotal, &inv.TaxTotalAccounting, &inv.GrandTotal,
+		&inv.TotalPrepaid, &inv.RoundingAmount, &inv.DuePayableAmount,
+	} {
+		*d = d.Neg()
+	}
+}
+
+// signCounterparts maps the invoice type codes (BT-3) of invoices to those of
+// the corresponding credit notes and vice versa.
+var signCounterparts = map[CodeDocument]CodeDocument{
+	380: 381, // commercial invoice, credit note
+	381: 380,
+	389: 261, // self-billed invoice, self-billed credit note
+	261: 389,
+}
+
+func negateAllowanceCharges(acs []AllowanceCharge) {
+	for i := range acs {
+		acs[i].BasisAmount = acs[i].BasisAmount.Neg()
+		acs[i].ActualAmount = acs[i].ActualAmount.Neg()
+	}
+}
+
+// NormalizeSigns converts a commercial invoice (380) with a negative invoice
+// total amount with VAT (BT-112) into a credit note (381) with positive
+// amounts, and a credit note with a negative total into an invoice, see
+// Negate. Self-billed invoices (389) and credit notes (261) are converted
+// likewise. It reports whether the invoice was changed. Other type codes, such
+// as the corrected invoice (384) which may be negative, are not changed.
+//
+// Receivers, in particular in UBL based networks such as PEPPOL, often reject
+// negative invoices; in UBL only type 381 is written as CreditNote document.
+func (inv *Invoice) NormalizeSigns() bool {
+	if !inv.hasSignConflict() {
+		return false
+	}
+	inv.Negate()
+	return true
+}
+
+// hasSignConflict reports whether the sign of the invoice total amount with
+// VAT (BT-112) contradicts the invoice type code: a negative invoice or a
+// negative credit note, self-billed or not.
+func (inv *Invoice) hasSignConflict() bool {
+	_, ok := signCounterparts[inv.InvoiceTypeCode]
+	return ok && inv.GrandTotal.IsNegative()
+}
+
+// validateSignConvention warns about invoices whose sign contradicts the
+// invoice type code (BR-USER-08).
+func (inv *Invoice) validateSignConvention() {
+	if !inv.hasSignConflict() {
+		return
+	}
+	if inv.InvoiceTypeCode == 380 || inv.InvoiceTypeCode == 389 {
+		inv.addWarning(rules.BRUSER08, fmt.Sprintf("Invoice (%d) has a negative invoice total amount with VAT %s; send it as credit note (%d) with positive amounts", inv.InvoiceTypeCode, inv.GrandTotal.StringFixed(2), signCounterparts[inv.InvoiceTypeCode]))
+		return
+	}
+	inv.addWarning(rules.BRUSER08, fmt.Sprintf("Credit note (%d) has a negative invoice total amount with VAT %s; the credited amounts should be positive", inv.InvoiceTypeCode, inv.GrandTotal.StringFixed(2)))
+}
@@ -0,0 +1,126 @@
+package einvoice
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func hasSignWarning(inv *Invoice) bool {
+	return slices.ContainsFunc(inv.Warnings(), func(w SemanticError) bool { return w.Rule.Code == "BR-USER-08" })
+}
+
+func TestNegate(t *testing.T) {
+	inv := correctionInvoice(t, nil)
+	orig := inv.clone()
+
+	inv.Negate()
+	if inv.InvoiceTypeCode != 381 {
+		t.Errorf("InvoiceTypeCode = %d, want 381", inv.InvoiceTypeCode)
+	}
+	line := inv.InvoiceLines[0]
+	for _, tc := range []struct {
+		name      string
+		got, want decimal.Decimal
+	}{
+		{"BilledQuantity", line.BilledQuantity, decimal.NewFromInt(-8)},
+		{"NetPrice", line.NetPrice, decimal.NewFromInt(120)},
+		{"line allowance", line.InvoiceLineAllowances[0].ActualAmount, decimal.NewFromInt(-40)},
+		{"Total", line.Total, decimal.NewFromInt(-920)},
+		{"allowance", inv.SpecifiedTradeAllowanceCharge[0].ActualAmount, decimal.NewFromInt(-60)},
+		{"BasisAmount", inv.TradeTaxes[0].BasisAmount, orig.TradeTaxes[0].BasisAmount.Neg()},
+		{"AllowanceTotal", inv.AllowanceTotal, decimal.NewFromInt(-60)},
+		{"TaxTotal", inv.TaxTotal, orig.TaxTotal.Neg()},
+		{"DuePayableAmount", inv.DuePayableAmount, orig.DuePayableAmount.Neg()},
+	} {
+		if !tc.got.Equal(tc.want) {
+			t.Errorf("%s = %s, want %s", tc.name, tc.got, tc.want)
+		}
+	}
+
+	// The negated amounts are still consistent
+	if err := inv.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if !hasSignWarning(inv) {
+		t.Errorf("Warnings() = %v, want BR-USER-08", inv.Warnings())
+	}
+
+	if !inv.NormalizeSigns() {
+		t.Fatal("NormalizeSigns() = false, want true")
+	}
+	if inv.InvoiceTypeCode != 380 || !inv.InvoiceLines[0].BilledQuantity.Equal(orig.InvoiceLines[0].BilledQuantity) || !inv.DuePayableAmount.Equal(orig.DuePayableAmount) {
+		t.Errorf("NormalizeSigns() = type %d, due %s, want the original invoice", inv.InvoiceTypeCode, inv.DuePayableAmount)
+	}
+	if inv.NormalizeSigns() {
+		t.Error("NormalizeSigns() changed a positive invoice")
+	}
+}
+
+func TestNormalizeSigns_NegativeInvoice(t *testing.T) {
+	// A refund exported by a billing system as negative invoice
+	inv := correctionInvoice(t, nil)
+	inv.Negate()
+	inv.InvoiceTypeCode = 380
+	inv.InvoiceReferencedDocument = []ReferencedDocument{{ID: "RE-2025-000"}}
+	if err := inv.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if !hasSignWarning(inv) {
+		t.Errorf("Warnings() = %v, want BR-USER-08", inv.Warnings())
+	}
+
+	if !inv.NormalizeSigns() || inv.InvoiceTypeCode != 381 || !inv.GrandTotal.IsPositive() {
+		t.Fatalf("NormalizeSigns() = type %d, total %s, want a positive credit note", inv.InvoiceTypeCode, inv.GrandTotal)
+	}
+	if err := inv.Validate(); err != nil || hasSignWarning(inv) {
+		t.Errorf("Validate() = %v, warnings %v", err, inv.Warnings())
+	}
+
+	inv.SchemaType = UBL
+	xml := writeWithOptions(t, inv, nil)
+	if !strings.Contains(xml, "<CreditNote ") {
+		t.Error("normalized credit note not written as UBL CreditNote document")
+	}
+	parsed, err := ParseReader(strings.NewReader(xml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.InvoiceTypeCode != 381 || !parsed.InvoiceLines[0].BilledQuantity.IsPositive() {
+		t.Errorf("parsed type %d, quantity %s", parsed.InvoiceTypeCode, parsed.InvoiceLines[0].BilledQuantity)
+	}
+}
+
+func TestNormalizeSigns_Corrective(t *testing.T) {
+	inv := correctionInvoice(t, nil)
+	inv.Negate()
+	inv.InvoiceTypeCode = 384
+	if inv.NormalizeSigns() {
+		t.Error("NormalizeSigns() changed a negative corrected invoice (384)")
+	}
+	inv.Validate()
+	if hasSignWarning(inv) {
+		t.Errorf("Warnings() = %v, want no BR-USER-08", inv.Warnings())
+	}
+}
+
+func TestNormalizeSigns_SelfBilled(t *testing.T) {
+	inv := correctionInvoice(t, nil)
+	inv.InvoiceTypeCode = 389
+	inv.Negate()
+	if inv.InvoiceTypeCode != 261 {
+		t.Errorf("InvoiceTypeCode = %d, want 261", inv.InvoiceTypeCode)
+	}
+
+	// A negative self-billed invoice is a self-billed credit note
+	inv.InvoiceTypeCode = 389
+	inv.Validate()
+	if !hasSignWarning(inv) {
+		t.Errorf("Warnings() = %v, want BR-USER-08", inv.Warnings())
+	}
+	if !inv.NormalizeSigns() || inv.InvoiceTypeCode != 261 || inv.GrandTotal.IsNegative() {
+		t.Errorf("NormalizeSigns() = type %d, total %s, want 261 with positive amounts", inv.InvoiceTypeCode, inv.GrandTotal)
+	}
+}
@@ -585,6 +585,10 @@ func (inv *Invoice) validateCore() {
 			if !allowsNegativeAmounts() && inv.SpecifiedTradeAllowanceCharge[i].BasisAmount.LessThan(decimal.Zero) {
 				inv.addViolation(rules.BRUSER02, "Document level allowance base amount must not be negative")
 			}
+			// BR-USER-11 Logistics service charges are charges only
+			if inv.SpecifiedTradeAllowanceCharge[i].LogisticsServiceCharge {
+				inv.addViolation(rules.BRUSER11, fmt.Sprintf("Document level allowance %q is marked as logistics service charge", inv.SpecifiedTradeAllowanceCharge[i].Reason))
+			}
 		}
 	}
 
@@ -693,6 +697,17 @@ func (inv *Invoice) validateCore() {
 		inv.addViolation(rules.BR53, "Tax total in accounting currency must be specified when tax currency code is provided")
 	}
 
+	// BR-USER-07: BT-111 must match BT-110 converted with the exchange rate.
+	// The VAT category tax amounts may be converted and rounded one by one,
+	// so the tolerance is 0.01 per VAT breakdown.
+	if rate, ok := inv.taxExchangeRate(); ok && !inv.TaxTotalAccounting.IsZero() {
+		expected := roundHalfUp(inv.TaxTotal.Mul(rate), 2)
+		tolerance := decimal.New(1, -2).Mul(decimal.NewFromInt(int64(max(1, len(inv.TradeTaxes)))))
+		if inv.TaxTotalAccounting.Sub(expected).Abs().GreaterThan(tolerance) {
+			inv.addViolation(rules.BRUSER07, fmt.Sprintf("Tax total in accounting currency %s %s does not match tax total %s × exchange rate %s = %s", inv.TaxTotalAccounting.StringFixed(2), inv.TaxCurrencyCode, inv.TaxTotal.StringFixed(2), rate, expected.StringFixed(2)))
+		}
+	}
+
 	// Validate TaxTotalAmount currency consistency
 	// EN 16931 specifies only BT-110 (invoice currency) and BT-111 (accounting currency) are allowed
 	for _, unexpectedCurrency := range inv.unexpectedTaxCurrencies {
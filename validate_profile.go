@@ -0,0 +1,51 @@
+package einvoice
+
+import (
+	"fmt"
+
+	"github.com/speedata/einvoice/rules"
+)
+
+// validateProfile reports content that is not allowed in the Factur-X profile
+// declared in BT-24 (FX-PROFILE-01 to FX-PROFILE-04). The writer omits such
+// content, so it would be lost when the invoice is written. The checks share
+// the profile restrictions with ConvertProfile.
+func (inv *Invoice) validateProfile() {
+	level := inv.ProfileLevel()
+	if level == 0 || level == levelExtended {
+		return
+	}
+	name := GetProfileName(inv.GuidelineSpecifiedDocumentContextParameter)
+	if name == "Unknown" && inv.isPEPPOL() {
+		name = "PEPPOL BIS Billing 3.0"
+	}
+
+	// The checks do not modify the invoice, so a shallow copy without the
+	// lines avoids reporting the content of lines which are not allowed at all.
+	checked := inv
+	if level < levelBasic {
+		inv.addProfileViolations(rules.FXPROFILE03, name, inv.lineContent())
+		cp := *inv
+		cp.InvoiceLines = nil
+		checked = &cp
+	}
+	inv.addProfileViolations(rules.FXPROFILE01, name, checked.extendedContent())
+	if level < levelEN16931 {
+		inv.addProfileViolations(rules.FXPROFILE02, name, checked.en16931Content())
+	}
+	if level < levelBasicWL {
+		inv.addProfileViolations(rules.FXPROFILE04, name, checked.basicWLContent())
+	}
+}
+
+// addProfileViolations adds a violation of rule for each content found by a
+// profile restriction check.
+func (inv *Invoice) addProfileViolations(rule rules.Rule, profile string, changes profileChanges) {
+	for _, c := range changes {
+		field := c.Field
+		if c.Term != "" {
+			field += " (" + c.Term + ")"
+		}
+		inv.addViolation(rule, fmt.Sprintf("%s not allowed in the %s profile: %s", field, profile, c.Text))
+	}
+}
@@ -0,0 +1,109 @@
+package einvoice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// profileViolations returns the violations of the rule code.
+func profileViolations(t *testing.T, inv *Invoice, code string) []SemanticError {
+	t.Helper()
+	_ = inv.Validate()
+	var found []SemanticError
+	for _, v := range inv.violations {
+		if v.Rule.Code == code {
+			found = append(found, v)
+		}
+	}
+	return found
+}
+
+func TestValidateProfile_ExtendedContent(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/extended/zf25-subline-group-bundle.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := profileViolations(t, inv, "FX-PROFILE-01"); len(got) != 0 {
+		t.Fatalf("Extended invoice has FX-PROFILE-01 violations: %v", got)
+	}
+
+	orig, err := ParseXMLFile("testdata/cii/extended/zf25-subline-group-bundle.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.GuidelineSpecifiedDocumentContextParameter = SpecEN16931
+	orig.GuidelineSpecifiedDocumentContextParameter = SpecEN16931
+	if got := profileViolations(t, inv, "FX-PROFILE-01"); len(got) == 0 {
+		t.Error("sub invoice lines in EN 16931 not reported")
+	}
+	// The check must not modify the invoice.
+	assertInvoiceEqual(t, orig, inv)
+
+	if _, err := inv.ConvertProfile(SpecEN16931); err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Validate(); err != nil {
+		t.Errorf("converted invoice: Validate() error = %v", err)
+	}
+}
+
+func TestValidateProfile_Basic(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/basic/zugferd-basic-1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	inv.InvoiceLines[0].Description = "not in Basic"
+	inv.SpecifiedProcuringProjectID = "P-1"
+	got := profileViolations(t, inv, "FX-PROFILE-02")
+	if len(got) != 2 {
+		t.Errorf("got %d FX-PROFILE-02 violations, want 2: %v", len(got), got)
+	}
+	var valErr *ValidationError
+	if err := inv.Validate(); !errors.As(err, &valErr) || !valErr.HasRuleCode("FX-PROFILE-02") {
+		t.Errorf("Validate() error = %v, want FX-PROFILE-02", err)
+	}
+}
+
+func TestValidateProfile_WithoutLines(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.GuidelineSpecifiedDocumentContextParameter = SpecFacturXBasicWL
+	if got := profileViolations(t, inv, "FX-PROFILE-03"); len(got) != 1 {
+		t.Errorf("got %d FX-PROFILE-03 violations, want 1: %v", len(got), got)
+	}
+	// The content of the lines is covered by FX-PROFILE-03.
+	for _, v := range profileViolations(t, inv, "FX-PROFILE-02") {
+		if strings.HasPrefix(v.Text, "InvoiceLines") {
+			t.Errorf("line content reported: %s", v.Text)
+		}
+	}
+}
+
+func TestValidateProfile_Minimum(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/minimum/zugferd-minimum-buchungshilfe.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := profileViolations(t, inv, "FX-PROFILE-04"); len(got) != 0 {
+		t.Fatalf("Minimum fixture has FX-PROFILE-04 violations: %v", got)
+	}
+	inv.Notes = []Note{{Text: "note"}}
+	inv.PaymentReference = "REF"
+	if got := profileViolations(t, inv, "FX-PROFILE-04"); len(got) != 2 {
+		t.Errorf("got %d FX-PROFILE-04 violations, want 2: %v", len(got), got)
+	}
+}
@@ -11,6 +11,19 @@ import (
 // given category code from the invoice lines and the document-level
 // allowances/charges, and returns the number of contributing amounts.
 //
+// The result is rounded to two decimals (commercial rounding) ready to compare
+// against the declared breakdown amount. The returned count drives the EXTENDED
+// per-amount tolerance in checkVATCategoryBasis.
+func (inv *Invoice) sumDetailLineBasis(category string, rate decimal.Decimal, matchRate bool) (decimal.Decimal, int) {
+	components := inv.detailLineBasis(category, rate, matchRate)
+	return roundHalfUp(sumComponents(components), 2), len(components)
+}
+
+// detailLineBasis returns the amounts making up the VAT category taxable
+// amount (BT-116) for the given category code: the line net amounts (BT-131)
+// and the document-level allowances (negative, BT-92) and charges (BT-99).
+// ExplainTotals lists them, sumDetailLineBasis adds them up.
+//
 // Only detail lines contribute: sub invoice line aggregation lines (GROUP /
 // INFORMATION, BT-X-8) are excluded via isDetailLine so their subtotal or
 // informational amounts are never double counted into a VAT basis (EXTENDED,
@@ -21,13 +34,8 @@ import (
 // rate contribute (used by categories that validate one breakdown entry per
 // distinct rate, e.g. Standard rated); categories with a single rate pass
 // matchRate=false to sum the whole category.
-//
-// The result is rounded to two decimals (commercial rounding) ready to compare
-// against the declared breakdown amount. The returned count drives the EXTENDED
-// per-amount tolerance in checkVATCategoryBasis.
-func (inv *Invoice) sumDetailLineBasis(category string, rate decimal.Decimal, matchRate bool) (decimal.Decimal, int) {
-	basis := decimal.Zero
-	count := 0
+func (inv *Invoice) detailLineBasis(category string, rate decimal.Decimal, matchRate bool) []TotalComponent {
+	var components []TotalComponent
 	for i := range inv.InvoiceLines {
 		line := &inv.InvoiceLines[i]
 		if !line.isDetailLine() || line.TaxCategoryCode != category {
@@ -36,8 +44,7 @@ func (inv *Invoice) sumDetailLineBasis(category string, rate decimal.Decimal, ma
 		if matchRate && !line.TaxRateApplicablePercent.Equal(rate) {
 			continue
 		}
-		basis = basis.Add(line.Total)
-		count++
+		components = append(components, TotalComponent{Term: "BT-131", Label: "line " + line.LineID, Amount: line.Total})
 	}
 	for i := range inv.SpecifiedTradeAllowanceCharge {
 		ac := &inv.SpecifiedTradeAllowanceCharge[i]
@@ -48,13 +55,12 @@ func (inv *Invoice) sumDetailLineBasis(category string, rate decimal.Decimal, ma
 			continue
 		}
 		if ac.ChargeIndicator {
-			basis = basis.Add(ac.ActualAmount)
+			components = append(components, TotalComponent{Term: "BT-99", Label: allowanceChargeLabel(ac, i), Amount: ac.ActualAmount})
 		} else {
-			basis = basis.Sub(ac.ActualAmount)
+			components = append(components, TotalComponent{Term: "BT-92", Label: allowanceChargeLabel(ac, i), Amount: ac.ActualAmount.Neg()})
 		}
-		count++
 	}
-	return roundHalfUp(basis, 2), count
+	return components
 }
 
 // checkVATCategoryBasis compares the declared VAT category taxable amount
@@ -102,13 +102,16 @@ func (inv *Invoice) validateVATIntracommunity() {
 
 	// BR-IC-6 Innergemeinschaftliche Lieferung
 	// Verify taxable amount calculation for category K
-	for i := range inv.TradeTaxes {
-		if inv.TradeTaxes[i].CategoryCode == "K" {
-			// Detail lines only; aggregation lines (GROUP / INFORMATION) are
-			// excluded so they are not double counted (EXTENDED).
-			expectedBasis, _ := inv.sumDetailLineBasis("K", decimal.Zero, false)
-			if !inv.TradeTaxes[i].BasisAmount.Equal(expectedBasis) {
-				inv.addViolation(rules.BRIC6, fmt.Sprintf("Intra-community supply taxable amount mismatch: got %s, expected %s", inv.TradeTaxes[i].BasisAmount.StringFixed(2), expectedBasis.StringFixed(2)))
+	// Like BR-IC-8 this needs the line items, so it does not apply to BasicWL and Minimum.
+	if inv.ProfileLevel() >= levelBasic || (inv.ProfileLevel() == 0 && len(inv.InvoiceLines) > 0) {
+		for i := range inv.TradeTaxes {
+			if inv.TradeTaxes[i].CategoryCode == "K" {
+				// Detail lines only; aggregation lines (GROUP / INFORMATION) are
+				// excluded so they are not double counted (EXTENDED).
+				expectedBasis, _ := inv.sumDetailLineBasis("K", decimal.Zero, false)
+				if !inv.TradeTaxes[i].BasisAmount.Equal(expectedBasis) {
+					inv.addViolation(rules.BRIC6, fmt.Sprintf("Intra-community supply taxable amount mismatch: got %s, expected %s", inv.TradeTaxes[i].BasisAmount.StringFixed(2), expectedBasis.StringFixed(2)))
+				}
 			}
 		}
 	}
@@ -297,6 +297,31 @@ func TestBRIC6_TaxableAmountRoundingPrecision(t *testing.T) {
 	}
 }
 
+func TestBRIC6_BasicWLWithoutLines(t *testing.T) {
+	t.Parallel()
+
+	// BasicWL invoices have no lines, the VAT breakdown carries the taxable amount
+	inv := Invoice{
+		GuidelineSpecifiedDocumentContextParameter: SpecFacturXBasicWL,
+		TradeTaxes: []TradeTax{
+			{
+				CategoryCode: "K",
+				BasisAmount:  decimal.NewFromFloat(100.0),
+			},
+		},
+		Seller: Party{VATaxRegistration: "DE123"},
+		Buyer:  Party{VATaxRegistration: "DE456"},
+	}
+
+	_ = inv.Validate()
+
+	for _, v := range inv.violations {
+		if v.Rule.Code == "BR-IC-06" {
+			t.Errorf("unexpected BR-IC-06 violation for BasicWL: %s", v.Text)
+		}
+	}
+}
+
 func TestBRIC7_NonZeroVATAmount(t *testing.T) {
 	t.Parallel()
 
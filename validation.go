@@ -245,6 +245,11 @@ func (inv *Invoice) Validate() error {
 		inv.validateCore()
 		inv.validateCalculations()
 		inv.validateDecimals()
+		inv.validateSignConvention()
+		inv.validateAdvancePayments()
+
+		// Content not allowed in the declared Factur-X profile (FX-PROFILE-*)
+		inv.validateProfile()
 
 		// Auto-detect and run PEPPOL validation based on specification identifier
 		if inv.isPEPPOL() {
@@ -268,6 +273,12 @@ func (inv *Invoice) Validate() error {
 		//   - Sweden (isSwedish)
 	}
 
+	// Invoices unwrapped from a PEPPOL SBDH envelope: header must match content
+	inv.validateSBDH()
+
+	// Invoices read from a PDF: XMP conformance level must match BT-24
+	inv.validatePDFConformanceLevel()
+
 	// Return error if violations exist (include warnings for convenience)
 	if len(inv.violations) > 0 {
 		return &ValidationError{
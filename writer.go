@@ -1,10 +1,15 @@
 package einvoice
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"regexp"
+	"strings"
+	"unicode"
 
+	"github.com/beevik/etree"
 	"github.com/shopspring/decimal"
 )
 
@@ -80,14 +85,210 @@ var ErrUnsupportedSchema = errors.New("unsupported schema")
 //	inv.UpdateTotals()
 //	err := inv.Write(os.Stdout)
 func (inv *Invoice) Write(w io.Writer) error {
+	return inv.WriteWithOptions(w, nil)
+}
+
+// WriteWithOptions writes the invoice as XML like Write, with control over the
+// serialization: compact or indented output, the XML declaration and
+// encoding, the namespace prefixes and the schema location. If opts is nil,
+// the output is the same as with Write.
+//
+// Invalid options (an unsupported encoding, an invalid or duplicate
+// namespace prefix) return an error wrapping ErrWrite.
+//
+// Example for a compact UBL invoice with a default namespace for the basic
+// components:
+//
+//	err := inv.WriteWithOptions(w, &einvoice.WriteOptions{
+//		Compact:        true,
+//		XMLDeclaration: true,
+//		Prefixes: map[string]string{
+//			"urn:oasis:names:specification:ubl:schema:xsd:Invoice-2":                  "ubl",
+//			"urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2": "",
+//		},
+//	})
+func (inv *Invoice) WriteWithOptions(w io.Writer, opts *WriteOptions) error {
 	switch inv.SchemaType {
 	case UBL:
-		return writeUBL(inv, w)
+		return writeUBL(inv, w, opts)
 	case CII, SchemaTypeUnknown:
 		// Programmatically created invoices have SchemaTypeUnknown (zero value)
 		// Treat them the same as CII for writing
-		return writeCII(inv, w)
+		return writeCII(inv, w, opts)
 	default:
 		return ErrUnsupportedSchema
 	}
 }
+
+// WriteOptions control the XML serialization of WriteWithOptions. The zero
+// value (and nil) produces the same output as Write.
+//
+// The output is deterministic: the same invoice written with the same options
+// always results in the same bytes, so the XML can be hashed and signed.
+type WriteOptions struct {
+	// Compact writes the XML without indentation and line breaks.
+	Compact bool
+	// Indent is the number of spaces per nesting level. Zero means two
+	// spaces, a negative value indents with tabs. Ignored if Compact is set.
+	Indent int
+	// XMLDeclaration writes an XML declaration with the encoding. The
+	// declaration is always written for encodings other than UTF-8.
+	XMLDeclaration bool
+	// Encoding is the character encoding of the output: "UTF-8" (default),
+	// "ISO-8859-1" or "US-ASCII". Characters that cannot be represented in
+	// the encoding are written as character references.
+	Encoding string
+	// Prefixes maps namespace URIs to the prefixes used in the output, for
+	// example the CII namespace
+	// "urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100"
+	// to "ram". The empty prefix declares the namespace as default namespace.
+	// Namespaces not in the map keep their standard prefix.
+	Prefixes map[string]string
+	// SchemaLocation is written as xsi:schemaLocation attribute on the root
+	// element, for example
+	// "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100 Factur-X_1.08_EN16931.xsd".
+	SchemaLocation string
+}
+
+const nsXSI = "http://www.w3.org/2001/XMLSchema-instance"
+
+var ncNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// encoding returns the canonical name of the output encoding and the highest
+// code point that can be written without a character reference.
+func (opts *WriteOptions) encoding() (string, rune, error) {
+	if opts == nil || opts.Encoding == "" {
+		return "UTF-8", unicode.MaxRune, nil
+	}
+	switch strings.ToUpper(opts.Encoding) {
+	case "UTF-8", "UTF8":
+		return "UTF-8", unicode.MaxRune, nil
+	case "ISO-8859-1", "LATIN1", "LATIN-1":
+		return "ISO-8859-1", 0xff, nil
+	case "US-ASCII", "ASCII":
+		return "US-ASCII", 0x7f, nil
+	}
+	return "", 0, fmt.Errorf("%w: unsupported encoding %q", ErrWrite, opts.Encoding)
+}
+
+// apply sets the namespace prefixes, the schema location, the XML declaration
+// and the indentation of the document.
+func (opts *WriteOptions) apply(doc *etree.Document) error {
+	if opts == nil {
+		doc.Indent(2)
+		return nil
+	}
+	enc, _, err := opts.encoding()
+	if err != nil {
+		return err
+	}
+	root := doc.Root()
+	if len(opts.Prefixes) > 0 {
+		if err = renamePrefixes(root, opts.Prefixes); err != nil {
+			return err
+		}
+	}
+	if opts.SchemaLocation != "" {
+		if root.SelectAttr("xmlns:xsi") != nil {
+			return fmt.Errorf("%w: prefix xsi is already in use", ErrWrite)
+		}
+		root.CreateAttr("xmlns:xsi", nsXSI)
+		root.CreateAttr("xsi:schemaLocation", opts.SchemaLocation)
+	}
+	if opts.XMLDeclaration || enc != "UTF-8" {
+		doc.InsertChildAt(0, etree.NewProcInst("xml", `version="1.0" encoding="`+enc+`"`))
+	}
+	switch {
+	case opts.Compact:
+	case opts.Indent < 0:
+		doc.IndentTabs()
+	case opts.Indent == 0:
+		doc.Indent(2)
+	default:
+		doc.Indent(opts.Indent)
+	}
+	return nil
+}
+
+// writeTo writes the document in the requested encoding.
+func (opts *WriteOptions) writeTo(doc *etree.Document, w io.Writer) error {
+	_, maxRune, err := opts.encoding()
+	if err != nil {
+		return err
+	}
+	if maxRune == unicode.MaxRune {
+		_, err = doc.WriteTo(w)
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err = doc.WriteTo(&buf); err != nil {
+		return err
+	}
+	// Markup is ASCII only, so all other characters are in text or attribute
+	// values where character references are allowed.
+	out := make([]byte, 0, buf.Len())
+	for _, r := range buf.String() {
+		if r > maxRune {
+			out = fmt.Appendf(out, "&#%d;", r)
+		} else {
+			out = append(out, byte(r))
+		}
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// renamePrefixes replaces the namespace prefixes declared on the root element
+// according to prefixes (namespace URI to prefix) and renames all elements.
+func renamePrefixes(root *etree.Element, prefixes map[string]string) error {
+	rename := map[string]string{}
+	used := map[string]string{}
+	var decls []etree.Attr
+	for _, attr := range root.Attr {
+		var prefix string
+		switch {
+		case attr.Space == "xmlns":
+			prefix = attr.Key
+		case attr.Space == "" && attr.Key == "xmlns":
+			prefix = ""
+		default:
+			continue
+		}
+		newPrefix, ok := prefixes[attr.Value]
+		if !ok {
+			newPrefix = prefix
+		} else if newPrefix != "" && (!ncNameRE.MatchString(newPrefix) || strings.HasPrefix(strings.ToLower(newPrefix), "xml")) {
+			return fmt.Errorf("%w: invalid namespace prefix %q", ErrWrite, newPrefix)
+		}
+		if other, ok := used[newPrefix]; ok {
+			return fmt.Errorf("%w: prefix %q is used for %s and %s", ErrWrite, newPrefix, other, attr.Value)
+		}
+		used[newPrefix] = attr.Value
+		rename[prefix] = newPrefix
+		decls = append(decls, attr)
+	}
+	for _, attr := range decls {
+		root.RemoveAttr(attr.FullKey())
+	}
+	// Keep the declarations in their original order.
+	for _, attr := range decls {
+		var prefix string
+		if attr.Space == "xmlns" {
+			prefix = attr.Key
+		}
+		if newPrefix := rename[prefix]; newPrefix == "" {
+			root.CreateAttr("xmlns", attr.Value)
+		} else {
+			root.CreateAttr("xmlns:"+newPrefix, attr.Value)
+		}
+	}
+	var walk func(e *etree.Element)
+	walk = func(e *etree.Element) {
+		e.Space = rename[e.Space]
+		for _, c := range e.ChildElements() {
+			walk(c)
+		}
+	}
+	walk(root)
+	return nil
+}
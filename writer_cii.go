@@ -150,9 +150,13 @@ func writeCIIramIncludedSupplyChainTradeLineItem(invoiceLine *InvoiceLine, inv *
 		// BT-149: Item price base quantity (no decimal restriction per EN 16931)
 		bq.SetText(invoiceLine.BasisQuantity.String())
 	}
-	bq := lineItem.CreateElement("ram:SpecifiedLineTradeDelivery").CreateElement("ram:BilledQuantity")
+	sltd := lineItem.CreateElement("ram:SpecifiedLineTradeDelivery")
+	bq := sltd.CreateElement("ram:BilledQuantity")
 	bq.CreateAttr("unitCode", invoiceLine.BilledQuantityUnit)
 	bq.SetText(invoiceLine.BilledQuantity.StringFixed(4))
+	if is(levelExtended, inv) {
+		writeCIILineTradeDeliveryExtended(invoiceLine, sltd)
+	}
 
 	slts := lineItem.CreateElement("ram:SpecifiedLineTradeSettlement")
 
@@ -257,6 +261,40 @@ func writeCIIramIncludedSupplyChainTradeLineItem(invoiceLine *InvoiceLine, inv *
 	}
 }
 
+// writeCIILineTradeDeliveryExtended writes the Extended elements of the line
+// delivery after the billed quantity.
+func writeCIILineTradeDeliveryExtended(invoiceLine *InvoiceLine, sltd *etree.Element) {
+	if !invoiceLine.PackageQuantity.IsZero() {
+		pq := sltd.CreateElement("ram:PackageQuantity")
+		if invoiceLine.PackageQuantityUnit != "" {
+			pq.CreateAttr("unitCode", invoiceLine.PackageQuantityUnit)
+		}
+		pq.SetText(invoiceLine.PackageQuantity.StringFixed(4))
+	}
+	if !invoiceLine.ActualDeliveryDate.IsZero() {
+		addTimeCIIUDT(sltd.CreateElement("ram:ActualDeliverySupplyChainEvent").CreateElement("ram:OccurrenceDateTime"), invoiceLine.ActualDeliveryDate)
+	}
+	writeCIILineReference(sltd, "ram:DespatchAdviceReferencedDocument", invoiceLine.DespatchAdviceReferencedDocument)
+	writeCIILineReference(sltd, "ram:DeliveryNoteReferencedDocument", invoiceLine.DeliveryNoteReferencedDocument)
+}
+
+// writeCIILineReference writes a reference to a line of another document.
+func writeCIILineReference(parent *etree.Element, name string, ref *LineReference) {
+	if ref == nil {
+		return
+	}
+	elt := parent.CreateElement(name)
+	if ref.ID != "" {
+		elt.CreateElement("ram:IssuerAssignedID").SetText(ref.ID)
+	}
+	if ref.LineID != "" {
+		elt.CreateElement("ram:LineID").SetText(ref.LineID)
+	}
+	if !ref.Date.IsZero() {
+		addTimeCIIQDT(elt.CreateElement("ram:FormattedIssueDateTime"), ref.Date)
+	}
+}
+
 func writeCIIParty(inv *Invoice, party *Party, parent *etree.Element, partyType CodePartyType) {
 	for _, id := range party.ID {
 		parent.CreateElement("ram:ID").SetText(id)
@@ -431,6 +469,14 @@ func writeCIIramApplicableHeaderTradeDelivery(inv *Invoice, parent *etree.Elemen
 	if inv.ShipTo != nil {
 		writeCIIParty(inv, inv.ShipTo, elt.CreateElement("ram:ShipToTradeParty"), CShipToParty)
 	}
+	if is(levelExtended, inv) {
+		if inv.UltimateShipTo != nil {
+			writeCIIParty(inv, inv.UltimateShipTo, elt.CreateElement("ram:UltimateShipToTradeParty"), CShipToParty)
+		}
+		if inv.ShipFrom != nil {
+			writeCIIParty(inv, inv.ShipFrom, elt.CreateElement("ram:ShipFromTradeParty"), CUnknownParty)
+		}
+	}
 
 	// BT-72: Actual delivery date (BasicWL and above)
 	if is(levelBasicWL, inv) && !inv.OccurrenceDateTime.IsZero() {
@@ -446,6 +492,14 @@ func writeCIIramApplicableHeaderTradeDelivery(inv *Invoice, parent *etree.Elemen
 	if inv.ReceivingAdviceReferencedDocument != "" {
 		elt.CreateElement("ram:ReceivingAdviceReferencedDocument").CreateElement("ram:IssuerAssignedID").SetText(inv.ReceivingAdviceReferencedDocument)
 	}
+	// EXTENDED: delivery note reference
+	if dn := inv.DeliveryNoteReferencedDocument; dn != nil && is(levelExtended, inv) {
+		dnElt := elt.CreateElement("ram:DeliveryNoteReferencedDocument")
+		dnElt.CreateElement("ram:IssuerAssignedID").SetText(dn.ID)
+		if !dn.Date.IsZero() {
+			addTimeCIIQDT(dnElt.CreateElement("ram:FormattedIssueDateTime"), dn.Date)
+		}
+	}
 }
 
 func writeCIIramSpecifiedTradeSettlementHeaderMonetarySummation(inv *Invoice, parent *etree.Element) {
@@ -517,11 +571,33 @@ func writeCIIramApplicableHeaderTradeSettlement(inv *Invoice, parent *etree.Elem
 	// BT-5: Invoice currency code (required)
 	elt.CreateElement("ram:InvoiceCurrencyCode").SetText(inv.InvoiceCurrencyCode)
 
+	// EXTENDED: invoicer and invoicee
+	if is(levelExtended, inv) {
+		if inv.InvoicerTradeParty != nil {
+			writeCIIParty(inv, inv.InvoicerTradeParty, elt.CreateElement("ram:InvoicerTradeParty"), CSellerParty)
+		}
+		if inv.InvoiceeTradeParty != nil {
+			writeCIIParty(inv, inv.InvoiceeTradeParty, elt.CreateElement("ram:InvoiceeTradeParty"), CBuyerParty)
+		}
+	}
+
 	// PayeeTradeParty BG-10
 	if pt := inv.PayeeTradeParty; pt != nil {
 		writeCIIParty(inv, pt, elt.CreateElement("ram:PayeeTradeParty"), CPayeeParty)
 	}
 
+	// EXTENDED: exchange rate to the VAT accounting currency
+	if ce := inv.TaxCurrencyExchange; ce != nil && is(levelExtended, inv) {
+		ceElt := elt.CreateElement("ram:TaxApplicableTradeCurrencyExchange")
+		ceElt.CreateElement("ram:SourceCurrencyCode").SetText(ce.SourceCurrencyCode)
+		ceElt.CreateElement("ram:TargetCurrencyCode").SetText(ce.TargetCurrencyCode)
+		// CII has no operator, the rate is always multiplied
+		ceElt.CreateElement("ram:ConversionRate").SetText(ce.multiplyRate().String())
+		if !ce.ConversionRateDate.IsZero() {
+			addTimeCIIUDT(ceElt.CreateElement("ram:ConversionRateDateTime"), ce.ConversionRateDate)
+		}
+	}
+
 	if is(levelBasicWL, inv) {
 		for i := range inv.PaymentMeans {
 			pmElt := elt.CreateElement("ram:SpecifiedTradeSettlementPaymentMeans")
@@ -604,6 +680,9 @@ func writeCIIramApplicableHeaderTradeSettlement(inv *Invoice, parent *etree.Elem
 	}
 
 	for i := range inv.SpecifiedTradeAllowanceCharge {
+		if inv.SpecifiedTradeAllowanceCharge[i].LogisticsServiceCharge && inv.SpecifiedTradeAllowanceCharge[i].ChargeIndicator && is(levelExtended, inv) {
+			continue
+		}
 		stacElt := elt.CreateElement("ram:SpecifiedTradeAllowanceCharge")
 		stacElt.CreateElement("ram:ChargeIndicator").CreateElement("udt:Indicator").SetText(strconv.FormatBool(inv.SpecifiedTradeAllowanceCharge[i].ChargeIndicator))
 		// BT-94, BT-101: CalculationPercent is optional - must come before BasisAmount per CII sequence
@@ -629,6 +708,22 @@ func writeCIIramApplicableHeaderTradeSettlement(inv *Invoice, parent *etree.Elem
 		ctt.CreateElement("ram:RateApplicablePercent").SetText(formatPercent(inv.SpecifiedTradeAllowanceCharge[i].CategoryTradeTaxRateApplicablePercent))
 	}
 
+	// EXTENDED: logistics service charges follow the allowances and charges
+	if is(levelExtended, inv) {
+		for _, ac := range inv.SpecifiedTradeAllowanceCharge {
+			if !ac.LogisticsServiceCharge || !ac.ChargeIndicator {
+				continue
+			}
+			lsc := elt.CreateElement("ram:SpecifiedLogisticsServiceCharge")
+			lsc.CreateElement("ram:Description").SetText(ac.Reason)
+			lsc.CreateElement("ram:AppliedAmount").SetText(ac.ActualAmount.StringFixed(2))
+			att := lsc.CreateElement("ram:AppliedTradeTax")
+			att.CreateElement("ram:TypeCode").SetText(ac.CategoryTradeTaxType)
+			att.CreateElement("ram:CategoryCode").SetText(ac.CategoryTradeTaxCategoryCode)
+			att.CreateElement("ram:RateApplicablePercent").SetText(formatPercent(ac.CategoryTradeTaxRateApplicablePercent))
+		}
+	}
+
 	// BT-20
 	for i := range inv.SpecifiedTradePaymentTerms {
 		spt := elt.CreateElement("ram:SpecifiedTradePaymentTerms")
@@ -643,6 +738,14 @@ func writeCIIramApplicableHeaderTradeSettlement(inv *Invoice, parent *etree.Elem
 		if inv.SpecifiedTradePaymentTerms[i].DirectDebitMandateID != "" {
 			spt.CreateElement("ram:DirectDebitMandateID").SetText(inv.SpecifiedTradePaymentTerms[i].DirectDebitMandateID)
 		}
+		if is(levelExtended, inv) {
+			// EXTENDED: partial payment amount, penalty and discount terms
+			if ppa := inv.SpecifiedTradePaymentTerms[i].PartialPaymentAmount; !ppa.IsZero() {
+				spt.CreateElement("ram:PartialPaymentAmount").SetText(ppa.StringFixed(2))
+			}
+			writeCIIPaymentAdjustmentTerms(spt, "ram:ApplicableTradePaymentPenaltyTerms", "ram:ActualPenaltyAmount", inv.SpecifiedTradePaymentTerms[i].PenaltyTerms)
+			writeCIIPaymentAdjustmentTerms(spt, "ram:ApplicableTradePaymentDiscountTerms", "ram:ActualDiscountAmount", inv.SpecifiedTradePaymentTerms[i].DiscountTerms)
+		}
 	}
 
 	writeCIIramSpecifiedTradeSettlementHeaderMonetarySummation(inv, elt)
@@ -659,6 +762,72 @@ func writeCIIramApplicableHeaderTradeSettlement(inv *Invoice, parent *etree.Elem
 		rstaac := elt.CreateElement("ram:ReceivableSpecifiedTradeAccountingAccount")
 		rstaac.CreateElement("ram:ID").SetText(inv.ReceivableSpecifiedTradeAccountingAccount)
 	}
+
+	// EXTENDED: prepayments deducted in a final invoice
+	if is(levelExtended, inv) {
+		for _, ap := range inv.AdvancePayments {
+			apElt := elt.CreateElement("ram:SpecifiedAdvancePayment")
+			apElt.CreateElement("ram:PaidAmount").SetText(ap.PaidAmount.StringFixed(2))
+			if !ap.ReceivedDate.IsZero() {
+				addTimeCIIQDT(apElt.CreateElement("ram:FormattedReceivedDateTime"), ap.ReceivedDate)
+			}
+			for _, tt := range ap.TradeTaxes {
+				itt := apElt.CreateElement("ram:IncludedTradeTax")
+				itt.CreateElement("ram:CalculatedAmount").SetText(tt.CalculatedAmount.StringFixed(2))
+				typeCode := tt.TypeCode
+				if typeCode == "" {
+					typeCode = "VAT"
+				}
+				itt.CreateElement("ram:TypeCode").SetText(typeCode)
+				if tt.ExemptionReason != "" {
+					itt.CreateElement("ram:ExemptionReason").SetText(tt.ExemptionReason)
+				}
+				if !tt.BasisAmount.IsZero() {
+					itt.CreateElement("ram:BasisAmount").SetText(tt.BasisAmount.StringFixed(2))
+				}
+				itt.CreateElement("ram:CategoryCode").SetText(tt.CategoryCode)
+				if tt.ExemptionReasonCode != "" {
+					itt.CreateElement("ram:ExemptionReasonCode").SetText(tt.ExemptionReasonCode)
+				}
+				itt.CreateElement("ram:RateApplicablePercent").SetText(formatPercent(tt.Percent))
+			}
+			if ap.InvoiceID != "" {
+				ref := apElt.CreateElement("ram:InvoiceSpecifiedReferencedDocument")
+				ref.CreateElement("ram:IssuerAssignedID").SetText(ap.InvoiceID)
+				if !ap.InvoiceDate.IsZero() {
+					addTimeCIIQDT(ref.CreateElement("ram:FormattedIssueDateTime"), ap.InvoiceDate)
+				}
+			}
+		}
+	}
+}
+
+// writeCIIPaymentAdjustmentTerms writes penalty or discount terms. actual is
+// the element name of the penalty or discount amount.
+func writeCIIPaymentAdjustmentTerms(parent *etree.Element, name, actual string, terms *PaymentAdjustmentTerms) {
+	if terms == nil {
+		return
+	}
+	elt := parent.CreateElement(name)
+	if !terms.BasisDate.IsZero() {
+		addTimeCIIUDT(elt.CreateElement("ram:BasisDateTime"), terms.BasisDate)
+	}
+	if !terms.BasisPeriodMeasure.IsZero() {
+		bpm := elt.CreateElement("ram:BasisPeriodMeasure")
+		if terms.BasisPeriodUnit != "" {
+			bpm.CreateAttr("unitCode", terms.BasisPeriodUnit)
+		}
+		bpm.SetText(terms.BasisPeriodMeasure.String())
+	}
+	if !terms.BasisAmount.IsZero() {
+		elt.CreateElement("ram:BasisAmount").SetText(terms.BasisAmount.StringFixed(2))
+	}
+	if !terms.CalculationPercent.IsZero() {
+		elt.CreateElement("ram:CalculationPercent").SetText(formatPercent(terms.CalculationPercent))
+	}
+	if !terms.ActualAmount.IsZero() {
+		elt.CreateElement(actual).SetText(terms.ActualAmount.StringFixed(2))
+	}
 }
 
 func writeCIIrsmSupplyChainTradeTransaction(inv *Invoice, parent *etree.Element) {
@@ -702,7 +871,7 @@ func writeCIIrsmExchangedDocument(inv *Invoice, root *etree.Element) {
 }
 
 // writeCII writes an invoice in CII (Cross Industry Invoice) format used by ZUGFeRD/Factur-X.
-func writeCII(inv *Invoice, writer io.Writer) error {
+func writeCII(inv *Invoice, writer io.Writer, opts *WriteOptions) error {
 	var err error
 
 	doc := etree.NewDocument()
@@ -716,8 +885,10 @@ func writeCII(inv *Invoice, writer io.Writer) error {
 	writeCIIrsmExchangedDocument(inv, root)
 	writeCIIrsmSupplyChainTradeTransaction(inv, root)
 
-	doc.Indent(2)
-	if _, err = doc.WriteTo(writer); err != nil {
+	if err = opts.apply(doc); err != nil {
+		return fmt.Errorf("write CII: %w", err)
+	}
+	if err = opts.writeTo(doc, writer); err != nil {
 		return fmt.Errorf("write CII: failed to write to the writer %w", err)
 	}
 
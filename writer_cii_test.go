@@ -1090,3 +1090,113 @@ func BenchmarkWriteCII(b *testing.B) {
 		b.SetBytes(int64(buf.Len()))
 	}
 }
+
+func TestWrite_Extended(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/extended/zugferd-extended-1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.InvoiceeTradeParty == nil || len(inv.SpecifiedTradePaymentTerms) == 0 || inv.SpecifiedTradePaymentTerms[0].DiscountTerms == nil {
+		t.Fatal("Extended fields of the fixture not parsed")
+	}
+	delivery := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	inv.UltimateShipTo = &Party{Name: "Final Recipient"}
+	inv.ShipFrom = &Party{Name: "Warehouse"}
+	inv.InvoicerTradeParty = &Party{Name: "Billing Service"}
+	inv.DeliveryNoteReferencedDocument = &ReferencedDocument{ID: "DN-1", Date: delivery}
+	inv.TaxCurrencyExchange = &CurrencyExchange{SourceCurrencyCode: "EUR", TargetCurrencyCode: "CHF", ConversionRate: decimal.RequireFromString("0.95"), ConversionRateDate: delivery}
+	inv.SpecifiedTradePaymentTerms[0].PartialPaymentAmount = decimal.RequireFromString("100.00")
+	line := &inv.InvoiceLines[0]
+	line.PackageQuantity = decimal.NewFromInt(2)
+	line.PackageQuantityUnit = "XCT"
+	line.ActualDeliveryDate = delivery
+	line.DespatchAdviceReferencedDocument = &LineReference{ID: "DA-1", LineID: "3", Date: delivery}
+	line.DeliveryNoteReferencedDocument = &LineReference{ID: "DN-1", LineID: "4"}
+
+	var buf bytes.Buffer
+	if err := inv.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := buf.String()
+
+	// The elements must follow the sequence of the CII schema.
+	order := []string{
+		"<ram:BilledQuantity", "<ram:PackageQuantity", "<ram:ActualDeliverySupplyChainEvent", "<ram:DespatchAdviceReferencedDocument", "<ram:DeliveryNoteReferencedDocument",
+		"<ram:ShipToTradeParty", "<ram:UltimateShipToTradeParty", "<ram:ShipFromTradeParty", "<ram:DeliveryNoteReferencedDocument>\n        <ram:IssuerAssignedID>DN-1",
+		"<ram:InvoiceCurrencyCode>", "<ram:InvoicerTradeParty>", "<ram:InvoiceeTradeParty>", "<ram:TaxApplicableTradeCurrencyExchange>", "<ram:SpecifiedTradeSettlementPaymentMeans>",
+		"<ram:SpecifiedTradeAllowanceCharge>", "<ram:SpecifiedLogisticsServiceCharge>", "<ram:PartialPaymentAmount>", "<ram:ApplicableTradePaymentPenaltyTerms>", "<ram:ApplicableTradePaymentDiscountTerms>",
+	}
+	pos := 0
+	for _, s := range order {
+		i := strings.Index(xml[pos:], s)
+		if i < 0 {
+			t.Fatalf("%s missing or out of order", s)
+		}
+		pos += i
+	}
+
+	parsed, err := ParseReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInvoiceEqual(t, inv, parsed)
+}
+
+func TestWrite_LogisticsServiceChargeAllowance(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/extended/zugferd-extended-1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.SpecifiedTradeAllowanceCharge = append(inv.SpecifiedTradeAllowanceCharge, AllowanceCharge{
+		ActualAmount:                          decimal.RequireFromString("5.00"),
+		Reason:                                "Freight discount",
+		CategoryTradeTaxType:                  "VAT",
+		CategoryTradeTaxCategoryCode:          "S",
+		CategoryTradeTaxRateApplicablePercent: decimal.NewFromInt(19),
+		LogisticsServiceCharge:                true,
+	})
+
+	var buf bytes.Buffer
+	if err := inv.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// An allowance must not turn into a charge
+	if strings.Contains(buf.String(), "<ram:Description>Freight discount</ram:Description>") ||
+		!strings.Contains(buf.String(), "<ram:Reason>Freight discount</ram:Reason>") {
+		t.Error("allowance marked as logistics service charge not written as allowance")
+	}
+	_ = inv.Validate()
+	if !hasViolationCode(inv, "BR-USER-11") {
+		t.Errorf("violations = %v, want BR-USER-11", inv.violations)
+	}
+}
+
+func TestWrite_ExtendedFieldsInEN16931(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/extended/zugferd-extended-1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.GuidelineSpecifiedDocumentContextParameter = SpecEN16931
+	inv.InvoiceLines[0].DespatchAdviceReferencedDocument = &LineReference{ID: "DA-1", LineID: "3"}
+
+	var buf bytes.Buffer
+	if err := inv.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := buf.String()
+	for _, s := range []string{"InvoiceeTradeParty", "SpecifiedLogisticsServiceCharge", "ApplicableTradePaymentDiscountTerms", "DespatchAdviceReferencedDocument"} {
+		if strings.Contains(xml, s) {
+			t.Errorf("EN 16931 output contains %s", s)
+		}
+	}
+	// The logistics service charge is written as a document level charge.
+	if !strings.Contains(xml, "<ram:Reason>Transportkosten: Frachbetrag</ram:Reason>") {
+		t.Error("logistics service charge not written as charge")
+	}
+}
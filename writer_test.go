@@ -0,0 +1,203 @@
+package einvoice
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func writeWithOptions(t *testing.T, inv *Invoice, opts *WriteOptions) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := inv.WriteWithOptions(&buf, opts); err != nil {
+		t.Fatalf("WriteWithOptions() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestWriteWithOptions_Default(t *testing.T) {
+	t.Parallel()
+
+	for _, fixture := range []string{"testdata/cii/en16931/CII_example1.xml", "testdata/ubl/invoice/UBL-Invoice-2.1-Example.xml"} {
+		inv, err := ParseXMLFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := inv.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if got := writeWithOptions(t, inv, &WriteOptions{}); got != buf.String() {
+			t.Errorf("%s: zero options differ from Write", fixture)
+		}
+		if got := writeWithOptions(t, inv, nil); got != buf.String() {
+			t.Errorf("%s: nil options differ from Write", fixture)
+		}
+	}
+}
+
+func TestWriteWithOptions_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	opts := &WriteOptions{
+		Compact:        true,
+		XMLDeclaration: true,
+		SchemaLocation: nsCIIRootInvoice + " Factur-X_1.08_EN16931.xsd",
+		Prefixes: map[string]string{
+			nsCIIRootInvoice: "inv",
+			"urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100": "",
+			"urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100":                        "u",
+		},
+	}
+	first := ""
+	for range 5 {
+		inv, err := ParseXMLFile("testdata/cii/en16931/CII_example1.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := writeWithOptions(t, inv, opts)
+		if first == "" {
+			first = got
+		} else if got != first {
+			t.Fatal("output is not deterministic")
+		}
+	}
+}
+
+func TestWriteWithOptions_Format(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compact := writeWithOptions(t, inv, &WriteOptions{Compact: true})
+	if strings.Contains(compact, "\n") {
+		t.Error("compact output contains line breaks")
+	}
+	if got := writeWithOptions(t, inv, &WriteOptions{Indent: -1}); !strings.Contains(got, "\n\t<rsm:ExchangedDocumentContext>") {
+		t.Error("output is not indented with tabs")
+	}
+	if got := writeWithOptions(t, inv, &WriteOptions{Indent: 4}); !strings.Contains(got, "\n    <rsm:ExchangedDocumentContext>") {
+		t.Error("output is not indented with four spaces")
+	}
+	if got := writeWithOptions(t, inv, &WriteOptions{XMLDeclaration: true}); !strings.HasPrefix(got, `<?xml version="1.0" encoding="UTF-8"?>`+"\n<rsm:CrossIndustryInvoice") {
+		t.Errorf("output does not start with the XML declaration: %.60q", got)
+	}
+
+	loc := nsCIIRootInvoice + " CrossIndustryInvoice.xsd"
+	got := writeWithOptions(t, inv, &WriteOptions{SchemaLocation: loc})
+	if !strings.Contains(got, `xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="`+loc+`"`) {
+		t.Error("schema location missing")
+	}
+	if _, err := ParseReader(strings.NewReader(got)); err != nil {
+		t.Errorf("ParseReader() error = %v", err)
+	}
+}
+
+func TestWriteWithOptions_Encoding(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/cii/en16931/CII_example1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.Seller.Name = "Müller & Söhne €"
+
+	got := writeWithOptions(t, inv, &WriteOptions{Encoding: "iso-8859-1"})
+	if !strings.HasPrefix(got, `<?xml version="1.0" encoding="ISO-8859-1"?>`) {
+		t.Errorf("missing declaration: %.60q", got)
+	}
+	if !strings.Contains(got, "M\xfcller &amp; S\xf6hne &#8364;") {
+		t.Error("name not encoded in ISO-8859-1")
+	}
+
+	got = writeWithOptions(t, inv, &WriteOptions{Encoding: "US-ASCII"})
+	if !strings.Contains(got, "M&#252;ller &amp; S&#246;hne &#8364;") {
+		t.Error("name not encoded in US-ASCII")
+	}
+	back, err := ParseReader(strings.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Seller.Name != inv.Seller.Name {
+		t.Errorf("seller name = %q, want %q", back.Seller.Name, inv.Seller.Name)
+	}
+
+	var buf bytes.Buffer
+	if err := inv.WriteWithOptions(&buf, &WriteOptions{Encoding: "EBCDIC"}); !errors.Is(err, ErrWrite) {
+		t.Errorf("unsupported encoding: error = %v, want ErrWrite", err)
+	}
+}
+
+func TestWriteWithOptions_Prefixes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fixture  string
+		prefixes map[string]string
+		want     []string
+	}{
+		{
+			fixture: "testdata/cii/en16931/CII_example1.xml",
+			prefixes: map[string]string{
+				"urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100": "",
+				"urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100":                        "u",
+			},
+			want: []string{`xmlns="urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100"`, "<SellerTradeParty>", "<u:DateTimeString", "<rsm:ExchangedDocument>"},
+		},
+		{
+			fixture: "testdata/ubl/invoice/UBL-Invoice-2.1-Example.xml",
+			prefixes: map[string]string{
+				nsUBLInvoice: "ubl",
+				nsUBLCBC:     "",
+			},
+			want: []string{`<ubl:Invoice xmlns:ubl="` + nsUBLInvoice + `"`, `xmlns="` + nsUBLCBC + `"`, "<ID>TOSL108</ID>", "<cac:AccountingSupplierParty>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			t.Parallel()
+
+			inv, err := ParseXMLFile(tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := writeWithOptions(t, inv, &WriteOptions{Prefixes: tt.prefixes})
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("output does not contain %s", s)
+				}
+			}
+			back, err := ParseReader(strings.NewReader(got))
+			if err != nil {
+				t.Fatalf("ParseReader() error = %v", err)
+			}
+			if back.InvoiceNumber != inv.InvoiceNumber || !back.DuePayableAmount.Equal(inv.DuePayableAmount) || len(back.InvoiceLines) != len(inv.InvoiceLines) {
+				t.Error("invoice changed after writing with custom prefixes")
+			}
+		})
+	}
+}
+
+func TestWriteWithOptions_InvalidPrefixes(t *testing.T) {
+	t.Parallel()
+
+	inv, err := ParseXMLFile("testdata/ubl/invoice/UBL-Invoice-2.1-Example.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, prefixes := range []map[string]string{
+		{nsUBLCBC: ""},    // conflicts with the default namespace of the root
+		{nsUBLCBC: "cac"}, // conflicts with the standard prefix
+		{nsUBLCBC: "1x"},
+		{nsUBLCBC: "xmlfoo"},
+	} {
+		var buf bytes.Buffer
+		if err := inv.WriteWithOptions(&buf, &WriteOptions{Prefixes: prefixes}); !errors.Is(err, ErrWrite) {
+			t.Errorf("prefixes %v: error = %v, want ErrWrite", prefixes, err)
+		}
+	}
+}
@@ -12,7 +12,7 @@ import (
 
 // writeUBL writes an invoice in UBL 2.1 format (Invoice or CreditNote).
 // The document type is determined by the InvoiceTypeCode.
-func writeUBL(inv *Invoice, writer io.Writer) error {
+func writeUBL(inv *Invoice, writer io.Writer, opts *WriteOptions) error {
 	doc := etree.NewDocument()
 
 	// Determine if this is a CreditNote (type code 381) or Invoice
@@ -43,12 +43,15 @@ func writeUBL(inv *Invoice, writer io.Writer) error {
 	writeUBLAllowanceCharge(inv, root, prefix)
 	writeUBLPaymentMeans(inv, root, prefix)
 	writeUBLPaymentTerms(inv, root, prefix)
+	writeUBLTaxExchangeRate(inv, root)
 	writeUBLTaxTotal(inv, root, prefix)
 	writeUBLMonetarySummation(inv, root, prefix)
 	writeUBLLines(inv, root, prefix)
 
-	doc.Indent(2)
-	if _, err := doc.WriteTo(writer); err != nil {
+	if err := opts.apply(doc); err != nil {
+		return fmt.Errorf("write UBL: %w", err)
+	}
+	if err := opts.writeTo(doc, writer); err != nil {
 		return fmt.Errorf("write UBL: failed to write to the writer: %w", err)
 	}
 
@@ -564,6 +567,29 @@ func writeUBLPaymentTerms(inv *Invoice, root *etree.Element, prefix string) {
 	}
 }
 
+// writeUBLTaxExchangeRate writes the exchange rate from the invoice currency
+// to the VAT accounting currency (cac:TaxExchangeRate) with its mathematic
+// operator, Multiply if none is given. Unlike CII, where the exchange rate is
+// only part of the Factur-X EXTENDED profile, cac:TaxExchangeRate belongs to
+// the UBL 2.1 Invoice and CreditNote schemas that every UBL specification
+// identifier uses, so it is written regardless of the profile.
+func writeUBLTaxExchangeRate(inv *Invoice, root *etree.Element) {
+	ce := inv.TaxCurrencyExchange
+	if ce == nil {
+		return
+	}
+	rate := root.CreateElement("cac:TaxExchangeRate")
+	rate.CreateElement("cbc:SourceCurrencyCode").SetText(ce.SourceCurrencyCode)
+	rate.CreateElement("cbc:TargetCurrencyCode").SetText(ce.TargetCurrencyCode)
+	rate.CreateElement("cbc:CalculationRate").SetText(ce.ConversionRate.String())
+	operator := ce.MathematicOperatorCode
+	if operator == "" {
+		operator = "Multiply"
+	}
+	rate.CreateElement("cbc:MathematicOperatorCode").SetText(operator)
+	addTimeUBL(rate, "cbc:Date", ce.ConversionRateDate)
+}
+
 // writeUBLLines writes all invoice line items (BG-25)
 func writeUBLLines(inv *Invoice, root *etree.Element, prefix string) {
 	// Determine line element and quantity element names based on document type